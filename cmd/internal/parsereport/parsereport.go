@@ -0,0 +1,84 @@
+// Package parsereport — вывод ошибок разбора (*der.ParseError) утилитами командной строки: построчный текст
+// со смещением, ASN.1-путём и командой openssl asn1parse или JSON-отчёт {"file", "error"}.
+package parsereport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+)
+
+// AsParseError возвращает *der.ParseError из цепочки err; прочие ошибки оборачиваются с неизвестным смещением.
+func AsParseError(err error) *der.ParseError {
+	var perr *der.ParseError
+	if !errors.As(err, &perr) {
+		perr = &der.ParseError{Offset: -1, Err: err}
+	}
+	return perr
+}
+
+// WriteText печатает ошибку разбора файла file построчно: сообщение с префиксом what, ASN.1-путь, смещение,
+// ожидаемый/фактический тег, причину и команду openssl asn1parse для просмотра места ошибки (inform — DER или PEM).
+func WriteText(w io.Writer, e *der.ParseError, what, file, inform string) {
+	fmt.Fprintf(w, "%s: %v\n", what, e)
+	if e.Path != "" {
+		fmt.Fprintf(w, i18n.T("  путь:      %s\n"), e.Path)
+	}
+	if e.Offset >= 0 {
+		fmt.Fprintf(w, i18n.T("  смещение:  %d (0x%x)\n"), e.Offset, e.Offset)
+	}
+	if e.Expected != "" {
+		fmt.Fprintf(w, i18n.T("  ожидалось: %s\n"), e.Expected)
+	}
+	if e.Actual != "" {
+		fmt.Fprintf(w, i18n.T("  получено:  %s\n"), e.Actual)
+	}
+	if e.Err != nil {
+		fmt.Fprintf(w, i18n.T("  причина:   %v\n"), e.Err)
+	}
+	if e.Offset >= 0 {
+		fmt.Fprintf(w, i18n.T("  просмотр:  openssl asn1parse -inform %s -in %s -i -offset %d\n"), inform, file, e.Offset)
+	}
+}
+
+// WriteJSON пишет JSON-отчёт об ошибке разбора: {"file": ..., "error": {message, offset, path, expected, actual, cause}}.
+func WriteJSON(w io.Writer, e *der.ParseError, file string) error {
+	b, err := json.MarshalIndent(struct {
+		File  string          `json:"file"`
+		Error *der.ParseError `json:"error"`
+	}{file, e}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// Report выводит ошибку разбора err файла file. При jsonFormat отчёт WriteJSON пишется туда же,
+// куда и обычный отчёт (outputPath или stdout); иначе, а также если JSON записать не удалось, — WriteText в stderr.
+func Report(err error, what, file, inform string, jsonFormat bool, outputPath string) {
+	e := AsParseError(err)
+	if jsonFormat {
+		var werr error
+		if outputPath != "" {
+			var f *os.File
+			if f, werr = os.Create(outputPath); werr == nil {
+				werr = WriteJSON(f, e, file)
+				if cerr := f.Close(); werr == nil {
+					werr = cerr
+				}
+			}
+		} else {
+			werr = WriteJSON(os.Stdout, e, file)
+		}
+		if werr == nil {
+			return
+		}
+	}
+	WriteText(os.Stderr, e, what, file, inform)
+}
@@ -0,0 +1,35 @@
+package parsereport
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+)
+
+// TestWrite проверяет текстовый и JSON-вывод ошибки разбора.
+func TestWrite(t *testing.T) {
+	perr := AsParseError(&der.ParseError{Offset: 16, Path: "PFX.authSafe", Expected: "SEQUENCE", Actual: "SET", Err: der.ErrTruncated})
+	var text bytes.Buffer
+	WriteText(&text, perr, "разбор", "r.p12", "DER")
+	for _, want := range []string{"разбор: PFX.authSafe", "16 (0x10)", "SEQUENCE", "openssl asn1parse -inform DER -in r.p12 -i -offset 16"} {
+		if !bytes.Contains(text.Bytes(), []byte(want)) {
+			t.Errorf("WriteText: нет %q в\n%s", want, text.String())
+		}
+	}
+	var js bytes.Buffer
+	if err := WriteJSON(&js, perr, "r.p12"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(js.Bytes(), []byte(`"file": "r.p12"`)) || !bytes.Contains(js.Bytes(), []byte(`"offset": 16`)) {
+		t.Errorf("WriteJSON: %s", js.String())
+	}
+
+	plain := AsParseError(errors.New("boom"))
+	text.Reset()
+	WriteText(&text, plain, "разбор", "r.p12", "DER")
+	if plain.Offset != -1 || bytes.Contains(text.Bytes(), []byte("openssl")) {
+		t.Errorf("AsParseError(boom): %+v\n%s", plain, text.String())
+	}
+}
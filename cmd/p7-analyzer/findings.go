@@ -0,0 +1,94 @@
+// findings.go — -format sarif|junit p7-analyzer: правила (разбор, сертификат подписанта) и результаты проверок файла для CI.
+package main
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sgw-registry/registry-analyzer/internal/asn1schema"
+	"github.com/sgw-registry/registry-analyzer/internal/asn1tree"
+	"github.com/sgw-registry/registry-analyzer/internal/cms"
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+	"github.com/sgw-registry/registry-analyzer/internal/findings"
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+)
+
+// ID правил для -format sarif|junit.
+const (
+	ruleParse      = "cms/parse"
+	ruleSignerCert = "cms/signerCert"
+)
+
+// cmsRules — правила p7-analyzer.
+var cmsRules = []findings.Rule{
+	{ID: ruleParse, Name: "Parse", Description: "Файл разбирается как CMS ContentInfo с SignedData", Level: findings.LevelError},
+	{ID: ruleSignerCert, Name: "SignerCert", Description: "Сертификат подписанта найден в certificates или в eContent (PEM) по SID", Level: findings.LevelError},
+}
+
+// writeFindings выводит результаты проверок файла в SARIF или JUnit XML (format) и возвращает код выхода:
+// 1 — файл не разобран, 2 — сертификат подписанта не найден, иначе 0. Смещения — в DER (для PEM — в декодированном).
+func writeFindings(path string, data []byte, limits cms.Limits, format, outputPath string) int {
+	report := &findings.Report{Tool: "p7-analyzer", Rules: cmsRules}
+	derData := data
+	var c *cms.Container
+	var err error
+	if isPEM(data) {
+		if block, _ := pem.Decode(data); block != nil {
+			derData = block.Bytes
+		}
+		c, err = cms.ParseCMSFromPEMWithLimits(data, limits)
+	} else {
+		c, err = cms.ParseCMSWithLimits(data, limits)
+	}
+	code := 0
+	if err != nil {
+		var perr *der.ParseError
+		if !errors.As(err, &perr) {
+			perr = &der.ParseError{Offset: -1, Err: err}
+		}
+		report.Results = append(report.Results, findings.Result{RuleID: ruleParse, Message: perr.Error(), File: path, Path: perr.Path, Offset: perr.Offset})
+		code = 1
+	} else {
+		report.Results = append(report.Results, findings.Result{RuleID: ruleParse, OK: true, File: path, Path: "ContentInfo", Offset: 0,
+			Message: i18n.Sprintf("сертификатов: %d, в eContent: %d, подписантов: %d", len(c.Certificates), len(c.EContentCerts), len(c.SignerInfos))})
+		// Как в BuildReport: найденный сертификат относится к первому SignerInfo.
+		for i := range c.SignerInfos {
+			res := findings.Result{RuleID: ruleSignerCert, File: path, Path: fmt.Sprintf("ContentInfo.content.signerInfos[%d]", i+1), Offset: -1,
+				Message: i18n.T("сертификат подписанта не найден в certificates и eContent")}
+			if i == 0 && c.SignerCert != nil {
+				res.OK, res.Message = true, c.SignerCert.Subject.String()
+			} else {
+				code = 2
+			}
+			report.Results = append(report.Results, res)
+		}
+		if s, err := asn1schema.RegistrySchema(); err == nil {
+			nodes, _ := asn1tree.Parse(derData, 0, limits.MaxDepth)
+			s.Annotate(nodes, "ContentInfo")
+			findings.Locate(report.Results, nodes)
+		}
+	}
+
+	w := io.Writer(os.Stdout)
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("запись %s: %v\n"), outputPath, err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	write := report.WriteSARIF
+	if format == "junit" {
+		write = report.WriteJUnit
+	}
+	if err := write(w); err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("запись отчёта: %v\n"), err)
+		return 1
+	}
+	return code
+}
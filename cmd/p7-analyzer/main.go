@@ -1,6 +1,6 @@
 // Пакет main — точка входа утилиты анализа контейнеров CMS/PKCS#7 (.p7).
 //
-// p7-analyzer читает файл .p7 (PEM с -----BEGIN CMS-----/-----END CMS----- или DER),
+// p7-analyzer читает файл .p7 или набор сертификатов .p7b (PEM с -----BEGIN CMS-----/-----BEGIN PKCS7----- или DER),
 // разбирает ContentInfo → SignedData, извлекает сертификаты из certificates и из eContent (PEM),
 // выводит отчёт и поддерживает экспорт сертификатов в PEM.
 //
@@ -9,47 +9,85 @@ package main
 
 import (
 	"bytes"
+	"encoding/pem"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/sgw-registry/registry-analyzer/cmd/internal/parsereport"
+	"github.com/sgw-registry/registry-analyzer/internal/asn1schema"
+	"github.com/sgw-registry/registry-analyzer/internal/asn1tree"
 	"github.com/sgw-registry/registry-analyzer/internal/cms"
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+	"github.com/sgw-registry/registry-analyzer/internal/htmlreport"
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
 )
 
 func main() {
-	format := flag.String("format", "text", "Формат вывода: text, json, pem")
-	outputPath := flag.String("output", "", "Записать вывод в файл (по умолчанию — stdout)")
-	exportCertsDir := flag.String("export-certs-dir", "", "Выгрузить каждый сертификат из SignedData.certificates в отдельный PEM в директорию (cert-1.pem, ...)")
-	exportAllCerts := flag.Bool("export-all-certs", false, "Выгрузить все сертификаты (SignedData + eContent) в один PEM с именем контейнера (file.p7 → file.pem)")
-	exportEContentCertsDir := flag.String("export-econtent-certs-dir", "", "Выгрузить каждый сертификат из eContent в отдельный PEM в директорию (econtent-1.pem, ...)")
-	exportSignerCert := flag.Bool("export-signer-cert", false, "Выгрузить сертификат подписанта в PEM (file.p7 → file_signer.pem)")
-	noColor := flag.Bool("no-color", false, "Отключить цветной вывод и иконки")
-	colorFlag := flag.String("color", "auto", "Цвет: auto (только TTY), always, never")
+	// Язык сообщений выбирается до объявления флагов, чтобы справка -h выводилась уже на нём.
+	if err := i18n.Init(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	format := flag.String("format", "text", i18n.T("Формат вывода: text, json, pem, asn1 (дерево DER с полями ContentInfo/SignedData), html (самодостаточная HTML-страница), sarif, junit (результаты проверок для CI)"))
+	outputPath := flag.String("output", "", i18n.T("Записать вывод в файл (по умолчанию — stdout)"))
+	exportCertsDir := flag.String("export-certs-dir", "", i18n.T("Выгрузить каждый сертификат из SignedData.certificates в отдельный PEM в директорию (cert-1.pem, ...)"))
+	exportAllCerts := flag.Bool("export-all-certs", false, i18n.T("Выгрузить все сертификаты (SignedData + eContent) в один PEM с именем контейнера (file.p7 → file.pem)"))
+	exportEContentCertsDir := flag.String("export-econtent-certs-dir", "", i18n.T("Выгрузить каждый сертификат из eContent в отдельный PEM в директорию (econtent-1.pem, ...)"))
+	exportSignerCert := flag.Bool("export-signer-cert", false, i18n.T("Выгрузить сертификат подписанта в PEM (file.p7 → file_signer.pem)"))
+	noColor := flag.Bool("no-color", false, i18n.T("Отключить цветной вывод и иконки"))
+	colorFlag := flag.String("color", "auto", i18n.T("Цвет: auto (только TTY), always, never"))
+	maxSize := flag.Int("max-size", cms.DefaultLimits.MaxInputSize, i18n.T("Максимальный размер контейнера (DER), байт; больший файл не разбирается"))
+	flag.String("lang", "", i18n.T(i18n.FlagUsage))
 	flag.Parse()
 
 	if flag.NArg() < 1 {
-		fmt.Fprintf(os.Stderr, "Использование: %s [опции] <файл.p7>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, i18n.T("Использование: %s [опции] <файл.p7|файл.p7b>\n"), os.Args[0])
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
 	path := flag.Arg(0)
-	data, err := os.ReadFile(path)
+	// PEM длиннее DER (base64 и заголовки): файл читается с запасом, лимит DER проверяет разборщик.
+	data, err := der.ReadFile(path, 2*int64(*maxSize))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "чтение файла: %v\n", err)
+		fmt.Fprintf(os.Stderr, i18n.T("чтение файла: %v\n"), err)
 		os.Exit(1)
 	}
 
 	// Разбор: PEM (BEGIN CMS / PKCS7) или сырой DER
+	limits := cms.DefaultLimits
+	limits.MaxInputSize = *maxSize
+
+	// Дерево ASN.1 выводится до разбора (для PEM — по DER из первого блока): работает и для повреждённого файла.
+	if *format == "asn1" {
+		derData := data
+		if block, _ := pem.Decode(data); block != nil {
+			derData = block.Bytes
+		}
+		useColor := !*noColor && (*colorFlag == "always" || (*colorFlag != "never" && isTerminal(os.Stdout)))
+		os.Exit(asn1tree.WriteTree(derData, asn1schema.RegistrySchema, "ContentInfo", limits.MaxDepth, useColor, *outputPath))
+	}
+
+	// Результаты проверок (разбор, сертификат подписанта) для CI; ошибка разбора — тоже результат, а не сообщение в stderr.
+	if *format == "sarif" || *format == "junit" {
+		os.Exit(writeFindings(path, data, limits, *format, *outputPath))
+	}
+
 	var container *cms.Container
 	if isPEM(data) {
-		container, err = cms.ParseCMSFromPEM(data)
+		container, err = cms.ParseCMSFromPEMWithLimits(data, limits)
 	} else {
-		container, err = cms.ParseCMS(data)
+		container, err = cms.ParseCMSWithLimits(data, limits)
 	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "разбор CMS: %v\n", err)
+		inform := "DER"
+		if isPEM(data) {
+			inform = "PEM"
+		}
+		parsereport.Report(err, i18n.T("разбор CMS"), path, inform, *format == "json", *outputPath)
 		os.Exit(1)
 	}
 
@@ -62,7 +100,7 @@ func main() {
 	// Экспорты
 	if *exportCertsDir != "" {
 		if err := os.MkdirAll(*exportCertsDir, 0755); err != nil {
-			fmt.Fprintf(os.Stderr, "создание директории %s: %v\n", *exportCertsDir, err)
+			fmt.Fprintf(os.Stderr, i18n.T("создание директории %s: %v\n"), *exportCertsDir, err)
 			os.Exit(1)
 		}
 		n, err := container.ExportCertsToDir(*exportCertsDir)
@@ -70,7 +108,7 @@ func main() {
 			fmt.Fprintf(os.Stderr, "export-certs-dir: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Fprintf(os.Stderr, "Выгружено %d сертификатов из SignedData в %s\n", n, *exportCertsDir)
+		fmt.Fprintf(os.Stderr, i18n.T("Выгружено %d сертификатов из SignedData в %s\n"), n, *exportCertsDir)
 	}
 	if *exportAllCerts {
 		outPath := filepath.Join(dir, baseName+".pem")
@@ -79,11 +117,11 @@ func main() {
 			os.Exit(1)
 		}
 		total := len(container.Certificates) + len(container.EContentCerts)
-		fmt.Fprintf(os.Stderr, "Все сертификаты (%d шт.) записаны в %s\n", total, outPath)
+		fmt.Fprintf(os.Stderr, i18n.T("Все сертификаты (%d шт.) записаны в %s\n"), total, outPath)
 	}
 	if *exportEContentCertsDir != "" {
 		if err := os.MkdirAll(*exportEContentCertsDir, 0755); err != nil {
-			fmt.Fprintf(os.Stderr, "создание директории %s: %v\n", *exportEContentCertsDir, err)
+			fmt.Fprintf(os.Stderr, i18n.T("создание директории %s: %v\n"), *exportEContentCertsDir, err)
 			os.Exit(1)
 		}
 		n, err := container.ExportEContentCertsToDir(*exportEContentCertsDir)
@@ -91,14 +129,14 @@ func main() {
 			fmt.Fprintf(os.Stderr, "export-econtent-certs-dir: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Fprintf(os.Stderr, "Выгружено %d сертификатов из eContent в %s\n", n, *exportEContentCertsDir)
+		fmt.Fprintf(os.Stderr, i18n.T("Выгружено %d сертификатов из eContent в %s\n"), n, *exportEContentCertsDir)
 	}
 	if *exportSignerCert {
 		outPath := filepath.Join(dir, baseName+"_signer.pem")
 		if err := container.ExportSignerCert(outPath); err != nil {
-			fmt.Fprintf(os.Stderr, "export-signer-cert: %v (подписант не найден среди certificates/eContent)\n", err)
+			fmt.Fprintf(os.Stderr, i18n.T("export-signer-cert: %v (подписант не найден среди certificates/eContent)\n"), err)
 		} else {
-			fmt.Fprintf(os.Stderr, "Сертификат подписанта записан в %s\n", outPath)
+			fmt.Fprintf(os.Stderr, i18n.T("Сертификат подписанта записан в %s\n"), outPath)
 		}
 	}
 
@@ -116,14 +154,21 @@ func main() {
 		}
 	case "pem":
 		out = container.ToAllCertsPEM()
+	case "html":
+		var buf bytes.Buffer
+		if err := htmlreport.Write(&buf, container.HTMLReport(path, time.Now())); err != nil {
+			fmt.Fprintf(os.Stderr, "HTML: %v\n", err)
+			os.Exit(1)
+		}
+		out = buf.Bytes()
 	default:
-		fmt.Fprintf(os.Stderr, "неизвестный формат: %s\n", *format)
+		fmt.Fprintf(os.Stderr, i18n.T("неизвестный формат: %s\n"), *format)
 		os.Exit(1)
 	}
 
 	if *outputPath != "" {
 		if err := os.WriteFile(*outputPath, out, 0644); err != nil {
-			fmt.Fprintf(os.Stderr, "запись %s: %v\n", *outputPath, err)
+			fmt.Fprintf(os.Stderr, i18n.T("запись %s: %v\n"), *outputPath, err)
 			os.Exit(1)
 		}
 	} else {
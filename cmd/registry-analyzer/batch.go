@@ -0,0 +1,312 @@
+// batch.go — пакетный режим registry-analyzer (-format ndjson|csv): разворачивание файлов, масок и директорий,
+// параллельный разбор (-jobs) и запись по строке на контейнер в исходном порядке файлов.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+	"github.com/sgw-registry/registry-analyzer/internal/registry"
+)
+
+// batchOptions — параметры пакетного анализа (-format ndjson|csv).
+type batchOptions struct {
+	format    string // ndjson, csv; sarif, junit — findingsMain
+	jobs      int
+	limits    registry.Limits
+	verify    bool
+	verifyOpt registry.VerifyOptions
+	validate  bool
+	password  keystorePassword // пароль хранилищ PKCS#12 (-password, -empty-password)
+}
+
+// batchRecord — одна запись пакетного отчёта на файл. При ошибке чтения или разбора заполнены только File и Error.
+type batchRecord struct {
+	File                  string          `json:"file"`
+	Error                 *der.ParseError `json:"error,omitempty"`
+	VIN                   string          `json:"vin,omitempty"`
+	VER                   string          `json:"ver,omitempty"`
+	UID                   string          `json:"uid,omitempty"`
+	Signer                string          `json:"signer,omitempty"`
+	CertificateCount      int             `json:"certificateCount"`
+	SafeBagCount          int             `json:"safeBagCount"`
+	EarliestExpiry        string          `json:"earliestExpiry,omitempty"` // RFC3339
+	EarliestExpirySubject string          `json:"earliestExpirySubject,omitempty"`
+	Verified              *bool           `json:"verified,omitempty"`    // -verify
+	SchemaValid           *bool           `json:"schemaValid,omitempty"` // -validate
+	Kind                  string          `json:"kind,omitempty"`        // registry.KindRegistry или KindKeystore
+}
+
+// csvHeader — столбцы CSV; совпадают с ключами NDJSON, error — текст ошибки.
+var csvHeader = []string{"file", "error", "vin", "ver", "uid", "signer", "certificateCount", "safeBagCount",
+	"earliestExpiry", "earliestExpirySubject", "verified", "schemaValid", "kind"}
+
+// batchMain — точка входа пакетного режима: раскрывает аргументы, пишет отчёт в outputPath (или stdout)
+// и возвращает код выхода runBatch.
+func batchMain(args []string, recursive bool, outputPath string, opt batchOptions) int {
+	if exportFlagsSet("-format " + opt.format) {
+		return 1
+	}
+	files, err := expandInputs(args, recursive)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	w := io.Writer(os.Stdout)
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("запись в файл: %v\n"), err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	code, err := runBatch(w, files, opt)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("запись отчёта: %v\n"), err)
+		return 1
+	}
+	if outputPath != "" {
+		fmt.Fprintf(os.Stderr, i18n.T("Отчёт по %d файлам записан в %s\n"), len(files), outputPath)
+	}
+	return code
+}
+
+// exportFlagsSet сообщает в stderr о флагах -export-*, которые не поддерживаются в режиме mode ("-format csv")
+// с несколькими файлами.
+func exportFlagsSet(mode string) bool {
+	var exportFlags []string
+	flag.Visit(func(f *flag.Flag) {
+		if strings.HasPrefix(f.Name, "export-") {
+			exportFlags = append(exportFlags, "-"+f.Name)
+		}
+	})
+	if len(exportFlags) > 0 {
+		fmt.Fprintf(os.Stderr, i18n.T("%s не поддерживается с %s\n"), strings.Join(exportFlags, ", "), mode)
+		return true
+	}
+	return false
+}
+
+// expandInputs раскрывает аргументы командной строки в список файлов: маски (*, ?, [...]) — через filepath.Glob,
+// директории — файлы *.p12 и *.pfx (с -recursive — во всех поддиректориях), остальное — как есть. Повторы убираются.
+// Маска без совпадений возвращается как есть, чтобы в отчёте появилась запись об ошибке.
+func expandInputs(args []string, recursive bool) ([]string, error) {
+	var out []string
+	seen := make(map[string]bool)
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	for _, arg := range args {
+		if strings.ContainsAny(arg, "*?[") {
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, i18n.Errorf("маска %s: %w", arg, err)
+			}
+			if len(matches) == 0 {
+				add(arg)
+			}
+			for _, m := range matches {
+				if info, err := os.Stat(m); err == nil && info.IsDir() {
+					continue
+				}
+				add(m)
+			}
+			continue
+		}
+		info, err := os.Stat(arg)
+		if err != nil || !info.IsDir() {
+			add(arg)
+			continue
+		}
+		files, err := registryFilesInDir(arg, recursive)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			add(f)
+		}
+	}
+	return out, nil
+}
+
+// registryFilesInDir возвращает отсортированный список файлов *.p12 и *.pfx (без учёта регистра) в директории.
+func registryFilesInDir(dir string, recursive bool) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != dir && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(p); strings.EqualFold(ext, ".p12") || strings.EqualFold(ext, ".pfx") {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, i18n.Errorf("обход %s: %w", dir, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// analyzeFile читает и разбирает один контейнер; ошибки становятся записью, а не завершением программы.
+func analyzeFile(path string, opt batchOptions) batchRecord {
+	rec := batchRecord{File: path}
+	fail := func(err error) batchRecord {
+		var perr *der.ParseError
+		if !errors.As(err, &perr) {
+			perr = &der.ParseError{Offset: -1, Err: err}
+		}
+		rec.Error = perr
+		return rec
+	}
+	data, err := der.ReadFile(path, int64(opt.limits.MaxInputSize))
+	if err != nil {
+		return fail(err)
+	}
+	if opt.validate {
+		v, err := validateSchema(data, opt.limits.MaxDepth)
+		if err != nil {
+			return fail(err)
+		}
+		rec.SchemaValid = &v.Valid
+	}
+	c, err := opt.password.parse(data, opt.limits)
+	if err != nil {
+		return fail(err)
+	}
+	rec.Kind = c.Kind
+	s := c.Summary()
+	rec.VIN, rec.VER, rec.UID, rec.Signer = s.VIN, s.VER, s.UID, s.Signer
+	rec.CertificateCount, rec.SafeBagCount = s.CertificateCount, s.SafeBagCount
+	if !s.EarliestExpiry.IsZero() {
+		rec.EarliestExpiry = s.EarliestExpiry.UTC().Format(time.RFC3339)
+		rec.EarliestExpirySubject = s.EarliestExpirySubject
+	}
+	if opt.verify {
+		ok := c.MacVerified() // хранилище PKCS#12: проверяется MAC
+		if c.Kind != registry.KindKeystore {
+			ok = registry.VerifyOK(c.Verify(opt.verifyOpt))
+		}
+		rec.Verified = &ok
+	}
+	return rec
+}
+
+// runBatch разбирает files в opt.jobs горутинах и пишет записи в w в порядке files (по мере готовности).
+// Возвращает код выхода: 1 — есть файлы с ошибкой, 2 — не прошла -verify, 3 — несоответствия -validate, иначе 0.
+func runBatch(w io.Writer, files []string, opt batchOptions) (int, error) {
+	results := make([]chan batchRecord, len(files))
+	for i := range results {
+		results[i] = make(chan batchRecord, 1)
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range max(opt.jobs, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] <- analyzeFile(files[i], opt)
+			}
+		}()
+	}
+	// done останавливает раздачу файлов при досрочном выходе (ошибка записи): воркеры дорабатывают
+	// текущие файлы, и wg.Wait не ждёт разбора оставшихся.
+	done := make(chan struct{})
+	go func() {
+		defer close(next)
+		for i := range files {
+			select {
+			case next <- i:
+			case <-done:
+				return
+			}
+		}
+	}()
+	defer wg.Wait()
+	defer close(done)
+
+	var cw *csv.Writer
+	enc := json.NewEncoder(w)
+	if opt.format == "csv" {
+		cw = csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return 1, err
+		}
+	}
+	failed, unverified, invalid := false, false, false
+	for i := range files {
+		rec := <-results[i]
+		failed = failed || rec.Error != nil
+		unverified = unverified || (rec.Verified != nil && !*rec.Verified)
+		invalid = invalid || (rec.SchemaValid != nil && !*rec.SchemaValid)
+		var err error
+		if cw != nil {
+			err = cw.Write(rec.csvRow())
+			cw.Flush()
+			if err == nil {
+				err = cw.Error()
+			}
+		} else {
+			err = enc.Encode(rec)
+		}
+		if err != nil {
+			return 1, err
+		}
+	}
+	switch {
+	case failed:
+		return 1, nil
+	case unverified:
+		return 2, nil
+	case invalid:
+		return 3, nil
+	}
+	return 0, nil
+}
+
+func (r batchRecord) csvRow() []string {
+	errText := ""
+	if r.Error != nil {
+		errText = r.Error.Error()
+	}
+	optBool := func(b *bool) string {
+		if b == nil {
+			return ""
+		}
+		return strconv.FormatBool(*b)
+	}
+	counts := func(n int) string {
+		if r.Error != nil {
+			return ""
+		}
+		return strconv.Itoa(n)
+	}
+	return []string{r.File, errText, r.VIN, r.VER, r.UID, r.Signer, counts(r.CertificateCount), counts(r.SafeBagCount),
+		r.EarliestExpiry, r.EarliestExpirySubject, optBool(r.Verified), optBool(r.SchemaValid), r.Kind}
+}
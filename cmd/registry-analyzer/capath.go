@@ -0,0 +1,65 @@
+// capath.go — -export-capath: директория для OpenSSL -CApath с сертификатами в PEM, ссылками subject_hash.N
+// как у c_rehash и manifest.json с ролями.
+package main
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+	"github.com/sgw-registry/registry-analyzer/internal/registry"
+)
+
+// capathManifest — имя манифеста в директории -export-capath; OpenSSL читает только файлы вида hash.N.
+const capathManifest = "manifest.json"
+
+// capathLink — имя ссылки, которую создают c_rehash и openssl rehash (hash.N для сертификатов).
+var capathLink = regexp.MustCompile(`^[0-9a-f]{8}\.[0-9]+$`)
+
+// exportCAPathDir записывает сертификаты контейнера в директорию dir для -CApath: PEM-файлы, относительные
+// символические ссылки subject_hash.N на них и manifest.json (ссылка, файл, subject, роли). Прежние ссылки hash.N
+// удаляются, как это делает openssl rehash, чтобы не осталось ссылок на сертификаты прошлой выгрузки.
+// Возвращает число сертификатов.
+func exportCAPathDir(c *registry.Container, dir string) (int, error) {
+	entries, err := c.CAPathEntries()
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) == 0 {
+		return 0, i18n.Errorf("в контейнере нет сертификатов X.509")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+	old, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	for _, de := range old {
+		if de.Type()&os.ModeSymlink != 0 && capathLink.MatchString(de.Name()) {
+			if err := os.Remove(filepath.Join(dir, de.Name())); err != nil {
+				return 0, err
+			}
+		}
+	}
+	for _, e := range entries {
+		path := filepath.Join(dir, e.File)
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: e.CertDER}), 0644); err != nil {
+			return 0, err
+		}
+		if err := os.Symlink(e.File, filepath.Join(dir, e.Link)); err != nil {
+			return 0, err
+		}
+	}
+	manifest, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, capathManifest), append(manifest, '\n'), 0644); err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
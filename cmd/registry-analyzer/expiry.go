@@ -0,0 +1,207 @@
+// expiry.go — режим -expiring-within: сроки сертификатов и периодов ролей из файлов, масок и директорий
+// одним списком по дате (text, json, ndjson) и коды выхода для мониторинга.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+	"github.com/sgw-registry/registry-analyzer/internal/registry"
+)
+
+// Коды выхода режима -expiring-within (1 — как обычно, ошибка чтения или разбора).
+const (
+	exitExpiring = 4 // есть сроки, истекающие в пределах окна
+	exitExpired  = 5 // есть истёкшие сроки
+)
+
+// expiryRecord — элемент отчёта -expiring-within: срок из registry.Expiring с файлом и числом оставшихся дней,
+// либо ошибка чтения/разбора файла (заполнены только File и Error).
+type expiryRecord struct {
+	File  string          `json:"file"`
+	Error *der.ParseError `json:"error,omitempty"`
+	*registry.ExpiryItem
+	DaysLeft *int `json:"daysLeft,omitempty"`
+}
+
+// parseWindow разбирает окно -expiring-within: дни ("30d"), недели ("2w") или длительность Go ("36h").
+func parseWindow(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	var d time.Duration
+	if unit != 0 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil {
+			return 0, i18n.Errorf("окно %q: ожидается число дней (30d), недель (2w) или длительность (36h)", s)
+		}
+		d = time.Duration(n) * unit
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, i18n.Errorf("окно %q: ожидается число дней (30d), недель (2w) или длительность (36h)", s)
+		}
+	}
+	if d < 0 {
+		return 0, i18n.Errorf("окно %q: отрицательная длительность", s)
+	}
+	return d, nil
+}
+
+// expiryMain — режим -expiring-within: для файлов, масок и директорий (как в пакетном режиме) собирает
+// сертификаты и периоды ролей, истекающие в пределах окна, выводит их одним списком по дате
+// (format: text, json, ndjson) и возвращает код выхода: 1 — есть неразобранные файлы, exitExpired — есть истёкшие,
+// exitExpiring — есть истекающие, иначе 0.
+func expiryMain(args []string, recursive bool, outputPath, window, format string, limits registry.Limits, password keystorePassword, useColor bool) int {
+	within, err := parseWindow(window)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if format != "text" && format != "json" && format != "ndjson" {
+		fmt.Fprintf(os.Stderr, i18n.T("-expiring-within поддерживает -format text, json или ndjson, не %s\n"), format)
+		return 1
+	}
+	if exportFlagsSet("-expiring-within") {
+		return 1
+	}
+	files, err := expandInputs(args, recursive)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	now := time.Now()
+	var items, failures []expiryRecord
+	for _, path := range files {
+		c, err := parseFile(path, limits, password)
+		if err != nil {
+			var perr *der.ParseError
+			if !errors.As(err, &perr) {
+				perr = &der.ParseError{Offset: -1, Err: err}
+			}
+			failures = append(failures, expiryRecord{File: path, Error: perr})
+			continue
+		}
+		for _, item := range c.Expiring(now, within) {
+			days := int(item.NotAfter.Sub(now).Hours() / 24)
+			items = append(items, expiryRecord{File: path, ExpiryItem: &item, DaysLeft: &days})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].NotAfter.Before(items[j].NotAfter) })
+
+	w := io.Writer(os.Stdout)
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("запись в файл: %v\n"), err)
+			return 1
+		}
+		defer f.Close()
+		w, useColor = f, false
+	}
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(append(items, failures...))
+	case "ndjson":
+		enc := json.NewEncoder(w)
+		for _, r := range append(items, failures...) {
+			if err = enc.Encode(r); err != nil {
+				break
+			}
+		}
+	default:
+		var sb strings.Builder
+		expiryTextOutput(&sb, items, failures, window, now.Add(within), useColor)
+		_, err = io.WriteString(w, sb.String())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("запись отчёта: %v\n"), err)
+		return 1
+	}
+	if outputPath != "" {
+		fmt.Fprintf(os.Stderr, i18n.T("Сроки по %d файлам записаны в %s\n"), len(files), outputPath)
+	}
+
+	code := 0
+	for _, r := range items {
+		if r.Status == registry.ExpiryExpired {
+			code = exitExpired
+			break
+		}
+		code = exitExpiring
+	}
+	if len(failures) > 0 {
+		code = 1
+	}
+	return code
+}
+
+// parseFile читает и разбирает один контейнер (хранилище PKCS#12 — с паролем password).
+func parseFile(path string, limits registry.Limits, password keystorePassword) (*registry.Container, error) {
+	data, err := der.ReadFile(path, int64(limits.MaxInputSize))
+	if err != nil {
+		return nil, err
+	}
+	return password.parse(data, limits)
+}
+
+// expiryKinds — подписи источников сроков для текстового отчёта.
+var expiryKinds = map[string]string{
+	registry.ExpirySigner:  "подписант",
+	registry.ExpiryChain:   "цепочка",
+	registry.ExpirySafeBag: "мешок",
+	registry.ExpiryRole:    "роль",
+}
+
+func expiryTextOutput(sb *strings.Builder, items, failures []expiryRecord, window string, deadline time.Time, useColor bool) {
+	c := func(code string) string {
+		if useColor {
+			return code
+		}
+		return ""
+	}
+	title := i18n.Sprintf("Сроки, истекающие в течение %s (до %s)", window, deadline.Format("2006-01-02"))
+	if useColor {
+		sb.WriteString(fmt.Sprintf("%s%s %s%s\n", registry.Bold, registry.IconTime, title, registry.Reset))
+	} else {
+		sb.WriteString("=== " + title + " ===\n")
+	}
+	if len(items) == 0 {
+		sb.WriteString(i18n.Sprintf("  %sистекающих сроков нет%s\n", c(registry.Green), c(registry.Reset)))
+	}
+	for _, r := range items {
+		status, color := "EXPIRING", registry.Yellow
+		if r.Status == registry.ExpiryExpired {
+			status, color = "EXPIRED", registry.Red
+		}
+		what := r.Subject
+		if r.Kind == registry.ExpiryRole || (r.Kind == registry.ExpirySafeBag && r.Role != "") {
+			what = strings.TrimSpace(r.Role + " " + r.Subject)
+		}
+		if r.Serial != "" {
+			what += " (serial " + r.Serial + ")"
+		}
+		sb.WriteString(i18n.Sprintf("  %s%-8s%s %s %5d дн.  %s  %s[%d] %s%s %s\n", c(color), status, c(registry.Reset),
+			r.NotAfter.UTC().Format("2006-01-02 15:04"), *r.DaysLeft, r.File, c(registry.Dim), r.Index, i18n.T(expiryKinds[r.Kind]), c(registry.Reset), what))
+	}
+	for _, r := range failures {
+		sb.WriteString(fmt.Sprintf("  %sERROR%s    %s: %v\n", c(registry.Red), c(registry.Reset), r.File, r.Error))
+	}
+}
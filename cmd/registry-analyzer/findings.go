@@ -0,0 +1,185 @@
+// findings.go — -format sarif|junit: правила registry-analyzer (разбор, -validate, -verify, MAC хранилища)
+// и результаты проверок по каждому файлу для CI.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sgw-registry/registry-analyzer/internal/asn1schema"
+	"github.com/sgw-registry/registry-analyzer/internal/asn1tree"
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+	"github.com/sgw-registry/registry-analyzer/internal/findings"
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+	"github.com/sgw-registry/registry-analyzer/internal/registry"
+)
+
+// ID правил для -format sarif|junit.
+const (
+	ruleParse  = "registry/parse"
+	ruleSchema = "registry/schema"
+	ruleVerify = "registry/verify/" // + имя проверки registry.Check*
+	ruleMac    = "registry/mac"
+)
+
+// registryRules — правила registry-analyzer: разбор, -validate и проверки -verify.
+var registryRules = []findings.Rule{
+	{ID: ruleParse, Name: "Parse", Description: "Контейнер читается и разбирается как ATOM-PKCS12-REGISTRY или хранилище PKCS#12", Level: findings.LevelError},
+	{ID: ruleSchema, Name: "Schema", Description: "DER контейнера соответствует модулю registry.asn1 (-validate)", Level: findings.LevelError},
+	{ID: ruleVerify + registry.CheckSignerCert, Name: "SignerCert", Description: "Сертификат подписанта найден в SignedData.certificates по SID", Level: findings.LevelError},
+	{ID: ruleVerify + registry.CheckContentType, Name: "ContentType", Description: "Атрибут contentType совпадает с eContentType", Level: findings.LevelError},
+	{ID: ruleVerify + registry.CheckMessageDigest, Name: "MessageDigest", Description: "Атрибут messageDigest совпадает с хешем eContent", Level: findings.LevelError},
+	{ID: ruleVerify + registry.CheckAlgorithmProtection, Name: "AlgorithmProtection", Description: "Атрибут CMSAlgorithmProtection (RFC 6211) совпадает с алгоритмами SignerInfo", Level: findings.LevelError},
+	{ID: ruleVerify + registry.CheckSignature, Name: "Signature", Description: "Подпись authenticatedAttributes проверяется ключом подписанта", Level: findings.LevelError},
+	{ID: ruleMac, Name: "Mac", Description: "MAC хранилища PKCS#12 совпадает с паролем -password (-verify)", Level: findings.LevelError},
+}
+
+// verifyCheckPaths — элемент SignerInfo, к которому относится проверка -verify (путь после signerInfos[n]).
+var verifyCheckPaths = map[string]string{
+	registry.CheckSignerCert:          "",
+	registry.CheckContentType:         ".authenticatedAttributes(id-contentType)",
+	registry.CheckMessageDigest:       ".authenticatedAttributes(id-messageDigest)",
+	registry.CheckAlgorithmProtection: ".authenticatedAttributes(id-aa-CMSAlgorithmProtection)",
+	registry.CheckSignature:           ".encryptedDigest",
+}
+
+// fileFindings выполняет проверки одного файла (разбор; -validate и -verify по opt) и возвращает их результаты
+// с ASN.1-путями и смещениями. Ошибка чтения или разбора — нарушение registry/parse, остальные проверки пропускаются
+// (кроме -validate, которая работает и для повреждённого файла).
+func fileFindings(path string, opt batchOptions) []findings.Result {
+	var out []findings.Result
+	parseFailed := func(err error) []findings.Result {
+		var perr *der.ParseError
+		if !errors.As(err, &perr) {
+			perr = &der.ParseError{Offset: -1, Err: err}
+		}
+		return append(out, findings.Result{RuleID: ruleParse, Message: perr.Error(), File: path, Path: perr.Path, Offset: perr.Offset})
+	}
+	data, err := der.ReadFile(path, int64(opt.limits.MaxInputSize))
+	if err != nil {
+		return parseFailed(err)
+	}
+	if opt.validate {
+		v, err := validateSchema(data, opt.limits.MaxDepth)
+		if err != nil {
+			return parseFailed(err)
+		}
+		if v.Valid {
+			out = append(out, findings.Result{RuleID: ruleSchema, OK: true, Message: i18n.T("контейнер соответствует модулю") + " " + v.Module, File: path, Path: asn1schema.RegistryRoot, Offset: 0})
+		}
+		for _, e := range v.Mismatches {
+			out = append(out, findings.Result{RuleID: ruleSchema, Message: mismatchMessage(e), File: path, Path: e.Path, Offset: e.Offset})
+		}
+	}
+	c, err := opt.password.parse(data, opt.limits)
+	if err != nil {
+		return parseFailed(err)
+	}
+	out = append(out, findings.Result{RuleID: ruleParse, OK: true, Message: i18n.Sprintf("сертификатов: %d, мешков: %d, подписантов: %d",
+		len(c.Certificates), len(c.SafeBagInfos), len(c.Signers)), File: path, Path: asn1schema.RegistryRoot, Offset: 0})
+	if !opt.verify {
+		return out
+	}
+	if c.Kind == registry.KindKeystore {
+		msg := i18n.T("macData отсутствует")
+		if c.Mac != nil {
+			msg = fmt.Sprintf("%s, %d iterations: %s", c.Mac.Algorithm, c.Mac.Iterations, c.Mac.Status)
+		}
+		return append(out, findings.Result{RuleID: ruleMac, OK: c.MacVerified(), Message: msg, File: path, Path: "PFX.macData", Offset: -1})
+	}
+	results := c.Verify(opt.verifyOpt)
+	if len(results) == 0 {
+		out = append(out, findings.Result{RuleID: ruleVerify + registry.CheckSignerCert, Message: i18n.T("подписанты отсутствуют"),
+			File: path, Path: "PFX.authSafe.content.signerInfos", Offset: -1})
+	}
+	for _, r := range results {
+		for _, ch := range r.Checks {
+			out = append(out, findings.Result{RuleID: ruleVerify + ch.Name, OK: ch.OK, Message: ch.Detail, File: path,
+				Path: fmt.Sprintf("PFX.authSafe.content.signerInfos[%d]%s", r.SignerIndex, verifyCheckPaths[ch.Name]), Offset: -1})
+		}
+	}
+	// Смещения элементов SignerInfo — по дереву DER, подписанному registry.asn1.
+	if s, err := asn1schema.RegistrySchema(); err == nil {
+		nodes, _ := asn1tree.Parse(data, 0, opt.limits.MaxDepth)
+		s.Annotate(nodes, asn1schema.RegistryRoot)
+		findings.Locate(out, nodes)
+	}
+	return out
+}
+
+// mismatchMessage — текст несоответствия -validate (как в validationTextOutput).
+func mismatchMessage(e *der.ParseError) string {
+	var msg []string
+	if e.Expected != "" || e.Actual != "" {
+		msg = append(msg, i18n.Sprintf("ожидалось %s, получено %s", e.Expected, e.Actual))
+	}
+	if e.Err != nil {
+		msg = append(msg, e.Err.Error())
+	}
+	return strings.Join(msg, "; ")
+}
+
+// findingsMain — точка входа -format sarif|junit: как в пакетном режиме принимает файлы, маски и директории,
+// пишет один отчёт со всеми проверками и возвращает код выхода: 1 — есть неразобранные файлы,
+// 2 — не прошла -verify, 3 — несоответствия -validate, иначе 0.
+func findingsMain(args []string, recursive bool, outputPath string, opt batchOptions) int {
+	if exportFlagsSet("-format " + opt.format) {
+		return 1
+	}
+	files, err := expandInputs(args, recursive)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	report := &findings.Report{Tool: "registry-analyzer", Rules: registryRules}
+	for _, f := range files {
+		report.Results = append(report.Results, fileFindings(f, opt)...)
+	}
+	w := io.Writer(os.Stdout)
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("запись в файл: %v\n"), err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	write := report.WriteSARIF
+	if opt.format == "junit" {
+		write = report.WriteJUnit
+	}
+	if err := write(w); err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("запись отчёта: %v\n"), err)
+		return 1
+	}
+	if outputPath != "" {
+		fmt.Fprintf(os.Stderr, i18n.T("Результаты проверок (%d файлов) записаны в %s\n"), len(files), outputPath)
+	}
+	failed, unverified, invalid := false, false, false
+	for _, r := range report.Results {
+		if r.OK {
+			continue
+		}
+		switch {
+		case r.RuleID == ruleParse:
+			failed = true
+		case strings.HasPrefix(r.RuleID, ruleVerify):
+			unverified = true
+		case r.RuleID == ruleSchema:
+			invalid = true
+		}
+	}
+	switch {
+	case failed:
+		return 1
+	case unverified:
+		return 2
+	case invalid:
+		return 3
+	}
+	return 0
+}
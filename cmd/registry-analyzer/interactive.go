@@ -0,0 +1,79 @@
+// interactive.go — режим -interactive: запуск просмотра internal/tui в терминале (посимвольный режим через stty,
+// размер окна) с выгрузкой сертификата узла в PEM.
+package main
+
+import (
+	"encoding/pem"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+	"github.com/sgw-registry/registry-analyzer/internal/registry"
+	"github.com/sgw-registry/registry-analyzer/internal/tui"
+)
+
+// interactiveMain открывает просмотр контейнера в терминале (-interactive). Терминал переводится в посимвольный
+// режим через stty (есть в Linux, macOS и сессиях SSH) и восстанавливается при выходе.
+// Сертификат узла выгружается клавишей e в PEM рядом с контейнером: <контейнер>_<roleName или cert-N>.pem.
+func interactiveMain(c *registry.Container, path string, verification []registry.SignerVerification, useColor bool) int {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		fmt.Fprintln(os.Stderr, i18n.T("-interactive: stdin и stdout должны быть терминалом"))
+		return 1
+	}
+	saved, err := stty("-g")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("-interactive: посимвольный режим терминала недоступен (stty): %v\n"), err)
+		return 1
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("-interactive: посимвольный режим терминала недоступен (stty): %v\n"), err)
+		return 1
+	}
+	defer stty(saved)
+
+	baseName := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	b := tui.New(c.BrowseTree(verification), filepath.Base(path))
+	b.UseColor = useColor
+	b.Export = func(n *registry.BrowseNode) (string, error) {
+		name := filepath.Join(filepath.Dir(path), baseName+"_"+n.ExportName)
+		outPath := name + ".pem"
+		for i := 2; ; i++ {
+			if _, err := os.Stat(outPath); os.IsNotExist(err) {
+				break
+			}
+			outPath = fmt.Sprintf("%s-%d.pem", name, i)
+		}
+		block := &pem.Block{Type: "CERTIFICATE", Bytes: n.Cert.Raw}
+		return outPath, os.WriteFile(outPath, pem.EncodeToMemory(block), 0644)
+	}
+	if err := tui.Run(os.Stdin, os.Stdout, b, terminalSize); err != nil {
+		stty(saved)
+		fmt.Fprintf(os.Stderr, "-interactive: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// stty выполняет stty с терминалом stdin и возвращает вывод без перевода строки.
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// terminalSize возвращает ширину и высоту терминала (stty size); 80×24, если размер неизвестен.
+func terminalSize() (int, int) {
+	out, err := stty("size")
+	var rows, cols int
+	if err != nil {
+		return 80, 24
+	}
+	if _, err := fmt.Sscan(out, &rows, &cols); err != nil || rows <= 0 || cols <= 0 {
+		return 80, 24
+	}
+	return cols, rows
+}
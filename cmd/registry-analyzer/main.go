@@ -3,57 +3,188 @@
 // registry-analyzer разбирает контейнеры .p12 в формате ATOM-PKCS12-REGISTRY (гибрид PKCS#12 PFX и CMS SignedData),
 // извлекает сертификаты из SignedData.certificates и из eContent (SafeContents/SafeBag), атрибуты подписантов (VIN, VER, UID)
 // и выводит отчёт в текстовом, JSON или PEM формате. Поддерживается экспорт сертификатов в отдельные PEM-файлы.
+// Обычные хранилища PKCS#12 (RFC 7292) открываются паролем (-password): вид контейнера выводится в отчёте.
 //
 // Запуск: go run ./cmd/registry-analyzer [опции] <файл.p12>
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"encoding/pem"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"time"
 
+	"github.com/sgw-registry/registry-analyzer/cmd/internal/parsereport"
+	"github.com/sgw-registry/registry-analyzer/internal/asn1schema"
+	"github.com/sgw-registry/registry-analyzer/internal/asn1tree"
+	"github.com/sgw-registry/registry-analyzer/internal/cms"
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+	"github.com/sgw-registry/registry-analyzer/internal/htmlreport"
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
 	"github.com/sgw-registry/registry-analyzer/internal/registry"
 )
 
 func main() {
+	// Язык сообщений выбирается до объявления флагов, чтобы справка -h выводилась уже на нём.
+	if err := i18n.Init(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 	// Флаги вывода: text — человекочитаемый отчёт; json — полный JSON; json-certificates — только данные сертификатов; pem — PEM-цепочка.
-	format := flag.String("format", "text", "Формат вывода: text, json, json-certificates, pem")
-	outputPath := flag.String("output", "", "Записать вывод в файл (по умолчанию — stdout)")
-	exportCertsDir := flag.String("export-certs-dir", "", "Выгрузить каждый сертификат из SignedData в отдельный PEM-файл в указанную директорию (cert-1.pem, cert-2.pem, ...)")
-	exportSafebagCerts := flag.Bool("export-safebag-certs", false, "Выгрузить все сертификаты из SafeBags в один PEM-файл с именем контейнера (например owner_registry.p12 → owner_registry.pem)")
-	exportSafebagCertsDir := flag.String("export-safebag-certs-dir", "", "Выгрузить каждый сертификат из SafeBags в отдельный PEM-файл в указанную директорию (имя: roleName_Serial.pem)")
-	exportSignerCert := flag.Bool("export-signer-cert", false, "Выгрузить сертификат подписанта контейнера в PEM-файл с именем контейнера (например owner_registry.p12 → owner_registry_signer.pem)")
-	noColor := flag.Bool("no-color", false, "Отключить цветной вывод и иконки")
-	colorFlag := flag.String("color", "auto", "Цвет: auto (только TTY), always, never")
+	format := flag.String("format", "text", i18n.T("Формат вывода: text, json, json-certificates, pem, jwks (JWK Set ключей SafeBags), pins (пины SHA-256 SPKI по ролям), builder-config, asn1 (дерево DER с полями registry.asn1), html (самодостаточная HTML-страница); ndjson, csv — пакетный анализ нескольких файлов; sarif, junit — результаты проверок для CI"))
+	outputPath := flag.String("output", "", i18n.T("Записать вывод в файл (по умолчанию — stdout)"))
+	exportDir := flag.String("export-dir", "", i18n.T("Для -format builder-config: директория для PEM сертификатов SafeBags, на которые ссылается конфиг"))
+	exportCertsDir := flag.String("export-certs-dir", "", i18n.T("Выгрузить каждый сертификат из SignedData в отдельный PEM-файл в указанную директорию (cert-1.pem, cert-2.pem, ...)"))
+	exportSafebagCerts := flag.Bool("export-safebag-certs", false, i18n.T("Выгрузить все сертификаты из SafeBags в один PEM-файл с именем контейнера (например owner_registry.p12 → owner_registry.pem)"))
+	exportSafebagCertsDir := flag.String("export-safebag-certs-dir", "", i18n.T("Выгрузить каждый сертификат из SafeBags в отдельный PEM-файл в указанную директорию (имя: roleName_Serial.pem)"))
+	exportSignerCert := flag.Bool("export-signer-cert", false, i18n.T("Выгрузить сертификат подписанта контейнера в PEM-файл с именем контейнера (например owner_registry.p12 → owner_registry_signer.pem)"))
+	verify := flag.Bool("verify", false, i18n.T("Проверить подпись: сертификат подписанта, contentType, messageDigest, CMSAlgorithmProtection, подпись атрибутов (код выхода 2 при ошибке)"))
+	validate := flag.Bool("validate", false, i18n.T("Проверить DER контейнера по registry.asn1 и вывести все несоответствия (код выхода 3 при несоответствиях)"))
+	requireAlgProtection := flag.Bool("require-algorithm-protection", false, i18n.T("При -verify требовать наличие атрибута CMSAlgorithmProtection (RFC 6211)"))
+	noColor := flag.Bool("no-color", false, i18n.T("Отключить цветной вывод и иконки"))
+	colorFlag := flag.String("color", "auto", i18n.T("Цвет: auto (только TTY), always, never"))
+	maxSize := flag.Int("max-size", registry.DefaultLimits.MaxInputSize, i18n.T("Максимальный размер контейнера, байт; больший файл не читается и не разбирается"))
+	recursive := flag.Bool("recursive", false, i18n.T("Для -format ndjson|csv|sarif|junit и -expiring-within: искать *.p12 в поддиректориях переданных директорий"))
+	expiringWithin := flag.String("expiring-within", "", i18n.T("Вывести сертификаты (подписант, цепочка, мешки) и roleValidityPeriod, истекающие в течение окна (30d, 2w, 36h), по дате; код выхода 5 — есть истёкшие, 4 — истекающие"))
+	jobs := flag.Int("jobs", runtime.GOMAXPROCS(0), i18n.T("Для -format ndjson|csv: число файлов, разбираемых параллельно"))
+	exportTruststore := flag.String("export-truststore", "", i18n.T("Записать сертификаты SafeBags в хранилище доверенных сертификатов PKCS#12 (RFC 7292) для keytool, .NET и OpenSSL; пароль — -password или -password-file"))
+	truststoreSigner := flag.Bool("truststore-signer", false, i18n.T("Для -export-truststore: добавить сертификат подписанта контейнера"))
+	truststoreChain := flag.Bool("truststore-chain", false, i18n.T("Для -export-truststore: добавить остальные сертификаты SignedData (цепочку CA)"))
+	exportP7b := flag.String("export-p7b", "", i18n.T("Записать сертификаты SafeBags и SignedData в набор сертификатов CMS без подписантов (.p7b, RFC 5652) для Windows и MDM"))
+	p7bPEM := flag.Bool("p7b-pem", false, i18n.T("Для -export-p7b: PEM (-----BEGIN PKCS7-----) вместо DER"))
+	exportCAPath := flag.String("export-capath", "", i18n.T("Записать сертификаты (подписант, цепочка, SafeBags) в директорию для OpenSSL -CApath: PEM, ссылки subject_hash.N как c_rehash и manifest.json с ролями"))
+	exportCanonical := flag.String("export-canonical", "", i18n.T("Записать реестр в опорной DER-форме ADR-011 (нормализация IMPLICIT-вариантов) и сообщить, действительна ли подпись после нормализации"))
+	interactive := flag.Bool("interactive", false, i18n.T("Интерактивный просмотр в терминале: дерево PFX → SignedData → сертификаты, SafeBags, подписанты; поля, ASN.1 и hex узла, статус подписи, выгрузка сертификата в PEM (клавиша e)"))
+	passwordFlag := flag.String("password", "", i18n.T("Пароль хранилища PKCS#12 (RFC 7292): проверка MAC, расшифровка encryptedData и ключей; пароль хранилища -export-truststore"))
+	passwordFile := flag.String("password-file", "", i18n.T("Прочитать пароль хранилища PKCS#12 из файла (первая строка); не оставляет пароль в истории shell"))
+	emptyPassword := flag.Bool("empty-password", false, i18n.T("Проверить MAC и расшифровать хранилище PKCS#12 пустым паролем (без флага пустой пароль — пароль не задан)"))
+	flag.String("lang", "", i18n.T(i18n.FlagUsage))
 	flag.Parse()
 
-	// Проверка обязательного аргумента — пути к файлу .p12.
+	// Проверка обязательного аргумента — пути к файлу .p12 (в пакетном режиме — файлов, масок или директорий).
 	if flag.NArg() < 1 {
-		fmt.Fprintf(os.Stderr, "Использование: %s [опции] <файл.p12>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, i18n.T("Использование: %s [опции] <файл.p12>\n       %s -format ndjson|csv|sarif|junit [-recursive] <файл.p12|маска|директория>...\n       %s -expiring-within 30d [-recursive] <файл.p12|маска|директория>...\n"), os.Args[0], os.Args[0], os.Args[0])
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
+
+	password, err := readPassword(*passwordFlag, *passwordFile, *emptyPassword)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Мониторинг сроков: файлы, маски и директории как в пакетном режиме, один список по дате.
+	if *expiringWithin != "" {
+		limits := registry.DefaultLimits
+		limits.MaxInputSize = *maxSize
+		useColor := !*noColor && (*colorFlag == "always" || (*colorFlag != "never" && isTerminal(os.Stdout)))
+		os.Exit(expiryMain(flag.Args(), *recursive, *outputPath, *expiringWithin, strings.ToLower(*format), limits, password, useColor))
+	}
+
+	// Пакетный режим: по записи на контейнер, ошибка разбора одного файла не прерывает обработку остальных.
+	if f := strings.ToLower(*format); f == "ndjson" || f == "csv" {
+		limits := registry.DefaultLimits
+		limits.MaxInputSize = *maxSize
+		os.Exit(batchMain(flag.Args(), *recursive, *outputPath, batchOptions{
+			format:    f,
+			jobs:      *jobs,
+			limits:    limits,
+			verify:    *verify,
+			verifyOpt: registry.VerifyOptions{RequireAlgorithmProtection: *requireAlgProtection},
+			validate:  *validate,
+			password:  password,
+		}))
+	}
+	// Результаты проверок для CI: по результату на проверку (разбор, -validate, -verify) каждого файла.
+	if f := strings.ToLower(*format); f == "sarif" || f == "junit" {
+		limits := registry.DefaultLimits
+		limits.MaxInputSize = *maxSize
+		os.Exit(findingsMain(flag.Args(), *recursive, *outputPath, batchOptions{
+			format:    f,
+			limits:    limits,
+			verify:    *verify,
+			verifyOpt: registry.VerifyOptions{RequireAlgorithmProtection: *requireAlgProtection},
+			validate:  *validate,
+			password:  password,
+		}))
+	}
+	if flag.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, i18n.T("несколько файлов поддерживаются только с -format ndjson, csv, sarif или junit"))
+		os.Exit(1)
+	}
 	path := flag.Arg(0)
-	data, err := os.ReadFile(path)
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		fmt.Fprintf(os.Stderr, i18n.T("%s — директория; для пакетного анализа используйте -format ndjson или csv\n"), path)
+		os.Exit(1)
+	}
+	data, err := der.ReadFile(path, int64(*maxSize))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "чтение файла: %v\n", err)
+		fmt.Fprintf(os.Stderr, i18n.T("чтение файла: %v\n"), err)
 		os.Exit(1)
 	}
 
-	// Разбор DER-кодированного PFX: извлекаем SignedData, сертификаты, SafeBags и подписантов.
-	c, err := registry.Parse(data)
+	// Разбор DER-кодированного PFX: извлекаем SignedData, сертификаты, SafeBags и подписантов (с лимитами разбора).
+	limits := registry.DefaultLimits
+	limits.MaxInputSize = *maxSize
+
+	// Дерево ASN.1 выводится до разбора контейнера: так его можно получить и для повреждённого файла.
+	if strings.EqualFold(*format, "asn1") {
+		useColor := !*noColor && (*colorFlag == "always" || (*colorFlag != "never" && isTerminal(os.Stdout)))
+		os.Exit(asn1tree.WriteTree(data, asn1schema.RegistrySchema, "PFX", limits.MaxDepth, useColor, *outputPath))
+	}
+
+	// Проверка по registry.asn1 (по флагу -validate) выполняется до разбора и сообщает все несоответствия.
+	var validation *schemaValidation
+	if *validate {
+		if validation, err = validateSchema(data, limits.MaxDepth); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("схема: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+
+	c, err := password.parse(data, limits)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "разбор контейнера: %v\n", err)
+		if validation != nil && !strings.EqualFold(*format, "json") {
+			var sb strings.Builder
+			validationTextOutput(&sb, validation, false)
+			fmt.Fprint(os.Stderr, strings.TrimPrefix(sb.String(), "\n"))
+		}
+		parsereport.Report(err, i18n.T("разбор контейнера"), path, "DER", strings.EqualFold(*format, "json"), *outputPath)
 		os.Exit(1)
 	}
+	// Хранилище PKCS#12 разобрано, но пароль не подошёл: MAC не совпал, зашифрованные мешки не извлечены.
+	if c.Kind == registry.KindKeystore {
+		if c.Mac != nil && c.Mac.Status == registry.MacStatusFailed {
+			fmt.Fprintln(os.Stderr, i18n.T("предупреждение: MAC хранилища не совпал — неверный пароль (-password) или изменённое содержимое"))
+		}
+		for i, as := range c.AuthSafe {
+			if as.Error != "" {
+				fmt.Fprintf(os.Stderr, i18n.T("предупреждение: AuthenticatedSafe[%d] (%s): %s\n"), i+1, as.ContentType, as.Error)
+			}
+		}
+	}
+
+	// Интерактивный просмотр: подпись проверяется сразу, её статус показывается в дереве и панели подробностей.
+	if *interactive {
+		var results []registry.SignerVerification
+		if c.Kind == registry.KindRegistry {
+			results = c.Verify(registry.VerifyOptions{RequireAlgorithmProtection: *requireAlgProtection})
+		}
+		useColor := !*noColor && *colorFlag != "never"
+		os.Exit(interactiveMain(c, path, results, useColor))
+	}
 
 	// Выгрузка каждого сертификата из SignedData в отдельный PEM-файл (имя по roleName подписанта или cert-N).
 	if *exportCertsDir != "" {
 		if err := os.MkdirAll(*exportCertsDir, 0755); err != nil {
-			fmt.Fprintf(os.Stderr, "создание директории %s: %v\n", *exportCertsDir, err)
+			fmt.Fprintf(os.Stderr, i18n.T("создание директории %s: %v\n"), *exportCertsDir, err)
 			os.Exit(1)
 		}
 		// Избегаем коллизий имён: при повторе добавляем суффикс -2, -3.
@@ -70,11 +201,11 @@ func main() {
 			}
 			filename := filepath.Join(*exportCertsDir, name+".pem")
 			if err := os.WriteFile(filename, pem.EncodeToMemory(block), 0644); err != nil {
-				fmt.Fprintf(os.Stderr, "запись %s: %v\n", filename, err)
+				fmt.Fprintf(os.Stderr, i18n.T("запись %s: %v\n"), filename, err)
 				os.Exit(1)
 			}
 		}
-		fmt.Fprintf(os.Stderr, "Выгружено %d сертификатов в %s\n", len(c.Certificates), *exportCertsDir)
+		fmt.Fprintf(os.Stderr, i18n.T("Выгружено %d сертификатов в %s\n"), len(c.Certificates), *exportCertsDir)
 	}
 
 	// Выгрузка всех сертификатов из SafeBags в один PEM-файл с именем контейнера.
@@ -90,7 +221,7 @@ func main() {
 		}
 		outPath := filepath.Join(filepath.Dir(path), baseName+".pem")
 		if err := os.WriteFile(outPath, pemOut, 0644); err != nil {
-			fmt.Fprintf(os.Stderr, "запись %s: %v\n", outPath, err)
+			fmt.Fprintf(os.Stderr, i18n.T("запись %s: %v\n"), outPath, err)
 			os.Exit(1)
 		}
 		n := 0
@@ -99,13 +230,13 @@ func main() {
 				n++
 			}
 		}
-		fmt.Fprintf(os.Stderr, "Сертификаты из SafeBags (%d шт.) записаны в %s\n", n, outPath)
+		fmt.Fprintf(os.Stderr, i18n.T("Сертификаты из SafeBags (%d шт.) записаны в %s\n"), n, outPath)
 	}
 
 	// Выгрузка каждого сертификата из SafeBags в отдельный PEM-файл (имя: roleName_Serial.pem).
 	if *exportSafebagCertsDir != "" {
 		if err := os.MkdirAll(*exportSafebagCertsDir, 0755); err != nil {
-			fmt.Fprintf(os.Stderr, "создание директории %s: %v\n", *exportSafebagCertsDir, err)
+			fmt.Fprintf(os.Stderr, i18n.T("создание директории %s: %v\n"), *exportSafebagCertsDir, err)
 			os.Exit(1)
 		}
 		usedNames := make(map[string]int)
@@ -125,12 +256,12 @@ func main() {
 			}
 			filename := filepath.Join(*exportSafebagCertsDir, name+".pem")
 			if err := os.WriteFile(filename, pem.EncodeToMemory(block), 0644); err != nil {
-				fmt.Fprintf(os.Stderr, "запись %s: %v\n", filename, err)
+				fmt.Fprintf(os.Stderr, i18n.T("запись %s: %v\n"), filename, err)
 				os.Exit(1)
 			}
 			n++
 		}
-		fmt.Fprintf(os.Stderr, "Выгружено %d сертификатов из SafeBags в %s\n", n, *exportSafebagCertsDir)
+		fmt.Fprintf(os.Stderr, i18n.T("Выгружено %d сертификатов из SafeBags в %s\n"), n, *exportSafebagCertsDir)
 	}
 
 	// Выгрузка сертификата подписанта контейнера в отдельный PEM-файл.
@@ -146,21 +277,114 @@ func main() {
 			os.Exit(1)
 		}
 		if len(pemOut) == 0 {
-			fmt.Fprintf(os.Stderr, "Сертификат подписанта не найден в контейнере\n")
+			fmt.Fprint(os.Stderr, i18n.T("Сертификат подписанта не найден в контейнере\n"))
 			os.Exit(1)
 		}
 		if err := os.WriteFile(outPath, pemOut, 0644); err != nil {
-			fmt.Fprintf(os.Stderr, "запись %s: %v\n", outPath, err)
+			fmt.Fprintf(os.Stderr, i18n.T("запись %s: %v\n"), outPath, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, i18n.T("Сертификат подписанта записан в %s\n"), outPath)
+	}
+
+	// Выгрузка сертификатов в хранилище PKCS#12 (truststore) с паролем -password: псевдонимы — roleName_Serial.
+	if *exportTruststore != "" {
+		if password.value == "" {
+			fmt.Fprintln(os.Stderr, i18n.T("export-truststore: требуется -password или -password-file (keytool не открывает хранилище без пароля)"))
+			os.Exit(1)
+		}
+		out, err := c.Truststore(password.value, registry.TruststoreOptions{IncludeSigner: *truststoreSigner, IncludeChain: *truststoreChain})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "export-truststore: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(*exportTruststore, out, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("запись %s: %v\n"), *exportTruststore, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, i18n.T("Хранилище доверенных сертификатов PKCS#12 записано в %s\n"), *exportTruststore)
+	}
+
+	// Выгрузка сертификатов в набор .p7b (вырожденный SignedData без подписантов), DER или PEM (-p7b-pem).
+	if *exportP7b != "" {
+		certs := c.BundleCertificates()
+		if len(certs) == 0 {
+			fmt.Fprintln(os.Stderr, i18n.T("export-p7b: в контейнере нет сертификатов X.509"))
 			os.Exit(1)
 		}
-		fmt.Fprintf(os.Stderr, "Сертификат подписанта записан в %s\n", outPath)
+		marshal := cms.MarshalCertsOnly
+		if *p7bPEM {
+			marshal = cms.CertsOnlyPEM
+		}
+		out, err := marshal(certs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "export-p7b: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(*exportP7b, out, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("запись %s: %v\n"), *exportP7b, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, i18n.T("Набор сертификатов .p7b (%d шт.) записан в %s\n"), len(certs), *exportP7b)
+	}
+
+	// Выгрузка в директорию -CApath: PEM-файлы, ссылки subject_hash.N (как c_rehash) и манифест ссылка → роли.
+	if *exportCAPath != "" {
+		n, err := exportCAPathDir(c, *exportCAPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "export-capath: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, i18n.T("Директория -CApath: %d сертификатов и %s записаны в %s\n"), n, capathManifest, *exportCAPath)
+	}
+
+	// Нормализация к опорной форме ADR-011: список изменений и действительность подписи — в stderr.
+	if *exportCanonical != "" {
+		res, err := c.Marshal(registry.MarshalCanonical)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "export-canonical: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(*exportCanonical, res.DER, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("запись %s: %v\n"), *exportCanonical, err)
+			os.Exit(1)
+		}
+		if !res.Changed {
+			fmt.Fprintf(os.Stderr, i18n.T("Реестр уже в опорной форме, копия записана в %s\n"), *exportCanonical)
+		} else {
+			fmt.Fprintf(os.Stderr, i18n.T("Реестр в опорной форме записан в %s, изменения:\n"), *exportCanonical)
+			for _, ch := range res.Changes {
+				fmt.Fprintf(os.Stderr, "  - %s\n", ch)
+			}
+		}
+		switch {
+		case res.SignatureValid:
+			fmt.Fprintln(os.Stderr, i18n.T("Подпись после нормализации действительна"))
+		case res.OriginalSignatureValid:
+			fmt.Fprintln(os.Stderr, i18n.T("Подпись после нормализации НЕДЕЙСТВИТЕЛЬНА (исходная была действительна): изменились подписанные байты"))
+		default:
+			fmt.Fprintln(os.Stderr, i18n.T("Подпись недействительна и в исходном реестре"))
+		}
+	}
+
+	// Проверка подписи (по флагу -verify): результаты добавляются в отчёт text/json.
+	// У хранилища PKCS#12 подписи нет — -verify проверяет MAC (строка MAC в отчёте).
+	var verification []registry.SignerVerification
+	verifyOK := true
+	if *verify {
+		if c.Kind == registry.KindKeystore {
+			verifyOK = c.MacVerified()
+		} else {
+			verification = c.Verify(registry.VerifyOptions{RequireAlgorithmProtection: *requireAlgProtection})
+			verifyOK = registry.VerifyOK(verification)
+		}
 	}
 
 	// Функция вывода: в файл или stdout в зависимости от флага -output.
 	writeOut := func(b []byte) {
 		if *outputPath != "" {
 			if err := os.WriteFile(*outputPath, b, 0644); err != nil {
-				fmt.Fprintf(os.Stderr, "запись в файл: %v\n", err)
+				fmt.Fprintf(os.Stderr, i18n.T("запись в файл: %v\n"), err)
 				os.Exit(1)
 			}
 		} else {
@@ -171,14 +395,21 @@ func main() {
 	// Формирование и вывод в выбранном формате.
 	switch strings.ToLower(*format) {
 	case "json":
-		out, err := c.ToJSON()
+		r := c.JSONOutput()
+		if *verify && c.Kind != registry.KindKeystore {
+			r.Verification = registry.ReportVerification(verification)
+		}
+		if validation != nil {
+			r.SchemaValidation = validation.report()
+		}
+		out, err := json.MarshalIndent(r, "", "  ")
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "json: %v\n", err)
 			os.Exit(1)
 		}
 		writeOut(out)
 		if *outputPath != "" {
-			fmt.Fprintf(os.Stderr, "Полный отчёт записан в %s\n", *outputPath)
+			fmt.Fprintf(os.Stderr, i18n.T("Полный отчёт записан в %s\n"), *outputPath)
 		}
 	case "json-certificates":
 		out, err := c.ToCertificatesJSON()
@@ -188,7 +419,78 @@ func main() {
 		}
 		writeOut(out)
 		if *outputPath != "" {
-			fmt.Fprintf(os.Stderr, "Данные сертификатов записаны в %s\n", *outputPath)
+			fmt.Fprintf(os.Stderr, i18n.T("Данные сертификатов записаны в %s\n"), *outputPath)
+		}
+	case "builder-config":
+		// Конфиг registry-builder по содержимому реестра: сертификаты мешков — в PEM в -export-dir, подписант — заглушки.
+		if *exportDir == "" {
+			fmt.Fprint(os.Stderr, i18n.T("builder-config: требуется -export-dir <директория>\n"))
+			os.Exit(1)
+		}
+		exp, err := c.BuilderConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "builder-config: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.MkdirAll(*exportDir, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("создание директории %s: %v\n"), *exportDir, err)
+			os.Exit(1)
+		}
+		for i, der := range exp.CertDER {
+			filename := filepath.Join(*exportDir, exp.CertNames[i]+".pem")
+			if err := os.WriteFile(filename, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+				fmt.Fprintf(os.Stderr, i18n.T("запись %s: %v\n"), filename, err)
+				os.Exit(1)
+			}
+			exp.Config.SafeBags[i].Cert = filepath.ToSlash(filename)
+		}
+		for _, w := range exp.Warnings {
+			fmt.Fprintf(os.Stderr, i18n.T("предупреждение: %s\n"), w)
+		}
+		// Без экранирования HTML: заглушки "<signer-cert.pem>" остаются читаемыми.
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(exp.Config); err != nil {
+			fmt.Fprintf(os.Stderr, "json: %v\n", err)
+			os.Exit(1)
+		}
+		writeOut(buf.Bytes())
+		fmt.Fprintf(os.Stderr, i18n.T("Сертификаты SafeBags (%d шт.) записаны в %s; signerCert/signerKey в конфиге — заглушки\n"), len(exp.CertDER), *exportDir)
+	case "html":
+		// Одна HTML-страница без внешних ресурсов; проверки -verify и -validate — отдельными секциями.
+		page := c.HTMLReport(path, verification, time.Now())
+		if validation != nil {
+			page.Sections = append(page.Sections, validationHTML(validation))
+		}
+		var buf bytes.Buffer
+		if err := htmlreport.Write(&buf, page); err != nil {
+			fmt.Fprintf(os.Stderr, "html: %v\n", err)
+			os.Exit(1)
+		}
+		writeOut(buf.Bytes())
+		if *outputPath != "" {
+			fmt.Fprintf(os.Stderr, i18n.T("HTML-отчёт записан в %s\n"), *outputPath)
+		}
+	case "jwks", "pins":
+		// Ключи сертификатов SafeBags для сервисов: JWK Set (kid = localKeyID) или пины SPKI по roleName.
+		var v interface{} = c.Pins()
+		if strings.ToLower(*format) == "jwks" {
+			set, warnings := c.JWKS()
+			for _, w := range warnings {
+				fmt.Fprintf(os.Stderr, i18n.T("предупреждение: %s\n"), w)
+			}
+			v = set
+		}
+		out, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "json: %v\n", err)
+			os.Exit(1)
+		}
+		writeOut(append(out, '\n'))
+		if *outputPath != "" {
+			fmt.Fprintf(os.Stderr, i18n.T("Ключи SafeBags (%s) записаны в %s\n"), strings.ToLower(*format), *outputPath)
 		}
 	case "pem":
 		out, err := c.ToPEM()
@@ -198,24 +500,81 @@ func main() {
 		}
 		writeOut(out)
 		if *outputPath != "" {
-			fmt.Fprintf(os.Stderr, "Сертификаты (PEM) записаны в %s\n", *outputPath)
+			fmt.Fprintf(os.Stderr, i18n.T("Сертификаты (PEM) записаны в %s\n"), *outputPath)
 		}
 	default:
 		// Цветной вывод только в TTY и если не отключён флагами.
 		useColor := !*noColor && (*colorFlag == "always" || (*colorFlag != "never" && isTerminal(os.Stdout)))
 		var sb strings.Builder
 		c.TextOutput(&sb, useColor)
+		if *verify && c.Kind != registry.KindKeystore {
+			registry.VerificationTextOutput(&sb, verification, useColor)
+		}
+		if validation != nil {
+			validationTextOutput(&sb, validation, useColor)
+		}
 		text := sb.String()
 		if *outputPath != "" {
 			if err := os.WriteFile(*outputPath, []byte(text), 0644); err != nil {
-				fmt.Fprintf(os.Stderr, "запись в файл: %v\n", err)
+				fmt.Fprintf(os.Stderr, i18n.T("запись в файл: %v\n"), err)
 				os.Exit(1)
 			}
-			fmt.Fprintf(os.Stderr, "Текстовый отчёт записан в %s\n", *outputPath)
+			fmt.Fprintf(os.Stderr, i18n.T("Текстовый отчёт записан в %s\n"), *outputPath)
 		} else {
 			fmt.Print(text)
 		}
 	}
+
+	// Код выхода 2 — подпись контейнера (у хранилища PKCS#12 — MAC) не прошла проверку (-verify).
+	if !verifyOK {
+		os.Exit(2)
+	}
+	// Код выхода 3 — контейнер не соответствует registry.asn1 (-validate).
+	if validation != nil && !validation.Valid {
+		// В форматах без секции проверки (pem, json-certificates, jwks, pins, builder-config) несоответствия выводятся в stderr.
+		if f := strings.ToLower(*format); f == "pem" || f == "json-certificates" || f == "jwks" || f == "pins" || f == "builder-config" {
+			var sb strings.Builder
+			validationTextOutput(&sb, validation, false)
+			fmt.Fprint(os.Stderr, strings.TrimPrefix(sb.String(), "\n"))
+		}
+		os.Exit(3)
+	}
+}
+
+// keystorePassword — пароль хранилищ PKCS#12; empty — пустой пароль задан явно (-empty-password).
+type keystorePassword struct {
+	value string
+	empty bool
+}
+
+// parse разбирает контейнер паролем p: без пароля MAC хранилища не проверяется и ничего не расшифровывается.
+func (p keystorePassword) parse(data []byte, limits registry.Limits) (*registry.Container, error) {
+	if p.empty {
+		return registry.ParseWithEmptyPassword(data, limits)
+	}
+	return registry.ParseWithPassword(data, limits, p.value)
+}
+
+// readPassword возвращает пароль хранилища из -password, первой строки файла -password-file или -empty-password.
+func readPassword(password, passwordFile string, empty bool) (keystorePassword, error) {
+	if empty {
+		if password != "" || passwordFile != "" {
+			return keystorePassword{}, i18n.Errorf("-empty-password нельзя указывать вместе с -password или -password-file")
+		}
+		return keystorePassword{empty: true}, nil
+	}
+	if passwordFile == "" {
+		return keystorePassword{value: password}, nil
+	}
+	if password != "" {
+		return keystorePassword{}, i18n.Errorf("-password и -password-file нельзя указывать вместе")
+	}
+	b, err := os.ReadFile(passwordFile)
+	if err != nil {
+		return keystorePassword{}, i18n.Errorf("чтение пароля: %w", err)
+	}
+	line, _, _ := strings.Cut(string(b), "\n")
+	return keystorePassword{value: strings.TrimSuffix(line, "\r")}, nil
 }
 
 // isTerminal возвращает true, если f — терминал (в этом случае включается цветной вывод).
@@ -0,0 +1,113 @@
+// validate.go — флаг -validate: проверка контейнера по ASN.1-модулю registry.asn1 и вывод несоответствий
+// в текстовом, JSON и HTML-отчётах.
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sgw-registry/registry-analyzer/internal/asn1schema"
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+	"github.com/sgw-registry/registry-analyzer/internal/htmlreport"
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+	"github.com/sgw-registry/registry-analyzer/internal/registry"
+	"github.com/sgw-registry/registry-analyzer/report"
+)
+
+// schemaValidation — результат проверки контейнера по registry.asn1 (флаг -validate); в JSON — поле schemaValidation.
+type schemaValidation struct {
+	Module     string            `json:"module"`
+	Valid      bool              `json:"valid"`
+	Mismatches []*der.ParseError `json:"mismatches"`
+}
+
+// validateSchema проверяет DER контейнера по встроенному registry.asn1 (до разбора, поэтому работает и для повреждённого файла).
+func validateSchema(data []byte, maxDepth int) (*schemaValidation, error) {
+	m, err := asn1schema.Registry()
+	if err != nil {
+		return nil, err
+	}
+	s, err := asn1schema.RegistrySchema()
+	if err != nil {
+		return nil, err
+	}
+	errs := asn1schema.Validate(s, data, asn1schema.RegistryRoot, maxDepth)
+	if errs == nil {
+		errs = []*der.ParseError{}
+	}
+	return &schemaValidation{Module: m.Name, Valid: len(errs) == 0, Mismatches: errs}, nil
+}
+
+// report переводит результат проверки в формат JSON-отчёта (поле schemaValidation).
+func (v *schemaValidation) report() *report.SchemaValidation {
+	out := &report.SchemaValidation{Module: v.Module, Valid: v.Valid, Mismatches: make([]report.Mismatch, 0, len(v.Mismatches))}
+	for _, e := range v.Mismatches {
+		m := report.Mismatch{Message: e.Error(), Path: e.Path, Expected: e.Expected, Actual: e.Actual}
+		if e.Offset >= 0 {
+			offset := e.Offset
+			m.Offset = &offset
+		}
+		if e.Err != nil {
+			m.Cause = e.Err.Error()
+		}
+		out.Mismatches = append(out.Mismatches, m)
+	}
+	return out
+}
+
+// validationTextOutput дописывает секцию проверки по registry.asn1 в оформлении VerificationTextOutput.
+func validationTextOutput(sb *strings.Builder, v *schemaValidation, useColor bool) {
+	dim, val, head, okColor, failColor, reset := "", "", "", "", "", ""
+	if useColor {
+		dim, val, head, okColor, failColor, reset = registry.Dim, registry.Cyan, registry.Bold+registry.Yellow, registry.Bold+registry.Green, registry.Bold+registry.Red, registry.Reset
+		sb.WriteString("\n" + head + registry.IconPFX + " " + i18n.T("Проверка по registry.asn1") + reset + "\n")
+	} else {
+		sb.WriteString("\n=== " + i18n.T("Проверка по registry.asn1") + " ===\n")
+	}
+	if v.Valid {
+		sb.WriteString(i18n.Sprintf("  %sOK%s %sконтейнер соответствует модулю %s%s\n", okColor, reset, dim, v.Module, reset))
+		return
+	}
+	sb.WriteString(i18n.Sprintf("  %sFAILED%s %sнесоответствий: %d%s\n", failColor, reset, dim, len(v.Mismatches), reset))
+	for _, e := range v.Mismatches {
+		mark := failColor + "✗" + reset
+		if !useColor {
+			mark = "FAIL"
+		}
+		detail := e.Path
+		if e.Offset >= 0 {
+			detail = strings.TrimSpace(fmt.Sprintf("%s @%d", e.Path, e.Offset))
+		}
+		var msg []string
+		if e.Expected != "" || e.Actual != "" {
+			msg = append(msg, i18n.Sprintf("ожидалось %s, получено %s", e.Expected, e.Actual))
+		}
+		if e.Err != nil {
+			msg = append(msg, e.Err.Error())
+		}
+		sb.WriteString(fmt.Sprintf("    %s %s%s:%s %s%s%s\n", mark, dim, detail, reset, val, strings.Join(msg, "; "), reset))
+	}
+}
+
+// validationHTML — секция проверки по registry.asn1 для -format html.
+func validationHTML(v *schemaValidation) htmlreport.Section {
+	s := htmlreport.Section{Title: i18n.T("Проверка по registry.asn1"), Open: true, Badge: "OK", Status: htmlreport.StatusOK}
+	if v.Valid {
+		s.Fields = []htmlreport.Field{{Name: i18n.T("Модуль"), Value: v.Module}}
+		return s
+	}
+	s.Badge, s.Status = i18n.Sprintf("несоответствий: %d", len(v.Mismatches)), htmlreport.StatusFail
+	t := htmlreport.Table{Caption: i18n.T("Модуль") + " " + v.Module, Header: []string{i18n.T("Путь"), i18n.T("Смещение"), i18n.T("Ожидалось"), i18n.T("Получено"), i18n.T("Ошибка")}}
+	for _, e := range v.Mismatches {
+		offset, msg := "—", ""
+		if e.Offset >= 0 {
+			offset = fmt.Sprint(e.Offset)
+		}
+		if e.Err != nil {
+			msg = e.Err.Error()
+		}
+		t.Rows = append(t.Rows, htmlreport.Row{Cells: []string{e.Path, offset, e.Expected, e.Actual, msg}, Status: htmlreport.StatusFail})
+	}
+	s.Tables = []htmlreport.Table{t}
+	return s
+}
@@ -0,0 +1,178 @@
+// issue.go — выпуск сертификатов ролей встроенным CA устройства при сборке (safeBags[].issue, ca, issueDir):
+// сертификаты выпускаются в памяти, файлы в issueDir записываются только после успешной сборки реестра.
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/sgw-registry/registry-analyzer/internal/pki"
+	"github.com/sgw-registry/registry-analyzer/internal/registry"
+)
+
+// subjectData — данные для шаблона subject выпускаемого сертификата.
+type subjectData struct {
+	RoleName string
+	Index    int
+	VIN      string
+	UID      string
+}
+
+// issuedCert — результат выпуска: сертификат и ключ в памяти и пути, по которым они будут записаны.
+type issuedCert struct {
+	Index    int // индекс мешка в cfg.SafeBags
+	Cert     *x509.Certificate
+	Key      crypto.Signer // nil, если ключ не генерировался (CSR)
+	CertPath string
+	KeyPath  string // пусто, если ключ не генерировался (CSR)
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// issueSafeBagCerts выпускает сертификаты для мешков с полем issue; localKeyID, если не задан, берётся из SKID
+// (fallback в BuildRegistry). Файлы не записываются (см. writeIssued), но пути проверяются заранее: имена
+// в cfg.IssueDir уникальны, а существующие файлы без force не перезаписываются.
+func issueSafeBagCerts(cfg *registry.Config, force bool) ([]issuedCert, error) {
+	var pending bool
+	for _, sb := range cfg.SafeBags {
+		if sb.Issue != nil {
+			pending = true
+		}
+	}
+	if !pending {
+		return nil, nil
+	}
+	if cfg.CA == nil || cfg.CA.Cert == "" || cfg.CA.Key == "" {
+		return nil, fmt.Errorf("safeBags[].issue requires ca.cert and ca.key")
+	}
+	issuer, err := pki.LoadIssuer(cfg.CA.Cert, cfg.CA.Key)
+	if err != nil {
+		return nil, err
+	}
+	dir := cfg.IssueDir
+	if dir == "" {
+		dir = "issued"
+	}
+
+	var out []issuedCert
+	seen := make(map[string]int)
+	for i := range cfg.SafeBags {
+		sb := &cfg.SafeBags[i]
+		if sb.Issue == nil {
+			continue
+		}
+		if sb.Cert != "" {
+			return nil, fmt.Errorf("safeBags[%d]: cert and issue are mutually exclusive", i)
+		}
+		ic, err := issueOne(issuer, sb, i, cfg, dir)
+		if err != nil {
+			return nil, fmt.Errorf("safeBags[%d] issue: %w", i, err)
+		}
+		if prev, ok := seen[ic.CertPath]; ok {
+			return nil, fmt.Errorf("safeBags[%d] issue: %s is also written by safeBags[%d] (set issue.name)", i, ic.CertPath, prev)
+		}
+		seen[ic.CertPath] = i
+		if !force {
+			for _, p := range []string{ic.CertPath, ic.KeyPath} {
+				if _, err := os.Stat(p); p != "" && err == nil {
+					return nil, fmt.Errorf("safeBags[%d] issue: %s already exists (use -force to overwrite)", i, p)
+				}
+			}
+		}
+		out = append(out, ic)
+	}
+	return out, nil
+}
+
+// writeIssued записывает выпущенные ключи (0600) и сертификаты в PEM.
+func writeIssued(issued []issuedCert) error {
+	for _, ic := range issued {
+		if err := os.MkdirAll(filepath.Dir(ic.CertPath), 0755); err != nil {
+			return err
+		}
+		if ic.Key != nil {
+			if err := pki.WriteKeyPEM(ic.KeyPath, ic.Key); err != nil {
+				return err
+			}
+		}
+		if err := pki.WriteCertPEM(ic.CertPath, ic.Cert); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// issueOne выпускает сертификат одного мешка: из нового ключа или по CSR.
+func issueOne(issuer *pki.Issuer, sb *registry.SafeBagConfig, index int, cfg *registry.Config, dir string) (issuedCert, error) {
+	spec := sb.Issue.CertSpec
+	data := subjectData{RoleName: sb.RoleName, Index: index + 1, VIN: cfg.VIN, UID: cfg.UID}
+	subject, err := renderSubject(spec.Subject, data)
+	if err != nil {
+		return issuedCert{}, err
+	}
+	spec.Subject = subject
+
+	name := sb.Issue.Name
+	if name == "" {
+		name = fmt.Sprintf("%s-%d", sb.RoleName, index+1)
+	}
+	name = strings.Trim(unsafeNameChars.ReplaceAllString(name, "_"), "_")
+	if name == "" {
+		name = fmt.Sprintf("safebag-%d", index+1)
+	}
+	res := issuedCert{Index: index, CertPath: filepath.Join(dir, name+".pem")}
+
+	var pub crypto.PublicKey
+	if sb.Issue.CSR != "" {
+		raw, err := os.ReadFile(sb.Issue.CSR)
+		if err != nil {
+			return res, err
+		}
+		csr, err := pki.ParseCSR(raw)
+		if err != nil {
+			return res, fmt.Errorf("%s: %w", sb.Issue.CSR, err)
+		}
+		pub = csr.PublicKey
+		if spec.Subject == "" {
+			// DN из CSR копируется в DER: строка Subject.String() не разбирается обратно при экранированных
+			// запятых и атрибутах вне списка ParseSubject (например emailAddress).
+			spec.RawSubject = csr.RawSubject
+		}
+	} else {
+		key, err := pki.GenerateKey(spec.KeyType)
+		if err != nil {
+			return res, err
+		}
+		res.Key, res.KeyPath = key, filepath.Join(dir, name+"-key.pem")
+		pub = key.Public()
+	}
+	if spec.Subject == "" && spec.RawSubject == nil {
+		return res, fmt.Errorf("subject required")
+	}
+
+	res.Cert, err = issuer.Issue(spec, pub)
+	return res, err
+}
+
+// renderSubject подставляет поля мешка в шаблон subject (например "/CN={{.RoleName}}-Certificate").
+func renderSubject(tmpl string, data subjectData) (string, error) {
+	if !strings.Contains(tmpl, "{{") {
+		return tmpl, nil
+	}
+	t, err := template.New("subject").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("subject template: %w", err)
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("subject template: %w", err)
+	}
+	return b.String(), nil
+}
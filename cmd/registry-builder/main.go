@@ -2,10 +2,12 @@
 //
 // registry-builder создаёт .p12 контейнеры по JSON-конфигу: подписант (сертификат + ключ ECDSA),
 // атрибуты подписанта (VIN, VER, UID), список SafeBags (сертификаты ролей с roleName, roleValidityPeriod, localKeyID).
+// Сертификаты ролей могут выпускаться при сборке встроенным мини-CA (safeBags[].issue, ca, issueDir).
 // Структура вывода соответствует эталону (полный SignedData в content [0], OCTET STRING eContent, сортировка атрибутов по DER).
 // Созданный реестр можно проверить утилитой registry-analyzer.
 //
 // Запуск: go run ./cmd/registry-builder -config <config.json> -output <имя>.p12
+// Предварительный просмотр без подписи: go run ./cmd/registry-builder -config <config.json> -plan [-format json]
 package main
 
 import (
@@ -20,37 +22,32 @@ import (
 	"strings"
 	"time"
 
+	"github.com/sgw-registry/registry-analyzer/internal/cms"
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
 	"github.com/sgw-registry/registry-analyzer/internal/registry"
 )
 
-// Config — конфигурация сборки реестра (JSON).
-type Config struct {
-	SignerCert   string          `json:"signerCert"`
-	SignerKey    string          `json:"signerKey"`
-	VIN          string          `json:"vin"`
-	VERTimestamp string          `json:"verTimestamp"`
-	VERVersion   int             `json:"verVersion"`
-	UID          string          `json:"uid"`
-	SafeBags     []SafeBagConfig `json:"safeBags"`
-}
-
-// SafeBagConfig — один мешок в конфиге: путь к сертификату и атрибуты.
-type SafeBagConfig struct {
-	Cert          string `json:"cert"`
-	RoleName      string `json:"roleName"`
-	RoleNotBefore string `json:"roleNotBefore"`
-	RoleNotAfter  string `json:"roleNotAfter"`
-	LocalKeyID    string `json:"localKeyID"` // hex
-}
-
 func main() {
-	configPath := flag.String("config", "", "Путь к JSON-конфигу (signerCert, signerKey, vin, verTimestamp, verVersion, uid, safeBags)")
-	outputPath := flag.String("output", "", "Выходной файл реестра (.p12)")
+	// Язык сообщений выбирается до объявления флагов, чтобы справка -h выводилась уже на нём.
+	if err := i18n.Init(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	configPath := flag.String("config", "", i18n.T("Путь к JSON-конфигу (signerCert, signerKey, vin, verTimestamp, verVersion, uid, safeBags, signedAttributes)"))
+	outputPath := flag.String("output", "", i18n.T("Выходной файл реестра (.p12)"))
+	plan := flag.Bool("plan", false, i18n.T("Показать план сборки (подписант, атрибуты, мешки, localKeyID, предупреждения) без подписи; ключ подписанта не читается"))
+	format := flag.String("format", "text", i18n.T("Формат плана (-plan): text, json"))
+	warnDays := flag.Int("warn-days", 30, i18n.T("Порог предупреждения об истечении сертификатов в плане, дней"))
+	noColor := flag.Bool("no-color", false, i18n.T("Отключить цветной вывод плана"))
+	colorFlag := flag.String("color", "auto", i18n.T("Цвет плана: auto (только TTY), always, never"))
+	force := flag.Bool("force", false, i18n.T("Перезаписывать существующие ключи и сертификаты в issueDir (safeBags[].issue)"))
+	flag.String("lang", "", i18n.T(i18n.FlagUsage))
 	flag.Parse()
 
-	// Оба параметра обязательны.
-	if *configPath == "" || *outputPath == "" {
-		fmt.Fprintf(os.Stderr, "Использование: %s -config <config.json> -output <имя>.p12\n", os.Args[0])
+	// Оба параметра обязательны (в режиме -plan — только -config).
+	if *configPath == "" || (*outputPath == "" && !*plan) {
+		fmt.Fprintf(os.Stderr, i18n.T("Использование: %s -config <config.json> -output <имя>.p12\n"), os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s -config <config.json> -plan [-format text|json]\n", os.Args[0])
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
@@ -58,27 +55,44 @@ func main() {
 	// Загрузка и разбор JSON-конфига.
 	data, err := os.ReadFile(*configPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "чтение конфига: %v\n", err)
+		fmt.Fprintf(os.Stderr, i18n.T("чтение конфига: %v\n"), err)
 		os.Exit(1)
 	}
 
-	var cfg Config
+	var cfg registry.Config
 	if err := json.Unmarshal(data, &cfg); err != nil {
-		fmt.Fprintf(os.Stderr, "разбор конфига: %v\n", err)
+		fmt.Fprintf(os.Stderr, i18n.T("разбор конфига: %v\n"), err)
 		os.Exit(1)
 	}
 
+	// Режим -plan: только предварительный просмотр, без ключей, выпуска сертификатов и записи реестра.
+	if *plan {
+		useColor := !*noColor && (*colorFlag == "always" || (*colorFlag != "never" && isTerminal(os.Stdout)))
+		if err := runPlan(&cfg, *format, *warnDays, useColor && *format != "json"); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("план сборки: %v\n"), err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Загрузка сертификата и ключа подписанта из PEM-файлов.
 	signerCert, signerKey, err := loadSigner(cfg.SignerCert, cfg.SignerKey)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "загрузка подписанта: %v\n", err)
+		fmt.Fprintf(os.Stderr, i18n.T("загрузка подписанта: %v\n"), err)
+		os.Exit(1)
+	}
+
+	// Выпуск сертификатов ролей от CA устройства (safeBags[].issue): в памяти, в issueDir — после сборки.
+	issued, err := issueSafeBagCerts(&cfg, *force)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("выпуск сертификатов: %v\n"), err)
 		os.Exit(1)
 	}
 
 	// Загрузка сертификатов ролей и атрибутов мешков из конфига.
-	safeBags, err := loadSafeBags(cfg.SafeBags)
+	safeBags, _, err := loadSafeBags(cfg.SafeBags, issued)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "загрузка SafeBags: %v\n", err)
+		fmt.Fprintf(os.Stderr, i18n.T("загрузка SafeBags: %v\n"), err)
 		os.Exit(1)
 	}
 
@@ -88,52 +102,88 @@ func main() {
 		verTime, _ = time.Parse(time.RFC3339, cfg.VERTimestamp)
 	}
 
-	// Атрибуты подписанта для SignerInfo.authenticatedAttributes [0] (VIN, VER, UID).
-	attrs := registry.SignerAttrs{
-		VIN:          cfg.VIN,
-		VERTimestamp: verTime,
-		VERVersion:   cfg.VERVersion,
-		UID:          cfg.UID,
+	// Атрибуты подписанта для SignerInfo.authenticatedAttributes [0] (VIN, VER, UID и дополнительные).
+	attrs, err := signerAttrs(&cfg, verTime)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("атрибуты подписанта: %v\n"), err)
+		os.Exit(1)
 	}
 
 	// Сборка DER-кодированного PFX (PFX → authSafe ContentInfo → SignedData → signerInfos, eContent, certificates).
 	der, err := registry.BuildRegistry(signerCert, signerKey, safeBags, attrs)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "сборка реестра: %v\n", err)
+		fmt.Fprintf(os.Stderr, i18n.T("сборка реестра: %v\n"), err)
 		os.Exit(1)
 	}
 
-	// Запись результата в выходной файл.
+	// Реестр собран: запись выпущенных ключей и сертификатов, затем результата в выходной файл.
+	if err := writeIssued(issued); err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("выпуск сертификатов: %v\n"), err)
+		os.Exit(1)
+	}
+	for _, ic := range issued {
+		if ic.KeyPath != "" {
+			fmt.Fprintf(os.Stderr, i18n.T("Выпущен сертификат: %s (ключ %s)\n"), ic.CertPath, ic.KeyPath)
+		} else {
+			fmt.Fprintf(os.Stderr, i18n.T("Выпущен сертификат по CSR: %s\n"), ic.CertPath)
+		}
+	}
 	if err := os.WriteFile(*outputPath, der, 0644); err != nil {
-		fmt.Fprintf(os.Stderr, "запись %s: %v\n", *outputPath, err)
+		fmt.Fprintf(os.Stderr, i18n.T("запись %s: %v\n"), *outputPath, err)
 		os.Exit(1)
 	}
 
-	fmt.Fprintf(os.Stderr, "Создан реестр: %s\n", *outputPath)
-	fmt.Fprintf(os.Stderr, "Проверка: ./registry-analyzer %s\n", *outputPath)
+	fmt.Fprintf(os.Stderr, i18n.T("Создан реестр: %s\n"), *outputPath)
+	fmt.Fprintf(os.Stderr, i18n.T("Проверка: ./registry-analyzer %s\n"), *outputPath)
+}
+
+// signerAttrs собирает атрибуты подписанта из конфига: VIN, VER, UID, signingTime, CMSAlgorithmProtection и signedAttributes.
+func signerAttrs(cfg *registry.Config, verTime time.Time) (registry.SignerAttrs, error) {
+	attrs := registry.SignerAttrs{
+		VIN:                 cfg.VIN,
+		VERTimestamp:        verTime,
+		VERVersion:          cfg.VERVersion,
+		UID:                 cfg.UID,
+		AlgorithmProtection: cfg.AlgorithmProtection,
+	}
+	switch cfg.SigningTime {
+	case "":
+	case "now":
+		attrs.SigningTime = time.Now().UTC().Truncate(time.Second)
+	default:
+		t, err := time.Parse(time.RFC3339, cfg.SigningTime)
+		if err != nil {
+			return attrs, fmt.Errorf("signingTime: %w", err)
+		}
+		attrs.SigningTime = t
+	}
+	for i, sa := range cfg.SignedAttributes {
+		values := sa.Values
+		if sa.Value != "" {
+			values = append([]string{sa.Value}, values...)
+		}
+		ca := registry.CustomAttribute{OID: sa.OID, Type: sa.Type, Values: values}
+		if _, err := ca.Attribute(); err != nil {
+			return attrs, fmt.Errorf("signedAttributes[%d] (%s): %w", i, sa.Name, err)
+		}
+		attrs.Custom = append(attrs.Custom, ca)
+	}
+	return attrs, nil
 }
 
 // loadSigner загружает сертификат подписанта и приватный ключ ECDSA из PEM-файлов.
 // Возвращает (*x509.Certificate, *ecdsa.PrivateKey, error). Ключ должен соответствовать публичному ключу сертификата.
 func loadSigner(certPath, keyPath string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
-	certPEM, err := os.ReadFile(certPath)
+	cert, err := loadSignerCert(certPath)
 	if err != nil {
-		return nil, nil, fmt.Errorf("signer cert: %w", err)
-	}
-	block, _ := pem.Decode(certPEM)
-	if block == nil {
-		return nil, nil, fmt.Errorf("signer cert: no PEM block")
-	}
-	cert, err := x509.ParseCertificate(block.Bytes)
-	if err != nil {
-		return nil, nil, fmt.Errorf("signer cert: %w", err)
+		return nil, nil, err
 	}
 
 	keyPEM, err := os.ReadFile(keyPath)
 	if err != nil {
 		return nil, nil, fmt.Errorf("signer key: %w", err)
 	}
-	block, _ = pem.Decode(keyPEM)
+	block, _ := pem.Decode(keyPEM)
 	if block == nil {
 		return nil, nil, fmt.Errorf("signer key: no PEM block")
 	}
@@ -145,43 +195,101 @@ func loadSigner(certPath, keyPath string) (*x509.Certificate, *ecdsa.PrivateKey,
 	return cert, key, nil
 }
 
+// loadSignerCert загружает только сертификат подписанта из PEM (режим -plan ключ не читает).
+func loadSignerCert(certPath string) (*x509.Certificate, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("signer cert: %w", err)
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("signer cert: no PEM block")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("signer cert: %w", err)
+	}
+	return cert, nil
+}
+
 // loadSafeBags преобразует конфиг мешков в формат registry.SafeBagInput.
-// Для каждого мешка: читает сертификат из PEM, парсит roleNotBefore/roleNotAfter (RFC3339), декодирует localKeyID (hex).
-func loadSafeBags(cfgs []SafeBagConfig) ([]registry.SafeBagInput, error) {
-	var out []registry.SafeBagInput
+// Набор .p7b в cert даёт по мешку на каждый сертификат с атрибутами этого элемента конфига;
+// мешки с issue получают сертификат из issued. origin[j] — индекс элемента cfgs, из которого получен мешок j.
+func loadSafeBags(cfgs []registry.SafeBagConfig, issued []issuedCert) (out []registry.SafeBagInput, origin []int, err error) {
+	issuedDER := make(map[int][]byte, len(issued))
+	for _, ic := range issued {
+		issuedDER[ic.Index] = ic.Cert.Raw
+	}
 	for i, c := range cfgs {
-		certPEM, err := os.ReadFile(c.Cert)
+		// Сертификат выпущен при сборке или ещё не выпущен (режим -plan: мешок без CertDER, план покажет описание выпуска).
+		if c.Issue != nil && c.Cert == "" {
+			in, err := safeBagInput(i, c, issuedDER[i])
+			if err != nil {
+				return nil, nil, err
+			}
+			out, origin = append(out, in), append(origin, i)
+			continue
+		}
+		certs, err := readSafeBagCerts(c.Cert)
 		if err != nil {
-			return nil, fmt.Errorf("safeBags[%d] cert %s: %w", i, c.Cert, err)
+			return nil, nil, fmt.Errorf("safeBags[%d] cert %s: %w", i, c.Cert, err)
 		}
-		block, _ := pem.Decode(certPEM)
-		if block == nil {
-			return nil, fmt.Errorf("safeBags[%d] cert: no PEM block", i)
+		if len(certs) > 1 && c.LocalKeyID != "" {
+			return nil, nil, i18n.Errorf("safeBags[%d]: localKeyID задан для набора из %d сертификатов", i, len(certs))
 		}
-
-		var localKeyID []byte
-		if c.LocalKeyID != "" {
-			localKeyID, err = hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(c.LocalKeyID), "0x"))
+		for _, certDER := range certs {
+			in, err := safeBagInput(i, c, certDER)
 			if err != nil {
-				return nil, fmt.Errorf("safeBags[%d] localKeyID: %w", i, err)
+				return nil, nil, err
 			}
+			out, origin = append(out, in), append(origin, i)
 		}
+	}
+	return out, origin, nil
+}
 
-		var nb, na time.Time
-		if c.RoleNotBefore != "" {
-			nb, _ = time.Parse(time.RFC3339, c.RoleNotBefore)
-		}
-		if c.RoleNotAfter != "" {
-			na, _ = time.Parse(time.RFC3339, c.RoleNotAfter)
+// readSafeBagCerts читает сертификат мешка: первый блок PEM или все сертификаты набора .p7b
+// (PEM PKCS7/CMS либо DER ContentInfo, как выгружает registry-analyzer -export-p7b и Windows).
+func readSafeBagCerts(path string) ([][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block != nil && block.Type != cms.PEMTypePKCS7 && block.Type != "CMS" {
+		return [][]byte{block.Bytes}, nil
+	}
+	certs, err := cms.ParseCertificates(data)
+	if err != nil {
+		return nil, fmt.Errorf("no PEM block or .p7b: %w", err)
+	}
+	return certs, nil
+}
+
+// safeBagInput переводит атрибуты мешка из конфига (RFC3339-сроки роли, hex localKeyID) в registry.SafeBagInput.
+func safeBagInput(i int, c registry.SafeBagConfig, certDER []byte) (registry.SafeBagInput, error) {
+	var localKeyID []byte
+	if c.LocalKeyID != "" {
+		var err error
+		localKeyID, err = hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(c.LocalKeyID), "0x"))
+		if err != nil {
+			return registry.SafeBagInput{}, fmt.Errorf("safeBags[%d] localKeyID: %w", i, err)
 		}
+	}
 
-		out = append(out, registry.SafeBagInput{
-			CertDER:       block.Bytes,
-			RoleName:      c.RoleName,
-			RoleNotBefore: nb,
-			RoleNotAfter:  na,
-			LocalKeyID:    localKeyID,
-		})
+	var nb, na time.Time
+	if c.RoleNotBefore != "" {
+		nb, _ = time.Parse(time.RFC3339, c.RoleNotBefore)
 	}
-	return out, nil
+	if c.RoleNotAfter != "" {
+		na, _ = time.Parse(time.RFC3339, c.RoleNotAfter)
+	}
+
+	return registry.SafeBagInput{
+		CertDER:       certDER,
+		RoleName:      c.RoleName,
+		RoleNotBefore: nb,
+		RoleNotAfter:  na,
+		LocalKeyID:    localKeyID,
+	}, nil
 }
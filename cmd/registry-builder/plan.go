@@ -0,0 +1,138 @@
+// plan.go — режим -plan registry-builder: план сборки (подписант, атрибуты, мешки, localKeyID, предупреждения)
+// в text или json без ключа подписанта; мешки safeBags[].issue описываются, а не выпускаются.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+	"github.com/sgw-registry/registry-analyzer/internal/pki"
+	"github.com/sgw-registry/registry-analyzer/internal/registry"
+)
+
+// runPlan выводит план сборки (-plan): подписант, атрибуты, мешки, localKeyID и предупреждения.
+// Ключ подписанта и ключ CA не читаются; сертификаты для safeBags[].issue не выпускаются, а описываются.
+func runPlan(cfg *registry.Config, format string, warnDays int, useColor bool) error {
+	signerCert, err := loadSignerCert(cfg.SignerCert)
+	if err != nil {
+		return err
+	}
+	safeBags, origin, err := loadSafeBags(cfg.SafeBags, nil)
+	if err != nil {
+		return i18n.Errorf("загрузка SafeBags: %w", err)
+	}
+	verTime := time.Time{}
+	if cfg.VERTimestamp != "" {
+		verTime, _ = time.Parse(time.RFC3339, cfg.VERTimestamp)
+	}
+	attrs, err := signerAttrs(cfg, verTime)
+	if err != nil {
+		return i18n.Errorf("атрибуты подписанта: %w", err)
+	}
+
+	plan, err := registry.BuildPlan(signerCert, safeBags, attrs, registry.PlanOptions{
+		ExpiryWarning: time.Duration(warnDays) * 24 * time.Hour,
+	})
+	if err != nil {
+		return err
+	}
+	describeIssues(cfg, plan, origin)
+
+	switch format {
+	case "json":
+		b, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	case "text", "":
+		var sb strings.Builder
+		plan.TextOutput(&sb, useColor)
+		fmt.Print(sb.String())
+	default:
+		return i18n.Errorf("неизвестный формат плана %q (text, json)", format)
+	}
+	return nil
+}
+
+// describeIssues дополняет план описанием сертификатов, которые будут выпущены при сборке (safeBags[].issue):
+// издатель (только сертификат CA), итоговый subject по шаблону или CSR. origin[j] — индекс cfg.SafeBags мешка j плана.
+func describeIssues(cfg *registry.Config, plan *registry.Plan, origin []int) {
+	var caSubject string
+	for j, i := range origin {
+		sb := cfg.SafeBags[i]
+		if sb.Issue == nil || sb.Cert != "" || j >= len(plan.SafeBags) {
+			continue
+		}
+		scope := fmt.Sprintf("safeBags[%d]", i+1)
+		warn := func(format string, args ...interface{}) {
+			plan.Warnings = append(plan.Warnings, registry.PlanWarning{Scope: scope, Message: i18n.Sprintf(format, args...)})
+		}
+		if caSubject == "" {
+			caSubject = "?"
+			if cfg.CA == nil || cfg.CA.Cert == "" {
+				warn("issue требует ca.cert и ca.key")
+			} else if data, err := os.ReadFile(cfg.CA.Cert); err != nil {
+				warn("CA: %v", err)
+			} else if ca, err := pki.ParseCertificatePEM(data); err != nil {
+				warn("CA %s: %v", cfg.CA.Cert, err)
+			} else {
+				caSubject = ca.Subject.String()
+			}
+		}
+
+		subject, err := renderSubject(sb.Issue.Subject, subjectData{RoleName: sb.RoleName, Index: i + 1, VIN: cfg.VIN, UID: cfg.UID})
+		if err != nil {
+			warn("%v", err)
+		}
+		source := i18n.T("новый ключ") + " " + keyTypeOrDefault(sb.Issue.KeyType)
+		fromCSR := false
+		if sb.Issue.CSR != "" {
+			source = "CSR " + sb.Issue.CSR
+			if subject == "" {
+				if data, err := os.ReadFile(sb.Issue.CSR); err != nil {
+					warn("CSR: %v", err)
+				} else if csr, err := pki.ParseCSR(data); err != nil {
+					warn("CSR %s: %v", sb.Issue.CSR, err)
+				} else {
+					// DN из CSR выпускается без изменений (RawSubject), поэтому ParseSubject к нему не применяется.
+					subject, fromCSR = csr.Subject.String(), true
+				}
+			}
+		}
+		if subject == "" {
+			warn("subject не задан")
+		} else if !fromCSR {
+			if name, err := pki.ParseSubject(subject); err != nil {
+				warn("subject: %v", err)
+			} else {
+				subject = name.String()
+			}
+		}
+		plan.SafeBags[j].Cert.Subject = subject
+		plan.SafeBags[j].Issue = i18n.Sprintf("будет выпущен CA %s (%s)", caSubject, source)
+		if plan.SafeBags[j].LocalKeyIDSource == registry.LocalKeyIDNone {
+			plan.SafeBags[j].LocalKeyIDSource = registry.LocalKeyIDFromSKID
+		}
+	}
+}
+
+func keyTypeOrDefault(kt string) string {
+	if kt == "" {
+		return pki.KeyECDSAP256
+	}
+	return kt
+}
+
+// isTerminal возвращает true, если f — терминал (в этом случае включается цветной вывод).
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return (info.Mode() & os.ModeCharDevice) != 0
+}
@@ -0,0 +1,131 @@
+// der.go — режим -der registry-diff: структурное сравнение деревьев DER двух реестров (asn1tree.Diff)
+// с маской изменчивых полей (-mask) и вывод в text или json.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sgw-registry/registry-analyzer/internal/asn1schema"
+	"github.com/sgw-registry/registry-analyzer/internal/asn1tree"
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+	"github.com/sgw-registry/registry-analyzer/internal/registry"
+)
+
+// derDiff — результат режима -der для JSON.
+type derDiff struct {
+	Old         string                `json:"old"`
+	New         string                `json:"new"`
+	Masked      []string              `json:"masked"`
+	Differences []asn1tree.Difference `json:"differences"`
+}
+
+// runDERDiff сравнивает деревья DER двух файлов, подписанные полями registry.asn1, и возвращает код выхода:
+// 0 — структура совпадает, 2 — есть различия, 1 — ошибка чтения или разбора.
+func runDERDiff(oldPath, newPath string, mask []string, limits registry.Limits, jsonFormat, useColor bool, outputPath string) int {
+	s, err := asn1schema.RegistrySchema()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	tree := func(path string) []*asn1tree.Node {
+		data, err := der.ReadFile(path, int64(limits.MaxInputSize))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("чтение файла: %v\n"), err)
+			return nil
+		}
+		nodes, err := asn1tree.Parse(data, 0, limits.MaxDepth)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("разбор DER %s: %v\n"), path, err)
+			return nil
+		}
+		s.Annotate(nodes, asn1schema.RegistryRoot)
+		return nodes
+	}
+	a, b := tree(oldPath), tree(newPath)
+	if a == nil || b == nil {
+		return 1
+	}
+	d := derDiff{Old: oldPath, New: newPath, Masked: mask, Differences: asn1tree.Diff(a, b, asn1tree.DiffOptions{Mask: mask})}
+	if d.Differences == nil {
+		d.Differences = []asn1tree.Difference{}
+	}
+
+	var out []byte
+	if jsonFormat {
+		b, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "JSON: %v\n", err)
+			return 1
+		}
+		out = append(b, '\n')
+	} else {
+		var sb strings.Builder
+		derDiffTextOutput(&sb, &d, useColor)
+		out = []byte(sb.String())
+	}
+	if outputPath != "" {
+		if err := os.WriteFile(outputPath, out, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("запись в файл: %v\n"), err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, i18n.T("Отчёт сравнения DER записан в %s\n"), outputPath)
+	} else {
+		os.Stdout.Write(out)
+	}
+	if len(d.Differences) > 0 {
+		return 2
+	}
+	return 0
+}
+
+// derDiffKinds — подписи видов различий для текстового отчёта (ключи каталога i18n).
+var derDiffKinds = map[string]string{
+	asn1tree.DiffTag:           "тег",
+	asn1tree.DiffLengthForm:    "форма длины",
+	asn1tree.DiffEncapsulation: "вложенный DER",
+	asn1tree.DiffOrder:         "порядок",
+	asn1tree.DiffMissing:       "нет во втором",
+	asn1tree.DiffExtra:         "нет в первом",
+	asn1tree.DiffValue:         "значение",
+}
+
+func derDiffTextOutput(sb *strings.Builder, d *derDiff, useColor bool) {
+	c := func(code string) string {
+		if useColor {
+			return code
+		}
+		return ""
+	}
+	sb.WriteString(fmt.Sprintf("%s → %s\n", d.Old, d.New))
+	if useColor {
+		sb.WriteString("\n" + registry.Bold + registry.IconPFX + " " + i18n.T("Сравнение структуры DER") + registry.Reset + "\n")
+	} else {
+		sb.WriteString("\n=== " + i18n.T("Сравнение структуры DER") + " ===\n")
+	}
+	sb.WriteString(i18n.Sprintf("  %sмаскированы: %s%s\n", c(registry.Dim), strings.Join(d.Masked, "; "), c(registry.Reset)))
+	if len(d.Differences) == 0 {
+		sb.WriteString("  " + c(registry.Green) + i18n.T("структура совпадает") + c(registry.Reset) + "\n")
+		return
+	}
+	sb.WriteString(i18n.Sprintf("  различий: %d\n", len(d.Differences)))
+	offset := func(off int) string {
+		if off < 0 {
+			return "—"
+		}
+		return fmt.Sprintf("@%d", off)
+	}
+	for _, diff := range d.Differences {
+		sb.WriteString(fmt.Sprintf("    %s%s%s %s [%s / %s]\n", c(registry.Yellow), i18n.T(derDiffKinds[diff.Kind]), c(registry.Reset),
+			diff.Path, offset(diff.OffsetA), offset(diff.OffsetB)))
+		if diff.A != "" {
+			sb.WriteString(fmt.Sprintf("        %s- %s%s\n", c(registry.Red), diff.A, c(registry.Reset)))
+		}
+		if diff.B != "" {
+			sb.WriteString(fmt.Sprintf("        %s+ %s%s\n", c(registry.Green), diff.B, c(registry.Reset)))
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Пакет main — утилита семантического сравнения двух реестров ATOM-PKCS12-REGISTRY (registry-diff).
+//
+// registry-diff разбирает два контейнера .p12 и сравнивает их по смыслу, а не побайтно: атрибуты подписанта (VIN, VER, UID),
+// сертификат подписанта и SafeBags. Мешки сопоставляются по отпечатку сертификата (SHA-256), затем по localKeyID;
+// выводятся добавленные и удалённые мешки, изменения roleName и roleValidityPeriod и смена сертификата при том же localKeyID.
+// С -der сравнивается структура DER: деревья выравниваются узел за узлом по полям registry.asn1, изменчивые значения
+// (подпись, messageDigest, signingTime) маскируются — проверка совместимости вывода registry-builder с эталоном.
+//
+// Запуск: go run ./cmd/registry-diff [опции] [-lang en] <old.p12> <new.p12>
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sgw-registry/registry-analyzer/internal/asn1schema"
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+	"github.com/sgw-registry/registry-analyzer/internal/registry"
+)
+
+func main() {
+	// Язык сообщений выбирается до объявления флагов, чтобы справка -h выводилась уже на нём.
+	if err := i18n.Init(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	format := flag.String("format", "text", i18n.T("Формат вывода: text, json"))
+	outputPath := flag.String("output", "", i18n.T("Записать вывод в файл (по умолчанию — stdout)"))
+	noColor := flag.Bool("no-color", false, i18n.T("Отключить цветной вывод и иконки"))
+	colorFlag := flag.String("color", "auto", i18n.T("Цвет: auto (только TTY), always, never"))
+	derMode := flag.Bool("der", false, i18n.T("Сравнить структуру DER (теги, форма длины, порядок, значения) вместо семантики реестра"))
+	mask := flag.String("mask", "", i18n.T("Для -der: дополнительные маскируемые поля через запятую (подпись поля схемы или её окончание, например CertBag.certValue)"))
+	maxSize := flag.Int("max-size", registry.DefaultLimits.MaxInputSize, i18n.T("Максимальный размер контейнера, байт; больший файл не читается и не разбирается"))
+	flag.String("lang", "", i18n.T(i18n.FlagUsage))
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		fmt.Fprintf(os.Stderr, i18n.T("Использование: %s [опции] <old.p12> <new.p12>\n"), os.Args[0])
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	limits := registry.DefaultLimits
+	limits.MaxInputSize = *maxSize
+	useColor := !*noColor && *outputPath == "" && (*colorFlag == "always" || (*colorFlag != "never" && isTerminal(os.Stdout)))
+	if f := strings.ToLower(*format); f != "text" && f != "json" {
+		fmt.Fprintf(os.Stderr, i18n.T("неизвестный формат: %s (ожидается text или json)\n"), *format)
+		os.Exit(1)
+	}
+	if *derMode {
+		masked := append([]string{}, asn1schema.RegistryVolatile...)
+		for _, m := range strings.Split(*mask, ",") {
+			if m = strings.TrimSpace(m); m != "" {
+				masked = append(masked, m)
+			}
+		}
+		os.Exit(runDERDiff(flag.Arg(0), flag.Arg(1), masked, limits, strings.EqualFold(*format, "json"), useColor, *outputPath))
+	}
+	old := load(flag.Arg(0), limits)
+	new := load(flag.Arg(1), limits)
+	d := registry.Diff(old, new)
+
+	var out []byte
+	switch strings.ToLower(*format) {
+	case "json":
+		b, err := json.MarshalIndent(struct {
+			Old string `json:"old"`
+			New string `json:"new"`
+			*registry.RegistryDiff
+		}{flag.Arg(0), flag.Arg(1), d}, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "JSON: %v\n", err)
+			os.Exit(1)
+		}
+		out = append(b, '\n')
+	default:
+		var sb strings.Builder
+		if useColor {
+			sb.WriteString(fmt.Sprintf("%s%s%s → %s\n", registry.Bold, flag.Arg(0), registry.Reset, flag.Arg(1)))
+		} else {
+			sb.WriteString(fmt.Sprintf("%s → %s\n", flag.Arg(0), flag.Arg(1)))
+		}
+		d.TextOutput(&sb, useColor)
+		out = []byte(sb.String())
+	}
+	if *outputPath != "" {
+		if err := os.WriteFile(*outputPath, out, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("запись в файл: %v\n"), err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, i18n.T("Отчёт сравнения записан в %s\n"), *outputPath)
+	} else {
+		os.Stdout.Write(out)
+	}
+
+	// Код выхода 2 — реестры различаются (1 зарезервирован для ошибок чтения и разбора).
+	if !d.Empty() {
+		os.Exit(2)
+	}
+}
+
+// load читает и разбирает контейнер; при ошибке печатает её в stderr и завершает программу с кодом 1.
+func load(path string, limits registry.Limits) *registry.Container {
+	data, err := der.ReadFile(path, int64(limits.MaxInputSize))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("чтение файла: %v\n"), err)
+		os.Exit(1)
+	}
+	c, err := registry.ParseWithLimits(data, limits)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("разбор %s: %v\n"), path, err)
+		os.Exit(1)
+	}
+	return c
+}
+
+// isTerminal возвращает true, если f — терминал (в этом случае включается цветной вывод).
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return (info.Mode() & os.ModeCharDevice) != 0
+}
@@ -0,0 +1,313 @@
+// Пакет main — утилита подготовки тестовой PKI для реестров ATOM-PKCS12-REGISTRY (registry-pki).
+//
+// registry-pki заменяет scripts/generate_certs.sh и scripts/generate_signer_from_root.sh: по одному декларативному
+// JSON-описанию создаёт корневой CA, промежуточный CA (опционально), подписанта реестра с SKID/AKID и keyUsage
+// и сертификаты ролей (Driver, Passenger, IVI, Mobile-Driver). Раскладка файлов совпадает с тем, что ожидает config.json
+// (certs/signer.pem, certs/signer-key.pem, certs/driver.pem, ...); при заданном config.path пишется готовый конфиг registry-builder.
+// OpenSSL не требуется.
+//
+// Запуск: go run ./cmd/registry-pki [-spec pki.json] [-out certs] [-force] [-lang en]
+package main
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+	"github.com/sgw-registry/registry-analyzer/internal/pki"
+	"github.com/sgw-registry/registry-analyzer/internal/registry"
+)
+
+// Spec — декларативное описание PKI.
+// Root и Signer обязательны; Intermediate опционален. Листовые сертификаты выпускаются от Intermediate (если задан),
+// иначе от Root; при selfSigned=true — самоподписанные, как в scripts/generate_certs.sh.
+type Spec struct {
+	OutDir       string      `json:"outDir"`
+	Root         *EntitySpec `json:"root"`
+	Intermediate *EntitySpec `json:"intermediate,omitempty"`
+	Signer       *EntitySpec `json:"signer"`
+	Leaves       []LeafSpec  `json:"leaves"`
+	Config       *ConfigSpec `json:"config,omitempty"`
+}
+
+// EntitySpec — один выпускаемый сертификат: базовое имя файлов (<name>.pem, <name>-key.pem) и параметры сертификата.
+type EntitySpec struct {
+	Name string `json:"name"`
+	pki.CertSpec
+}
+
+// LeafSpec — сертификат роли для SafeBag: параметры сертификата, атрибуты мешка для config.json и способ выпуска.
+type LeafSpec struct {
+	EntitySpec
+	SelfSigned    bool   `json:"selfSigned,omitempty"`
+	RoleName      string `json:"roleName,omitempty"`
+	RoleNotBefore string `json:"roleNotBefore,omitempty"`
+	RoleNotAfter  string `json:"roleNotAfter,omitempty"`
+}
+
+// ConfigSpec — параметры генерируемого конфига registry-builder (как блок cat > config.json в generate_certs.sh).
+type ConfigSpec struct {
+	Path         string `json:"path"`
+	VIN          string `json:"vin"`
+	VERTimestamp string `json:"verTimestamp"`
+	VERVersion   int    `json:"verVersion"`
+	UID          string `json:"uid"`
+}
+
+// defaultSpec — набор файлов scripts/generate_signer_from_root.sh + scripts/generate_certs.sh, выстроенный в цепочку:
+// корневой CA (2 года) → промежуточный CA → подписант и четыре сертификата ролей.
+func defaultSpec() Spec {
+	leaf := func(name, cn, role, nb, na string) LeafSpec {
+		return LeafSpec{
+			EntitySpec: EntitySpec{Name: name, CertSpec: pki.CertSpec{Subject: "/CN=" + cn}},
+			RoleName:   role, RoleNotBefore: nb, RoleNotAfter: na,
+		}
+	}
+	return Spec{
+		OutDir:       "certs",
+		Root:         &EntitySpec{Name: "root-ca", CertSpec: pki.CertSpec{Subject: "/CN=ATOM Registry Root CA", IsCA: true, ValidityDays: 2 * pki.DefaultValidityDays}},
+		Intermediate: &EntitySpec{Name: "intermediate-ca", CertSpec: pki.CertSpec{Subject: "/CN=ATOM Registry Intermediate CA", IsCA: true, ValidityDays: 2 * pki.DefaultValidityDays}},
+		Signer:       &EntitySpec{Name: "signer", CertSpec: pki.CertSpec{Subject: "/CN=Owner Registry Signer", KeyUsage: []string{"digitalSignature", "nonRepudiation"}}},
+		Leaves: []LeafSpec{
+			leaf("driver", "Driver-Certificate", "delegate", "2026-01-15T17:40:20Z", "2027-01-15T17:40:20Z"),
+			leaf("passenger", "Passenger-Certificate", "not_delegate", "2026-01-15T17:40:20Z", "2027-01-15T17:40:20Z"),
+			leaf("ivi", "IVI-Certificate", "delegate", "2026-01-15T17:40:21Z", "2027-01-15T17:40:21Z"),
+			leaf("mobile-driver", "Mobile-Driver-Certificate", "driver-mobile", "2026-01-15T17:40:21Z", "2027-01-15T17:40:21Z"),
+		},
+		Config: &ConfigSpec{
+			Path:         "config.json",
+			VIN:          "EAY2AT0MPS2013376",
+			VERTimestamp: "2024-01-01T00:00:00Z",
+			VERVersion:   100,
+			UID:          "emailAddress=client.a@atom.team,CN=Client A,OU=Sales,O=KAMA,L=SPb,ST=SPb,C=RU",
+		},
+	}
+}
+
+func main() {
+	// Язык сообщений выбирается до объявления флагов, чтобы справка -h выводилась уже на нём.
+	if err := i18n.Init(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	specPath := flag.String("spec", "", i18n.T("Путь к JSON-описанию PKI (root, intermediate, signer, leaves, config); без флага — набор как в scripts/generate_certs.sh"))
+	outDir := flag.String("out", "", i18n.T("Каталог для ключей и сертификатов (переопределяет outDir из описания)"))
+	force := flag.Bool("force", false, i18n.T("Перезаписывать существующие ключи, сертификаты и конфиг"))
+	printSpec := flag.Bool("print-spec", false, i18n.T("Вывести описание PKI по умолчанию (JSON) и выйти"))
+	flag.String("lang", "", i18n.T(i18n.FlagUsage))
+	flag.Parse()
+
+	spec := defaultSpec()
+	if *printSpec {
+		b, _ := json.MarshalIndent(spec, "", "  ")
+		fmt.Println(string(b))
+		return
+	}
+	if *specPath != "" {
+		data, err := os.ReadFile(*specPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("чтение описания: %v\n"), err)
+			os.Exit(1)
+		}
+		spec = Spec{}
+		if err := json.Unmarshal(data, &spec); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("разбор описания: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+	if *outDir != "" {
+		spec.OutDir = *outDir
+	}
+	if err := run(&spec, *force); err != nil {
+		fmt.Fprintf(os.Stderr, "registry-pki: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run создаёт PKI по описанию: root → (intermediate) → signer и листовые сертификаты, затем config.json.
+func run(spec *Spec, force bool) error {
+	if spec.Root == nil || spec.Signer == nil {
+		return fmt.Errorf("root and signer are required")
+	}
+	if spec.OutDir == "" {
+		spec.OutDir = "certs"
+	}
+	if err := os.MkdirAll(spec.OutDir, 0755); err != nil {
+		return err
+	}
+	w := writer{dir: spec.OutDir, force: force}
+	if err := w.check(spec); err != nil {
+		return err
+	}
+
+	// 1. Корневой CA (самоподписанный, basicConstraints CA:true, keyCertSign/cRLSign).
+	root := *spec.Root
+	root.IsCA = true
+	rootIssuer, err := w.selfSigned(root)
+	if err != nil {
+		return fmt.Errorf("root: %w", err)
+	}
+
+	// 2. Промежуточный CA (опционально), выпускается корнем.
+	caIssuer := rootIssuer
+	if spec.Intermediate != nil {
+		inter := *spec.Intermediate
+		inter.IsCA = true
+		if caIssuer, err = w.issue(rootIssuer, inter); err != nil {
+			return fmt.Errorf("intermediate: %w", err)
+		}
+	}
+
+	// 3. Подписант реестра: SKID (hash), AKID (keyid издателя), keyUsage digitalSignature.
+	signer := *spec.Signer
+	if len(signer.KeyUsage) == 0 {
+		signer.KeyUsage = []string{"digitalSignature"}
+	}
+	if _, err := w.issue(caIssuer, signer); err != nil {
+		return fmt.Errorf("signer: %w", err)
+	}
+
+	// 4. Сертификаты ролей.
+	var bags []registry.SafeBagConfig
+	for i, l := range spec.Leaves {
+		var is *pki.Issuer
+		if l.SelfSigned {
+			is, err = w.selfSigned(l.EntitySpec)
+		} else {
+			is, err = w.issue(caIssuer, l.EntitySpec)
+		}
+		if err != nil {
+			return fmt.Errorf("leaves[%d] %s: %w", i, l.Name, err)
+		}
+		bags = append(bags, registry.SafeBagConfig{
+			Cert:          filepath.ToSlash(filepath.Join(spec.OutDir, l.Name+".pem")),
+			RoleName:      l.RoleName,
+			RoleNotBefore: l.RoleNotBefore,
+			RoleNotAfter:  l.RoleNotAfter,
+			LocalKeyID:    hex.EncodeToString(is.Cert.SubjectKeyId),
+		})
+	}
+
+	fmt.Fprintf(os.Stderr, i18n.T("Готово. Ключи и сертификаты: %s/\n"), spec.OutDir)
+
+	// 5. Конфиг registry-builder с актуальными localKeyID (SubjectKeyIdentifier).
+	if spec.Config != nil && spec.Config.Path != "" {
+		cfg := registry.Config{
+			SignerCert:   filepath.ToSlash(filepath.Join(spec.OutDir, spec.Signer.Name+".pem")),
+			SignerKey:    filepath.ToSlash(filepath.Join(spec.OutDir, spec.Signer.Name+"-key.pem")),
+			VIN:          spec.Config.VIN,
+			VERTimestamp: spec.Config.VERTimestamp,
+			VERVersion:   spec.Config.VERVersion,
+			UID:          spec.Config.UID,
+			SafeBags:     bags,
+		}
+		b, err := json.MarshalIndent(cfg, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(spec.Config.Path, append(b, '\n'), 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, i18n.T("  Конфиг:      %s\n"), spec.Config.Path)
+		fmt.Fprintf(os.Stderr, i18n.T("Сборка реестра:\n  ./registry-builder -config %s -output sgw-my-registry.p12\n"), spec.Config.Path)
+	}
+	return nil
+}
+
+// writer генерирует ключи и записывает пары <name>.pem / <name>-key.pem в каталог.
+type writer struct {
+	dir   string
+	force bool
+}
+
+func (w writer) paths(name string) (certPath, keyPath string) {
+	return filepath.Join(w.dir, name+".pem"), filepath.Join(w.dir, name+"-key.pem")
+}
+
+// check проверяет описание до генерации: имя сущности обязательно и уникально (иначе файлы одной сущности
+// перезапишут другую), ключ подписанта при заданном config.path — ECDSA (registry-builder подписывает только ECDSA),
+// а без -force ни один из выходных файлов, включая конфиг, не должен существовать. Конфликт, найденный
+// на середине выпуска, оставил бы PKI собранной наполовину.
+func (w writer) check(spec *Spec) error {
+	labels := []string{"root", "signer"}
+	names := []string{spec.Root.Name, spec.Signer.Name}
+	if spec.Intermediate != nil {
+		labels = append(labels, "intermediate")
+		names = append(names, spec.Intermediate.Name)
+	}
+	for i, l := range spec.Leaves {
+		labels = append(labels, fmt.Sprintf("leaves[%d]", i))
+		names = append(names, l.Name)
+	}
+	seen := make(map[string]string, len(names))
+	for i, name := range names {
+		if name == "" {
+			return fmt.Errorf("%s: name is required", labels[i])
+		}
+		if prev, ok := seen[name]; ok {
+			return fmt.Errorf("%s: name %q already used by %s", labels[i], name, prev)
+		}
+		seen[name] = labels[i]
+	}
+	var outputs []string
+	for _, name := range names {
+		certPath, keyPath := w.paths(name)
+		outputs = append(outputs, certPath, keyPath)
+	}
+	if spec.Config != nil && spec.Config.Path != "" {
+		switch strings.ToLower(spec.Signer.KeyType) {
+		case "", pki.KeyECDSAP256, pki.KeyECDSAP384:
+		default:
+			return fmt.Errorf("signer: keyType %q cannot sign a registry (registry-builder supports %s and %s only)", spec.Signer.KeyType, pki.KeyECDSAP256, pki.KeyECDSAP384)
+		}
+		outputs = append(outputs, spec.Config.Path)
+	}
+	if w.force {
+		return nil
+	}
+	for _, p := range outputs {
+		if _, err := os.Stat(p); err == nil {
+			return fmt.Errorf("%s already exists (use -force to overwrite)", p)
+		}
+	}
+	return nil
+}
+
+func (w writer) selfSigned(e EntitySpec) (*pki.Issuer, error) {
+	return w.create(e, func(key crypto.Signer) (*x509.Certificate, error) {
+		return pki.SelfSigned(e.CertSpec, key)
+	})
+}
+
+func (w writer) issue(issuer *pki.Issuer, e EntitySpec) (*pki.Issuer, error) {
+	return w.create(e, func(key crypto.Signer) (*x509.Certificate, error) {
+		return issuer.Issue(e.CertSpec, key.Public())
+	})
+}
+
+func (w writer) create(e EntitySpec, sign func(crypto.Signer) (*x509.Certificate, error)) (*pki.Issuer, error) {
+	certPath, keyPath := w.paths(e.Name)
+	key, err := pki.GenerateKey(e.KeyType)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := sign(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := pki.WriteKeyPEM(keyPath, key); err != nil {
+		return nil, err
+	}
+	if err := pki.WriteCertPEM(certPath, cert); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(os.Stderr, "  %-28s %s (SKID %x)\n", certPath, cert.Subject, cert.SubjectKeyId)
+	return &pki.Issuer{Cert: cert, Key: key}, nil
+}
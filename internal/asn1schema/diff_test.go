@@ -0,0 +1,80 @@
+package asn1schema
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sgw-registry/registry-analyzer/internal/asn1tree"
+	"github.com/sgw-registry/registry-analyzer/internal/registry"
+)
+
+// TestDiffRegistryVolatile собирает реестр дважды из одних данных с разным signingTime: с маской RegistryVolatile
+// структура совпадает, без маски различаются подпись и signingTime; другой VIN — различие значения.
+func TestDiffRegistryVolatile(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	template := &x509.Certificate{
+		Subject:      pkix.Name{CommonName: "Test Registry Signer"},
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		SubjectKeyId: []byte{1, 2, 3, 4},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(certDER)
+	s, err := RegistrySchema()
+	if err != nil {
+		t.Fatalf("RegistrySchema: %v", err)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	build := func(vin string, signingTime time.Time) []*asn1tree.Node {
+		t.Helper()
+		data, err := registry.BuildRegistry(cert, key, []registry.SafeBagInput{{
+			CertDER: certDER, RoleName: "delegate", RoleNotBefore: now, RoleNotAfter: now.Add(time.Hour), LocalKeyID: []byte{1, 2, 3, 4},
+		}}, registry.SignerAttrs{VIN: vin, VERTimestamp: now, VERVersion: 1, UID: "CN=Test", SigningTime: signingTime})
+		if err != nil {
+			t.Fatalf("BuildRegistry: %v", err)
+		}
+		nodes, err := asn1tree.Parse(data, 0, 32)
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		s.Annotate(nodes, RegistryRoot)
+		return nodes
+	}
+	a, b := build("TESTVIN123", now), build("TESTVIN123", now.Add(time.Minute))
+
+	if diffs := asn1tree.Diff(a, b, asn1tree.DiffOptions{Mask: RegistryVolatile}); len(diffs) != 0 {
+		t.Errorf("с маской RegistryVolatile: %+v", diffs)
+	}
+	unmasked := asn1tree.Diff(a, b, asn1tree.DiffOptions{})
+	for _, want := range []string{
+		"PFX.authSafe.content.signerInfos[1].encryptedDigest",
+		"PFX.authSafe.content.signerInfos[1].authenticatedAttributes(id-signingTime)",
+	} {
+		found := false
+		for _, d := range unmasked {
+			found = found || strings.HasPrefix(d.Path, want)
+		}
+		if !found {
+			t.Errorf("без маски нет различия в %s: %+v", want, unmasked)
+		}
+	}
+
+	diffs := asn1tree.Diff(a, build("OTHERVIN12", now), asn1tree.DiffOptions{Mask: RegistryVolatile})
+	if len(diffs) != 1 || diffs[0].Kind != asn1tree.DiffValue || diffs[0].Path != "PFX.authSafe.content.signerInfos[1].authenticatedAttributes(id-atom-vin).attrValues.VIN" {
+		t.Errorf("другой VIN: %+v", diffs)
+	}
+}
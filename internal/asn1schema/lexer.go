@@ -0,0 +1,93 @@
+package asn1schema
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenKind — вид лексемы ASN.1.
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota
+	tokIdent            // идентификатор или ключевое слово: SEQUENCE, id-atom-vin, PFX
+	tokNumber           // неотрицательное целое
+	tokAssign           // ::=
+	tokRange            // ..
+	tokPunct            // { } ( ) [ ] , ; |
+)
+
+type token struct {
+	kind      tokenKind
+	text      string
+	line, col int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of file"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// lex разбивает текст модуля на лексемы. Комментарии "--" длятся до конца строки или до следующего "--".
+func lex(src string) ([]token, error) {
+	var out []token
+	line, col := 1, 1
+	rs := []rune(src)
+	advance := func(n int) {
+		for ; n > 0; n-- {
+			if rs[0] == '\n' {
+				line, col = line+1, 1
+			} else {
+				col++
+			}
+			rs = rs[1:]
+		}
+	}
+	for len(rs) > 0 {
+		r := rs[0]
+		switch {
+		case unicode.IsSpace(r):
+			advance(1)
+		case r == '-' && len(rs) > 1 && rs[1] == '-':
+			advance(2)
+			for len(rs) > 0 && rs[0] != '\n' {
+				if rs[0] == '-' && len(rs) > 1 && rs[1] == '-' {
+					advance(2)
+					break
+				}
+				advance(1)
+			}
+		case strings.HasPrefix(string(rs[:min(3, len(rs))]), "::="):
+			out = append(out, token{tokAssign, "::=", line, col})
+			advance(3)
+		case r == '.' && len(rs) > 1 && rs[1] == '.':
+			out = append(out, token{tokRange, "..", line, col})
+			advance(2)
+		case strings.ContainsRune("{}()[],;|", r):
+			out = append(out, token{tokPunct, string(r), line, col})
+			advance(1)
+		case unicode.IsDigit(r):
+			n := 0
+			for n < len(rs) && unicode.IsDigit(rs[n]) {
+				n++
+			}
+			out = append(out, token{tokNumber, string(rs[:n]), line, col})
+			advance(n)
+		case unicode.IsLetter(r):
+			n := 0
+			for n < len(rs) && (unicode.IsLetter(rs[n]) || unicode.IsDigit(rs[n]) ||
+				rs[n] == '-' && n+1 < len(rs) && rs[n+1] != '-' && (unicode.IsLetter(rs[n+1]) || unicode.IsDigit(rs[n+1]))) {
+				n++
+			}
+			out = append(out, token{tokIdent, string(rs[:n]), line, col})
+			advance(n)
+		default:
+			return nil, fmt.Errorf("%d:%d: unexpected character %q", line, col, r)
+		}
+	}
+	out = append(out, token{tokEOF, "", line, col})
+	return out, nil
+}
@@ -0,0 +1,548 @@
+// Package asn1schema загружает модуль ASN.1 (подмножество нотации X.680, используемое в registry.asn1)
+// и проверяет DER-контейнер на соответствие загруженным типам, собирая все несоответствия.
+//
+// Поддерживаются: присваивания типов и значений OBJECT IDENTIFIER, SEQUENCE { ... }, SEQUENCE OF, SET OF, CHOICE,
+// теги [n] EXPLICIT/IMPLICIT (по умолчанию — из заголовка модуля, иначе EXPLICIT), OPTIONAL/DEFAULT,
+// ANY / ANY DEFINED BY, ограничение значения INTEGER (lo..hi) и именованные числа INTEGER {v3(3)}.
+package asn1schema
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"strconv"
+
+	"github.com/sgw-registry/registry-analyzer/internal/asn1tree"
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+)
+
+// Module — загруженный модуль ASN.1: типы и значения OBJECT IDENTIFIER в порядке определения.
+type Module struct {
+	Name       string
+	Tagging    string // EXPLICIT или IMPLICIT — тегирование по умолчанию
+	Types      map[string]*asn1tree.Type
+	TypeOrder  []string
+	Values     map[string]asn1.ObjectIdentifier
+	ValueOrder []string
+}
+
+// builtinTags — универсальные теги встроенных типов, которые допускаются в модуле.
+var builtinTags = map[string]int{
+	"BOOLEAN":           1,
+	"INTEGER":           der.TagInteger,
+	"BIT STRING":        3,
+	"OCTET STRING":      der.TagOctetString,
+	"NULL":              5,
+	"OBJECT IDENTIFIER": der.TagOID,
+	"ENUMERATED":        10,
+	"UTF8String":        12,
+	"PrintableString":   19,
+	"TeletexString":     20,
+	"T61String":         20,
+	"IA5String":         22,
+	"UTCTime":           23,
+	"GeneralizedTime":   24,
+	"VisibleString":     26,
+	"BMPString":         30,
+}
+
+// wellKnownArcs — имена корневых дуг OID, допустимые без числа: { iso 2 ... }.
+var wellKnownArcs = map[string]int{"itu-t": 0, "ccitt": 0, "iso": 1, "joint-iso-itu-t": 2, "joint-iso-ccitt": 2}
+
+type parser struct {
+	toks []token
+	pos  int
+	m    *Module
+}
+
+// Parse загружает модуль из текста src. Ошибки синтаксиса и ссылки на неопределённые типы или значения
+// возвращаются с позицией "строка:столбец".
+func Parse(src []byte) (*Module, error) {
+	toks, err := lex(string(src))
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks, m: &Module{
+		Tagging: "EXPLICIT",
+		Types:   make(map[string]*asn1tree.Type),
+		Values:  make(map[string]asn1.ObjectIdentifier),
+	}}
+	if err := p.module(); err != nil {
+		return nil, err
+	}
+	if err := p.m.check(); err != nil {
+		return nil, err
+	}
+	return p.m, nil
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return fmt.Errorf("%d:%d: %s", t.line, t.col, fmt.Sprintf(format, args...))
+}
+
+// accept съедает лексему с текстом text, если она следующая.
+func (p *parser) accept(text string) bool {
+	if t := p.peek(); t.kind != tokEOF && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return p.errorf(p.peek(), "expected %q, got %s", text, p.peek())
+	}
+	return nil
+}
+
+func (p *parser) ident() (token, error) {
+	t := p.next()
+	if t.kind != tokIdent {
+		return t, p.errorf(t, "expected identifier, got %s", t)
+	}
+	return t, nil
+}
+
+func (p *parser) number() (int64, error) {
+	t := p.next()
+	if t.kind != tokNumber {
+		return 0, p.errorf(t, "expected number, got %s", t)
+	}
+	v, err := strconv.ParseInt(t.text, 10, 64)
+	if err != nil {
+		return 0, p.errorf(t, "number %s: %v", t.text, err)
+	}
+	return v, nil
+}
+
+// module: Name [{ oid }] DEFINITIONS [EXPLICIT|IMPLICIT TAGS] ::= BEGIN присваивания END.
+func (p *parser) module() error {
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	p.m.Name = name.text
+	if p.peek().text == "{" {
+		if _, err := p.oidValue(); err != nil {
+			return err
+		}
+	}
+	if err := p.expect("DEFINITIONS"); err != nil {
+		return err
+	}
+	switch t := p.peek(); t.text {
+	case "EXPLICIT", "IMPLICIT":
+		p.next()
+		p.m.Tagging = t.text
+		if err := p.expect("TAGS"); err != nil {
+			return err
+		}
+	case "AUTOMATIC":
+		return p.errorf(t, "AUTOMATIC TAGS is not supported")
+	}
+	if err := p.expect("::="); err != nil {
+		return err
+	}
+	if err := p.expect("BEGIN"); err != nil {
+		return err
+	}
+	for !p.accept("END") {
+		if p.peek().kind == tokEOF {
+			return p.errorf(p.peek(), "expected END, got end of file")
+		}
+		if err := p.assignment(); err != nil {
+			return err
+		}
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return p.errorf(t, "unexpected %s after END", t)
+	}
+	return nil
+}
+
+// assignment: Type ::= тип (имя с заглавной буквы) или value OBJECT IDENTIFIER ::= { ... }.
+func (p *parser) assignment() error {
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	if isUpper(name.text) {
+		if _, dup := p.m.Types[name.text]; dup {
+			return p.errorf(name, "type %s is already defined", name.text)
+		}
+		if err := p.expect("::="); err != nil {
+			return err
+		}
+		t, err := p.typ()
+		if err != nil {
+			return err
+		}
+		t.Name = name.text // для Type ::= OtherType имя получает сама ссылка
+		p.m.Types[name.text] = t
+		p.m.TypeOrder = append(p.m.TypeOrder, name.text)
+		return nil
+	}
+	if _, dup := p.m.Values[name.text]; dup {
+		return p.errorf(name, "value %s is already defined", name.text)
+	}
+	if !p.accept("OBJECT") || !p.accept("IDENTIFIER") {
+		return p.errorf(p.peek(), "value %s: only OBJECT IDENTIFIER values are supported", name.text)
+	}
+	if err := p.expect("::="); err != nil {
+		return err
+	}
+	oid, err := p.oidValue()
+	if err != nil {
+		return err
+	}
+	p.m.Values[name.text] = oid
+	p.m.ValueOrder = append(p.m.ValueOrder, name.text)
+	return nil
+}
+
+// oidValue: { компоненты }, компонент — число, name(число) или ссылка на ранее определённый OID (только первым).
+func (p *parser) oidValue() (asn1.ObjectIdentifier, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var oid asn1.ObjectIdentifier
+	for !p.accept("}") {
+		t := p.next()
+		switch {
+		case t.kind == tokNumber:
+			v, err := strconv.Atoi(t.text)
+			if err != nil {
+				return nil, p.errorf(t, "arc %s: %v", t.text, err)
+			}
+			oid = append(oid, v)
+		case t.kind == tokIdent && p.accept("("):
+			v, err := p.number()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			oid = append(oid, int(v))
+		case t.kind == tokIdent && len(oid) == 0:
+			if base, ok := p.m.Values[t.text]; ok {
+				oid = append(oid, base...)
+			} else if arc, ok := wellKnownArcs[t.text]; ok {
+				oid = append(oid, arc)
+			} else {
+				return nil, p.errorf(t, "undefined value %s", t.text)
+			}
+		default:
+			return nil, p.errorf(t, "unexpected %s in OBJECT IDENTIFIER value", t)
+		}
+	}
+	if len(oid) < 2 {
+		return nil, p.errorf(p.toks[p.pos-1], "OBJECT IDENTIFIER value needs at least two arcs")
+	}
+	return oid, nil
+}
+
+// typ разбирает тип с необязательным ограничением в скобках.
+func (p *parser) typ() (*asn1tree.Type, error) {
+	t, err := p.baseType()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().text == "(" {
+		if err := p.constraint(t); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func (p *parser) baseType() (*asn1tree.Type, error) {
+	t := p.peek()
+	if t.text == "[" {
+		return p.taggedType()
+	}
+	if t.kind != tokIdent {
+		return nil, p.errorf(t, "expected type, got %s", t)
+	}
+	p.next()
+	switch t.text {
+	case "SEQUENCE", "SET":
+		if t.text == "SEQUENCE" && p.peek().text == "{" {
+			fields, err := p.components(false)
+			if err != nil {
+				return nil, err
+			}
+			return &asn1tree.Type{Kind: asn1tree.KindSequence, Fields: fields}, nil
+		}
+		if p.peek().text == "SIZE" {
+			p.next()
+			if err := p.skipParens(); err != nil {
+				return nil, err
+			}
+		}
+		if !p.accept("OF") {
+			return nil, p.errorf(p.peek(), "%s: expected OF, got %s (SET { ... } is not supported)", t.text, p.peek())
+		}
+		elem, err := p.typ()
+		if err != nil {
+			return nil, err
+		}
+		kind := asn1tree.KindSequenceOf
+		if t.text == "SET" {
+			kind = asn1tree.KindSetOf
+		}
+		return &asn1tree.Type{Kind: kind, Elem: elem}, nil
+	case "CHOICE":
+		fields, err := p.components(true)
+		if err != nil {
+			return nil, err
+		}
+		return &asn1tree.Type{Kind: asn1tree.KindChoice, Fields: fields}, nil
+	case "ANY":
+		if p.accept("DEFINED") {
+			if err := p.expect("BY"); err != nil {
+				return nil, err
+			}
+			if _, err := p.ident(); err != nil {
+				return nil, err
+			}
+		}
+		return &asn1tree.Type{Kind: asn1tree.KindAny}, nil
+	case "OCTET", "BIT", "OBJECT":
+		second := map[string]string{"OCTET": "STRING", "BIT": "STRING", "OBJECT": "IDENTIFIER"}[t.text]
+		if err := p.expect(second); err != nil {
+			return nil, err
+		}
+		return &asn1tree.Type{Kind: asn1tree.KindPrimitive, Tag: builtinTags[t.text+" "+second]}, nil
+	case "INTEGER", "ENUMERATED":
+		if p.peek().text == "{" {
+			if err := p.namedNumbers(); err != nil {
+				return nil, err
+			}
+		}
+		return &asn1tree.Type{Kind: asn1tree.KindPrimitive, Tag: builtinTags[t.text]}, nil
+	}
+	if tag, ok := builtinTags[t.text]; ok {
+		return &asn1tree.Type{Kind: asn1tree.KindPrimitive, Tag: tag}, nil
+	}
+	if !isUpper(t.text) {
+		return nil, p.errorf(t, "expected type, got %s", t)
+	}
+	return &asn1tree.Type{Kind: asn1tree.KindRef, Ref: t.text}, nil
+}
+
+// taggedType: [n] или [UNIVERSAL|APPLICATION|PRIVATE n] с необязательным EXPLICIT/IMPLICIT.
+func (p *parser) taggedType() (*asn1tree.Type, error) {
+	p.next()
+	if t := p.peek(); t.kind == tokIdent {
+		return nil, p.errorf(t, "tag class %s is not supported, only context-specific [n]", t.text)
+	}
+	n, err := p.number()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	t := &asn1tree.Type{Kind: asn1tree.KindTagged, Tag: int(n), Implicit: p.m.Tagging == "IMPLICIT"}
+	switch {
+	case p.accept("EXPLICIT"):
+		t.Implicit = false
+	case p.accept("IMPLICIT"):
+		t.Implicit = true
+	}
+	if t.Elem, err = p.typ(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// components: { name Type [OPTIONAL | DEFAULT value], ... }; у CHOICE OPTIONAL/DEFAULT не допускаются.
+func (p *parser) components(isChoice bool) ([]asn1tree.Field, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var fields []asn1tree.Field
+	seen := make(map[string]bool)
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if isUpper(name.text) {
+			return nil, p.errorf(name, "component name %s must start with a lower-case letter", name.text)
+		}
+		if seen[name.text] {
+			return nil, p.errorf(name, "duplicate component %s", name.text)
+		}
+		seen[name.text] = true
+		t, err := p.typ()
+		if err != nil {
+			return nil, err
+		}
+		f := asn1tree.Field{Name: name.text, Type: t}
+		if !isChoice {
+			switch {
+			case p.accept("OPTIONAL"):
+				f.Optional = true
+			case p.accept("DEFAULT"):
+				v := p.next()
+				if v.kind != tokNumber && v.kind != tokIdent {
+					return nil, p.errorf(v, "DEFAULT %s: only numbers and identifiers are supported", name.text)
+				}
+				f.Default = v.text
+			}
+		}
+		fields = append(fields, f)
+		if p.accept("}") {
+			return fields, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// namedNumbers пропускает { v3(3), ... } у INTEGER: имена значений на проверку не влияют.
+func (p *parser) namedNumbers() error {
+	p.next()
+	for {
+		if _, err := p.ident(); err != nil {
+			return err
+		}
+		if err := p.expect("("); err != nil {
+			return err
+		}
+		if _, err := p.number(); err != nil {
+			return err
+		}
+		if err := p.expect(")"); err != nil {
+			return err
+		}
+		if p.accept("}") {
+			return nil
+		}
+		if err := p.expect(","); err != nil {
+			return err
+		}
+	}
+}
+
+// constraint разбирает (lo..hi) или (n) у INTEGER; ограничения других типов (SIZE и т.п.) пропускаются.
+func (p *parser) constraint(t *asn1tree.Type) error {
+	if t.Kind != asn1tree.KindPrimitive || t.Tag != der.TagInteger {
+		return p.skipParens()
+	}
+	start := p.next()
+	lo, err := p.number()
+	if err != nil {
+		return err
+	}
+	hi := lo
+	if p.accept("..") {
+		if hi, err = p.number(); err != nil {
+			return err
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return err
+	}
+	if hi < lo {
+		return p.errorf(start, "empty range (%d..%d)", lo, hi)
+	}
+	t.Range = &asn1tree.Range{Min: lo, Max: hi}
+	return nil
+}
+
+func (p *parser) skipParens() error {
+	open := p.peek()
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for depth := 1; depth > 0; {
+		switch t := p.next(); {
+		case t.kind == tokEOF:
+			return p.errorf(open, "unbalanced parentheses")
+		case t.text == "(":
+			depth++
+		case t.text == ")":
+			depth--
+		}
+	}
+	return nil
+}
+
+// check проверяет, что все ссылки на типы определены, и приводит теги CHOICE/ANY к EXPLICIT (X.680, 31.2.7).
+func (m *Module) check() error {
+	var visit func(t *asn1tree.Type, where string) error
+	visit = func(t *asn1tree.Type, where string) error {
+		if t == nil {
+			return nil
+		}
+		switch t.Kind {
+		case asn1tree.KindRef:
+			if m.Types[t.Ref] == nil {
+				return fmt.Errorf("%s: undefined type %s", where, t.Ref)
+			}
+		case asn1tree.KindTagged:
+			if inner := m.resolve(t.Elem); inner != nil && (inner.Kind == asn1tree.KindChoice || inner.Kind == asn1tree.KindAny) {
+				t.Implicit = false
+			}
+			return visit(t.Elem, where)
+		case asn1tree.KindSequenceOf, asn1tree.KindSetOf:
+			return visit(t.Elem, where)
+		case asn1tree.KindSequence, asn1tree.KindChoice:
+			for _, f := range t.Fields {
+				if err := visit(f.Type, where+"."+f.Name); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	for _, name := range m.TypeOrder {
+		if err := visit(m.Types[name], name); err != nil {
+			return err
+		}
+	}
+	for _, name := range m.TypeOrder {
+		if m.resolve(m.Types[name]) == nil {
+			return fmt.Errorf("%s: circular type reference", name)
+		}
+	}
+	return nil
+}
+
+// resolve раскрывает ссылки на типы модуля (до 16 уровней, иначе nil).
+func (m *Module) resolve(t *asn1tree.Type) *asn1tree.Type {
+	for i := 0; t != nil && t.Kind == asn1tree.KindRef; i++ {
+		if i == 16 {
+			return nil
+		}
+		t = m.Types[t.Ref]
+	}
+	return t
+}
+
+// Schema возвращает схему для asn1tree: типы модуля и имена OID из значений модуля.
+func (m *Module) Schema() *asn1tree.Schema {
+	s := &asn1tree.Schema{Types: m.Types, OIDNames: make(map[string]string, len(m.Values))}
+	for _, name := range m.ValueOrder {
+		s.OIDNames[m.Values[name].String()] = name
+	}
+	return s
+}
+
+func isUpper(s string) bool {
+	return s != "" && s[0] >= 'A' && s[0] <= 'Z'
+}
@@ -0,0 +1,65 @@
+package asn1schema
+
+import (
+	"fmt"
+	"sync"
+
+	registryanalyzer "github.com/sgw-registry/registry-analyzer"
+	"github.com/sgw-registry/registry-analyzer/internal/asn1tree"
+)
+
+// RegistryRoot — тип корня контейнера ATOM-PKCS12-REGISTRY.
+const RegistryRoot = "PFX"
+
+var loadRegistry = sync.OnceValues(func() (*Module, error) {
+	m, err := Parse(registryanalyzer.RegistryASN1)
+	if err != nil {
+		return nil, fmt.Errorf("registry.asn1:%w", err)
+	}
+	return m, nil
+})
+
+// Registry возвращает модуль ATOM-PKCS12-REGISTRY, загруженный из встроенного registry.asn1 (разбирается один раз).
+func Registry() (*Module, error) {
+	return loadRegistry()
+}
+
+// RegistrySchema возвращает схему registry.asn1 с подсказками, которых нет в нотации модуля:
+// тип значения атрибута по attrType и DER внутри OCTET STRING.
+func RegistrySchema() (*asn1tree.Schema, error) {
+	m, err := Registry()
+	if err != nil {
+		return nil, err
+	}
+	s := m.Schema()
+	s.ValueTypes = registryValueTypes
+	s.Contains = registryContains
+	return s, nil
+}
+
+// registryValueTypes — тип значения атрибута по attrType (в registry.asn1 связь задана только комментариями и именами).
+var registryValueTypes = map[string]string{
+	"id-atom-vin":                "VIN",
+	"id-atom-ver":                "VER",
+	"id-atom-uid":                "UID",
+	"id-atom-roleName":           "RoleName",
+	"id-atom-roleValidityPeriod": "RoleValidityPeriod",
+	"id-contentType":             "ContentType",
+	"id-messageDigest":           "MessageDigest",
+	"id-localKeyID":              "LocalKeyId",
+}
+
+// registryContains — DER внутри OCTET STRING: SafeContents в eContent, X.509 в сертификатах.
+var registryContains = map[string]string{
+	"EncapsulatedContentInfo.eContent": "SafeContents",
+	"CertBag.certValue":                "X.509 Certificate",
+	"Certificate":                      "X.509 Certificate",
+}
+
+// RegistryVolatile — поля реестра, значения которых меняются при каждой подписи (маска asn1tree.Diff):
+// подпись SignerInfo, атрибуты messageDigest и signingTime.
+var RegistryVolatile = []string{
+	"SignerInfo.encryptedDigest",
+	"SignerInfo.authenticatedAttributes → id-messageDigest",
+	"SignerInfo.authenticatedAttributes → id-signingTime",
+}
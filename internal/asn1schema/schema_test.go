@@ -0,0 +1,171 @@
+package asn1schema
+
+import (
+	"encoding/asn1"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sgw-registry/registry-analyzer/internal/asn1tree"
+	"github.com/sgw-registry/registry-analyzer/internal/registry"
+)
+
+// TestRegistryModule проверяет загрузку встроенного registry.asn1: типы, теги, ограничения и значения OID.
+func TestRegistryModule(t *testing.T) {
+	m, err := Registry()
+	if err != nil {
+		t.Fatalf("Registry: %v", err)
+	}
+	if m.Name != "ATOM-PKCS12-REGISTRY" || m.Tagging != "EXPLICIT" {
+		t.Errorf("модуль %s, тегирование %s", m.Name, m.Tagging)
+	}
+	bag := m.Types["SafeBag"]
+	if bag == nil || bag.Kind != asn1tree.KindSequence || len(bag.Fields) != 3 {
+		t.Fatalf("SafeBag: %+v", bag)
+	}
+	if f := bag.Fields[2]; f.Name != "bagAttributes" || !f.Optional || f.Type.Kind != asn1tree.KindSetOf {
+		t.Errorf("SafeBag.bagAttributes: %+v", f)
+	}
+	if v := m.Types["CMSVersion"]; v == nil || v.Range == nil || v.Range.Min != 1 || v.Range.Max != 65535 {
+		t.Errorf("CMSVersion: ожидается INTEGER (1..65535)")
+	}
+	sid := m.Types["SignerIdentifier"]
+	if sid == nil || sid.Kind != asn1tree.KindChoice || sid.Fields[1].Type.Kind != asn1tree.KindTagged || sid.Fields[1].Type.Implicit {
+		t.Errorf("SignerIdentifier: ожидается CHOICE с [0] EXPLICIT")
+	}
+	if f := m.Types["MacData"].Fields[2]; f.Default != "1" {
+		t.Errorf("MacData.iterations DEFAULT = %q", f.Default)
+	}
+	for name, want := range map[string]string{
+		"id-signedData":              "1.2.840.113549.1.7.2",
+		"id-atom-vin":                "1.3.6.1.4.1.99999.1.1",
+		"id-atom-roleValidityPeriod": "1.3.6.1.4.1.99999.1.5",
+		"id-localKeyID":              "1.2.840.113549.1.9.21",
+		"id-ce-roleSyntax":           "2.5.29.3",
+	} {
+		if got := m.Values[name].String(); got != want {
+			t.Errorf("%s = %s, ожидается %s", name, got, want)
+		}
+	}
+	// Все подсказки схемы ссылаются на типы модуля.
+	for oid, typ := range registryValueTypes {
+		if m.Types[typ] == nil {
+			t.Errorf("ValueTypes[%s]: тип %s не определён в registry.asn1", oid, typ)
+		}
+	}
+}
+
+// TestParseModuleErrors проверяет сообщения об ошибках загрузки модуля (с позицией строка:столбец).
+func TestParseModuleErrors(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"undefined type", "M DEFINITIONS ::= BEGIN A ::= SEQUENCE { b B } END", "A.b: undefined type B"},
+		{"undefined value", "M DEFINITIONS ::= BEGIN id-x OBJECT IDENTIFIER ::= { base 1 } END", "1:54: undefined value base"},
+		{"no END", "M DEFINITIONS ::= BEGIN A ::= INTEGER", "expected END"},
+		{"duplicate type", "M DEFINITIONS ::= BEGIN A ::= INTEGER\nA ::= BOOLEAN END", "2:1: type A is already defined"},
+		{"duplicate component", "M DEFINITIONS ::= BEGIN A ::= SEQUENCE { a INTEGER, a BOOLEAN } END", "duplicate component a"},
+		{"SET", "M DEFINITIONS ::= BEGIN A ::= SET { a INTEGER } END", "SET { ... } is not supported"},
+		{"automatic", "M DEFINITIONS AUTOMATIC TAGS ::= BEGIN END", "AUTOMATIC TAGS is not supported"},
+		{"circular", "M DEFINITIONS ::= BEGIN A ::= B\nB ::= A END", "circular type reference"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.src))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("ошибка = %v, ожидается %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseImplicitModule проверяет IMPLICIT TAGS в заголовке и принудительный EXPLICIT для CHOICE.
+func TestParseImplicitModule(t *testing.T) {
+	m, err := Parse([]byte(`M DEFINITIONS IMPLICIT TAGS ::= BEGIN
+		A ::= SEQUENCE { a [0] INTEGER, b [1] C, c [2] EXPLICIT INTEGER }
+		C ::= CHOICE { x INTEGER, y BOOLEAN }
+	END`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	f := m.Types["A"].Fields
+	if !f[0].Type.Implicit || f[1].Type.Implicit || f[2].Type.Implicit {
+		t.Errorf("IMPLICIT: a=%v b=%v c=%v, ожидается true false false", f[0].Type.Implicit, f[1].Type.Implicit, f[2].Type.Implicit)
+	}
+}
+
+// TestRegistryModuleOIDs сверяет значения OID модуля с константами internal/registry, по которым идёт разбор и сборка.
+func TestRegistryModuleOIDs(t *testing.T) {
+	m, err := Registry()
+	if err != nil {
+		t.Fatalf("Registry: %v", err)
+	}
+	for name, want := range map[string]asn1.ObjectIdentifier{
+		"id-data":                    registry.OIDPKCS7Data,
+		"id-signedData":              registry.OIDPKCS7SignedData,
+		"id-contentType":             registry.OIDPKCS9ContentType,
+		"id-messageDigest":           registry.OIDPKCS9MessageDigest,
+		"id-certBag":                 registry.OIDCertBag,
+		"id-friendlyName":            registry.OIDPKCS9FriendlyName,
+		"id-localKeyID":              registry.OIDPKCS9LocalKeyID,
+		"id-atom-vin":                registry.OIDAtomVIN,
+		"id-atom-ver":                registry.OIDAtomVER,
+		"id-atom-uid":                registry.OIDAtomUID,
+		"id-atom-roleName":           registry.OIDAtomRoleName,
+		"id-atom-roleValidityPeriod": registry.OIDAtomRoleValidityPeriod,
+	} {
+		if got := m.Values[name]; !got.Equal(want) {
+			t.Errorf("registry.asn1 %s = %v, internal/registry — %v", name, got, want)
+		}
+	}
+}
+
+// TestAnnotateRegistry проверяет подписи узлов дерева полями загруженного registry.asn1 и раскрытие eContent.
+func TestAnnotateRegistry(t *testing.T) {
+	data := readSample(t, "owner_registry.p12")
+	s, err := RegistrySchema()
+	if err != nil {
+		t.Fatalf("RegistrySchema: %v", err)
+	}
+	nodes, err := asn1tree.Parse(data, 0, 32)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	s.Annotate(nodes, RegistryRoot)
+
+	labels := make(map[string]*asn1tree.Node)
+	asn1tree.Walk(nodes, func(n *asn1tree.Node) {
+		if _, ok := labels[n.Label]; !ok && n.Label != "" {
+			labels[n.Label] = n
+		}
+	})
+	for _, want := range []string{
+		"PFX", "PFX.version", "ContentInfo.contentType", "SignedData", "EncapsulatedContentInfo.eContent",
+		"SafeContents", "SafeBag.bagId", "CertBag.certValue", "X.509 Certificate",
+		"SafeBag.bagAttributes → id-atom-roleName", "RoleValidityPeriod.notBeforeTime",
+		"SignerInfo.authenticatedAttributes", "SignerInfo.encryptedDigest",
+	} {
+		if labels[want] == nil {
+			t.Errorf("нет узла с подписью %q", want)
+		}
+	}
+	if n := labels["PFX.version"]; n != nil && n.Value != "3" {
+		t.Errorf("PFX.version = %q, ожидается 3", n.Value)
+	}
+	if n := labels["ContentInfo.contentType"]; n != nil && !strings.Contains(n.Value, "id-signedData") {
+		t.Errorf("contentType = %q", n.Value)
+	}
+	if n := labels["SafeContents"]; n != nil && n.Depth < 5 {
+		t.Errorf("SafeContents на глубине %d: eContent не раскрыт", n.Depth)
+	}
+}
+
+func readSample(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", name))
+	if err != nil {
+		t.Skipf("%s не найден", name)
+	}
+	return data
+}
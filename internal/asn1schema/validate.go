@@ -0,0 +1,336 @@
+package asn1schema
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/sgw-registry/registry-analyzer/internal/asn1tree"
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+)
+
+var (
+	errUnexpected = errors.New("unexpected element")
+	errNotDER     = errors.New("value is not a DER-encoded structure")
+)
+
+// Validate разбирает data (DER) и проверяет его на соответствие типу root схемы s. Возвращает все несоответствия
+// (пустой срез — данные соответствуют модулю): тег, форма, порядок и наличие компонентов, лишние элементы,
+// кодирование и диапазон примитивов, вложенный DER (Schema.Contains) и значения атрибутов (Schema.ValueTypes).
+// Ошибка разбора DER входит в результат; обрезанные элементы не проверяются.
+func Validate(s *asn1tree.Schema, data []byte, root string, maxDepth int) []*der.ParseError {
+	nodes, err := asn1tree.Parse(data, 0, maxDepth)
+	v := &validator{s: s}
+	if err != nil {
+		var perr *der.ParseError
+		if !errors.As(err, &perr) {
+			perr = &der.ParseError{Offset: -1, Err: err}
+		}
+		v.errs = append(v.errs, perr)
+	}
+	t := s.Types[root]
+	if t == nil {
+		return append(v.errs, &der.ParseError{Offset: -1, Path: root, Err: fmt.Errorf("type %s is not defined", root)})
+	}
+	if len(nodes) == 0 {
+		if err == nil {
+			v.errs = append(v.errs, &der.ParseError{Offset: 0, Path: root, Expected: describe(s, t), Actual: "end of content"})
+		}
+		return v.errs
+	}
+	v.value(nodes[0], t, root, "", "")
+	for _, n := range nodes[1:] {
+		v.mismatch(n, root, "end of data", n.Name(), errUnexpected)
+	}
+	return v.errs
+}
+
+type validator struct {
+	s    *asn1tree.Schema
+	errs []*der.ParseError
+}
+
+func (v *validator) mismatch(n *asn1tree.Node, path, expected, actual string, err error) {
+	v.errs = append(v.errs, &der.ParseError{Offset: n.Offset, Path: path, Expected: expected, Actual: actual, Err: err})
+}
+
+// value проверяет узел n как значение типа t. field — "Тип.поле" для поиска в Schema.Contains,
+// definedBy — имя OID, определяющего тип ANY DEFINED BY.
+func (v *validator) value(n *asn1tree.Node, t *asn1tree.Type, path, field, definedBy string) {
+	if n.Truncated {
+		return // место обрыва уже в ошибке разбора
+	}
+	name := typeName(t)
+	rt := v.s.Resolve(t)
+	if rt == nil {
+		return
+	}
+	switch rt.Kind {
+	case asn1tree.KindAny:
+		if vt := v.s.Types[v.s.ValueTypes[definedBy]]; vt != nil {
+			v.value(n, vt, path, "", "")
+			return
+		}
+	case asn1tree.KindPrimitive:
+		if !v.expectTag(n, t, path, der.ClassUniversal, rt.Tag, false) {
+			return
+		}
+		v.primitive(n, rt, rt.Tag, path)
+	case asn1tree.KindSequence:
+		if v.expectTag(n, t, path, der.ClassUniversal, der.TagSequence, true) {
+			v.fields(n, rt, path)
+		}
+	case asn1tree.KindSequenceOf, asn1tree.KindSetOf:
+		tag := der.TagSequence
+		if rt.Kind == asn1tree.KindSetOf {
+			tag = der.TagSet
+		}
+		if v.expectTag(n, t, path, der.ClassUniversal, tag, true) {
+			v.elems(n.Children, rt.Elem, path, field, definedBy)
+		}
+	case asn1tree.KindChoice:
+		for _, f := range rt.Fields {
+			if v.s.Matches(f.Type, n) {
+				v.value(n, f.Type, path, field, definedBy)
+				return
+			}
+		}
+		alts := make([]string, len(rt.Fields))
+		for i, f := range rt.Fields {
+			alts[i] = f.Name + " " + describe(v.s, f.Type)
+		}
+		v.mismatch(n, path, "one of "+strings.Join(alts, " | "), n.Name(), nil)
+		return
+	case asn1tree.KindTagged:
+		if !n.Is(der.ClassContextSpecific, rt.Tag) {
+			v.mismatch(n, path, describe(v.s, t), n.Name(), nil)
+			return
+		}
+		// Вложенный DER (Contains) проверяется на уровне значения внутри тега.
+		if rt.Implicit {
+			v.implicit(n, rt.Elem, path, field, definedBy)
+			return
+		}
+		if !n.Constructed || len(n.Children) != 1 {
+			v.mismatch(n, path, fmt.Sprintf("[%d] EXPLICIT with one element", rt.Tag), explicitActual(n), nil)
+			return
+		}
+		v.value(n.Children[0], rt.Elem, path, field, definedBy)
+		return
+	}
+	v.contained(n, path, field, name)
+}
+
+// expectTag проверяет класс, тег и форму (constructed для SEQUENCE/SET, primitive для остальных универсальных).
+func (v *validator) expectTag(n *asn1tree.Node, t *asn1tree.Type, path string, class, tag int, constructed bool) bool {
+	if !n.Is(class, tag) {
+		v.mismatch(n, path, describe(v.s, t), n.Name(), nil)
+		return false
+	}
+	if n.Constructed != constructed {
+		form := " (primitive)"
+		if n.Constructed {
+			form = " (constructed)"
+		}
+		v.mismatch(n, path, describe(v.s, t), n.Name()+form, nil)
+		return false
+	}
+	return true
+}
+
+// implicit проверяет [n] IMPLICIT T: тег уже проверен, содержимое — как у T.
+func (v *validator) implicit(n *asn1tree.Node, t *asn1tree.Type, path, field, definedBy string) {
+	rt := v.s.Resolve(t)
+	retagged := *n
+	retagged.Class = der.ClassUniversal
+	switch rt.Kind {
+	case asn1tree.KindPrimitive:
+		retagged.Tag = rt.Tag
+	case asn1tree.KindSequence, asn1tree.KindSequenceOf:
+		retagged.Tag = der.TagSequence
+	case asn1tree.KindSetOf:
+		retagged.Tag = der.TagSet
+	}
+	v.value(&retagged, t, path, field, definedBy)
+}
+
+// fields сопоставляет потомков с компонентами SEQUENCE. Если обязательный компонент не совпал, а элемент подходит
+// под следующий компонент, компонент считается пропущенным; иначе элемент считается ошибочным значением компонента.
+func (v *validator) fields(n *asn1tree.Node, t *asn1tree.Type, path string) {
+	owner := t.Name
+	if owner == "" {
+		owner = lastPathElem(path)
+	}
+	children := n.Children
+	definedBy := ""
+	i := 0
+	for fi, f := range t.Fields {
+		fpath := path + "." + f.Name
+		if i >= len(children) {
+			if !f.Optional && f.Default == "" {
+				v.errs = append(v.errs, &der.ParseError{Offset: n.Offset + n.HeaderLen + n.Len(), Path: fpath,
+					Expected: describe(v.s, f.Type), Actual: "end of content"})
+			}
+			continue
+		}
+		ch := children[i]
+		if !v.s.Matches(f.Type, ch) {
+			if f.Optional || f.Default != "" {
+				continue
+			}
+			if fi+1 < len(t.Fields) && v.s.Matches(t.Fields[fi+1].Type, ch) {
+				v.mismatch(ch, fpath, describe(v.s, f.Type), "missing (next is "+ch.Name()+")", nil)
+				continue
+			}
+		}
+		v.value(ch, f.Type, fpath, owner+"."+f.Name, definedBy)
+		if ch.Is(der.ClassUniversal, der.TagOID) && !ch.Constructed {
+			definedBy = v.oidName(ch)
+		}
+		i++
+	}
+	for _, ch := range children[i:] {
+		v.mismatch(ch, path, "end of "+describe(v.s, t), ch.Name(), errUnexpected)
+	}
+}
+
+func (v *validator) elems(children []*asn1tree.Node, elem *asn1tree.Type, path, field, definedBy string) {
+	for i, ch := range children {
+		v.value(ch, elem, fmt.Sprintf("%s[%d]", path, i+1), field, definedBy)
+	}
+}
+
+// contained проверяет DER внутри OCTET STRING по Schema.Contains (по подписи поля или имени типа).
+// Если типа нет в схеме (X.509 Certificate), достаточно корректного вложенного DER.
+func (v *validator) contained(n *asn1tree.Node, path, field, typ string) {
+	target, ok := v.s.Contains[field]
+	if !ok {
+		target, ok = v.s.Contains[typ]
+	}
+	if !ok {
+		return
+	}
+	if !n.Is(der.ClassUniversal, der.TagOctetString) || n.Constructed {
+		return // тег уже проверен в value
+	}
+	if !n.Encapsulated || len(n.Children) == 0 {
+		v.mismatch(n, path, target+" (DER)", fmt.Sprintf("OCTET STRING of %d bytes", n.Len()), errNotDER)
+		return
+	}
+	if t := v.s.Types[target]; t != nil {
+		v.value(n.Children[0], t, path, target, "")
+	}
+}
+
+// primitive проверяет кодирование значения универсального типа tag (через encoding/asn1) и ограничение INTEGER.
+func (v *validator) primitive(n *asn1tree.Node, t *asn1tree.Type, tag int, path string) {
+	full := n.Full
+	if n.Class != der.ClassUniversal || n.Tag != tag {
+		// Значение [n] IMPLICIT: заголовок заменяется универсальным для проверки содержимого.
+		full, _ = asn1.Marshal(asn1.RawValue{Tag: tag, Bytes: n.Content})
+	}
+	var err error
+	switch tag {
+	case der.TagInteger:
+		var x *big.Int
+		if _, err = asn1.Unmarshal(full, &x); err == nil && t.Range != nil {
+			if !x.IsInt64() || x.Int64() < t.Range.Min || x.Int64() > t.Range.Max {
+				v.mismatch(n, path, fmt.Sprintf("%s (%d..%d)", describe(v.s, t), t.Range.Min, t.Range.Max), x.String(), nil)
+			}
+		}
+	case der.TagOID:
+		var oid asn1.ObjectIdentifier
+		_, err = asn1.Unmarshal(full, &oid)
+	case 1: // BOOLEAN
+		var b bool
+		_, err = asn1.Unmarshal(full, &b)
+	case 12: // UTF8String
+		var s string
+		_, err = asn1.UnmarshalWithParams(full, &s, "utf8")
+	case 19, 22: // PrintableString, IA5String
+		var s string
+		_, err = asn1.Unmarshal(full, &s)
+	case 23: // UTCTime
+		var tm time.Time
+		_, err = asn1.UnmarshalWithParams(full, &tm, "utc")
+	case 24: // GeneralizedTime
+		var tm time.Time
+		_, err = asn1.UnmarshalWithParams(full, &tm, "generalized")
+	case 5: // NULL
+		if n.Len() != 0 {
+			err = errors.New("NULL with content")
+		}
+	}
+	if err != nil {
+		v.mismatch(n, path, describe(v.s, t), n.Name(), fmt.Errorf("invalid value: %w", err))
+	}
+}
+
+func (v *validator) oidName(n *asn1tree.Node) string {
+	var oid asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(n.Full, &oid); err != nil {
+		return ""
+	}
+	return v.s.OIDNames[oid.String()]
+}
+
+// describe возвращает ожидаемый тег типа для сообщений: "SEQUENCE (SafeBag)", "[0] EXPLICIT", "INTEGER (CMSVersion)".
+func describe(s *asn1tree.Schema, t *asn1tree.Type) string {
+	name := typeName(t)
+	rt := s.Resolve(t)
+	if rt == nil {
+		return name
+	}
+	var tag string
+	switch rt.Kind {
+	case asn1tree.KindAny:
+		tag = "ANY"
+	case asn1tree.KindPrimitive:
+		tag = der.TagName(der.ClassUniversal, rt.Tag)
+	case asn1tree.KindSequence, asn1tree.KindSequenceOf:
+		tag = "SEQUENCE"
+	case asn1tree.KindSetOf:
+		tag = "SET"
+	case asn1tree.KindChoice:
+		tag = "CHOICE"
+	case asn1tree.KindTagged:
+		tag = fmt.Sprintf("[%d] EXPLICIT", rt.Tag)
+		if rt.Implicit {
+			tag = fmt.Sprintf("[%d] IMPLICIT", rt.Tag)
+		}
+	}
+	if name != "" && name != tag {
+		return tag + " (" + name + ")"
+	}
+	return tag
+}
+
+func explicitActual(n *asn1tree.Node) string {
+	if !n.Constructed {
+		return n.Name() + " (primitive)"
+	}
+	return fmt.Sprintf("%s with %d elements", n.Name(), len(n.Children))
+}
+
+func typeName(t *asn1tree.Type) string {
+	if t == nil {
+		return ""
+	}
+	if t.Kind == asn1tree.KindRef {
+		return t.Ref
+	}
+	return t.Name
+}
+
+func lastPathElem(path string) string {
+	if i := strings.LastIndexByte(path, '.'); i >= 0 {
+		path = path[i+1:]
+	}
+	if i := strings.IndexByte(path, '['); i >= 0 {
+		path = path[:i]
+	}
+	return path
+}
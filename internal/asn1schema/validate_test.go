@@ -0,0 +1,160 @@
+package asn1schema
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sgw-registry/registry-analyzer/internal/asn1tree"
+	"github.com/sgw-registry/registry-analyzer/internal/registry"
+)
+
+// TestValidateSamples проверяет, что эталонные контейнеры соответствуют registry.asn1.
+func TestValidateSamples(t *testing.T) {
+	s, err := RegistrySchema()
+	if err != nil {
+		t.Fatalf("RegistrySchema: %v", err)
+	}
+	for _, name := range []string{"owner_registry.p12", "IVI_Certificate_registry.p12", "Driver_Certificate_registry.p12"} {
+		t.Run(name, func(t *testing.T) {
+			for _, e := range Validate(s, readSample(t, name), RegistryRoot, 32) {
+				t.Errorf("несоответствие: %v", e)
+			}
+		})
+	}
+}
+
+// TestValidateBuilt проверяет, что registry-builder собирает контейнер по registry.asn1:
+// изменение спецификации или сборщика, нарушающее соответствие, обнаруживается этим тестом.
+func TestValidateBuilt(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	template := &x509.Certificate{
+		Subject:      pkix.Name{CommonName: "Test Registry Signer"},
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		SubjectKeyId: []byte{1, 2, 3, 4},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(certDER)
+	now := time.Now().UTC().Truncate(time.Second)
+	data, err := registry.BuildRegistry(cert, key, []registry.SafeBagInput{{
+		CertDER: certDER, RoleName: "delegate", RoleNotBefore: now, RoleNotAfter: now.Add(time.Hour), LocalKeyID: []byte{1, 2, 3, 4},
+	}}, registry.SignerAttrs{VIN: "TESTVIN123", VERTimestamp: now, VERVersion: 1, UID: "CN=Test", SigningTime: now, AlgorithmProtection: true})
+	if err != nil {
+		t.Fatalf("BuildRegistry: %v", err)
+	}
+	s, err := RegistrySchema()
+	if err != nil {
+		t.Fatalf("RegistrySchema: %v", err)
+	}
+	for _, e := range Validate(s, data, RegistryRoot, 32) {
+		t.Errorf("несоответствие: %v", e)
+	}
+}
+
+// TestValidateMismatches портит несколько элементов эталонного контейнера и проверяет, что сообщается каждое
+// несоответствие — с путём, смещением и ожидаемым типом.
+func TestValidateMismatches(t *testing.T) {
+	data := readSample(t, "owner_registry.p12")
+	s, err := RegistrySchema()
+	if err != nil {
+		t.Fatalf("RegistrySchema: %v", err)
+	}
+	nodes, err := asn1tree.Parse(data, 0, 32)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	s.Annotate(nodes, RegistryRoot)
+	find := func(label string) *asn1tree.Node {
+		var found *asn1tree.Node
+		asn1tree.Walk(nodes, func(n *asn1tree.Node) {
+			if found == nil && n.Label == label {
+				found = n
+			}
+		})
+		if found == nil {
+			t.Fatalf("узел %q не найден", label)
+		}
+		return found
+	}
+	version := find("SignerInfo.version")
+	vin := find("VIN")
+	roleTime := find("RoleValidityPeriod.notBeforeTime")
+
+	bad := append([]byte(nil), data...)
+	bad[version.Offset+version.HeaderLen] = 0 // CMSVersion вне (1..65535)
+	bad[vin.Offset] = 0x13                    // UTF8String → PrintableString
+	bad[roleTime.Offset+roleTime.HeaderLen] = 'x'
+
+	errs := Validate(s, bad, RegistryRoot, 32)
+	want := []struct {
+		offset             int
+		path, expected, in string
+	}{
+		{roleTime.Offset, "eContent[1].bagAttributes[2].attrValues[1].notBeforeTime", "GeneralizedTime", "invalid value"},
+		{version.Offset, "signerInfos[1].version", "INTEGER (CMSVersion) (1..65535)", "got 0"},
+		{vin.Offset, "signerInfos[1].authenticatedAttributes[2].attrValues[1]", "UTF8String (VIN)", "got PrintableString"},
+	}
+	if len(errs) != len(want) {
+		for _, e := range errs {
+			t.Log(e)
+		}
+		t.Fatalf("несоответствий: %d, ожидается %d", len(errs), len(want))
+	}
+	for i, w := range want {
+		e := errs[i]
+		if e.Offset != w.offset || !strings.Contains(e.Path, w.path) || e.Expected != w.expected || !strings.Contains(e.Error(), w.in) {
+			t.Errorf("[%d] %v; ожидается offset %d, путь с %q, expected %q", i, e, w.offset, w.path, w.expected)
+		}
+	}
+}
+
+// TestValidateStructure проверяет сообщения о пропущенных, лишних и обрезанных элементах.
+func TestValidateStructure(t *testing.T) {
+	s, err := RegistrySchema()
+	if err != nil {
+		t.Fatalf("RegistrySchema: %v", err)
+	}
+	tests := []struct {
+		name string
+		data []byte
+		want []string
+	}{
+		{"empty PFX", []byte{0x30, 0x00}, []string{
+			"PFX.version: offset 2 (0x2): expected INTEGER, got end of content",
+			"PFX.authSafe: offset 2 (0x2): expected SEQUENCE (ContentInfo), got end of content",
+		}},
+		{"wrong root", []byte{0x31, 0x00}, []string{"PFX: offset 0 (0x0): expected SEQUENCE (PFX), got SET"}},
+		{"missing authSafe", []byte{0x30, 0x03, 0x02, 0x01, 0x03}, []string{"PFX.authSafe: offset 5 (0x5): expected SEQUENCE (ContentInfo), got end of content"}},
+		{"trailing data", []byte{0x02, 0x01, 0x03, 0x05, 0x00}, []string{
+			"PFX: offset 0 (0x0): expected SEQUENCE (PFX), got INTEGER",
+			"PFX: offset 3 (0x3): expected end of data, got NULL: unexpected element",
+		}},
+		{"truncated", []byte{0x30, 0x10, 0x02, 0x01}, []string{"offset 2 (0x2): der: truncated"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := Validate(s, tt.data, RegistryRoot, 32)
+			var got []string
+			for _, e := range errs {
+				got = append(got, e.Error())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("получено:\n%s\nожидается:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package asn1tree
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Виды структурных различий (Difference.Kind).
+const (
+	DiffTag           = "tag"           // разные класс, номер тега или форма (cons/prim)
+	DiffLengthForm    = "length-form"   // длина закодирована по-разному (короткая / длинная / неопределённая)
+	DiffEncapsulation = "encapsulation" // вложенный DER раскрыт только с одной стороны
+	DiffOrder         = "order"         // те же элементы в другом порядке
+	DiffMissing       = "missing"       // элемент есть только в первом дереве
+	DiffExtra         = "extra"         // элемент есть только во втором дереве
+	DiffValue         = "value"         // разное значение примитива
+)
+
+// Difference — одно структурное различие двух деревьев. OffsetA/OffsetB — смещения узлов (-1 — узла нет).
+type Difference struct {
+	Kind    string `json:"kind"`
+	Path    string `json:"path"`
+	Label   string `json:"label,omitempty"`
+	OffsetA int    `json:"offsetA"`
+	OffsetB int    `json:"offsetB"`
+	A       string `json:"a,omitempty"`
+	B       string `json:"b,omitempty"`
+}
+
+// DiffOptions — параметры Diff. Mask — подписи полей схемы с изменчивыми значениями (подпись, messageDigest):
+// узел маскируется, если его Label совпадает с элементом Mask или оканчивается на него; у маскированного узла
+// сравнивается только тег, значение, длина и потомки — нет.
+type DiffOptions struct {
+	Mask []string
+}
+
+// Diff выравнивает два дерева (желательно подписанных одной схемой через Annotate) узел за узлом и возвращает
+// структурные различия в порядке обхода. Потомки сопоставляются по ключу (подпись поля схемы или тег): при
+// совпадении последовательностей ключей — попарно, при тех же ключах в другом порядке — с различием DiffOrder
+// и сопоставлением по ключу, иначе — по наибольшей общей подпоследовательности с DiffMissing/DiffExtra.
+func Diff(a, b []*Node, opt DiffOptions) []Difference {
+	d := &differ{opt: opt}
+	d.children("", a, b)
+	return d.out
+}
+
+type differ struct {
+	opt DiffOptions
+	out []Difference
+}
+
+func (d *differ) add(kind, path string, a, b *Node, va, vb string) {
+	diff := Difference{Kind: kind, Path: path, OffsetA: -1, OffsetB: -1, A: va, B: vb}
+	if a != nil {
+		diff.OffsetA, diff.Label = a.Offset, a.Label
+	}
+	if b != nil {
+		diff.OffsetB = b.Offset
+		if diff.Label == "" {
+			diff.Label = b.Label
+		}
+	}
+	d.out = append(d.out, diff)
+}
+
+func (d *differ) masked(n *Node) bool {
+	for _, m := range d.opt.Mask {
+		if m != "" && (n.Label == m || strings.HasSuffix(n.Label, m)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *differ) node(path string, a, b *Node) {
+	if a.Class != b.Class || a.Tag != b.Tag || a.Constructed != b.Constructed {
+		d.add(DiffTag, path, a, b, tagDescription(a), tagDescription(b))
+		return
+	}
+	if d.masked(a) || d.masked(b) {
+		return
+	}
+	if fa, fb := lengthForm(a), lengthForm(b); fa != fb {
+		d.add(DiffLengthForm, path, a, b, fa, fb)
+	}
+	if a.Encapsulated != b.Encapsulated {
+		d.add(DiffEncapsulation, path, a, b, encapsulation(a), encapsulation(b))
+		return
+	}
+	if !a.Constructed && !a.Encapsulated && !bytes.Equal(a.Content, b.Content) {
+		d.add(DiffValue, path, a, b, shortValue(a), shortValue(b))
+		return
+	}
+	d.children(path, a.Children, b.Children)
+}
+
+// children сопоставляет потомков одного узла; parent — путь родителя.
+func (d *differ) children(parent string, a, b []*Node) {
+	ka, kb := keys(a), keys(b)
+	pa, pb := paths(parent, a, ka), paths(parent, b, kb)
+	if slicesEqual(ka, kb) {
+		for i := range a {
+			d.node(pa[i], a[i], b[i])
+		}
+		return
+	}
+	if sameMultiset(ka, kb) {
+		d.add(DiffOrder, parentPath(parent), nil, nil, strings.Join(ka, ", "), strings.Join(kb, ", "))
+		used := make([]bool, len(b))
+		for i := range a {
+			for j := range b {
+				if !used[j] && ka[i] == kb[j] {
+					used[j] = true
+					d.node(pa[i], a[i], b[j])
+					break
+				}
+			}
+		}
+		return
+	}
+	i, j := 0, 0
+	for _, m := range lcs(ka, kb) {
+		for ; i < m[0]; i++ {
+			d.add(DiffMissing, pa[i], a[i], nil, ka[i], "")
+		}
+		for ; j < m[1]; j++ {
+			d.add(DiffExtra, pb[j], nil, b[j], "", kb[j])
+		}
+		d.node(pa[i], a[i], b[j])
+		i, j = i+1, j+1
+	}
+	for ; i < len(a); i++ {
+		d.add(DiffMissing, pa[i], a[i], nil, ka[i], "")
+	}
+	for ; j < len(b); j++ {
+		d.add(DiffExtra, pb[j], nil, b[j], "", kb[j])
+	}
+}
+
+// key — ключ сопоставления узла: подпись схемы, иначе имя тега.
+func key(n *Node) string {
+	if n.Label != "" {
+		return n.Label
+	}
+	return n.Name()
+}
+
+func keys(nodes []*Node) []string {
+	out := make([]string, len(nodes))
+	for i, n := range nodes {
+		out[i] = key(n)
+	}
+	return out
+}
+
+// WalkPaths обходит дерево в прямом порядке (как Walk) и передаёт fn каждый узел с его путём (тем же, что в Difference.Path).
+// Составной узел с подписью-типом получает путь родителя, поэтому первым для пути приходит внешний узел.
+func WalkPaths(nodes []*Node, fn func(path string, n *Node)) {
+	var walk func(parent string, nodes []*Node)
+	walk = func(parent string, nodes []*Node) {
+		ps := paths(parent, nodes, keys(nodes))
+		for i, n := range nodes {
+			fn(ps[i], n)
+			walk(ps[i], n.Children)
+		}
+	}
+	walk("", nodes)
+}
+
+// paths строит пути потомков как в ошибках разбора ("PFX.authSafe.content.signerInfos..."): имя поля из подписи
+// схемы или тег, с номером (с 1) среди соседей с тем же ключом, если таких несколько. Узлы с подписью-типом
+// ("SignedData", "X.509 Certificate") и раскрытые OCTET STRING без подписи в путь не входят.
+func paths(parent string, nodes []*Node, ks []string) []string {
+	count := make(map[string]int)
+	for _, k := range ks {
+		count[k]++
+	}
+	last := parent[strings.LastIndex(parent, ".")+1:]
+	seen := make(map[string]int)
+	out := make([]string, len(nodes))
+	for i, n := range nodes {
+		seg := segment(n, parent == "")
+		if seg != "" && count[ks[i]] > 1 {
+			seen[ks[i]]++
+			seg += fmt.Sprintf("[%d]", seen[ks[i]])
+		}
+		switch {
+		case seg == "":
+			out[i] = parent
+		case parent == "":
+			out[i] = seg
+		case n.Label != "" && (strings.HasPrefix(seg, last+"[") || strings.HasPrefix(seg, last+"(")):
+			// Элемент SET OF / SEQUENCE OF подписан полем родителя: "certificates[1]", "bagAttributes(id-localKeyID)".
+			out[i] = parent + seg[len(last):]
+		default:
+			out[i] = parent + "." + seg
+		}
+	}
+	return out
+}
+
+// segment — элемент пути: "authenticatedAttributes(id-messageDigest)" для "SignerInfo.authenticatedAttributes → id-messageDigest",
+// "SafeContents[2]" для элемента SEQUENCE OF; пусто для составного узла с подписью-типом (кроме корня)
+// и раскрытого OCTET STRING без подписи.
+func segment(n *Node, root bool) string {
+	if n.Label == "" {
+		if n.Encapsulated {
+			return ""
+		}
+		return n.Name()
+	}
+	field, defined, _ := strings.Cut(n.Label, " → ")
+	switch i := strings.LastIndex(field, "."); {
+	case i >= 0 && !strings.Contains(field, " "):
+		field = field[i+1:]
+	case !root && !strings.Contains(field, "[") && (n.Constructed || n.Encapsulated):
+		return ""
+	}
+	if defined != "" {
+		field += "(" + defined + ")"
+	}
+	return field
+}
+
+func parentPath(p string) string {
+	if p == "" {
+		return "(корень)"
+	}
+	return p
+}
+
+func slicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameMultiset(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	count := make(map[string]int)
+	for _, k := range a {
+		count[k]++
+	}
+	for _, k := range b {
+		if count[k]--; count[k] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// lcs возвращает пары индексов (i в a, j в b) наибольшей общей подпоследовательности в порядке возрастания.
+func lcs(a, b []string) [][2]int {
+	n, m := len(a), len(b)
+	t := make([][]int, n+1)
+	for i := range t {
+		t[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				t[i][j] = t[i+1][j+1] + 1
+			} else {
+				t[i][j] = max(t[i+1][j], t[i][j+1])
+			}
+		}
+	}
+	var out [][2]int
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, [2]int{i, j})
+			i, j = i+1, j+1
+		case t[i+1][j] >= t[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return out
+}
+
+func tagDescription(n *Node) string {
+	form := "prim"
+	if n.Constructed {
+		form = "cons"
+	}
+	return fmt.Sprintf("%s %s", n.Name(), form)
+}
+
+// lengthForm описывает кодирование длины по первому байту после тега.
+func lengthForm(n *Node) string {
+	lenOff := 1
+	if n.Tag >= 31 {
+		for lenOff < n.HeaderLen && n.Full[lenOff]&0x80 != 0 {
+			lenOff++
+		}
+		lenOff++
+	}
+	if lenOff >= len(n.Full) {
+		return "?"
+	}
+	switch l := n.Full[lenOff]; {
+	case l == 0x80:
+		return "неопределённая"
+	case l < 0x80:
+		return "короткая"
+	default:
+		return fmt.Sprintf("длинная (%d байт)", l&0x7f)
+	}
+}
+
+func encapsulation(n *Node) string {
+	if n.Encapsulated {
+		return "вложенный DER"
+	}
+	return "без вложенного DER"
+}
+
+// shortValue — значение примитива для отчёта: Value из Annotate или hex, длинное — с сокращением.
+func shortValue(n *Node) string {
+	v := n.Value
+	if v == "" {
+		v = hexValue(n.Content)
+	}
+	if r := []rune(v); len(r) > 80 {
+		v = string(r[:77]) + "..."
+	}
+	return v
+}
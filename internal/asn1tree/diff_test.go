@@ -0,0 +1,81 @@
+package asn1tree
+
+import (
+	"testing"
+)
+
+// TestDiff проверяет виды различий на деревьях без схемы: значение, замена элемента (ключ — тег), форма длины,
+// порядок и лишний элемент.
+func TestDiff(t *testing.T) {
+	parse := func(data []byte) []*Node {
+		t.Helper()
+		nodes, err := Parse(data, 0, 16)
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		return nodes
+	}
+	// SEQUENCE { INTEGER 1, OCTET STRING "ab", SET { INTEGER 2, BOOLEAN TRUE } }
+	base := []byte{0x30, 0x0f, 0x02, 0x01, 0x01, 0x04, 0x02, 'a', 'b', 0x31, 0x06, 0x02, 0x01, 0x02, 0x01, 0x01, 0xff}
+	tests := []struct {
+		name string
+		data []byte
+		kind string
+		path string
+	}{
+		{"value", []byte{0x30, 0x0f, 0x02, 0x01, 0x07, 0x04, 0x02, 'a', 'b', 0x31, 0x06, 0x02, 0x01, 0x02, 0x01, 0x01, 0xff}, DiffValue, "SEQUENCE.INTEGER"},
+		{"replaced", []byte{0x30, 0x0f, 0x02, 0x01, 0x01, 0x0c, 0x02, 'a', 'b', 0x31, 0x06, 0x02, 0x01, 0x02, 0x01, 0x01, 0xff}, DiffMissing, "SEQUENCE.OCTET STRING"},
+		{"length form", []byte{0x30, 0x10, 0x02, 0x01, 0x01, 0x04, 0x81, 0x02, 'a', 'b', 0x31, 0x06, 0x02, 0x01, 0x02, 0x01, 0x01, 0xff}, DiffLengthForm, "SEQUENCE.OCTET STRING"},
+		{"order", []byte{0x30, 0x0f, 0x02, 0x01, 0x01, 0x04, 0x02, 'a', 'b', 0x31, 0x06, 0x01, 0x01, 0xff, 0x02, 0x01, 0x02}, DiffOrder, "SEQUENCE.SET"},
+		{"extra", []byte{0x30, 0x11, 0x02, 0x01, 0x01, 0x04, 0x02, 'a', 'b', 0x31, 0x06, 0x02, 0x01, 0x02, 0x01, 0x01, 0xff, 0x05, 0x00}, DiffExtra, "SEQUENCE.NULL"},
+	}
+	if diffs := Diff(parse(base), parse(base), DiffOptions{}); len(diffs) != 0 {
+		t.Fatalf("дерево отличается от себя: %+v", diffs)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs := Diff(parse(base), parse(tt.data), DiffOptions{})
+			if len(diffs) == 0 || diffs[0].Kind != tt.kind || diffs[0].Path != tt.path {
+				t.Fatalf("различия %+v, ожидается %s в %s", diffs, tt.kind, tt.path)
+			}
+		})
+	}
+}
+
+// TestDiffMask проверяет, что у маскированного узла сравнивается только тег.
+func TestDiffMask(t *testing.T) {
+	a, _ := Parse([]byte{0x30, 0x03, 0x04, 0x01, 0x01}, 0, 16)
+	b, _ := Parse([]byte{0x30, 0x04, 0x04, 0x02, 0x02, 0x02}, 0, 16)
+	a[0].Children[0].Label, b[0].Children[0].Label = "T.sig", "T.sig"
+	if diffs := Diff(a, b, DiffOptions{Mask: []string{"T.sig"}}); len(diffs) != 0 {
+		t.Errorf("маскированное значение сообщено как различие: %+v", diffs)
+	}
+	if diffs := Diff(a, b, DiffOptions{}); len(diffs) != 1 || diffs[0].Kind != DiffValue || diffs[0].Path != "SEQUENCE.sig" {
+		t.Errorf("без маски: %+v", diffs)
+	}
+	c, _ := Parse([]byte{0x30, 0x03, 0x0c, 0x01, 0x01}, 0, 16)
+	c[0].Children[0].Label = "T.sig"
+	if diffs := Diff(a, c, DiffOptions{Mask: []string{"T.sig"}}); len(diffs) != 1 || diffs[0].Kind != DiffTag {
+		t.Errorf("другой тег маскированного поля: %+v", diffs)
+	}
+}
+
+// TestWalkPaths проверяет пути и смещения узлов при обходе.
+func TestWalkPaths(t *testing.T) {
+	// SEQUENCE { INTEGER 1, INTEGER 2, BOOLEAN TRUE }
+	nodes, err := Parse([]byte{0x30, 0x09, 0x02, 0x01, 0x01, 0x02, 0x01, 0x02, 0x01, 0x01, 0xff}, 0, 16)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	got := make(map[string]int)
+	WalkPaths(nodes, func(path string, n *Node) { got[path] = n.Offset })
+	want := map[string]int{"SEQUENCE": 0, "SEQUENCE.INTEGER[1]": 2, "SEQUENCE.INTEGER[2]": 5, "SEQUENCE.BOOLEAN": 8}
+	if len(got) != len(want) {
+		t.Fatalf("пути %v, ожидается %v", got, want)
+	}
+	for p, off := range want {
+		if o, ok := got[p]; !ok || o != off {
+			t.Errorf("%s: смещение %d (%v), ожидается %d", p, o, ok, off)
+		}
+	}
+}
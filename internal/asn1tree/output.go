@@ -0,0 +1,90 @@
+package asn1tree
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+)
+
+// ANSI-коды для вывода в TTY.
+const (
+	ansiReset  = "\033[0m"
+	ansiDim    = "\033[2m"
+	ansiCyan   = "\033[36m"
+	ansiYellow = "\033[33m"
+	ansiRed    = "\033[31m"
+)
+
+var classNames = [...]string{"univ", "appl", "cont", "priv"}
+
+// TextOutput выводит дерево: смещение, длина заголовка, длина содержимого, класс, форма (cons/prim),
+// тег с отступом по глубине, подпись поля схемы и значение (у обрезанных узлов длина — доступная часть). err — ошибка Parse (печатается после дерева).
+func TextOutput(sb *strings.Builder, nodes []*Node, err error, useColor bool) {
+	c := func(code, s string) string {
+		if !useColor || s == "" {
+			return s
+		}
+		return code + s + ansiReset
+	}
+	fmt.Fprintf(sb, "%s\n", c(ansiDim, fmt.Sprintf("%6s %3s %6s %-4s %-4s  %s", "offset", "hl", "len", "cls", "form", i18n.T("tag / поле = значение"))))
+	Walk(nodes, func(n *Node) {
+		form := "prim"
+		if n.Constructed {
+			form = "cons"
+		} else if n.Encapsulated {
+			form = "enc"
+		}
+		line := fmt.Sprintf("%6d %3d %6d %-4s %-4s  %s%s", n.Offset, n.HeaderLen, n.Len(), classNames[n.Class], form,
+			strings.Repeat("  ", n.Depth), n.Name())
+		if n.Label != "" {
+			line += "  " + c(ansiCyan, n.Label)
+		}
+		if n.Truncated {
+			line += " " + c(ansiRed, "("+i18n.T("обрезан")+")")
+		}
+		if n.Value != "" {
+			line += " = " + c(ansiYellow, n.Value)
+		}
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	})
+	if err != nil {
+		var perr *der.ParseError
+		msg := err.Error()
+		if errors.As(err, &perr) && perr.Offset >= 0 {
+			msg = fmt.Sprintf("offset %d: %v", perr.Offset, perr.Err)
+		}
+		fmt.Fprintf(sb, "%s\n", c(ansiRed, "!! "+i18n.T("разбор остановлен")+": "+msg))
+	}
+}
+
+// WriteTree выводит в stdout или outputPath дерево DER, поля которого подписаны по схеме из loadSchema начиная с типа root.
+// Возвращает код выхода: 1, если DER не разобран до конца (дерево всё равно выводится до места ошибки).
+func WriteTree(data []byte, loadSchema func() (*Schema, error), root string, maxDepth int, useColor bool, outputPath string) int {
+	schema, err := loadSchema()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("схема: %v\n"), err)
+		return 1
+	}
+	nodes, err := Parse(data, 0, maxDepth)
+	schema.Annotate(nodes, root)
+	var sb strings.Builder
+	TextOutput(&sb, nodes, err, useColor && outputPath == "")
+	if outputPath != "" {
+		if werr := os.WriteFile(outputPath, []byte(sb.String()), 0644); werr != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("запись в файл: %v\n"), werr)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, i18n.T("Дерево ASN.1 записано в %s\n"), outputPath)
+	} else {
+		fmt.Print(sb.String())
+	}
+	if err != nil {
+		return 1
+	}
+	return 0
+}
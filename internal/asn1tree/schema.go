@@ -0,0 +1,256 @@
+package asn1tree
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+)
+
+// Kind — вид типа схемы ASN.1.
+type Kind int
+
+const (
+	KindAny        Kind = iota // ANY, ANY DEFINED BY
+	KindPrimitive              // универсальный тег: INTEGER, OCTET STRING, UTF8String, ...
+	KindSequence               // SEQUENCE { ... }
+	KindSequenceOf             // SEQUENCE OF T
+	KindSetOf                  // SET OF T
+	KindChoice                 // CHOICE { ... }
+	KindTagged                 // [n] EXPLICIT/IMPLICIT T
+	KindRef                    // ссылка на именованный тип
+)
+
+// Type — тип схемы. Name — имя для именованных типов (PFX, SafeBag); у вложенных анонимных типов пусто.
+type Type struct {
+	Name     string
+	Kind     Kind
+	Tag      int     // KindPrimitive: универсальный тег; KindTagged: номер контекстного тега
+	Implicit bool    // KindTagged: IMPLICIT (по умолчанию EXPLICIT, как в registry.asn1)
+	Fields   []Field // KindSequence, KindChoice
+	Elem     *Type   // KindSequenceOf, KindSetOf, KindTagged
+	Ref      string  // KindRef
+	Range    *Range  // KindPrimitive INTEGER: ограничение значения, например CMSVersion (1..65535)
+}
+
+// Range — ограничение значения INTEGER (lo..hi).
+type Range struct {
+	Min, Max int64
+}
+
+// Field — компонент SEQUENCE или альтернатива CHOICE.
+type Field struct {
+	Name     string
+	Type     *Type
+	Optional bool
+	Default  string
+}
+
+// Schema — типы модуля и знания, которых нет в самой нотации: тип значения атрибута по OID
+// (ANY DEFINED BY) и вложенный DER внутри OCTET STRING.
+type Schema struct {
+	Types    map[string]*Type
+	OIDNames map[string]string // OID → имя из модуля (id-atom-vin, ...)
+	// ValueTypes: имя OID (attrType, algorithm) → тип значения ANY DEFINED BY (VIN, RoleValidityPeriod, ...).
+	ValueTypes map[string]string
+	// Contains: подпись поля или имя типа → тип DER внутри OCTET STRING (например CertBag.certValue → X.509 Certificate).
+	// Если типа нет в Types, вложенный узел только подписывается.
+	Contains map[string]string
+}
+
+// Annotate заполняет Value у всех узлов и Label у узлов, совпавших со схемой; root — тип первого узла (PFX).
+// При s == nil заполняются только значения.
+// Узлы, не совпавшие со схемой, остаются без подписи: дерево выводится целиком в любом случае.
+func (s *Schema) Annotate(nodes []*Node, root string) {
+	Walk(nodes, func(n *Node) { n.Value = formatValue(n, s.oidNames()) })
+	if s == nil {
+		return
+	}
+	if t := s.Types[root]; t != nil && len(nodes) > 0 {
+		s.annotate(nodes[0], t, root, root, "")
+	}
+}
+
+func (s *Schema) oidNames() map[string]string {
+	if s == nil {
+		return nil
+	}
+	return s.OIDNames
+}
+
+// Resolve раскрывает ссылки на именованные типы; nil — тип не определён.
+func (s *Schema) Resolve(t *Type) *Type {
+	for i := 0; t != nil && t.Kind == KindRef && i < 16; i++ {
+		t = s.Types[t.Ref]
+	}
+	return t
+}
+
+// Matches сообщает, подходит ли узел под первый тег типа t (для CHOICE — под тег любой альтернативы).
+func (s *Schema) Matches(t *Type, n *Node) bool {
+	t = s.Resolve(t)
+	if t == nil {
+		return false
+	}
+	switch t.Kind {
+	case KindAny:
+		return true
+	case KindPrimitive:
+		return n.Is(der.ClassUniversal, t.Tag)
+	case KindSequence, KindSequenceOf:
+		return n.Is(der.ClassUniversal, der.TagSequence) && n.Constructed
+	case KindSetOf:
+		return n.Is(der.ClassUniversal, der.TagSet) && n.Constructed
+	case KindChoice:
+		for _, f := range t.Fields {
+			if s.Matches(f.Type, n) {
+				return true
+			}
+		}
+	case KindTagged:
+		return n.Is(der.ClassContextSpecific, t.Tag)
+	}
+	return false
+}
+
+// annotate подписывает n как значение типа t. field — подпись ближайшего поля (для элементов SET OF),
+// definedBy — имя OID, определяющего тип ANY (attrType, algorithm).
+func (s *Schema) annotate(n *Node, t *Type, label, field, definedBy string) {
+	if label != "" {
+		n.Label = label
+	}
+	name := t.Name
+	if t.Kind == KindRef {
+		name = t.Ref
+	}
+	t = s.Resolve(t)
+	if t == nil {
+		return
+	}
+	switch t.Kind {
+	case KindSequence:
+		if n.Constructed {
+			s.annotateFields(n.Children, t, field)
+		}
+	case KindSequenceOf, KindSetOf:
+		if n.Constructed {
+			s.annotateElems(n.Children, t.Elem, field, definedBy)
+		}
+	case KindChoice:
+		for _, f := range t.Fields {
+			if s.Matches(f.Type, n) {
+				s.annotate(n, f.Type, label+" ("+f.Name+")", field, definedBy)
+				break
+			}
+		}
+	case KindTagged:
+		inner := s.Resolve(t.Elem)
+		innerName := typeName(t.Elem)
+		if !t.Implicit && len(n.Children) == 1 && s.Matches(t.Elem, n.Children[0]) {
+			s.annotate(n.Children[0], t.Elem, innerName, field, definedBy)
+		} else if inner != nil && n.Constructed {
+			// IMPLICIT (или EXPLICIT, закодированный как IMPLICIT, как в CMS signedAttrs): содержимое [n] — сразу поля типа.
+			s.annotate(&Node{Children: n.Children, TLV: der.TLV{Constructed: true}}, t.Elem, "", field, definedBy)
+		}
+	case KindAny:
+		if vt := s.Types[s.ValueTypes[definedBy]]; vt != nil && s.Matches(vt, n) {
+			n.Label = s.ValueTypes[definedBy]
+			s.annotate(n, vt, "", s.ValueTypes[definedBy], "")
+		}
+	}
+	s.annotateContained(n, field, name)
+}
+
+// annotateFields сопоставляет дочерние узлы с компонентами SEQUENCE по порядку (OPTIONAL/DEFAULT можно пропустить).
+func (s *Schema) annotateFields(children []*Node, t *Type, parent string) {
+	owner := t.Name
+	if owner == "" {
+		owner = parent
+	}
+	definedBy := ""
+	i := 0
+	for _, f := range t.Fields {
+		if i >= len(children) {
+			return
+		}
+		ch := children[i]
+		if !s.Matches(f.Type, ch) {
+			if f.Optional || f.Default != "" {
+				continue
+			}
+			return
+		}
+		label := owner + "." + f.Name
+		s.annotate(ch, f.Type, label, label, definedBy)
+		if ch.Is(der.ClassUniversal, der.TagOID) && !ch.Constructed {
+			definedBy = oidName(oidString(ch), s.OIDNames)
+		}
+		i++
+	}
+}
+
+// annotateElems подписывает элементы SET OF / SEQUENCE OF: пары SEQUENCE { OID, значение } (Attribute,
+// AlgorithmIdentifier) — по имени OID ("SafeBag.bagAttributes → id-atom-roleName"), остальные — по индексу.
+func (s *Schema) annotateElems(children []*Node, elem *Type, field, definedBy string) {
+	et := s.Resolve(elem)
+	for i, ch := range children {
+		label := fmt.Sprintf("%s[%d]", field, i+1)
+		if et != nil && et.Kind == KindSequence && len(et.Fields) == 2 && len(ch.Children) > 0 &&
+			ch.Children[0].Is(der.ClassUniversal, der.TagOID) {
+			if name := oidName(oidString(ch.Children[0]), s.OIDNames); name != "" {
+				label = field + " → " + name
+			} else {
+				label = field + " → " + oidString(ch.Children[0])
+			}
+		}
+		if !s.Matches(elem, ch) {
+			ch.Label = label
+			continue
+		}
+		s.annotate(ch, elem, label, label, definedBy)
+	}
+}
+
+// annotateContained подписывает DER, вложенный в OCTET STRING (сам узел или единственный потомок [n] EXPLICIT),
+// если для поля или типа задан Contains.
+func (s *Schema) annotateContained(n *Node, field, typ string) {
+	target, ok := s.Contains[field]
+	if !ok {
+		target, ok = s.Contains[typ]
+	}
+	if !ok {
+		return
+	}
+	oct := n
+	if !oct.Encapsulated && len(n.Children) == 1 {
+		oct = n.Children[0]
+	}
+	if !oct.Encapsulated || len(oct.Children) == 0 {
+		return
+	}
+	root := oct.Children[0]
+	if t := s.Types[target]; t != nil && s.Matches(t, root) {
+		s.annotate(root, t, target, target, "")
+		return
+	}
+	root.Label = target
+}
+
+func typeName(t *Type) string {
+	if t == nil {
+		return ""
+	}
+	if t.Kind == KindRef {
+		return t.Ref
+	}
+	return t.Name
+}
+
+// oidString возвращает OID узла в точечной записи (первая часть Value до пробела).
+func oidString(n *Node) string {
+	v := formatValue(n, nil)
+	if i := strings.IndexByte(v, ' '); i > 0 {
+		return v[:i]
+	}
+	return v
+}
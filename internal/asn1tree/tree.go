@@ -0,0 +1,110 @@
+// Package asn1tree строит дерево TLV из DER с абсолютными смещениями, подписывает узлы именами полей
+// из схемы ASN.1 (registry.asn1, загружается internal/asn1schema) и выводит его в текстовом виде — замена
+// `openssl asn1parse` для анализаторов.
+package asn1tree
+
+import (
+	"errors"
+
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+)
+
+// Node — узел дерева: TLV, глубина, дочерние узлы и подписи.
+// Encapsulated — дочерние узлы разобраны из значения OCTET STRING / BIT STRING (вложенный DER).
+type Node struct {
+	der.TLV
+	Depth        int
+	Children     []*Node
+	Encapsulated bool
+	Truncated    bool   // содержимое короче заявленной длины (в Content — доступная часть)
+	Label        string // поле схемы: "SafeBag.bagAttributes → id-atom-roleName"
+	Value        string // значение примитива: число, OID с именем, строка, hex
+}
+
+// Parse строит дерево TLV для data (base — смещение data во входном файле).
+// maxDepth > 0 ограничивает вложенность. При ошибке возвращаются узлы, разобранные до неё, и *der.ParseError.
+func Parse(data []byte, base, maxDepth int) ([]*Node, error) {
+	return parseLevel(data, base, 0, maxDepth)
+}
+
+func parseLevel(data []byte, base, depth, maxDepth int) ([]*Node, error) {
+	if maxDepth > 0 && depth >= maxDepth {
+		return nil, &der.ParseError{Offset: base, Err: der.ErrLimitExceeded}
+	}
+	var nodes []*Node
+	c := der.NewCursor("", data, base)
+	for !c.Done() {
+		off := c.Offset()
+		t, err := c.Next("")
+		if err != nil {
+			if !errors.Is(err, der.ErrTruncated) || t.HeaderLen == 0 {
+				return nodes, err
+			}
+			// Содержимое обрезано: узел выводится с доступной частью, потомки — до места обрыва.
+			t.Offset = off
+			n := &Node{TLV: t, Depth: depth, Truncated: true}
+			nodes = append(nodes, n)
+			if t.Constructed {
+				var inner error
+				if n.Children, inner = parseLevel(t.Content, off+t.HeaderLen, depth+1, maxDepth); inner != nil {
+					return nodes, inner
+				}
+			}
+			return nodes, err
+		}
+		n := &Node{TLV: t, Depth: depth}
+		nodes = append(nodes, n)
+		start := t.Offset + t.HeaderLen
+		if t.Constructed {
+			n.Children, err = parseLevel(t.Content, start, depth+1, maxDepth)
+			if err != nil {
+				return nodes, err
+			}
+			continue
+		}
+		if inner, skip := encapsulated(t); inner != nil {
+			if children, err := parseLevel(inner, start+skip, depth+1, maxDepth); err == nil {
+				n.Children, n.Encapsulated = children, true
+			}
+		}
+	}
+	return nodes, nil
+}
+
+// encapsulated возвращает вложенный DER из OCTET STRING или BIT STRING (без неиспользуемых битов),
+// если значение целиком — один корректный SEQUENCE или SET. skip — длина префикса BIT STRING.
+func encapsulated(t der.TLV) (inner []byte, skip int) {
+	if t.Class != der.ClassUniversal {
+		return nil, 0
+	}
+	content := t.Content
+	switch t.Tag {
+	case der.TagOctetString:
+	case tagBitString:
+		if len(content) < 1 || content[0] != 0 {
+			return nil, 0
+		}
+		content, skip = content[1:], 1
+	default:
+		return nil, 0
+	}
+	if len(content) < 2 || (content[0] != 0x30 && content[0] != 0x31) {
+		return nil, 0
+	}
+	first, rest, err := der.Read(content)
+	if err != nil || len(rest) != 0 || !first.Constructed {
+		return nil, 0
+	}
+	if err := der.CheckDepth(content, 0); err != nil {
+		return nil, 0
+	}
+	return content, skip
+}
+
+// Walk вызывает fn для каждого узла в порядке обхода в глубину.
+func Walk(nodes []*Node, fn func(n *Node)) {
+	for _, n := range nodes {
+		fn(n)
+		Walk(n.Children, fn)
+	}
+}
@@ -0,0 +1,76 @@
+package asn1tree
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+)
+
+func readOwnerRegistry(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", "owner_registry.p12"))
+	if err != nil {
+		t.Skip("owner_registry.p12 не найден")
+	}
+	return data
+}
+
+// TestParseOffsets проверяет абсолютные смещения узлов и раскрытие вложенного DER в eContent.
+func TestParseOffsets(t *testing.T) {
+	data := readOwnerRegistry(t)
+	nodes, err := Parse(data, 0, 32)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	encapsulated := 0
+	Walk(nodes, func(n *Node) {
+		// Смещение узла указывает на его полный TLV во входном файле.
+		if got := data[n.Offset : n.Offset+n.HeaderLen+n.Len()]; string(got) != string(n.Full) {
+			t.Fatalf("узел %s: смещение %d не совпадает с TLV", n.Name(), n.Offset)
+		}
+		if n.Encapsulated {
+			encapsulated++
+		}
+	})
+	if encapsulated == 0 {
+		t.Error("вложенный DER (eContent, сертификаты) не раскрыт")
+	}
+}
+
+// TestParseTruncated проверяет, что обрезанный файл выводится до места обрыва с *der.ParseError.
+func TestParseTruncated(t *testing.T) {
+	data := readOwnerRegistry(t)
+	nodes, err := Parse(data[:700], 0, 32)
+	if !errors.Is(err, der.ErrTruncated) {
+		t.Fatalf("ошибка = %v, ожидается der.ErrTruncated", err)
+	}
+	var perr *der.ParseError
+	if !errors.As(err, &perr) || perr.Offset <= 0 {
+		t.Fatalf("ожидается *der.ParseError со смещением, получено %v", err)
+	}
+	if len(nodes) != 1 || !nodes[0].Truncated || len(nodes[0].Children) == 0 {
+		t.Fatalf("корень должен быть обрезан и содержать разобранные потомки")
+	}
+
+	(*Schema)(nil).Annotate(nodes, "PFX")
+	var sb strings.Builder
+	TextOutput(&sb, nodes, err, false)
+	out := sb.String()
+	for _, want := range []string{"INTEGER = 3", "(обрезан)", "!! разбор остановлен: offset"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("в выводе нет %q:\n%s", want, out)
+		}
+	}
+}
+
+// TestParseMaxDepth проверяет ограничение вложенности.
+func TestParseMaxDepth(t *testing.T) {
+	data := readOwnerRegistry(t)
+	if _, err := Parse(data, 0, 3); !errors.Is(err, der.ErrLimitExceeded) {
+		t.Fatalf("ошибка = %v, ожидается der.ErrLimitExceeded", err)
+	}
+}
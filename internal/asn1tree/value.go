@@ -0,0 +1,181 @@
+package asn1tree
+
+import (
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+)
+
+// Универсальные теги, которых нет в der (значения примитивов).
+const (
+	tagBoolean         = 1
+	tagBitString       = 3
+	tagNull            = 5
+	tagEnumerated      = 10
+	tagUTF8String      = 12
+	tagPrintableString = 19
+	tagT61String       = 20
+	tagIA5String       = 22
+	tagUTCTime         = 23
+	tagGeneralizedTime = 24
+	tagVisibleString   = 26
+	tagBMPString       = 30
+)
+
+// maxHexBytes — сколько байт OCTET STRING / BIT STRING показывать в hex; остальное сокращается.
+const maxHexBytes = 32
+
+// OIDNames — имена известных OID (алгоритмы, PKCS#7/#9/#12, атрибуты X.520, расширения X.509).
+// Имена из модуля ASN.1 (id-atom-vin и т.п.) добавляются схемой и имеют приоритет.
+var OIDNames = map[string]string{
+	"1.2.840.113549.1.7.1":       "id-data",
+	"1.2.840.113549.1.7.2":       "id-signedData",
+	"1.2.840.113549.1.7.6":       "id-encryptedData",
+	"1.2.840.113549.1.9.1":       "emailAddress",
+	"1.2.840.113549.1.9.3":       "id-contentType",
+	"1.2.840.113549.1.9.4":       "id-messageDigest",
+	"1.2.840.113549.1.9.5":       "id-signingTime",
+	"1.2.840.113549.1.9.15":      "smimeCapabilities",
+	"1.2.840.113549.1.9.20":      "id-friendlyName",
+	"1.2.840.113549.1.9.21":      "id-localKeyID",
+	"1.2.840.113549.1.9.52":      "id-aa-CMSAlgorithmProtection",
+	"1.2.840.113549.1.9.22.1":    "x509Certificate",
+	"1.2.840.113549.1.9.22.2":    "sdsiCertificate",
+	"1.2.840.113549.1.12.10.1.1": "keyBag",
+	"1.2.840.113549.1.12.10.1.2": "pkcs8ShroudedKeyBag",
+	"1.2.840.113549.1.12.10.1.3": "id-certBag",
+	"1.2.840.113549.1.12.10.1.4": "crlBag",
+	"1.2.840.113549.1.12.10.1.5": "secretBag",
+	"1.2.840.113549.1.12.10.1.6": "safeContentsBag",
+	"1.2.840.113549.1.1.1":       "rsaEncryption",
+	"1.2.840.113549.1.1.11":      "sha256WithRSAEncryption",
+	"1.2.840.113549.1.1.12":      "sha384WithRSAEncryption",
+	"1.2.840.113549.1.1.13":      "sha512WithRSAEncryption",
+	"1.2.840.113549.2.9":         "hmacWithSHA256",
+	"1.2.840.10045.2.1":          "id-ecPublicKey",
+	"1.2.840.10045.3.1.7":        "prime256v1",
+	"1.3.132.0.34":               "secp384r1",
+	"1.3.132.0.35":               "secp521r1",
+	"1.2.840.10045.4.3.2":        "ecdsa-with-SHA256",
+	"1.2.840.10045.4.3.3":        "ecdsa-with-SHA384",
+	"1.2.840.10045.4.3.4":        "ecdsa-with-SHA512",
+	"1.3.101.112":                "Ed25519",
+	"2.16.840.1.101.3.4.2.1":     "sha256",
+	"2.16.840.1.101.3.4.2.2":     "sha384",
+	"2.16.840.1.101.3.4.2.3":     "sha512",
+	"2.5.4.3":                    "commonName",
+	"2.5.4.5":                    "serialNumber",
+	"2.5.4.6":                    "countryName",
+	"2.5.4.7":                    "localityName",
+	"2.5.4.8":                    "stateOrProvinceName",
+	"2.5.4.10":                   "organizationName",
+	"2.5.4.11":                   "organizationalUnitName",
+	"2.5.29.14":                  "subjectKeyIdentifier",
+	"2.5.29.15":                  "keyUsage",
+	"2.5.29.17":                  "subjectAltName",
+	"2.5.29.19":                  "basicConstraints",
+	"2.5.29.31":                  "cRLDistributionPoints",
+	"2.5.29.32":                  "certificatePolicies",
+	"2.5.29.35":                  "authorityKeyIdentifier",
+	"2.5.29.37":                  "extKeyUsage",
+	"1.3.6.1.5.5.7.1.1":          "authorityInfoAccess",
+	"1.3.6.1.5.5.7.3.1":          "serverAuth",
+	"1.3.6.1.5.5.7.3.2":          "clientAuth",
+	"1.3.6.1.5.5.7.1.24":         "id-ce-attCertValidityPeriod",
+}
+
+// formatValue возвращает текстовое значение примитивного узла; names — имена OID (может быть nil).
+func formatValue(n *Node, names map[string]string) string {
+	if n.Constructed || n.Class != der.ClassUniversal {
+		if !n.Constructed && n.Len() > 0 {
+			return hexValue(n.Content)
+		}
+		return ""
+	}
+	c := n.Content
+	switch n.Tag {
+	case tagBoolean:
+		if len(c) == 1 && c[0] != 0 {
+			return "TRUE"
+		}
+		return "FALSE"
+	case der.TagInteger, tagEnumerated:
+		if len(c) == 0 {
+			return ""
+		}
+		v := new(big.Int).SetBytes(c)
+		if c[0]&0x80 != 0 {
+			v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(len(c)*8)))
+		}
+		if len(c) <= 8 {
+			return v.String()
+		}
+		return "0x" + hex.EncodeToString(c)
+	case der.TagOID:
+		var oid asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(n.Full, &oid); err != nil {
+			return hexValue(c)
+		}
+		s := oid.String()
+		if name := oidName(s, names); name != "" {
+			return s + " (" + name + ")"
+		}
+		return s
+	case tagNull:
+		return ""
+	case tagUTF8String, tagPrintableString, tagIA5String, tagT61String, tagVisibleString:
+		if !utf8.Valid(c) {
+			return hexValue(c)
+		}
+		return strconv.Quote(string(c))
+	case tagBMPString:
+		if len(c)%2 != 0 {
+			return hexValue(c)
+		}
+		u := make([]uint16, len(c)/2)
+		for i := range u {
+			u[i] = uint16(c[2*i])<<8 | uint16(c[2*i+1])
+		}
+		return strconv.Quote(string(utf16.Decode(u)))
+	case tagUTCTime, tagGeneralizedTime:
+		return string(c)
+	case tagBitString:
+		if n.Encapsulated {
+			return "encapsulates"
+		}
+		if len(c) > 0 && c[0] != 0 {
+			return fmt.Sprintf("%s (unused bits %d)", hexValue(c[1:]), c[0])
+		}
+		if len(c) > 0 {
+			return hexValue(c[1:])
+		}
+		return ""
+	case der.TagOctetString:
+		if n.Encapsulated {
+			return "encapsulates"
+		}
+		return hexValue(c)
+	}
+	return hexValue(c)
+}
+
+// oidName ищет имя OID сначала в names (схема), затем в OIDNames.
+func oidName(oid string, names map[string]string) string {
+	if name := names[oid]; name != "" {
+		return name
+	}
+	return OIDNames[oid]
+}
+
+func hexValue(b []byte) string {
+	if len(b) <= maxHexBytes {
+		return hex.EncodeToString(b)
+	}
+	return fmt.Sprintf("%s… (%d bytes)", hex.EncodeToString(b[:maxHexBytes]), len(b))
+}
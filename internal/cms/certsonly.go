@@ -0,0 +1,93 @@
+// certsonly.go — вырожденный SignedData без подписантов (certs-only, .p7b, RFC 5652 5.2): наборы сертификатов
+// для Windows, MDM и openssl pkcs7 -print_certs.
+package cms
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"sort"
+)
+
+// PEMTypePKCS7 — тип PEM-блока .p7b (как у openssl crl2pkcs7 и Windows certutil).
+const PEMTypePKCS7 = "PKCS7"
+
+// CertsOnly сообщает, что контейнер — набор сертификатов без подписантов (.p7b).
+func (c *Container) CertsOnly() bool {
+	return len(c.SignerInfos) == 0
+}
+
+// MarshalCertsOnly кодирует сертификаты (DER) в ContentInfo с вырожденным SignedData: version 1, пустые
+// digestAlgorithms и signerInfos, encapContentInfo без eContent, certificates [0] IMPLICIT SET OF Certificate
+// в порядке DER (X.690). Повторяющиеся сертификаты записываются один раз.
+func MarshalCertsOnly(certs [][]byte) ([]byte, error) {
+	var set []byte
+	var sorted [][]byte
+	seen := make(map[string]bool)
+	for i, der := range certs {
+		if _, err := x509.ParseCertificate(der); err != nil {
+			return nil, fmt.Errorf("certificate %d: %w", i+1, err)
+		}
+		if !seen[string(der)] {
+			seen[string(der)] = true
+			sorted = append(sorted, der)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+	for _, der := range sorted {
+		set = append(set, der...)
+	}
+	sd, err := asn1.Marshal(struct {
+		Version          int
+		DigestAlgorithms []AlgorithmIdentifier `asn1:"set"`
+		EncapContentInfo struct{ EContentType asn1.ObjectIdentifier }
+		Certificates     asn1.RawValue
+		SignerInfos      []SignerInfo `asn1:"set"`
+	}{
+		Version:          1,
+		DigestAlgorithms: []AlgorithmIdentifier{},
+		EncapContentInfo: struct{ EContentType asn1.ObjectIdentifier }{OIDPKCS7Data},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: set},
+		SignerInfos:      []SignerInfo{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(ContentInfo{
+		ContentType: OIDPKCS7SignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd},
+	})
+}
+
+// CertsOnlyPEM — MarshalCertsOnly в PEM (-----BEGIN PKCS7-----).
+func CertsOnlyPEM(certs [][]byte) ([]byte, error) {
+	der, err := MarshalCertsOnly(certs)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: PEMTypePKCS7, Bytes: der}), nil
+}
+
+// ParseCertificates возвращает DER сертификатов набора .p7b — PEM (PKCS7, CMS) или DER ContentInfo, сертификаты
+// SignedData.certificates и eContent — либо одного сертификата X.509 в DER.
+func ParseCertificates(data []byte) ([][]byte, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	} else if cert, err := x509.ParseCertificate(data); err == nil {
+		return [][]byte{cert.Raw}, nil
+	}
+	c, err := ParseCMS(data)
+	if err != nil {
+		return nil, err
+	}
+	var out [][]byte
+	for _, cert := range append(c.Certificates, c.EContentCerts...) {
+		out = append(out, cert.Raw)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no certificates in SignedData")
+	}
+	return out, nil
+}
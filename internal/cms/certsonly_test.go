@@ -0,0 +1,77 @@
+package cms
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCert выпускает самоподписанный сертификат ECDSA P-256 с CN=cn.
+func testCert(t *testing.T, cn string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+// TestCertsOnly проверяет набор .p7b: MarshalCertsOnly без подписантов и повторов разбирается ParseCMS
+// как certs-only, сертификаты возвращает ParseCertificates из DER и PEM, отчёт помечает набор.
+func TestCertsOnly(t *testing.T) {
+	a, b := testCert(t, "A"), testCert(t, "B")
+	der, err := MarshalCertsOnly([][]byte{a, b, a})
+	if err != nil {
+		t.Fatalf("MarshalCertsOnly: %v", err)
+	}
+	c, err := ParseCMS(der)
+	if err != nil {
+		t.Fatalf("ParseCMS: %v", err)
+	}
+	if !c.CertsOnly() || len(c.Certificates) != 2 || c.Version != 1 || c.EContentSize != 0 {
+		t.Fatalf("CertsOnly = %v, сертификатов %d, version %d, eContentSize %d", c.CertsOnly(), len(c.Certificates), c.Version, c.EContentSize)
+	}
+	if r := c.BuildReport(false); !r.CertsOnly || !strings.Contains(c.ToText(false), "certs-only") {
+		t.Error("отчёт не помечает набор certs-only")
+	}
+
+	pemData, err := CertsOnlyPEM([][]byte{b, a})
+	if err != nil {
+		t.Fatalf("CertsOnlyPEM: %v", err)
+	}
+	if !bytes.HasPrefix(pemData, []byte("-----BEGIN PKCS7-----")) {
+		t.Errorf("PEM начинается с %q", pemData[:20])
+	}
+	for name, data := range map[string][]byte{"DER": der, "PEM": pemData} {
+		certs, err := ParseCertificates(data)
+		if err != nil {
+			t.Fatalf("ParseCertificates(%s): %v", name, err)
+		}
+		if len(certs) != 2 {
+			t.Errorf("ParseCertificates(%s): %d сертификатов, ожидается 2", name, len(certs))
+		}
+	}
+	if certs, err := ParseCertificates(a); err != nil || len(certs) != 1 || !bytes.Equal(certs[0], a) {
+		t.Errorf("ParseCertificates(сертификат DER) = %d, %v", len(certs), err)
+	}
+	if _, err := MarshalCertsOnly([][]byte{[]byte("not a cert")}); err == nil {
+		t.Error("MarshalCertsOnly принял не сертификат")
+	}
+}
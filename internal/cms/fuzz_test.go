@@ -0,0 +1,26 @@
+package cms
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// FuzzParseCMS: ParseCMS не должен паниковать ни на каком входе; корпус засевается образцами из pining-list.
+// Запуск: go test -run='^$' -fuzz=FuzzParseCMS -fuzztime=30s ./internal/cms
+func FuzzParseCMS(f *testing.F) {
+	paths, _ := filepath.Glob(filepath.Join("..", "..", "pining-list", "*.p7"))
+	for _, p := range paths {
+		if data, err := os.ReadFile(p); err == nil {
+			f.Add(data)
+		}
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		c, err := ParseCMSFromPEM(data)
+		if err != nil {
+			return
+		}
+		_ = c.ToText(false)
+		_, _ = c.ToJSON(false)
+	})
+}
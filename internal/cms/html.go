@@ -0,0 +1,93 @@
+package cms
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sgw-registry/registry-analyzer/internal/htmlreport"
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+)
+
+// HTMLReport собирает страницу отчёта (-format html) по данным BuildReport: SignedData, SignerInfo,
+// сертификаты из certificates и eContent, сертификат подписанта.
+func (c *Container) HTMLReport(title string, now time.Time) *htmlreport.Page {
+	r := c.BuildReport(true)
+	p := &htmlreport.Page{
+		Title:     "CMS / PKCS#7",
+		Subtitle:  title,
+		Generated: now.Format("2006-01-02 15:04:05 MST"),
+	}
+	sd := htmlreport.Section{
+		Title: "CMS SignedData",
+		Open:  true,
+		Fields: []htmlreport.Field{
+			{Name: "ContentType", Value: r.ContentType, Mono: true},
+			{Name: "Version", Value: fmt.Sprint(r.Version)},
+			{Name: "eContentType", Value: r.EContentType, Mono: true},
+			{Name: "eContentSize", Value: fmt.Sprint(r.EContentSize)},
+			{Name: "SignersCount", Value: fmt.Sprint(r.SignersCount)},
+		},
+	}
+	if r.CertsOnly {
+		sd.Badge = "certs-only (.p7b)"
+	}
+	p.Sections = append(p.Sections, sd)
+
+	signers := htmlreport.Section{Title: "SignerInfo", Badge: fmt.Sprint(len(r.SignerInfos)), Open: true}
+	for i, s := range r.SignerInfos {
+		sub := htmlreport.Section{
+			Title: fmt.Sprintf("[%d]", i+1),
+			Open:  true,
+			Fields: []htmlreport.Field{
+				{Name: "version", Value: fmt.Sprint(s.Version)},
+				{Name: "sidType", Value: s.SIDType},
+				{Name: "sid", Value: s.SID, Mono: true},
+				{Name: "digest", Value: s.DigestAlgorithm},
+				{Name: "signature", Value: s.DigestEncryptionAlgorithm},
+				{Name: "sigLen", Value: fmt.Sprint(s.EncryptedDigestLen)},
+			},
+		}
+		sub.Badge, sub.Status = i18n.T("сертификат найден"), htmlreport.StatusOK
+		if !s.SignerCertFound {
+			sub.Badge, sub.Status = i18n.T("сертификат не найден"), htmlreport.StatusFail
+		}
+		signers.Sections = append(signers.Sections, sub)
+	}
+	p.Sections = append(p.Sections, signers,
+		certsHTML("Certificates (SignedData)", r.Certificates, now),
+		certsHTML("eContentPEMCerts", r.EContentCerts, now))
+	if r.SignerCert != nil {
+		sc := certHTML(r.SignerCert.Subject, *r.SignerCert, now)
+		sc.Title, sc.Open = "SignerCert: "+r.SignerCert.Subject, true
+		p.Sections = append(p.Sections, sc)
+	}
+	return p
+}
+
+// certsHTML — секция со списком сертификатов; каждый сертификат — вложенная свёрнутая секция с PEM.
+func certsHTML(title string, certs []CertInfo, now time.Time) htmlreport.Section {
+	s := htmlreport.Section{Title: title, Badge: fmt.Sprint(len(certs))}
+	for i, ci := range certs {
+		s.Sections = append(s.Sections, certHTML(fmt.Sprintf("[%d] %s", i+1, ci.Subject), ci, now))
+	}
+	return s
+}
+
+func certHTML(title string, ci CertInfo, now time.Time) htmlreport.Section {
+	valid := htmlreport.Field{Name: "Valid", Value: ci.NotBefore + " — " + ci.NotAfter}
+	nb, errNB := time.Parse("2006-01-02 15:04:05", ci.NotBefore)
+	na, errNA := time.Parse("2006-01-02 15:04:05", ci.NotAfter)
+	if errNB == nil && errNA == nil && (now.Before(nb) || now.After(na)) {
+		valid.Status = htmlreport.StatusFail
+	}
+	return htmlreport.Section{
+		Title: title,
+		Fields: []htmlreport.Field{
+			{Name: "Issuer", Value: ci.Issuer},
+			{Name: "Serial", Value: ci.Serial, Mono: true},
+			valid,
+			{Name: "KeyAlg", Value: ci.KeyAlgorithm},
+			{Name: "PEM", Value: ci.PEM, Mono: true},
+		},
+	}
+}
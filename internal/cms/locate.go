@@ -0,0 +1,152 @@
+package cms
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+)
+
+// Пути основных узлов ContentInfo (RFC 5652).
+const (
+	pathContentInfo = "ContentInfo"
+	pathSignedData  = "ContentInfo.content.signedData"
+)
+
+// parseFailure превращает ошибку разбора err на этапе path в *der.ParseError: сначала ищется место нарушения
+// структурным обходом (locate), иначе возвращается err с путём этапа.
+func parseFailure(data []byte, maxDepth int, path string, err error) error {
+	if located := locate(data, maxDepth); located != nil {
+		return located
+	}
+	var perr *der.ParseError
+	if errors.As(err, &perr) {
+		if perr.Path == "" {
+			perr.Path = path
+		}
+		return err
+	}
+	return &der.ParseError{Offset: -1, Path: path, Err: err}
+}
+
+// locate обходит ContentInfo(SignedData) и возвращает первое структурное нарушение (*der.ParseError) или nil.
+// Проверяется только то, без чего ParseCMS не может продолжить: сертификаты и eContent разбираются мягко.
+func locate(data []byte, maxDepth int) error {
+	if err := der.CheckDepth(data, maxDepth); err != nil {
+		var perr *der.ParseError
+		if errors.As(err, &perr) {
+			perr.Path = pathContentInfo
+		}
+		return err
+	}
+	top := der.NewCursor("", data, 0)
+	ciTLV, err := top.Expect(pathContentInfo, der.ClassUniversal, der.TagSequence)
+	if err != nil {
+		return err
+	}
+	if !top.Done() {
+		return &der.ParseError{Offset: top.Offset(), Path: pathContentInfo, Expected: "end of data", Actual: "trailing bytes after ContentInfo"}
+	}
+	ci := top.Enter(pathContentInfo, ciTLV)
+	ct, ctTLV, err := ci.OID("contentType")
+	if err != nil {
+		return err
+	}
+	if !ct.Equal(OIDPKCS7SignedData) {
+		return &der.ParseError{Offset: ctTLV.Offset, Path: pathContentInfo + ".contentType", Expected: "pkcs7-signedData (" + OIDPKCS7SignedData.String() + ")", Actual: ct.String()}
+	}
+	contentTLV, err := ci.Expect("content", der.ClassContextSpecific, 0)
+	if err != nil {
+		return err
+	}
+	content := ci.Enter("content", contentTLV)
+	sdTLV, err := content.Expect("signedData", der.ClassUniversal, der.TagSequence)
+	if err != nil {
+		return err
+	}
+	sd := content.Enter("signedData", sdTLV)
+
+	if _, _, err := sd.Int("version"); err != nil {
+		return err
+	}
+	algsTLV, err := sd.Expect("digestAlgorithms", der.ClassUniversal, der.TagSet)
+	if err != nil {
+		return err
+	}
+	algs := sd.Enter("digestAlgorithms", algsTLV)
+	for i := 1; !algs.Done(); i++ {
+		if err := locateAlgorithmField(algs, fmt.Sprintf("digestAlgorithm[%d]", i)); err != nil {
+			return err
+		}
+	}
+	eciTLV, err := sd.Expect("encapContentInfo", der.ClassUniversal, der.TagSequence)
+	if err != nil {
+		return err
+	}
+	eci := sd.Enter("encapContentInfo", eciTLV)
+	if _, _, err := eci.OID("eContentType"); err != nil {
+		return err
+	}
+	eci.Optional(der.ClassContextSpecific, 0) // eContent
+	if err := eci.End(); err != nil {
+		return err
+	}
+	sd.Optional(der.ClassContextSpecific, 0) // certificates
+	sd.Optional(der.ClassContextSpecific, 1) // crls
+	siTLV, err := sd.Expect("signerInfos", der.ClassUniversal, der.TagSet)
+	if err != nil {
+		return err
+	}
+	sis := sd.Enter("signerInfos", siTLV)
+	for i := 1; !sis.Done(); i++ {
+		field := fmt.Sprintf("signerInfo[%d]", i)
+		t, err := sis.Expect(field, der.ClassUniversal, der.TagSequence)
+		if err != nil {
+			return err
+		}
+		if err := locateSignerInfo(sis.Enter(field, t)); err != nil {
+			return err
+		}
+	}
+	return sd.End()
+}
+
+// locateAlgorithmField читает поле AlgorithmIdentifier: SEQUENCE { algorithm OID, parameters ANY OPTIONAL }.
+func locateAlgorithmField(c *der.Cursor, field string) error {
+	t, err := c.Expect(field, der.ClassUniversal, der.TagSequence)
+	if err != nil {
+		return err
+	}
+	alg := c.Enter(field, t)
+	if _, _, err := alg.OID("algorithm"); err != nil {
+		return err
+	}
+	if !alg.Done() {
+		if _, err := alg.Next("parameters"); err != nil {
+			return err
+		}
+	}
+	return alg.End()
+}
+
+// locateSignerInfo проверяет поля SignerInfo.
+func locateSignerInfo(si *der.Cursor) error {
+	if _, _, err := si.Int("version"); err != nil {
+		return err
+	}
+	if _, err := si.Next("sid"); err != nil {
+		return err
+	}
+	if err := locateAlgorithmField(si, "digestAlgorithm"); err != nil {
+		return err
+	}
+	si.Optional(der.ClassContextSpecific, 0) // signedAttrs
+	if err := locateAlgorithmField(si, "signatureAlgorithm"); err != nil {
+		return err
+	}
+	if _, err := si.Expect("signature", der.ClassUniversal, der.TagOctetString); err != nil {
+		return err
+	}
+	si.Optional(der.ClassContextSpecific, 1) // unsignedAttrs
+	return si.End()
+}
@@ -10,6 +10,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
 )
 
 // ANSI-коды для цветного вывода в терминале.
@@ -60,6 +62,7 @@ type Report struct {
 	EContentType     string             `json:"eContentType"`
 	EContentSize     int                `json:"eContentSize"`
 	SignersCount     int                `json:"signersCount"`
+	CertsOnly        bool               `json:"certsOnly,omitempty"` // набор сертификатов без подписантов (.p7b)
 	SignerInfos      []SignerInfoSummary `json:"signerInfos,omitempty"`
 	Certificates     []CertInfo         `json:"certificates,omitempty"`
 	EContentCerts    []CertInfo         `json:"eContentPEMCerts,omitempty"`
@@ -94,6 +97,7 @@ func (c *Container) BuildReport(includePEM bool) Report {
 		EContentType:  c.EContentType.String(),
 		EContentSize:  c.EContentSize,
 		SignersCount:  len(c.SignerInfos),
+		CertsOnly:     c.CertsOnly(),
 	}
 	for i, si := range c.SignerInfos {
 		r.SignerInfos = append(r.SignerInfos, signerInfoSummary(&si, c.SignerCert != nil && i == 0))
@@ -204,6 +208,9 @@ func (c *Container) ToText(useColor bool) string {
 	b.WriteString(fmt.Sprintf("  %seContentType:%s  %s%s%s\n", dim, reset, val, r.EContentType, reset))
 	b.WriteString(fmt.Sprintf("  %seContentSize:%s  %s%d%s\n", dim, reset, val, r.EContentSize, reset))
 	b.WriteString(fmt.Sprintf("  %sSignersCount:%s  %s%d%s\n", dim, reset, val, r.SignersCount, reset))
+	if r.CertsOnly {
+		b.WriteString(fmt.Sprintf("  %sKind:%s          %scerts-only (.p7b)%s\n", dim, reset, val, reset))
+	}
 
 	// Секция: SignerInfo
 	if len(r.SignerInfos) > 0 {
@@ -231,7 +238,7 @@ func (c *Container) ToText(useColor bool) string {
 	} else {
 		b.WriteString("\n=== Certificates (SignedData) ===\n")
 	}
-	b.WriteString(fmt.Sprintf("  %s(%d записей)%s\n", dim, len(r.Certificates), reset))
+	b.WriteString(i18n.Sprintf("  %s(%d записей)%s\n", dim, len(r.Certificates), reset))
 	for i, ci := range r.Certificates {
 		if useColor {
 			b.WriteString(fmt.Sprintf("  %s[%d]%s %sSubject:%s %s%s%s\n", bold, i+1, reset, dim, reset, certHead, ci.Subject, reset))
@@ -248,7 +255,7 @@ func (c *Container) ToText(useColor bool) string {
 	} else {
 		b.WriteString("\n=== eContentPEMCerts ===\n")
 	}
-	b.WriteString(fmt.Sprintf("  %s(%d записей)%s\n", dim, len(r.EContentCerts), reset))
+	b.WriteString(i18n.Sprintf("  %s(%d записей)%s\n", dim, len(r.EContentCerts), reset))
 	for i, ci := range r.EContentCerts {
 		if useColor {
 			b.WriteString(fmt.Sprintf("  %s[%d]%s %sSubject:%s %s%s%s\n", bold, i+1, reset, dim, reset, certHead, ci.Subject, reset))
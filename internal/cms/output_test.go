@@ -0,0 +1,28 @@
+package cms
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+)
+
+// TestToTextLang проверяет, что текстовый отчёт выводится на выбранном языке, а по умолчанию — по-русски.
+func TestToTextLang(t *testing.T) {
+	der, err := MarshalCertsOnly([][]byte{testCert(t, "A")})
+	if err != nil {
+		t.Fatalf("MarshalCertsOnly: %v", err)
+	}
+	c, err := ParseCMS(der)
+	if err != nil {
+		t.Fatalf("ParseCMS: %v", err)
+	}
+	if out := c.ToText(false); !strings.Contains(out, "(1 записей)") {
+		t.Errorf("RU: нет счётчика записей:\n%s", out)
+	}
+	i18n.Set(i18n.EN)
+	t.Cleanup(func() { i18n.Set(i18n.RU) })
+	if out := c.ToText(true); !strings.Contains(out, "(1 entries)") || strings.Contains(out, "записей") {
+		t.Errorf("EN: отчёт не переведён:\n%s", out)
+	}
+}
@@ -5,52 +5,71 @@ import (
 	"crypto/x509"
 	"encoding/asn1"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"math/big"
+
+	"github.com/sgw-registry/registry-analyzer/internal/der"
 )
 
 // unwrapOctetString снимает обёртку OCTET STRING (0x04 ll ...) если есть.
 func unwrapOctetString(d []byte) []byte {
-	if len(d) < 2 || d[0] != 0x04 {
-		return d
-	}
-	skip := 2
-	l := int(d[1])
-	if d[1]&0x80 != 0 {
-		nlen := int(d[1] & 0x7f)
-		if 2+nlen > len(d) {
-			return d
-		}
-		l = 0
-		for i := 0; i < nlen; i++ {
-			l = l<<8 + int(d[2+i])
-		}
-		skip = 2 + nlen
+	return der.UnwrapOctetString(d)
+}
+
+// ErrLimitExceeded — превышен один из лимитов Limits (проверяется через errors.Is).
+var ErrLimitExceeded = der.ErrLimitExceeded
+
+// Limits — ограничения ParseCMSWithLimits. Нулевое или отрицательное поле — без ограничения.
+type Limits struct {
+	MaxInputSize    int // размер DER, байт
+	MaxDepth        int // глубина вложенности constructed-элементов
+	MaxCertificates int // сертификатов в SignedData.certificates и PEM-блоков в eContent
+	MaxSigners      int // SignerInfo в SignedData.signerInfos
+}
+
+// DefaultLimits — лимиты ParseCMS: списки пининга содержат десятки сертификатов.
+var DefaultLimits = Limits{
+	MaxInputSize:    16 << 20,
+	MaxDepth:        32,
+	MaxCertificates: 4096,
+	MaxSigners:      16,
+}
+
+// ParseCMS разбирает DER одного ContentInfo (pkcs7-signedData) с лимитами DefaultLimits и возвращает Container.
+func ParseCMS(data []byte) (*Container, error) {
+	return ParseCMSWithLimits(data, DefaultLimits)
+}
+
+// ParseCMSWithLimits — ParseCMS с заданными лимитами; при превышении ошибка оборачивает ErrLimitExceeded.
+// Ошибки разбора структуры — *der.ParseError со смещением (в DER, после декодирования PEM) и ASN.1-путём.
+func ParseCMSWithLimits(data []byte, lim Limits) (*Container, error) {
+	if lim.MaxInputSize > 0 && len(data) > lim.MaxInputSize {
+		return nil, fmt.Errorf("%w: input is %d bytes, limit %d", ErrLimitExceeded, len(data), lim.MaxInputSize)
 	}
-	if skip+l <= len(d) {
-		return d[skip : skip+l]
+	if err := der.CheckDepth(data, lim.MaxDepth); err != nil {
+		return nil, parseFailure(data, lim.MaxDepth, pathContentInfo, err)
 	}
-	return d
-}
 
-// ParseCMS разбирает DER одного ContentInfo (pkcs7-signedData) и возвращает Container.
-func ParseCMS(der []byte) (*Container, error) {
 	var ci ContentInfo
-	rest, err := asn1.Unmarshal(der, &ci)
+	rest, err := asn1.Unmarshal(data, &ci)
 	if err != nil {
-		return nil, fmt.Errorf("ContentInfo: %w", err)
+		return nil, parseFailure(data, lim.MaxDepth, pathContentInfo, err)
 	}
 	if len(rest) > 0 {
-		return nil, fmt.Errorf("trailing bytes after ContentInfo")
+		return nil, parseFailure(data, lim.MaxDepth, pathContentInfo, fmt.Errorf("trailing bytes after ContentInfo"))
 	}
 	if !ci.ContentType.Equal(OIDPKCS7SignedData) {
-		return nil, fmt.Errorf("contentType is not pkcs7-signedData: %v", ci.ContentType)
+		return nil, parseFailure(data, lim.MaxDepth, pathContentInfo+".contentType", fmt.Errorf("contentType is not pkcs7-signedData: %v", ci.ContentType))
 	}
 
 	var sd SignedData
 	_, err = asn1.Unmarshal(ci.Content.Bytes, &sd)
 	if err != nil {
-		return nil, fmt.Errorf("SignedData: %w", err)
+		return nil, parseFailure(data, lim.MaxDepth, pathSignedData, err)
+	}
+	if lim.MaxSigners > 0 && len(sd.SignerInfos) > lim.MaxSigners {
+		return nil, fmt.Errorf("%w: %d SignerInfos, limit %d", ErrLimitExceeded, len(sd.SignerInfos), lim.MaxSigners)
 	}
 
 	eContent := unwrapOctetString(sd.EncapContentInfo.EContent.Bytes)
@@ -67,9 +86,12 @@ func ParseCMS(der []byte) (*Container, error) {
 	setBytes := sd.Certificates.Bytes
 	if len(setBytes) > 0 {
 		if setBytes[0] != 0x31 {
-			setBytes = append([]byte{0x31}, setBytes...)
+			setBytes = der.PrependHeader(0x31, setBytes)
+		}
+		certs, err := parseCertificateSet(setBytes, lim.MaxCertificates)
+		if errors.Is(err, ErrLimitExceeded) {
+			return nil, fmt.Errorf("certificates: %w", err)
 		}
-		certs, err := parseCertificateSet(setBytes)
 		if err != nil {
 			// В некоторых контейнерах [0] может быть пустой или с другой структурой — не ломаем разбор
 			c.Certificates = nil
@@ -80,7 +102,10 @@ func ParseCMS(der []byte) (*Container, error) {
 
 	// PEM-сертификаты из eContent (если eContent — текст с -----BEGIN CERTIFICATE-----)
 	if len(eContent) > 0 {
-		certs := parsePEMCerts(eContent)
+		certs, err := parsePEMCerts(eContent, lim.MaxCertificates)
+		if err != nil {
+			return nil, fmt.Errorf("eContent: %w", err)
+		}
 		c.EContentCerts = certs
 	}
 
@@ -94,16 +119,22 @@ func ParseCMS(der []byte) (*Container, error) {
 
 // ParseCMSFromPEM читает PEM с границами -----BEGIN CMS----- / -----END CMS----- или -----BEGIN PKCS7----- / -----END PKCS7----- и разбирает тело как DER ContentInfo.
 func ParseCMSFromPEM(pemBytes []byte) (*Container, error) {
+	return ParseCMSFromPEMWithLimits(pemBytes, DefaultLimits)
+}
+
+// ParseCMSFromPEMWithLimits — ParseCMSFromPEM с заданными лимитами (применяются к DER после декодирования PEM).
+func ParseCMSFromPEMWithLimits(pemBytes []byte, lim Limits) (*Container, error) {
 	block, _ := pem.Decode(pemBytes)
 	if block == nil {
 		// Попробуем как сырой DER
-		return ParseCMS(pemBytes)
+		return ParseCMSWithLimits(pemBytes, lim)
 	}
 	// Тип может быть "CMS", "PKCS7", "PKCS#7" и т.д.
-	return ParseCMS(block.Bytes)
+	return ParseCMSWithLimits(block.Bytes, lim)
 }
 
-func parseCertificateSet(setBytes []byte) ([]*x509.Certificate, error) {
+// parseCertificateSet разбирает SET OF Certificate; max > 0 ограничивает число элементов.
+func parseCertificateSet(setBytes []byte, max int) ([]*x509.Certificate, error) {
 	var raw asn1.RawValue
 	_, err := asn1.Unmarshal(setBytes, &raw)
 	if err != nil {
@@ -114,31 +145,46 @@ func parseCertificateSet(setBytes []byte) ([]*x509.Certificate, error) {
 	}
 	var certs []*x509.Certificate
 	rest := raw.Bytes
-	for len(rest) > 0 {
+	for n := 1; len(rest) > 0; n++ {
+		if max > 0 && n > max {
+			return nil, fmt.Errorf("%w: more than %d certificates", ErrLimitExceeded, max)
+		}
 		var certOctet asn1.RawValue
 		rest, err = asn1.Unmarshal(rest, &certOctet)
 		if err != nil {
 			return nil, err
 		}
-		if certOctet.Class == asn1.ClassUniversal && certOctet.Tag == asn1.TagOctetString {
-			c, err := x509.ParseCertificate(certOctet.Bytes)
-			if err != nil {
-				return nil, err
-			}
-			certs = append(certs, c)
+		// Сертификат в обёртке OCTET STRING (как в реестрах ATOM) или стандартный Certificate (SEQUENCE, .p7b);
+		// прочие CertificateChoices ([0]..[3] — атрибутные и другие сертификаты) пропускаются.
+		var certDER []byte
+		switch {
+		case certOctet.Class == asn1.ClassUniversal && certOctet.Tag == asn1.TagOctetString:
+			certDER = certOctet.Bytes
+		case certOctet.Class == asn1.ClassUniversal && certOctet.Tag == asn1.TagSequence:
+			certDER = certOctet.FullBytes
+		default:
+			continue
+		}
+		c, err := x509.ParseCertificate(certDER)
+		if err != nil {
+			return nil, err
 		}
+		certs = append(certs, c)
 	}
 	return certs, nil
 }
 
-// parsePEMCerts извлекает все PEM-блоки CERTIFICATE из data.
-func parsePEMCerts(data []byte) []*x509.Certificate {
+// parsePEMCerts извлекает все PEM-блоки CERTIFICATE из data; max > 0 ограничивает число блоков.
+func parsePEMCerts(data []byte, max int) ([]*x509.Certificate, error) {
 	var certs []*x509.Certificate
-	for {
+	for n := 1; ; n++ {
 		block, rest := pem.Decode(data)
 		if block == nil {
 			break
 		}
+		if max > 0 && n > max {
+			return nil, fmt.Errorf("%w: more than %d PEM blocks", ErrLimitExceeded, max)
+		}
 		if block.Type == "CERTIFICATE" {
 			c, err := x509.ParseCertificate(block.Bytes)
 			if err == nil {
@@ -150,7 +196,7 @@ func parsePEMCerts(data []byte) []*x509.Certificate {
 			break
 		}
 	}
-	return certs
+	return certs, nil
 }
 
 // extractSKID извлекает SubjectKeyIdentifier из SignerInfo.sid ([0] IMPLICIT OCTET STRING или IssuerAndSerialNumber).
@@ -202,25 +248,14 @@ func findSignerBySKID(si *SignerInfo, certs, eContentCerts []*x509.Certificate)
 	return nil
 }
 
-// extractSerialFromIssuerAndSerial находит в DER IssuerAndSerialNumber значение serialNumber (последний INTEGER).
-func extractSerialFromIssuerAndSerial(der []byte) []byte {
+// extractSerialFromIssuerAndSerial возвращает serialNumber из содержимого IssuerAndSerialNumber
+// (SEQUENCE { issuer Name, serialNumber INTEGER }): последний INTEGER верхнего уровня длиной 1..32 байт.
+func extractSerialFromIssuerAndSerial(content []byte) []byte {
+	elems, _ := der.Children(content, 0)
 	var last []byte
-	for i := 0; i+2 <= len(der); i++ {
-		if der[i] != 0x02 {
-			continue
-		}
-		l := int(der[i+1])
-		start := i + 2
-		if der[i+1]&0x80 != 0 {
-			if der[i+1] == 0x81 && i+3 <= len(der) {
-				l = int(der[i+2])
-				start = i + 3
-			} else {
-				continue
-			}
-		}
-		if l >= 1 && l <= 32 && start+l <= len(der) {
-			last = der[start : start+l]
+	for _, t := range elems {
+		if t.Is(der.ClassUniversal, der.TagInteger) && !t.Constructed && t.Len() >= 1 && t.Len() <= 32 {
+			last = t.Content
 		}
 	}
 	return last
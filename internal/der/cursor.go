@@ -0,0 +1,152 @@
+// cursor.go — Cursor: последовательное чтение дочерних элементов с абсолютными смещениями и ASN.1-путём
+// для поиска места ошибки разбора (все ошибки — *ParseError).
+package der
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+// errMissing — обязательный элемент отсутствует (содержимое родителя закончилось).
+var errMissing = errors.New("element missing")
+
+// Cursor последовательно читает дочерние элементы constructed-значения, помня абсолютное смещение
+// и ASN.1-путь родителя. Все ошибки Cursor — *ParseError.
+type Cursor struct {
+	Path string
+	rest []byte
+	off  int
+}
+
+// NewCursor создаёт курсор по b; offset — смещение b во входных данных, path — ASN.1-путь содержимого.
+func NewCursor(path string, b []byte, offset int) *Cursor {
+	return &Cursor{Path: path, rest: b, off: offset}
+}
+
+// JoinPath добавляет к пути имя поля через точку.
+func JoinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// Done сообщает, что все элементы прочитаны.
+func (c *Cursor) Done() bool { return len(c.rest) == 0 }
+
+// Offset — смещение следующего непрочитанного элемента.
+func (c *Cursor) Offset() int { return c.off }
+
+// Peek возвращает следующий элемент, не сдвигая курсор; ok=false, если элементов нет или заголовок некорректен.
+func (c *Cursor) Peek() (t TLV, ok bool) {
+	t, _, err := Read(c.rest)
+	if err != nil {
+		return t, false
+	}
+	t.Offset = c.off
+	return t, true
+}
+
+// Next читает следующий элемент с любым тегом.
+func (c *Cursor) Next(field string) (TLV, error) {
+	return c.read(field, "")
+}
+
+// Expect читает следующий элемент и проверяет его класс и тег (SEQUENCE и SET должны быть constructed).
+func (c *Cursor) Expect(field string, class, tag int) (TLV, error) {
+	want := TagName(class, tag)
+	t, err := c.read(field, want)
+	if err != nil {
+		return t, err
+	}
+	if !t.Is(class, tag) {
+		return t, &ParseError{Offset: t.Offset, Path: JoinPath(c.Path, field), Expected: want, Actual: t.Name()}
+	}
+	if class == ClassUniversal && (tag == TagSequence || tag == TagSet) && !t.Constructed {
+		return t, &ParseError{Offset: t.Offset, Path: JoinPath(c.Path, field), Expected: want, Actual: t.Name() + " (primitive)"}
+	}
+	return t, nil
+}
+
+// Int читает INTEGER и проверяет, что он корректно закодирован и помещается в int (как ожидает encoding/asn1).
+func (c *Cursor) Int(field string) (int, TLV, error) {
+	t, err := c.Expect(field, ClassUniversal, TagInteger)
+	if err != nil {
+		return 0, t, err
+	}
+	var v int
+	if _, err := asn1.Unmarshal(t.Full, &v); err != nil {
+		return 0, t, c.Wrap(field, t, err)
+	}
+	return v, t, nil
+}
+
+// OID читает OBJECT IDENTIFIER.
+func (c *Cursor) OID(field string) (asn1.ObjectIdentifier, TLV, error) {
+	t, err := c.Expect(field, ClassUniversal, TagOID)
+	if err != nil {
+		return nil, t, err
+	}
+	var oid asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(t.Full, &oid); err != nil {
+		return nil, t, c.Wrap(field, t, err)
+	}
+	return oid, t, nil
+}
+
+// Optional читает следующий элемент, если у него заданные класс и тег; иначе курсор не сдвигается.
+func (c *Cursor) Optional(class, tag int) (TLV, bool) {
+	t, ok := c.Peek()
+	if !ok || !t.Is(class, tag) {
+		return TLV{}, false
+	}
+	c.rest = c.rest[len(t.Full):]
+	c.off += len(t.Full)
+	return t, true
+}
+
+// End проверяет, что после прочитанных полей не осталось лишних элементов.
+func (c *Cursor) End() error {
+	if c.Done() {
+		return nil
+	}
+	actual := "trailing data"
+	if t, ok := c.Peek(); ok {
+		actual = t.Name()
+	}
+	return &ParseError{Offset: c.off, Path: c.Path, Expected: "end of content", Actual: actual}
+}
+
+// Enter возвращает курсор по содержимому t; field добавляется к пути.
+func (c *Cursor) Enter(field string, t TLV) *Cursor {
+	return NewCursor(JoinPath(c.Path, field), t.Content, t.Offset+t.HeaderLen)
+}
+
+// Errorf создаёт ParseError для поля field элемента t.
+func (c *Cursor) Errorf(field string, t TLV, format string, args ...interface{}) *ParseError {
+	return &ParseError{Offset: t.Offset, Path: JoinPath(c.Path, field), Err: fmt.Errorf(format, args...)}
+}
+
+// Wrap оборачивает err в ParseError для поля field элемента t.
+func (c *Cursor) Wrap(field string, t TLV, err error) *ParseError {
+	return &ParseError{Offset: t.Offset, Path: JoinPath(c.Path, field), Err: err}
+}
+
+func (c *Cursor) read(field, want string) (TLV, error) {
+	path := JoinPath(c.Path, field)
+	if want == "" {
+		want = "element"
+	}
+	if len(c.rest) == 0 {
+		return TLV{}, &ParseError{Offset: c.off, Path: path, Expected: want, Actual: "end of content", Err: errMissing}
+	}
+	t, rest, err := Read(c.rest)
+	if err != nil {
+		return t, &ParseError{Offset: c.off, Path: path, Err: err}
+	}
+	t.Offset = c.off
+	c.rest = rest
+	c.off += len(t.Full)
+	return t, nil
+}
@@ -0,0 +1,238 @@
+// Package der — безопасный разбор DER TLV для ATOM-PKCS12-REGISTRY и CMS: чтение заголовков с проверкой длин,
+// обход дерева с ограничением глубины и числа узлов, снятие обёртки OCTET STRING и ограниченное чтение файлов.
+// Вся арифметика длин выполняется здесь; пакеты registry и cms не считают смещения вручную.
+package der
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Классы тегов (X.690, 8.1.2.2).
+const (
+	ClassUniversal       = 0
+	ClassApplication     = 1
+	ClassContextSpecific = 2
+	ClassPrivate         = 3
+)
+
+// Универсальные теги, с которыми работают разборщики.
+const (
+	TagInteger     = 2
+	TagOctetString = 4
+	TagOID         = 6
+	TagSequence    = 16
+	TagSet         = 17
+)
+
+// maxLengthOctets — не более 4 байт длины: объекты больше 4 ГиБ заведомо не помещаются в лимит входа.
+const maxLengthOctets = 4
+
+var (
+	// ErrTruncated — заголовок или содержимое выходят за границу данных.
+	ErrTruncated = errors.New("der: truncated")
+	// ErrIndefiniteLength — неопределённая длина (BER 0x80) в DER недопустима.
+	ErrIndefiniteLength = errors.New("der: indefinite length")
+	// ErrLimitExceeded — превышен лимит разбора (размер, глубина, число элементов).
+	ErrLimitExceeded = errors.New("der: limit exceeded")
+)
+
+// TLV — один разобранный элемент: класс, номер тега, признак constructed, смещение в исходных данных,
+// длина заголовка (тег + длина) и содержимое. Full — весь TLV (заголовок + содержимое).
+type TLV struct {
+	Class       int
+	Tag         int
+	Constructed bool
+	Offset      int
+	HeaderLen   int
+	Content     []byte
+	Full        []byte
+}
+
+// Len — длина содержимого.
+func (t TLV) Len() int { return len(t.Content) }
+
+// Is сообщает, совпадают ли класс и номер тега.
+func (t TLV) Is(class, tag int) bool { return t.Class == class && t.Tag == tag }
+
+// Read разбирает первый TLV в b и возвращает его и остаток. Длина проверяется до выделения памяти и срезов.
+// Если обрезано только содержимое, вместе с ErrTruncated возвращается TLV с HeaderLen и доступной частью Content.
+func Read(b []byte) (TLV, []byte, error) {
+	var t TLV
+	if len(b) < 2 {
+		return t, nil, ErrTruncated
+	}
+	t.Class = int(b[0] >> 6)
+	t.Constructed = b[0]&0x20 != 0
+	t.Tag = int(b[0] & 0x1f)
+	pos := 1
+	if t.Tag == 0x1f {
+		// Длинная форма номера тега: base-128, не более 4 байт (номера до 2^28).
+		t.Tag = 0
+		for i := 0; ; i++ {
+			if pos >= len(b) {
+				return t, nil, ErrTruncated
+			}
+			if i == 4 {
+				return t, nil, fmt.Errorf("der: tag number too large")
+			}
+			c := b[pos]
+			pos++
+			t.Tag = t.Tag<<7 | int(c&0x7f)
+			if c&0x80 == 0 {
+				break
+			}
+		}
+	}
+	if pos >= len(b) {
+		return t, nil, ErrTruncated
+	}
+	l := int(b[pos])
+	pos++
+	if l&0x80 != 0 {
+		n := l & 0x7f
+		if n == 0 {
+			return t, nil, ErrIndefiniteLength
+		}
+		if n > maxLengthOctets {
+			return t, nil, fmt.Errorf("der: length of %d octets is too large", n)
+		}
+		if len(b)-pos < n {
+			return t, nil, ErrTruncated
+		}
+		l = 0
+		for i := 0; i < n; i++ {
+			l = l<<8 | int(b[pos+i])
+		}
+		pos += n
+	}
+	t.HeaderLen = pos
+	if l < 0 || l > len(b)-pos {
+		// Заголовок прочитан, содержимое обрезано: возвращаем доступную часть (для вывода повреждённых файлов).
+		t.Content, t.Full = b[pos:], b
+		return t, nil, ErrTruncated
+	}
+	t.Content = b[pos : pos+l]
+	t.Full = b[:pos+l]
+	return t, b[pos+l:], nil
+}
+
+// Children разбирает содержимое constructed-элемента в список дочерних TLV (смещения — относительно base).
+func Children(content []byte, base int) ([]TLV, error) {
+	var out []TLV
+	off := base
+	for len(content) > 0 {
+		t, rest, err := Read(content)
+		if err != nil {
+			return out, err
+		}
+		t.Offset = off
+		off += len(t.Full)
+		out = append(out, t)
+		content = rest
+	}
+	return out, nil
+}
+
+// WalkFunc вызывается для каждого узла при обходе Walk; depth — глубина узла (0 для верхнего уровня).
+type WalkFunc func(t TLV, depth int) error
+
+// Walk обходит дерево TLV в b в глубину. В constructed-элементы спускается всегда; в примитивные — нет.
+// maxDepth > 0 ограничивает глубину вложенности, maxNodes > 0 — общее число узлов (иначе без ограничения).
+// Ошибки разбора и лимитов возвращаются как *ParseError со смещением элемента (без ASN.1-пути).
+func Walk(b []byte, maxDepth, maxNodes int, fn WalkFunc) error {
+	nodes := 0
+	var walk func(b []byte, base, depth int) error
+	walk = func(b []byte, base, depth int) error {
+		if maxDepth > 0 && depth >= maxDepth {
+			return &ParseError{Offset: base, Err: fmt.Errorf("%w: nesting depth > %d", ErrLimitExceeded, maxDepth)}
+		}
+		off := base
+		for len(b) > 0 {
+			t, rest, err := Read(b)
+			if err != nil {
+				return &ParseError{Offset: off, Err: err}
+			}
+			t.Offset = off
+			nodes++
+			if maxNodes > 0 && nodes > maxNodes {
+				return &ParseError{Offset: off, Err: fmt.Errorf("%w: more than %d elements", ErrLimitExceeded, maxNodes)}
+			}
+			if fn != nil {
+				if err := fn(t, depth); err != nil {
+					return err
+				}
+			}
+			if t.Constructed {
+				if err := walk(t.Content, off+t.HeaderLen, depth+1); err != nil {
+					return err
+				}
+			}
+			off += len(t.Full)
+			b = rest
+		}
+		return nil
+	}
+	return walk(b, 0, 0)
+}
+
+// CheckDepth проверяет, что вложенность constructed-элементов в b не превышает maxDepth.
+func CheckDepth(b []byte, maxDepth int) error {
+	return Walk(b, maxDepth, 0, nil)
+}
+
+// UnwrapOctetString возвращает содержимое OCTET STRING, если d начинается с корректного TLV OCTET STRING (0x04),
+// иначе — d как есть. Нужно для полей [0], которые бывают EXPLICIT (04 ll val) и IMPLICIT (сырые байты).
+func UnwrapOctetString(d []byte) []byte {
+	if len(d) < 2 || d[0] != TagOctetString {
+		return d
+	}
+	t, _, err := Read(d)
+	if err != nil {
+		return d
+	}
+	return t.Content
+}
+
+// PrependHeader кодирует TLV с однобайтовым тегом tag и содержимым content (длина в минимальной форме).
+// Используется для восстановления TLV при IMPLICIT-кодировании ([0] без внутреннего 0x30/0x31).
+func PrependHeader(tag byte, content []byte) []byte {
+	l := len(content)
+	out := make([]byte, 0, l+6)
+	out = append(out, tag)
+	switch {
+	case l < 0x80:
+		out = append(out, byte(l))
+	default:
+		var lb []byte
+		for v := l; v > 0; v >>= 8 {
+			lb = append([]byte{byte(v)}, lb...)
+		}
+		out = append(out, 0x80|byte(len(lb)))
+		out = append(out, lb...)
+	}
+	return append(out, content...)
+}
+
+// ReadFile читает файл, если его размер не превышает max байт (max <= 0 — без ограничения).
+// Размер проверяется по фактически прочитанным данным, а не по Stat: годится и для каналов, и для stdin.
+func ReadFile(path string, max int64) ([]byte, error) {
+	if max <= 0 {
+		return os.ReadFile(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > max {
+		return nil, fmt.Errorf("%w: %s is larger than %d bytes", ErrLimitExceeded, path, max)
+	}
+	return data, nil
+}
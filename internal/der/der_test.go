@@ -0,0 +1,95 @@
+package der
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// TestRead проверяет разбор заголовков: короткая и длинная форма длины, усечение, неопределённая длина.
+func TestRead(t *testing.T) {
+	long := PrependHeader(TagOctetString, bytes.Repeat([]byte{0xaa}, 300))
+	if !bytes.Equal(long[:4], []byte{0x04, 0x82, 0x01, 0x2c}) {
+		t.Fatalf("PrependHeader: заголовок % x", long[:4])
+	}
+	tlv, rest, err := Read(append(long, 0x05, 0x00))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if !tlv.Is(ClassUniversal, TagOctetString) || tlv.Len() != 300 || tlv.HeaderLen != 4 || len(rest) != 2 {
+		t.Errorf("Read: %+v, rest %d", tlv, len(rest))
+	}
+
+	for name, in := range map[string][]byte{
+		"пусто":                {},
+		"без длины":            {0x30},
+		"длина за границей":    {0x30, 0x05, 0x01},
+		"байты длины обрезаны": {0x30, 0x82, 0x01},
+		"длина 2^32":           {0x30, 0x84, 0xff, 0xff, 0xff, 0xff},
+	} {
+		if _, _, err := Read(in); !errors.Is(err, ErrTruncated) {
+			t.Errorf("%s: err = %v, ожидается ErrTruncated", name, err)
+		}
+	}
+	if _, _, err := Read([]byte{0x30, 0x80, 0x00, 0x00}); !errors.Is(err, ErrIndefiniteLength) {
+		t.Errorf("неопределённая длина: err = %v", err)
+	}
+	if _, _, err := Read([]byte{0x30, 0x89, 1, 2, 3, 4, 5, 6, 7, 8, 9}); err == nil {
+		t.Error("9 байт длины должны отклоняться")
+	}
+}
+
+// TestCheckDepth проверяет ограничение глубины вложенности.
+func TestCheckDepth(t *testing.T) {
+	b := []byte{0x02, 0x01, 0x01}
+	for i := 0; i < 10; i++ {
+		b = PrependHeader(0x30, b)
+	}
+	if err := CheckDepth(b, 11); err != nil {
+		t.Errorf("глубина 11: %v", err)
+	}
+	if err := CheckDepth(b, 10); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("глубина 10: err = %v, ожидается ErrLimitExceeded", err)
+	}
+}
+
+// TestUnwrapOctetString: обёртка снимается только с корректного OCTET STRING.
+func TestUnwrapOctetString(t *testing.T) {
+	if got := UnwrapOctetString([]byte{0x04, 0x02, 0xab, 0xcd}); !bytes.Equal(got, []byte{0xab, 0xcd}) {
+		t.Errorf("EXPLICIT: % x", got)
+	}
+	raw := []byte{0x04, 0x7f, 0x01} // длина больше данных — это не OCTET STRING, а сырые байты
+	if got := UnwrapOctetString(raw); !bytes.Equal(got, raw) {
+		t.Errorf("усечённый: % x", got)
+	}
+}
+
+// TestCursor проверяет абсолютные смещения и ASN.1-путь в ошибках Cursor.
+func TestCursor(t *testing.T) {
+	// SEQUENCE { INTEGER 1, SET { OCTET STRING } } со смещением 10 во входных данных.
+	inner := PrependHeader(0x31, []byte{0x04, 0x00})
+	seq := PrependHeader(0x30, append([]byte{0x02, 0x01, 0x01}, inner...))
+	top := NewCursor("", seq, 10)
+	s, err := top.Expect("Root", ClassUniversal, TagSequence)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := top.Enter("Root", s)
+	if v, _, err := root.Int("version"); err != nil || v != 1 {
+		t.Fatalf("Int: %d, %v", v, err)
+	}
+	_, err = root.Expect("items", ClassUniversal, TagSequence)
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("err = %v", err)
+	}
+	if perr.Path != "Root.items" || perr.Offset != 15 || perr.Expected != "SEQUENCE" || perr.Actual != "SET" {
+		t.Errorf("ParseError = %+v", perr)
+	}
+	if want := "Root.items: offset 15 (0xf): expected SEQUENCE, got SET"; perr.Error() != want {
+		t.Errorf("Error() = %q, ожидается %q", perr.Error(), want)
+	}
+	if _, err := root.Next("extra"); !errors.As(err, &perr) || perr.Actual != "end of content" {
+		t.Errorf("отсутствующий элемент: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+// errors.go — ошибка разбора ParseError со смещением, ASN.1-путём и ожидаемым/фактическим тегом,
+// её JSON-форма для отчётов и имена тегов для сообщений.
+package der
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ParseError — ошибка разбора с местом в данных: смещение элемента, ASN.1-путь
+// (например PFX.authSafe.content.signedData.encapContentInfo.eContent.safeBag[3].bagAttributes[1])
+// и ожидаемый/фактический тег. Err — причина (ErrTruncated, ошибка encoding/asn1 или x509 и т.п.).
+type ParseError struct {
+	Offset   int    // смещение начала элемента во входных данных; -1 — неизвестно
+	Path     string // ASN.1-путь до элемента
+	Expected string // ожидаемый тег или значение (например "SEQUENCE", "version 3")
+	Actual   string // фактический тег или значение
+	Err      error
+}
+
+func (e *ParseError) Error() string {
+	var parts []string
+	if e.Path != "" {
+		parts = append(parts, e.Path)
+	}
+	if e.Offset >= 0 {
+		parts = append(parts, fmt.Sprintf("offset %d (0x%x)", e.Offset, e.Offset))
+	}
+	if e.Expected != "" || e.Actual != "" {
+		parts = append(parts, fmt.Sprintf("expected %s, got %s", orNone(e.Expected), orNone(e.Actual)))
+	}
+	if e.Err != nil {
+		parts = append(parts, e.Err.Error())
+	}
+	return strings.Join(parts, ": ")
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+func orNone(s string) string {
+	if s == "" {
+		return "?"
+	}
+	return s
+}
+
+// universalNames — имена универсальных тегов (X.680, 8.4) для сообщений об ошибках и дерева TLV.
+var universalNames = map[int]string{
+	1: "BOOLEAN", 2: "INTEGER", 3: "BIT STRING", 4: "OCTET STRING", 5: "NULL", 6: "OBJECT IDENTIFIER",
+	10: "ENUMERATED", 12: "UTF8String", 16: "SEQUENCE", 17: "SET", 19: "PrintableString", 20: "T61String",
+	22: "IA5String", 23: "UTCTime", 24: "GeneralizedTime", 26: "VisibleString", 28: "UniversalString", 30: "BMPString",
+}
+
+// TagName возвращает имя тега: "SEQUENCE", "OCTET STRING", "[0]", "[APPLICATION 1]", "[UNIVERSAL 99]".
+func TagName(class, tag int) string {
+	switch class {
+	case ClassUniversal:
+		if n, ok := universalNames[tag]; ok {
+			return n
+		}
+		return fmt.Sprintf("[UNIVERSAL %d]", tag)
+	case ClassApplication:
+		return fmt.Sprintf("[APPLICATION %d]", tag)
+	case ClassContextSpecific:
+		return fmt.Sprintf("[%d]", tag)
+	default:
+		return fmt.Sprintf("[PRIVATE %d]", tag)
+	}
+}
+
+// Name — имя тега элемента (TagName).
+func (t TLV) Name() string { return TagName(t.Class, t.Tag) }
+
+// MarshalJSON кодирует ошибку для JSON-отчётов: message, offset (если известно), path, expected, actual, cause.
+func (e *ParseError) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Message  string `json:"message"`
+		Offset   *int   `json:"offset,omitempty"`
+		Path     string `json:"path,omitempty"`
+		Expected string `json:"expected,omitempty"`
+		Actual   string `json:"actual,omitempty"`
+		Cause    string `json:"cause,omitempty"`
+	}{Message: e.Error(), Path: e.Path, Expected: e.Expected, Actual: e.Actual}
+	if e.Offset >= 0 {
+		out.Offset = &e.Offset
+	}
+	if e.Err != nil {
+		out.Cause = e.Err.Error()
+	}
+	return json.Marshal(out)
+}
@@ -0,0 +1,98 @@
+// Package findings собирает результаты проверок анализаторов (разбор, -verify, -validate) в единый список
+// и выводит его в форматах для CI: SARIF 2.1.0 (-format sarif) и JUnit XML (-format junit).
+// Результат проверки — правило, уровень, сообщение, файл и место в ASN.1 (путь и смещение в DER).
+package findings
+
+import (
+	"strings"
+
+	"github.com/sgw-registry/registry-analyzer/internal/asn1tree"
+)
+
+// Уровни нарушения (как level в SARIF).
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+)
+
+// Rule — описание правила: ID попадает в ruleId (SARIF) и в имя testcase (JUnit), Level — уровень по умолчанию.
+type Rule struct {
+	ID          string
+	Name        string
+	Description string
+	Level       string
+}
+
+// Result — результат одной проверки. OK — проверка пройдена (в SARIF — kind "pass", в JUnit — testcase без failure).
+// Path — ASN.1-путь как в ошибках разбора ("PFX.authSafe.content.signerInfos[1].encryptedDigest"),
+// Offset — смещение элемента в DER (-1 — неизвестно).
+type Result struct {
+	RuleID  string
+	OK      bool
+	Level   string // уровень нарушения; пусто — уровень правила
+	Message string
+	File    string
+	Path    string
+	Offset  int
+}
+
+// Report — все результаты одного запуска анализатора. Tool — имя утилиты (driver.name в SARIF, имя testsuites в JUnit).
+type Report struct {
+	Tool    string
+	Rules   []Rule
+	Results []Result
+}
+
+// rule возвращает правило по ID и его индекс в Rules (-1 — правило не описано).
+func (r *Report) rule(id string) (Rule, int) {
+	for i, rule := range r.Rules {
+		if rule.ID == id {
+			return rule, i
+		}
+	}
+	return Rule{ID: id, Level: LevelError}, -1
+}
+
+// level — уровень нарушения результата: свой или правила.
+func (r *Report) level(res *Result) string {
+	if res.Level != "" {
+		return res.Level
+	}
+	rule, _ := r.rule(res.RuleID)
+	if rule.Level == "" {
+		return LevelError
+	}
+	return rule.Level
+}
+
+// Locate дополняет результаты без смещения смещениями узлов дерева DER (подписанного схемой) по пути:
+// если элемента с таким путём нет (например, отсутствующий атрибут), берётся ближайший существующий предок.
+func Locate(results []Result, nodes []*asn1tree.Node) {
+	offsets := make(map[string]int)
+	asn1tree.WalkPaths(nodes, func(path string, n *asn1tree.Node) {
+		if _, ok := offsets[path]; !ok {
+			offsets[path] = n.Offset
+		}
+	})
+	for i := range results {
+		if results[i].Offset >= 0 || results[i].Path == "" {
+			continue
+		}
+		for p := results[i].Path; p != ""; p = parentOf(p) {
+			if off, ok := offsets[p]; ok {
+				results[i].Offset = off
+				break
+			}
+		}
+	}
+}
+
+// parentOf отбрасывает последний элемент пути: ".field", "(id-...)" или "[n]".
+func parentOf(p string) string {
+	i := strings.LastIndexAny(p, ".([")
+	if i < 0 {
+		return ""
+	}
+	return p[:i]
+}
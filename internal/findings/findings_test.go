@@ -0,0 +1,98 @@
+package findings
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/sgw-registry/registry-analyzer/internal/asn1tree"
+)
+
+func testReport() *Report {
+	return &Report{
+		Tool:  "test-analyzer",
+		Rules: []Rule{{ID: "t/parse", Description: "разбор", Level: LevelError}, {ID: "t/check", Description: "проверка", Level: LevelWarning}},
+		Results: []Result{
+			{RuleID: "t/parse", OK: true, Message: "ok", File: "a.p12", Path: "PFX", Offset: 0},
+			{RuleID: "t/check", Message: "нарушение <1>", File: "a.p12", Path: "PFX.version", Offset: 4},
+			{RuleID: "t/parse", Message: "truncated", File: "dir/b.p12", Offset: -1},
+		},
+	}
+}
+
+// TestWriteSARIF проверяет kind/level, ruleIndex и места результатов.
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testReport().WriteSARIF(&buf); err != nil {
+		t.Fatalf("WriteSARIF: %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("SARIF не разбирается: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Tool.Driver.Rules) != 2 {
+		t.Fatalf("заголовок SARIF: %+v", log)
+	}
+	res := log.Runs[0].Results
+	if len(res) != 3 {
+		t.Fatalf("результатов %d, ожидается 3", len(res))
+	}
+	if res[0].Kind != "pass" || res[0].Level != "none" {
+		t.Errorf("пройденная проверка: kind %q level %q", res[0].Kind, res[0].Level)
+	}
+	if r := res[1]; r.Kind != "fail" || r.Level != LevelWarning || r.RuleIndex == nil || *r.RuleIndex != 1 ||
+		r.Locations[0].PhysicalLocation.Region.ByteOffset != 4 || r.Locations[0].LogicalLocations[0].FullyQualifiedName != "PFX.version" {
+		t.Errorf("нарушение: %+v", r)
+	}
+	if r := res[2]; r.Locations[0].PhysicalLocation.Region != nil || r.Locations[0].LogicalLocations != nil {
+		t.Errorf("результат без пути и смещения: %+v", r.Locations[0])
+	}
+	if !strings.Contains(buf.String(), "нарушение <1>") {
+		t.Error("сообщение экранировано")
+	}
+}
+
+// TestWriteJUnit проверяет разбиение по файлам и счётчики.
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testReport().WriteJUnit(&buf); err != nil {
+		t.Fatalf("WriteJUnit: %v", err)
+	}
+	var out junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("JUnit не разбирается: %v", err)
+	}
+	if out.Tests != 3 || out.Failures != 2 || len(out.Suites) != 2 {
+		t.Fatalf("testsuites: tests %d failures %d suites %d", out.Tests, out.Failures, len(out.Suites))
+	}
+	a := out.Suites[0]
+	if a.Name != "a.p12" || a.Tests != 2 || a.Failures != 1 || a.Cases[0].Failure != nil {
+		t.Errorf("testsuite a.p12: %+v", a)
+	}
+	if f := a.Cases[1].Failure; f == nil || f.Type != LevelWarning || !strings.Contains(f.Text, "смещение: 4") || a.Cases[1].Name != "t/check PFX.version" {
+		t.Errorf("failure: %+v", a.Cases[1])
+	}
+}
+
+// TestLocate проверяет смещение по пути и по ближайшему предку для отсутствующего элемента.
+func TestLocate(t *testing.T) {
+	// SEQUENCE { INTEGER 1, SET { BOOLEAN TRUE } }
+	nodes, err := asn1tree.Parse([]byte{0x30, 0x08, 0x02, 0x01, 0x01, 0x31, 0x03, 0x01, 0x01, 0xff}, 0, 16)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	results := []Result{
+		{Path: "SEQUENCE.SET.BOOLEAN", Offset: -1},
+		{Path: "SEQUENCE.SET(id-missing)", Offset: -1},
+		{Path: "SEQUENCE.INTEGER", Offset: 99},
+		{Offset: -1},
+	}
+	Locate(results, nodes)
+	for i, want := range []int{7, 5, 99, -1} {
+		if results[i].Offset != want {
+			t.Errorf("%q: смещение %d, ожидается %d", results[i].Path, results[i].Offset, want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package findings
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+)
+
+// Структуры JUnit XML в варианте, который читают CI (Jenkins, GitLab, GitHub Actions): testsuites → testsuite → testcase.
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit выводит отчёт в JUnit XML: testsuite на файл (в порядке первого появления), testcase на результат
+// (имя — ID правила и ASN.1-путь), нарушение — failure с уровнем в type и местом в тексте.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	out := junitSuites{Name: r.Tool}
+	index := make(map[string]int)
+	for i := range r.Results {
+		res := &r.Results[i]
+		si, ok := index[res.File]
+		if !ok {
+			si = len(out.Suites)
+			index[res.File] = si
+			out.Suites = append(out.Suites, junitSuite{Name: res.File})
+		}
+		tc := junitCase{Name: res.RuleID, ClassName: r.Tool, File: res.File}
+		if res.Path != "" {
+			tc.Name += " " + res.Path
+		}
+		if !res.OK {
+			text := res.Message
+			if res.Path != "" {
+				text += "\n" + i18n.T("путь") + ": " + res.Path
+			}
+			if res.Offset >= 0 {
+				text += i18n.Sprintf("\nсмещение: %d (0x%x)", res.Offset, res.Offset)
+			}
+			tc.Failure = &junitFailure{Message: res.Message, Type: r.level(res), Text: text}
+			out.Suites[si].Failures++
+			out.Failures++
+		}
+		out.Suites[si].Tests++
+		out.Tests++
+		out.Suites[si].Cases = append(out.Suites[si].Cases, tc)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
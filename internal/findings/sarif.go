@@ -0,0 +1,117 @@
+package findings
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+)
+
+// Структуры SARIF 2.1.0 (подмножество: driver с правилами, результаты с физическим и логическим местом).
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string       `json:"id"`
+	Name                 string       `json:"name,omitempty"`
+	ShortDescription     sarifMessage `json:"shortDescription"`
+	DefaultConfiguration struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex *int            `json:"ruleIndex,omitempty"`
+	Kind      string          `json:"kind"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation struct {
+		URI string `json:"uri"`
+	} `json:"artifactLocation"`
+	Region *struct {
+		ByteOffset int `json:"byteOffset"`
+	} `json:"region,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// WriteSARIF выводит отчёт в SARIF 2.1.0: один run, правила в tool.driver.rules, каждый результат — с kind
+// "pass" (level "none") или "fail", файлом и смещением (physicalLocation) и ASN.1-путём (logicalLocations).
+func (r *Report) WriteSARIF(w io.Writer) error {
+	run := sarifRun{Tool: sarifTool{Driver: sarifDriver{Name: r.Tool, Rules: []sarifRule{}}}, Results: []sarifResult{}}
+	for _, rule := range r.Rules {
+		sr := sarifRule{ID: rule.ID, Name: rule.Name, ShortDescription: sarifMessage{i18n.T(rule.Description)}}
+		sr.DefaultConfiguration.Level = rule.Level
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sr)
+	}
+	for i := range r.Results {
+		res := &r.Results[i]
+		out := sarifResult{RuleID: res.RuleID, Kind: "fail", Level: r.level(res), Message: sarifMessage{res.Message}}
+		if res.OK {
+			out.Kind, out.Level = "pass", "none"
+		}
+		if _, idx := r.rule(res.RuleID); idx >= 0 {
+			out.RuleIndex = &idx
+		}
+		var loc sarifLocation
+		if res.File != "" {
+			loc.PhysicalLocation = &sarifPhysicalLocation{}
+			loc.PhysicalLocation.ArtifactLocation.URI = filepath.ToSlash(res.File)
+			if res.Offset >= 0 {
+				loc.PhysicalLocation.Region = &struct {
+					ByteOffset int `json:"byteOffset"`
+				}{res.Offset}
+			}
+		}
+		if res.Path != "" {
+			loc.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: res.Path, Kind: "element"}}
+		}
+		if loc.PhysicalLocation != nil || loc.LogicalLocations != nil {
+			out.Locations = []sarifLocation{loc}
+		}
+		run.Results = append(run.Results, out)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
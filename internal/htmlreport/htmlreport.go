@@ -0,0 +1,75 @@
+// Package htmlreport выводит отчёт анализаторов (-format html) одним самодостаточным HTML-файлом:
+// стили встроены, внешних ресурсов и скриптов нет, секции сворачиваются элементом <details>.
+// Данные отчёта собирают пакеты registry и cms; здесь — только модель страницы и шаблон.
+package htmlreport
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+)
+
+// Статусы полей, строк и секций: определяют цвет значка (Section.Status) или строки.
+const (
+	StatusNone = ""
+	StatusOK   = "ok"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+
+// Page — страница отчёта: заголовок, подзаголовок (файл), время формирования и секции верхнего уровня.
+type Page struct {
+	Title     string
+	Subtitle  string
+	Generated string
+	Sections  []Section
+}
+
+// Section — сворачиваемая секция. Badge — краткая сводка справа от заголовка (например «OK», «4 мешка»)
+// с цветом по Status; Open — секция раскрыта при открытии файла. Вложенные секции выводятся после полей и таблиц.
+type Section struct {
+	Title    string
+	Badge    string
+	Status   string
+	Open     bool
+	Fields   []Field
+	Tables   []Table
+	Sections []Section
+}
+
+// Field — строка «имя: значение» секции. Mono — моноширинный шрифт (hex, OID, PEM).
+type Field struct {
+	Name   string
+	Value  string
+	Status string
+	Mono   bool
+}
+
+// Table — таблица секции; Status строки подсвечивает её целиком.
+type Table struct {
+	Caption string
+	Header  []string
+	Rows    []Row
+}
+
+// Row — строка таблицы.
+type Row struct {
+	Cells  []string
+	Status string
+}
+
+//go:embed report.html.tmpl
+var pageTemplate string
+
+// Функции шаблона: lang — код языка для <html lang>, t — перевод подписи страницы.
+var tmpl = template.Must(template.New("page").Funcs(template.FuncMap{
+	"lang": func() string { return string(i18n.Current()) },
+	"t":    i18n.T,
+}).Parse(pageTemplate))
+
+// Write выводит страницу в w. Все значения экранируются html/template.
+func Write(w io.Writer, p *Page) error {
+	return tmpl.Execute(w, p)
+}
@@ -0,0 +1,36 @@
+package htmlreport
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestWrite проверяет экранирование значений, вложенные секции и отсутствие внешних ресурсов.
+func TestWrite(t *testing.T) {
+	p := &Page{
+		Title:    "Отчёт",
+		Subtitle: `a"b<c>.p12`,
+		Sections: []Section{{
+			Title: "Верхняя", Badge: "OK", Status: StatusOK, Open: true,
+			Fields:   []Field{{Name: "VIN", Value: "<img src=x>", Mono: true}},
+			Tables:   []Table{{Header: []string{"#"}, Rows: []Row{{Cells: []string{"1"}, Status: StatusFail}}}},
+			Sections: []Section{{Title: "Вложенная"}},
+		}},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, p); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.String()
+	for _, s := range []string{"<details open>", `<span class="badge ok">OK</span>`, "&lt;img src=x&gt;", `<tr class="fail"><td>1</td></tr>`, "<summary>Вложенная</summary>"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("в выводе нет %q", s)
+		}
+	}
+	for _, s := range []string{"<img", "<script", "<link", "http://", "https://"} {
+		if strings.Contains(out, s) {
+			t.Errorf("в выводе есть %q", s)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+// catalog.go — английские переводы сообщений; ключ — исходная русская строка из кода (T, Sprintf, Errorf).
+package i18n
+
+var en = map[string]string{
+	"схема: %v\n":                  "schema: %v\n",
+	"запись в файл: %v\n":          "writing file: %v\n",
+	"Дерево ASN.1 записано в %s\n": "ASN.1 tree written to %s\n",
+	"сертификатов: %d, в eContent: %d, подписантов: %d":         "certificates: %d, in eContent: %d, signers: %d",
+	"сертификат подписанта не найден в certificates и eContent": "signer certificate not found in certificates or eContent",
+	"запись %s: %v\n":     "writing %s: %v\n",
+	"запись отчёта: %v\n": "writing report: %v\n",
+	"Файл разбирается как CMS ContentInfo с SignedData":                       "File parses as CMS ContentInfo with SignedData",
+	"Сертификат подписанта найден в certificates или в eContent (PEM) по SID": "Signer certificate is found by SID in certificates or eContent (PEM)",
+	"Формат вывода: text, json, pem, asn1 (дерево DER с полями ContentInfo/SignedData), html (самодостаточная HTML-страница), sarif, junit (результаты проверок для CI)": "Output format: text, json, pem, asn1 (DER tree with ContentInfo/SignedData fields), html (self-contained HTML page), sarif, junit (check results for CI)",
+	"Записать вывод в файл (по умолчанию — stdout)":                                                         "Write output to a file (default: stdout)",
+	"Выгрузить каждый сертификат из SignedData.certificates в отдельный PEM в директорию (cert-1.pem, ...)": "Export each certificate from SignedData.certificates to a separate PEM in the directory (cert-1.pem, ...)",
+	"Выгрузить все сертификаты (SignedData + eContent) в один PEM с именем контейнера (file.p7 → file.pem)": "Export all certificates (SignedData + eContent) to one PEM named after the container (file.p7 → file.pem)",
+	"Выгрузить каждый сертификат из eContent в отдельный PEM в директорию (econtent-1.pem, ...)":            "Export each certificate from eContent to a separate PEM in the directory (econtent-1.pem, ...)",
+	"Выгрузить сертификат подписанта в PEM (file.p7 → file_signer.pem)":                                     "Export the signer certificate to PEM (file.p7 → file_signer.pem)",
+	"Отключить цветной вывод и иконки":                                                                      "Disable colored output and icons",
+	"Цвет: auto (только TTY), always, never":                                                                "Color: auto (TTY only), always, never",
+	"Максимальный размер контейнера (DER), байт; больший файл не разбирается":                               "Maximum container size (DER), bytes; larger files are not parsed",
+	"Использование: %s [опции] <файл.p7|файл.p7b>\n":                                                        "Usage: %s [options] <file.p7|file.p7b>\n",
+	"чтение файла: %v\n":                                                         "reading file: %v\n",
+	"создание директории %s: %v\n":                                               "creating directory %s: %v\n",
+	"Выгружено %d сертификатов из SignedData в %s\n":                             "Exported %d certificates from SignedData to %s\n",
+	"Все сертификаты (%d шт.) записаны в %s\n":                                   "All certificates (%d) written to %s\n",
+	"Выгружено %d сертификатов из eContent в %s\n":                               "Exported %d certificates from eContent to %s\n",
+	"export-signer-cert: %v (подписант не найден среди certificates/eContent)\n": "export-signer-cert: %v (signer not found in certificates/eContent)\n",
+	"Сертификат подписанта записан в %s\n":                                       "Signer certificate written to %s\n",
+	"неизвестный формат: %s\n":                                                   "unknown format: %s\n",
+	"разбор CMS":               "parsing CMS",
+	"  путь:      %s\n":        "  path:      %s\n",
+	"  смещение:  %d (0x%x)\n": "  offset:    %d (0x%x)\n",
+	"  ожидалось: %s\n":        "  expected:  %s\n",
+	"  получено:  %s\n":        "  actual:    %s\n",
+	"  причина:   %v\n":        "  cause:     %v\n",
+	"  просмотр:  openssl asn1parse -inform %s -in %s -i -offset %d\n": "  inspect:   openssl asn1parse -inform %s -in %s -i -offset %d\n",
+	"Отчёт по %d файлам записан в %s\n":                                "Report on %d files written to %s\n",
+	"%s не поддерживается с %s\n":                                      "%s is not supported with %s\n",
+	"маска %s: %w": "pattern %s: %w",
+	"обход %s: %w": "walking %s: %w",
+	"в контейнере нет сертификатов X.509":                                     "the container has no X.509 certificates",
+	"окно %q: ожидается число дней (30d), недель (2w) или длительность (36h)": "window %q: expected a number of days (30d), weeks (2w) or a duration (36h)",
+	"окно %q: отрицательная длительность":                                     "window %q: negative duration",
+	"-expiring-within поддерживает -format text, json или ndjson, не %s\n":    "-expiring-within supports -format text, json or ndjson, not %s\n",
+	"Сроки по %d файлам записаны в %s\n":                                      "Expiry dates for %d files written to %s\n",
+	"Сроки, истекающие в течение %s (до %s)":                                  "Validity periods expiring within %s (by %s)",
+	"  %sистекающих сроков нет%s\n":                                           "  %snothing expires%s\n",
+	"  %s%-8s%s %s %5d дн.  %s  %s[%d] %s%s %s\n":                             "  %s%-8s%s %s %5d d.  %s  %s[%d] %s%s %s\n",
+	"подписант": "signer",
+	"цепочка":   "chain",
+	"мешок":     "bag",
+	"роль":      "role",
+	"контейнер соответствует модулю":                                                  "container conforms to module",
+	"сертификатов: %d, мешков: %d, подписантов: %d":                                   "certificates: %d, bags: %d, signers: %d",
+	"macData отсутствует":                                                             "no macData",
+	"подписанты отсутствуют":                                                          "no signers",
+	"ожидалось %s, получено %s":                                                       "expected %s, got %s",
+	"Результаты проверок (%d файлов) записаны в %s\n":                                 "Check results (%d files) written to %s\n",
+	"Контейнер читается и разбирается как ATOM-PKCS12-REGISTRY или хранилище PKCS#12": "Container is readable and parses as ATOM-PKCS12-REGISTRY or a PKCS#12 keystore",
+	"DER контейнера соответствует модулю registry.asn1 (-validate)":                   "Container DER conforms to the registry.asn1 module (-validate)",
+	"Сертификат подписанта найден в SignedData.certificates по SID":                   "Signer certificate is found by SID in SignedData.certificates",
+	"Атрибут contentType совпадает с eContentType":                                    "contentType attribute matches eContentType",
+	"Атрибут messageDigest совпадает с хешем eContent":                                "messageDigest attribute matches the eContent hash",
+	"Атрибут CMSAlgorithmProtection (RFC 6211) совпадает с алгоритмами SignerInfo":    "CMSAlgorithmProtection attribute (RFC 6211) matches the SignerInfo algorithms",
+	"Подпись authenticatedAttributes проверяется ключом подписанта":                   "authenticatedAttributes signature verifies with the signer key",
+	"MAC хранилища PKCS#12 совпадает с паролем -password (-verify)":                   "PKCS#12 keystore MAC matches the -password (-verify)",
+	"Формат вывода: text, json, json-certificates, pem, jwks (JWK Set ключей SafeBags), pins (пины SHA-256 SPKI по ролям), builder-config, asn1 (дерево DER с полями registry.asn1), html (самодостаточная HTML-страница); ndjson, csv — пакетный анализ нескольких файлов; sarif, junit — результаты проверок для CI": "Output format: text, json, json-certificates, pem, jwks (JWK Set of SafeBag keys), pins (SHA-256 SPKI pins by role), builder-config, asn1 (DER tree with registry.asn1 fields), html (self-contained HTML page); ndjson, csv — batch analysis of several files; sarif, junit — check results for CI",
+	"Для -format builder-config: директория для PEM сертификатов SafeBags, на которые ссылается конфиг":                                                                                                        "For -format builder-config: directory for the SafeBag certificate PEMs referenced by the config",
+	"Выгрузить каждый сертификат из SignedData в отдельный PEM-файл в указанную директорию (cert-1.pem, cert-2.pem, ...)":                                                                                      "Export each certificate from SignedData to a separate PEM file in the given directory (cert-1.pem, cert-2.pem, ...)",
+	"Выгрузить все сертификаты из SafeBags в один PEM-файл с именем контейнера (например owner_registry.p12 → owner_registry.pem)":                                                                             "Export all SafeBag certificates to one PEM file named after the container (e.g. owner_registry.p12 → owner_registry.pem)",
+	"Выгрузить каждый сертификат из SafeBags в отдельный PEM-файл в указанную директорию (имя: roleName_Serial.pem)":                                                                                           "Export each SafeBag certificate to a separate PEM file in the given directory (name: roleName_Serial.pem)",
+	"Выгрузить сертификат подписанта контейнера в PEM-файл с именем контейнера (например owner_registry.p12 → owner_registry_signer.pem)":                                                                      "Export the container signer certificate to a PEM file named after the container (e.g. owner_registry.p12 → owner_registry_signer.pem)",
+	"Проверить подпись: сертификат подписанта, contentType, messageDigest, CMSAlgorithmProtection, подпись атрибутов (код выхода 2 при ошибке)":                                                                "Verify the signature: signer certificate, contentType, messageDigest, CMSAlgorithmProtection, attribute signature (exit code 2 on failure)",
+	"Проверить DER контейнера по registry.asn1 и вывести все несоответствия (код выхода 3 при несоответствиях)":                                                                                                "Validate the container DER against registry.asn1 and list all mismatches (exit code 3 on mismatches)",
+	"При -verify требовать наличие атрибута CMSAlgorithmProtection (RFC 6211)":                                                                                                                                 "With -verify, require the CMSAlgorithmProtection attribute (RFC 6211)",
+	"Максимальный размер контейнера, байт; больший файл не читается и не разбирается":                                                                                                                          "Maximum container size, bytes; larger files are neither read nor parsed",
+	"Для -format ndjson|csv|sarif|junit и -expiring-within: искать *.p12 в поддиректориях переданных директорий":                                                                                               "For -format ndjson|csv|sarif|junit and -expiring-within: search for *.p12 in subdirectories of the given directories",
+	"Вывести сертификаты (подписант, цепочка, мешки) и roleValidityPeriod, истекающие в течение окна (30d, 2w, 36h), по дате; код выхода 5 — есть истёкшие, 4 — истекающие":                                    "List certificates (signer, chain, bags) and roleValidityPeriod expiring within the window (30d, 2w, 36h), by date; exit code 5 — some expired, 4 — some expiring",
+	"Для -format ndjson|csv: число файлов, разбираемых параллельно":                                                                                                                                            "For -format ndjson|csv: number of files parsed in parallel",
+	"Записать сертификаты SafeBags в хранилище доверенных сертификатов PKCS#12 (RFC 7292) для keytool, .NET и OpenSSL; пароль — -password или -password-file":                                                  "Write SafeBag certificates to a PKCS#12 truststore (RFC 7292) for keytool, .NET and OpenSSL; password from -password or -password-file",
+	"Для -export-truststore: добавить сертификат подписанта контейнера":                                                                                                                                        "For -export-truststore: add the container signer certificate",
+	"Для -export-truststore: добавить остальные сертификаты SignedData (цепочку CA)":                                                                                                                           "For -export-truststore: add the other SignedData certificates (CA chain)",
+	"Записать сертификаты SafeBags и SignedData в набор сертификатов CMS без подписантов (.p7b, RFC 5652) для Windows и MDM":                                                                                   "Write SafeBag and SignedData certificates to a certs-only CMS bundle (.p7b, RFC 5652) for Windows and MDM",
+	"Для -export-p7b: PEM (-----BEGIN PKCS7-----) вместо DER":                                                                                                                                                  "For -export-p7b: PEM (-----BEGIN PKCS7-----) instead of DER",
+	"Записать сертификаты (подписант, цепочка, SafeBags) в директорию для OpenSSL -CApath: PEM, ссылки subject_hash.N как c_rehash и manifest.json с ролями":                                                   "Write certificates (signer, chain, SafeBags) to a directory for OpenSSL -CApath: PEM, subject_hash.N links as c_rehash makes and manifest.json with roles",
+	"Записать реестр в опорной DER-форме ADR-011 (нормализация IMPLICIT-вариантов) и сообщить, действительна ли подпись после нормализации":                                                                    "Write the registry in the ADR-011 reference DER form (normalizing IMPLICIT variants) and report whether the signature stays valid",
+	"Пароль хранилища PKCS#12 (RFC 7292): проверка MAC, расшифровка encryptedData и ключей; пароль хранилища -export-truststore":                                                                               "PKCS#12 keystore password (RFC 7292): MAC check, decryption of encryptedData and keys; password of the -export-truststore keystore",
+	"Прочитать пароль хранилища PKCS#12 из файла (первая строка); не оставляет пароль в истории shell":                                                                                                         "Read the PKCS#12 keystore password from a file (first line); keeps the password out of shell history",
+	"Использование: %s [опции] <файл.p12>\n       %s -format ndjson|csv|sarif|junit [-recursive] <файл.p12|маска|директория>...\n       %s -expiring-within 30d [-recursive] <файл.p12|маска|директория>...\n": "Usage: %s [options] <file.p12>\n       %s -format ndjson|csv|sarif|junit [-recursive] <file.p12|pattern|directory>...\n       %s -expiring-within 30d [-recursive] <file.p12|pattern|directory>...\n",
+	"несколько файлов поддерживаются только с -format ndjson, csv, sarif или junit":                                                                                                                            "multiple files are supported only with -format ndjson, csv, sarif or junit",
+	"%s — директория; для пакетного анализа используйте -format ndjson или csv\n":                                                                                                                              "%s is a directory; use -format ndjson or csv for batch analysis\n",
+	"предупреждение: MAC хранилища не совпал — неверный пароль (-password) или изменённое содержимое":                                                                                                          "warning: keystore MAC mismatch — wrong password (-password) or modified content",
+	"предупреждение: AuthenticatedSafe[%d] (%s): %s\n":                                                       "warning: AuthenticatedSafe[%d] (%s): %s\n",
+	"Выгружено %d сертификатов в %s\n":                                                                       "Exported %d certificates to %s\n",
+	"Сертификаты из SafeBags (%d шт.) записаны в %s\n":                                                       "SafeBag certificates (%d) written to %s\n",
+	"Выгружено %d сертификатов из SafeBags в %s\n":                                                           "Exported %d SafeBag certificates to %s\n",
+	"Сертификат подписанта не найден в контейнере\n":                                                         "Signer certificate not found in the container\n",
+	"export-truststore: требуется -password или -password-file (keytool не открывает хранилище без пароля)":  "export-truststore: -password or -password-file is required (keytool does not open a keystore without a password)",
+	"Хранилище доверенных сертификатов PKCS#12 записано в %s\n":                                              "PKCS#12 truststore written to %s\n",
+	"export-p7b: в контейнере нет сертификатов X.509":                                                        "export-p7b: the container has no X.509 certificates",
+	"Набор сертификатов .p7b (%d шт.) записан в %s\n":                                                        ".p7b certificate bundle (%d) written to %s\n",
+	"Директория -CApath: %d сертификатов и %s записаны в %s\n":                                               "-CApath directory: %d certificates and %s written to %s\n",
+	"Реестр уже в опорной форме, копия записана в %s\n":                                                      "Registry is already in the reference form, copy written to %s\n",
+	"Реестр в опорной форме записан в %s, изменения:\n":                                                      "Registry in the reference form written to %s, changes:\n",
+	"Подпись после нормализации действительна":                                                               "Signature is valid after normalization",
+	"Подпись после нормализации НЕДЕЙСТВИТЕЛЬНА (исходная была действительна): изменились подписанные байты": "Signature is INVALID after normalization (the original was valid): signed bytes changed",
+	"Подпись недействительна и в исходном реестре":                                                           "Signature is invalid in the original registry as well",
+	"Полный отчёт записан в %s\n":                                                                            "Full report written to %s\n",
+	"Данные сертификатов записаны в %s\n":                                                                    "Certificate data written to %s\n",
+	"builder-config: требуется -export-dir <директория>\n":                                                   "builder-config: -export-dir <directory> is required\n",
+	"предупреждение: %s\n":                                                                                   "warning: %s\n",
+	"Сертификаты SafeBags (%d шт.) записаны в %s; signerCert/signerKey в конфиге — заглушки\n":               "SafeBag certificates (%d) written to %s; signerCert/signerKey in the config are placeholders\n",
+	"HTML-отчёт записан в %s\n":                                                                              "HTML report written to %s\n",
+	"Ключи SafeBags (%s) записаны в %s\n":                                                                    "SafeBag keys (%s) written to %s\n",
+	"Сертификаты (PEM) записаны в %s\n":                                                                      "Certificates (PEM) written to %s\n",
+	"Текстовый отчёт записан в %s\n":                                                                         "Text report written to %s\n",
+	"-password и -password-file нельзя указывать вместе":                                                     "-password and -password-file cannot be used together",
+	"чтение пароля: %w":                                                                                      "reading password: %w",
+	"разбор контейнера":                                                                                      "parsing container",
+	"Проверка по registry.asn1":                                                                              "Validation against registry.asn1",
+	"  %sOK%s %sконтейнер соответствует модулю %s%s\n":                                                       "  %sOK%s %scontainer conforms to module %s%s\n",
+	"  %sFAILED%s %sнесоответствий: %d%s\n":                                                                  "  %sFAILED%s %smismatches: %d%s\n",
+	"Модуль":             "Module",
+	"несоответствий: %d": "mismatches: %d",
+	"Путь":               "Path",
+	"Смещение":           "Offset",
+	"Ожидалось":          "Expected",
+	"Получено":           "Actual",
+	"Ошибка":             "Error",
+	"Путь к JSON-конфигу (signerCert, signerKey, vin, verTimestamp, verVersion, uid, safeBags, signedAttributes)": "Path to the JSON config (signerCert, signerKey, vin, verTimestamp, verVersion, uid, safeBags, signedAttributes)",
+	"Выходной файл реестра (.p12)":                                                                                "Output registry file (.p12)",
+	"Показать план сборки (подписант, атрибуты, мешки, localKeyID, предупреждения) без подписи; ключ подписанта не читается": "Show the build plan (signer, attributes, bags, localKeyID, warnings) without signing; the signer key is not read",
+	"Формат плана (-plan): text, json": "Plan format (-plan): text, json",
+	"Порог предупреждения об истечении сертификатов в плане, дней": "Certificate expiry warning threshold in the plan, days",
+	"Отключить цветной вывод плана":                                "Disable colored plan output",
+	"Цвет плана: auto (только TTY), always, never":                 "Plan color: auto (TTY only), always, never",
+	"Использование: %s -config <config.json> -output <имя>.p12\n":  "Usage: %s -config <config.json> -output <name>.p12\n",
+	"чтение конфига: %v\n":                                         "reading config: %v\n",
+	"разбор конфига: %v\n":                                         "parsing config: %v\n",
+	"план сборки: %v\n":                                            "build plan: %v\n",
+	"загрузка подписанта: %v\n":                                    "loading signer: %v\n",
+	"выпуск сертификатов: %v\n":                                    "issuing certificates: %v\n",
+	"Выпущен сертификат: %s (ключ %s)\n":                           "Issued certificate: %s (key %s)\n",
+	"Выпущен сертификат по CSR: %s\n":                              "Issued certificate from CSR: %s\n",
+	"загрузка SafeBags: %v\n":                                      "loading SafeBags: %v\n",
+	"атрибуты подписанта: %v\n":                                    "signer attributes: %v\n",
+	"сборка реестра: %v\n":                                         "building registry: %v\n",
+	"Создан реестр: %s\n":                                          "Registry created: %s\n",
+	"Проверка: ./registry-analyzer %s\n":                           "Check: ./registry-analyzer %s\n",
+	"safeBags[%d]: localKeyID задан для набора из %d сертификатов": "safeBags[%d]: localKeyID is set for a bundle of %d certificates",
+	"загрузка SafeBags: %w":                                        "loading SafeBags: %w",
+	"атрибуты подписанта: %w":                                      "signer attributes: %w",
+	"неизвестный формат плана %q (text, json)":                     "unknown plan format %q (text, json)",
+	"новый ключ":                                                   "new key",
+	"будет выпущен CA %s (%s)":                                     "to be issued by CA %s (%s)",
+	"issue требует ca.cert и ca.key":                               "issue requires ca.cert and ca.key",
+	"subject не задан":                                             "subject is not set",
+	"tag / поле = значение":                                        "tag / field = value",
+	"обрезан":                                                      "truncated",
+	"разбор остановлен":                                            "parsing stopped",
+	"сертификат найден":                                            "certificate found",
+	"сертификат не найден":                                         "certificate not found",
+	"  %s(%d записей)%s\n":                                         "  %s(%d entries)%s\n",
+	"путь":                                                         "path",
+	"\nсмещение: %d (0x%x)":                                        "\noffset: %d (0x%x)",
+	"Язык сообщений: ru, en (по умолчанию — по LC_ALL, LC_MESSAGES или LANG)": "Message language: ru, en (default: from LC_ALL, LC_MESSAGES or LANG)",
+	"неизвестный язык %q (ru, en)":                                            "unknown language %q (ru, en)",
+	"%d bytes (расшифрован паролем)":                                          "%d bytes (decrypted with the password)",
+	"%d bytes (не расшифрован: пароль не задан или не подошёл)":               "%d bytes (not decrypted: password not set or wrong)",
+	"safeBags[%d]: мешок %s без сертификата X.509 пропущен":                   "safeBags[%d]: bag %s without an X.509 certificate skipped",
+	"в реестре %d подписантов: атрибуты берутся у первого":                    "the registry has %d signers: attributes are taken from the first",
+	"eContent при пересборке по этому конфигу будет отличаться от исходного (порядок или кодирование атрибутов мешков)": "eContent rebuilt from this config will differ from the original (order or encoding of bag attributes)",
+	"safeBags[%d]: атрибуты мешка не отсортированы по DER, builder их отсортирует (ADR-011)":                            "safeBags[%d]: bag attributes are not sorted by DER, the builder will sort them (ADR-011)",
+	"safeBags[%d]: атрибут %s содержит %d значений, переносится первое":                                                 "safeBags[%d]: attribute %s has %d values, only the first is kept",
+	"safeBags[%d]: атрибут мешка %s не задаётся конфигом и при пересборке не сохранится":                                "safeBags[%d]: bag attribute %s cannot be set in the config and will be lost on rebuild",
+	"подписанный атрибут %s: тип значения не поддерживается конфигом, атрибут не перенесён":                             "signed attribute %s: value type is not supported by the config, attribute not carried over",
+	"Реестр ATOM-PKCS12-REGISTRY": "ATOM-PKCS12-REGISTRY registry",
+	"Хранилище PKCS#12":           "PKCS#12 keystore",
+	"%d байт":                     "%d bytes",
+	"не расшифровано":             "not decrypted",
+	"подписант контейнера":        "container signer",
+	"Роли на":                     "Roles as of",
+	"Статус":                      "Status",
+	"ошибка разбора":              "parse error",
+	"%d байт (не X.509)":          "%d bytes (not X.509)",
+	"Проверка подписи":            "Signature verification",
+	"Подписанты":                  "Signers",
+	"отсутствуют":                 "none",
+	"Проверка":                    "Check",
+	"Результат":                   "Result",
+	"Подробности":                 "Details",
+	"действует":                   "active",
+	"ещё не действует":            "not yet valid",
+	"истекла":                     "expired",
+	"contentType не поддерживается (ожидается data или encryptedData)": "unsupported contentType (expected data or encryptedData)",
+	"или":                             "or",
+	"  %s[%d]%s %s%s%s, мешков: %d\n": "  %s[%d]%s %s%s%s, bags: %d\n",
+	"Подписант контейнера":            "Container signer",
+	"  Signer [%d] (сертификат не найден в списке)\n": "  Signer [%d] (certificate not found in the list)\n",
+	"отсутствует": "absent",
+	"неверный пароль или изменённое содержимое": "wrong password or modified content",
+	"не проверен":                             "not verified",
+	"алгоритм не поддерживается":              "unsupported algorithm",
+	"  %s(подписанты отсутствуют)%s\n":        "  %s(no signers)%s\n",
+	"сертификат истёк %s":                     "certificate expired %s",
+	"сертификат ещё не действует (с %s)":      "certificate is not yet valid (from %s)",
+	"сертификат истекает через %d дн. (%s)":   "certificate expires in %d days (%s)",
+	"План сборки: подписант":                  "Build plan: signer",
+	"Атрибуты подписанта":                     "Signer attributes",
+	"Предупреждения":                          "Warnings",
+	"нет":                                     "none",
+	"сертификат не разбирается как X.509: %v": "certificate does not parse as X.509: %v",
+	"localKeyID не задан и у сертификата нет SubjectKeyIdentifier: атрибут не будет записан": "localKeyID is not set and the certificate has no SubjectKeyIdentifier: the attribute will not be written",
+	"localKeyID %s не совпадает с SubjectKeyIdentifier сертификата %x":                       "localKeyID %s does not match the certificate SubjectKeyIdentifier %x",
+	"localKeyID %s совпадает с safeBags[%d]":                                                 "localKeyID %s duplicates safeBags[%d]",
+	"roleName не задан":                                    "roleName is not set",
+	"roleNotBefore %s не раньше roleNotAfter %s":           "roleNotBefore %s is not before roleNotAfter %s",
+	"период роли истёк %s":                                 "role period expired %s",
+	"период роли (до %s) дольше срока сертификата (до %s)": "role period (until %s) outlasts the certificate (until %s)",
+	"Отчёт сформирован":                                    "Report generated",
+	"нет SubjectKeyIdentifier: SignerInfo.sid будет пустым, подписанта не найти по SID": "no SubjectKeyIdentifier: SignerInfo.sid will be empty, the signer cannot be found by SID",
+	"ключ подписанта не ECDSA P-256 (%s): сборка поддерживает только ecdsa-with-SHA256": "signer key is not ECDSA P-256 (%s): the builder supports only ecdsa-with-SHA256",
+	"в keyUsage нет digitalSignature":                                    "keyUsage lacks digitalSignature",
+	"нет ни одного SafeBag: eContent будет пустым":                       "no SafeBags: eContent will be empty",
+	"-interactive: stdin и stdout должны быть терминалом":                "-interactive: stdin and stdout must be a terminal",
+	"-interactive: посимвольный режим терминала недоступен (stty): %v\n": "-interactive: terminal raw mode is unavailable (stty): %v\n",
+	"Интерактивный просмотр в терминале: дерево PFX → SignedData → сертификаты, SafeBags, подписанты; поля, ASN.1 и hex узла, статус подписи, выгрузка сертификата в PEM (клавиша e)": "Interactive terminal browser: PFX → SignedData → certificates, SafeBags, signers tree; node fields, ASN.1 and hex, signature status, certificate export to PEM (key e)",
+	"у узла нет сертификата X.509": "the node has no X.509 certificate",
+	"выгрузка недоступна":          "export is unavailable",
+	"выгрузка: %v":                 "export: %v",
+	"Сертификат записан в %s":      "Certificate written to %s",
+	"справка":                      "help",
+	"выход":                        "quit",
+	"%d/%d  ↑↓ навигация  ←→ свернуть/раскрыть  Tab вид  PgUp/PgDn прокрутка": "%d/%d  ↑↓ navigate  ←→ collapse/expand  Tab view  PgUp/PgDn scroll",
+	"DER: смещение %d, %d байт":                                  "DER: offset %d, %d bytes",
+	"DER: %d байт (во входном файле не найден)":                  "DER: %d bytes (not found in the input file)",
+	"e — выгрузить сертификат в PEM":                             "e — export the certificate to PEM",
+	"у узла нет DER":                                             "the node has no DER",
+	"перемещение по дереву":                                      "move through the tree",
+	"раскрыть узел, свернуть узел или перейти к родителю":        "expand the node, collapse it or go to the parent",
+	"раскрыть или свернуть узел":                                 "expand or collapse the node",
+	"первый и последний узел":                                    "first and last node",
+	"панель: поля, дерево ASN.1, hex":                            "pane: fields, ASN.1 tree, hex",
+	"прокрутка панели":                                           "scroll the pane",
+	"выгрузить сертификат узла в PEM":                            "export the node certificate to PEM",
+	"подпись (MAC хранилища, период роли) действительна или нет": "signature (keystore MAC, role period) valid or not",
+	"Путь к JSON-описанию PKI (root, intermediate, signer, leaves, config); без флага — набор как в scripts/generate_certs.sh": "Path to the JSON PKI description (root, intermediate, signer, leaves, config); without the flag, the set from scripts/generate_certs.sh",
+	"Каталог для ключей и сертификатов (переопределяет outDir из описания)":                                                    "Directory for keys and certificates (overrides outDir from the description)",
+	"Перезаписывать существующие ключи, сертификаты и конфиг":                                                                  "Overwrite existing keys, certificates and config",
+	"Вывести описание PKI по умолчанию (JSON) и выйти":                                                                         "Print the default PKI description (JSON) and exit",
+	"чтение описания: %v\n":                                                          "reading description: %v\n",
+	"разбор описания: %v\n":                                                          "parsing description: %v\n",
+	"Готово. Ключи и сертификаты: %s/\n":                                             "Done. Keys and certificates: %s/\n",
+	"  Конфиг:      %s\n":                                                            "  Config:      %s\n",
+	"Сборка реестра:\n  ./registry-builder -config %s -output sgw-my-registry.p12\n": "Build the registry:\n  ./registry-builder -config %s -output sgw-my-registry.p12\n",
+	"Формат вывода: text, json":                                                      "Output format: text, json",
+	"Сравнить структуру DER (теги, форма длины, порядок, значения) вместо семантики реестра":                                    "Compare the DER structure (tags, length form, order, values) instead of registry semantics",
+	"Для -der: дополнительные маскируемые поля через запятую (подпись поля схемы или её окончание, например CertBag.certValue)": "With -der: extra comma-separated fields to mask (schema field label or its suffix, e.g. CertBag.certValue)",
+	"Использование: %s [опции] <old.p12> <new.p12>\n":    "Usage: %s [options] <old.p12> <new.p12>\n",
+	"неизвестный формат: %s (ожидается text или json)\n": "unknown format: %s (expected text or json)\n",
+	"Отчёт сравнения записан в %s\n":                     "Comparison report written to %s\n",
+	"разбор %s: %v\n":                    "parsing %s: %v\n",
+	"разбор DER %s: %v\n":                "parsing DER %s: %v\n",
+	"Отчёт сравнения DER записан в %s\n": "DER comparison report written to %s\n",
+	"Сравнение структуры DER":            "DER structure comparison",
+	"  %sмаскированы: %s%s\n":            "  %smasked: %s%s\n",
+	"структура совпадает":                "structure matches",
+	"  различий: %d\n":                   "  differences: %d\n",
+	"тег":                                "tag",
+	"форма длины":                        "length form",
+	"вложенный DER":                      "nested DER",
+	"порядок":                            "order",
+	"нет во втором":                      "missing in second",
+	"нет в первом":                       "missing in first",
+	"значение":                           "value",
+	"Сравнение реестров":                 "Registry comparison",
+	"отличий нет":                        "no differences",
+	"Подписант и атрибуты:":              "Signer and attributes:",
+	"  Удалённые мешки: %d\n":            "  Removed bags: %d\n",
+	"  Добавленные мешки: %d\n":          "  Added bags: %d\n",
+	"  Изменённые мешки: %d\n":           "  Changed bags: %d\n",
+	"    %s~ %s%s → #%d (по %s)\n":       "    %s~ %s%s → #%d (by %s)\n",
+
+	"пароль не задан": "password not set",
+	"не расшифровано: пароль хранилища не задан":                                                                "not decrypted: keystore password not set",
+	"Проверить MAC и расшифровать хранилище PKCS#12 пустым паролем (без флага пустой пароль — пароль не задан)": "Verify the MAC and decrypt the PKCS#12 keystore with an empty password (without the flag an empty password means no password)",
+	"-empty-password нельзя указывать вместе с -password или -password-file":                                    "-empty-password cannot be combined with -password or -password-file",
+	"Перезаписывать существующие ключи и сертификаты в issueDir (safeBags[].issue)":                             "Overwrite existing keys and certificates in issueDir (safeBags[].issue)",
+}
@@ -0,0 +1,114 @@
+// Package i18n — каталог сообщений для текстовых отчётов, справки и ошибок CLI на русском и английском.
+//
+// Ключ каталога — исходная русская строка (как msgid в gettext): код пишет сообщения по-русски через T,
+// Sprintf и Errorf, а при языке EN они заменяются переводом из каталога. Строка без перевода выводится как есть.
+// Язык задаётся один раз при запуске (Init по -lang или переменным окружения локали) и дальше только читается.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Lang — язык сообщений.
+type Lang string
+
+// Поддерживаемые языки.
+const (
+	RU Lang = "ru"
+	EN Lang = "en"
+)
+
+// FlagUsage — описание флага -lang в справке утилит.
+const FlagUsage = "Язык сообщений: ru, en (по умолчанию — по LC_ALL, LC_MESSAGES или LANG)"
+
+var current = RU
+
+// Set задаёт язык сообщений.
+func Set(l Lang) { current = l }
+
+// Current возвращает текущий язык сообщений.
+func Current() Lang { return current }
+
+// Parse разбирает значение -lang или переменной локали: ru, en, ru_RU.UTF-8, en-US и т.п.
+func Parse(s string) (Lang, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case s == "ru" || strings.HasPrefix(s, "ru_") || strings.HasPrefix(s, "ru-") || strings.HasPrefix(s, "ru."):
+		return RU, true
+	case s == "en" || strings.HasPrefix(s, "en_") || strings.HasPrefix(s, "en-") || strings.HasPrefix(s, "en."):
+		return EN, true
+	}
+	return "", false
+}
+
+// FromEnv выбирает язык по локали в порядке POSIX: LC_ALL, LC_MESSAGES, LANG (первая непустая).
+// Русская локаль — RU; без локали или C/POSIX — RU, как было до появления каталога; любая другая — EN.
+func FromEnv() Lang {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		v := os.Getenv(name)
+		if v == "" {
+			continue
+		}
+		if l, ok := Parse(v); ok {
+			return l
+		}
+		if v == "C" || v == "POSIX" || strings.HasPrefix(v, "C.") {
+			return RU
+		}
+		return EN
+	}
+	return RU
+}
+
+// Init задаёт язык по аргументам командной строки (-lang ru|en, -lang=en, --lang en) или, без флага, по FromEnv.
+// Вызывается до объявления флагов, чтобы справка flag.PrintDefaults выводилась на выбранном языке.
+func Init(args []string) error {
+	l := FromEnv()
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(a, "-"), "=")
+		if !strings.HasPrefix(a, "-") || name != "lang" {
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				break
+			}
+			i++
+			value = args[i]
+		}
+		parsed, ok := Parse(value)
+		if !ok {
+			Set(l)
+			return Errorf("неизвестный язык %q (ru, en)", value)
+		}
+		l = parsed
+	}
+	Set(l)
+	return nil
+}
+
+// T возвращает перевод сообщения msg на текущий язык.
+func T(msg string) string {
+	if current == EN {
+		if s, ok := en[msg]; ok {
+			return s
+		}
+	}
+	return msg
+}
+
+// Sprintf — fmt.Sprintf с переведённой строкой формата.
+func Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// Errorf — fmt.Errorf с переведённой строкой формата (%w сохраняется).
+func Errorf(format string, args ...interface{}) error {
+	return fmt.Errorf(T(format), args...)
+}
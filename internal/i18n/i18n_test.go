@@ -0,0 +1,109 @@
+package i18n
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+// TestCatalogVerbs проверяет, что перевод сохраняет глаголы формата ключа в том же порядке: иначе Sprintf
+// и Errorf на английском подставят аргументы не туда.
+func TestCatalogVerbs(t *testing.T) {
+	verb := regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+	for ru, en := range en {
+		if en == "" {
+			t.Errorf("%q: пустой перевод", ru)
+		}
+		if got, want := verb.FindAllString(en, -1), verb.FindAllString(ru, -1); !slices.Equal(got, want) {
+			t.Errorf("%q: глаголы %v, ожидаются %v", ru, got, want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want Lang
+		ok   bool
+	}{
+		{"ru", RU, true},
+		{"EN", EN, true},
+		{"ru_RU.UTF-8", RU, true},
+		{"en-US", EN, true},
+		{"en.UTF-8", EN, true},
+		{"de_DE.UTF-8", "", false},
+		{"english", "", false},
+		{"", "", false},
+	} {
+		if got, ok := Parse(tt.in); got != tt.want || ok != tt.ok {
+			t.Errorf("Parse(%q) = %q, %v; ожидается %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	for _, tt := range []struct {
+		all, messages, lang string
+		want                Lang
+	}{
+		{"", "", "", RU},
+		{"", "", "C.UTF-8", RU},
+		{"", "", "POSIX", RU},
+		{"", "", "en_US.UTF-8", EN},
+		{"", "", "de_DE.UTF-8", EN},
+		{"", "ru_RU.UTF-8", "en_US.UTF-8", RU},
+		{"en_GB.UTF-8", "ru_RU.UTF-8", "", EN},
+	} {
+		t.Setenv("LC_ALL", tt.all)
+		t.Setenv("LC_MESSAGES", tt.messages)
+		t.Setenv("LANG", tt.lang)
+		if got := FromEnv(); got != tt.want {
+			t.Errorf("LC_ALL=%q LC_MESSAGES=%q LANG=%q: %q, ожидается %q", tt.all, tt.messages, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestInit(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "ru_RU.UTF-8")
+	t.Cleanup(func() { Set(RU) })
+
+	for _, tt := range []struct {
+		args []string
+		want Lang
+	}{
+		{nil, RU},
+		{[]string{"-lang", "en", "file.p12"}, EN},
+		{[]string{"-format", "json", "--lang=en"}, EN},
+		{[]string{"-lang=en", "-lang", "ru"}, RU},
+		{[]string{"--", "-lang", "en"}, RU},
+		{[]string{"-language", "en"}, RU},
+	} {
+		if err := Init(tt.args); err != nil || Current() != tt.want {
+			t.Errorf("Init(%q) = %v, язык %q; ожидается %q", tt.args, err, Current(), tt.want)
+		}
+	}
+	if err := Init([]string{"-lang", "de"}); err == nil || Current() != RU {
+		t.Errorf("Init(-lang de) = %v, язык %q; ожидается ошибка и язык окружения", err, Current())
+	}
+}
+
+func TestT(t *testing.T) {
+	t.Cleanup(func() { Set(RU) })
+	Set(RU)
+	if got := T("или"); got != "или" {
+		t.Errorf("RU: T = %q", got)
+	}
+	Set(EN)
+	if got := T("или"); got != "or" {
+		t.Errorf("EN: T = %q", got)
+	}
+	if got := T("строка без перевода"); got != "строка без перевода" {
+		t.Errorf("EN без перевода: T = %q", got)
+	}
+	if got := Errorf("чтение пароля: %w", errors.New("test")).Error(); got != "reading password: test" {
+		t.Errorf("EN: Errorf = %q", got)
+	}
+}
@@ -0,0 +1,132 @@
+// hash.go — хеш имени как openssl x509 -subject_hash (X509_NAME_hash_ex): каноническая форма Name
+// по правилам OpenSSL и SHA-1 для имён ссылок в директории -CApath.
+package pki
+
+import (
+	"crypto/sha1"
+	"encoding/asn1"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// Теги строковых типов, которые OpenSSL приводит к каноническому виду (ASN1_MASK_CANON).
+var canonStringTags = map[int]bool{
+	asn1.TagUTF8String:      true,
+	asn1.TagPrintableString: true,
+	asn1.TagT61String:       true,
+	asn1.TagIA5String:       true,
+	26:                      true, // VisibleString
+	28:                      true, // UniversalString
+	asn1.TagBMPString:       true,
+}
+
+// SubjectHash возвращает хеш имени как openssl x509 -subject_hash (X509_NAME_hash_ex): первые 4 байта SHA-1
+// канонической формы имени, little-endian, 8 hex-цифр. Это имя ссылки в директории -CApath (c_rehash).
+// rawName — DER Name (x509.Certificate.RawSubject).
+func SubjectHash(rawName []byte) (string, error) {
+	canon, err := canonicalName(rawName)
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum(canon)
+	return fmt.Sprintf("%08x", binary.LittleEndian.Uint32(sum[:4])), nil
+}
+
+// canonicalName кодирует Name как x509_name_canon в OpenSSL: последовательность RDN (SET OF AttributeTypeAndValue)
+// без внешнего SEQUENCE, строковые значения — UTF8String после asn1_string_canon.
+func canonicalName(rawName []byte) ([]byte, error) {
+	var rdns []asn1.RawValue
+	if rest, err := asn1.Unmarshal(rawName, &rdns); err != nil {
+		return nil, err
+	} else if len(rest) > 0 {
+		return nil, fmt.Errorf("trailing bytes after Name")
+	}
+	var out []byte
+	for _, rdn := range rdns {
+		var atvs []struct {
+			Type  asn1.ObjectIdentifier
+			Value asn1.RawValue
+		}
+		if _, err := asn1.UnmarshalWithParams(rdn.FullBytes, &atvs, "set"); err != nil {
+			return nil, err
+		}
+		var set []asn1.RawValue
+		for _, atv := range atvs {
+			value := atv.Value
+			if value.Class == asn1.ClassUniversal && canonStringTags[value.Tag] {
+				value = asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagUTF8String, Bytes: []byte(canonString(value.Tag, value.Bytes))}
+			}
+			typ, err := asn1.Marshal(atv.Type)
+			if err != nil {
+				return nil, err
+			}
+			val, err := asn1.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true, Bytes: append(typ, val...)})
+		}
+		// SET OF в DER: элементы сортируются по кодировке, asn1.Marshal с тегом set делает это сам.
+		enc, err := asn1.MarshalWithParams(set, "set")
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, enc...)
+	}
+	return out, nil
+}
+
+// canonString приводит значение строки к виду asn1_string_canon: UTF-8, без пробелов по краям, серии пробельных
+// символов — один пробел, ASCII — в нижнем регистре (остальные символы не меняются).
+func canonString(tag int, b []byte) string {
+	var s string
+	switch tag {
+	case asn1.TagBMPString:
+		u := make([]uint16, 0, len(b)/2)
+		for i := 0; i+1 < len(b); i += 2 {
+			u = append(u, uint16(b[i])<<8|uint16(b[i+1]))
+		}
+		s = string(utf16.Decode(u))
+	case 28:
+		var sb strings.Builder
+		for i := 0; i+3 < len(b); i += 4 {
+			sb.WriteRune(rune(binary.BigEndian.Uint32(b[i:])))
+		}
+		s = sb.String()
+	case asn1.TagUTF8String:
+		s = string(b)
+	default:
+		// Однобайтовые строки (Printable, T61, IA5, Visible): байты как Latin-1, как ASN1_STRING_to_UTF8.
+		var sb strings.Builder
+		for _, c := range b {
+			sb.WriteRune(rune(c))
+		}
+		s = sb.String()
+	}
+	isSpace := func(c byte) bool { return c == ' ' || (c >= '\t' && c <= '\r') }
+	var out []byte
+	raw := []byte(s)
+	for len(raw) > 0 && isSpace(raw[0]) {
+		raw = raw[1:]
+	}
+	for len(raw) > 0 && isSpace(raw[len(raw)-1]) {
+		raw = raw[:len(raw)-1]
+	}
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case isSpace(c):
+			for i+1 < len(raw) && isSpace(raw[i+1]) {
+				i++
+			}
+			out = append(out, ' ')
+		case c >= 'A' && c <= 'Z':
+			out = append(out, c+'a'-'A')
+		default:
+			out = append(out, c)
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,417 @@
+// Package pki выпускает ключи и сертификаты X.509 для реестров ATOM-PKCS12-REGISTRY без OpenSSL:
+// корневой и промежуточный CA, подписант реестра и сертификаты ролей (Driver, Passenger, IVI, Mobile-Driver).
+// SubjectKeyIdentifier вычисляется как в OpenSSL (subjectKeyIdentifier=hash, RFC 5280 4.2.1.2 метод 1),
+// AuthorityKeyIdentifier берётся из SKID издателя (authorityKeyIdentifier=keyid:always).
+package pki
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// Типы ключей (поле CertSpec.KeyType).
+const (
+	KeyECDSAP256 = "ecdsa-p256"
+	KeyECDSAP384 = "ecdsa-p384"
+	KeyRSA2048   = "rsa-2048"
+	KeyRSA3072   = "rsa-3072"
+	KeyRSA4096   = "rsa-4096"
+	KeyEd25519   = "ed25519"
+)
+
+// DefaultValidityDays — срок действия сертификата по умолчанию (как DAYS=365 в scripts/generate_certs.sh).
+const DefaultValidityDays = 365
+
+// CertSpec — описание выпускаемого сертификата.
+// Subject — DN в стиле OpenSSL ("/CN=Driver-Certificate/O=ATOM") или RFC 4514 ("CN=Driver-Certificate,O=ATOM").
+// RawSubject — DER имени (например CSR.RawSubject); если задан, Subject не разбирается и имя копируется без изменений.
+// KeyUsage/ExtKeyUsage — имена как в OpenSSL (digitalSignature, keyCertSign, clientAuth, ...).
+// Для CA без явного KeyUsage выставляются keyCertSign и cRLSign; для листовых — digitalSignature.
+type CertSpec struct {
+	Subject      string   `json:"subject"`
+	RawSubject   []byte   `json:"-"`
+	KeyType      string   `json:"keyType,omitempty"`
+	ValidityDays int      `json:"validityDays,omitempty"`
+	NotBefore    string   `json:"notBefore,omitempty"` // RFC3339; по умолчанию — текущее время
+	IsCA         bool     `json:"isCA,omitempty"`
+	MaxPathLen   *int     `json:"maxPathLen,omitempty"`
+	KeyUsage     []string `json:"keyUsage,omitempty"`
+	ExtKeyUsage  []string `json:"extKeyUsage,omitempty"`
+	DNSNames     []string `json:"dnsNames,omitempty"`
+	Emails       []string `json:"emails,omitempty"`
+}
+
+// Issuer — издатель сертификатов: сертификат CA и его приватный ключ.
+type Issuer struct {
+	Cert *x509.Certificate
+	Key  crypto.Signer
+}
+
+// GenerateKey создаёт приватный ключ указанного типа (по умолчанию ECDSA P-256, как prime256v1 в скриптах).
+func GenerateKey(keyType string) (crypto.Signer, error) {
+	switch strings.ToLower(keyType) {
+	case "", KeyECDSAP256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case KeyECDSAP384:
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case KeyRSA2048:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case KeyRSA3072:
+		return rsa.GenerateKey(rand.Reader, 3072)
+	case KeyRSA4096:
+		return rsa.GenerateKey(rand.Reader, 4096)
+	case KeyEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", keyType)
+	}
+}
+
+// SubjectKeyID вычисляет SubjectKeyIdentifier: SHA-1 над битовой строкой subjectPublicKey (RFC 5280, 4.2.1.2, метод 1).
+func SubjectKeyID(pub crypto.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	var spki struct {
+		Algorithm        pkix.AlgorithmIdentifier
+		SubjectPublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(der, &spki); err != nil {
+		return nil, err
+	}
+	sum := sha1.Sum(spki.SubjectPublicKey.Bytes)
+	return sum[:], nil
+}
+
+// SelfSigned выпускает самоподписанный сертификат (корневой CA или автономный подписант).
+func SelfSigned(spec CertSpec, key crypto.Signer) (*x509.Certificate, error) {
+	tmpl, err := template(spec, key.Public())
+	if err != nil {
+		return nil, err
+	}
+	tmpl.AuthorityKeyId = tmpl.SubjectKeyId
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// Issue выпускает сертификат для открытого ключа pub, подписанный издателем.
+// Срок действия не выходит за пределы срока действия сертификата издателя.
+func (is *Issuer) Issue(spec CertSpec, pub crypto.PublicKey) (*x509.Certificate, error) {
+	if is == nil || is.Cert == nil || is.Key == nil {
+		return nil, fmt.Errorf("issuer certificate and key required")
+	}
+	if !is.Cert.IsCA {
+		return nil, fmt.Errorf("issuer %s is not a CA", is.Cert.Subject)
+	}
+	tmpl, err := template(spec, pub)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl.NotAfter.After(is.Cert.NotAfter) {
+		tmpl.NotAfter = is.Cert.NotAfter
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, is.Cert, pub, is.Key)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// template формирует шаблон x509.Certificate по CertSpec: случайный серийный номер, SKID, KeyUsage/ExtKeyUsage.
+func template(spec CertSpec, pub crypto.PublicKey) (*x509.Certificate, error) {
+	var subject pkix.Name
+	var err error
+	if len(spec.RawSubject) == 0 {
+		if subject, err = ParseSubject(spec.Subject); err != nil {
+			return nil, err
+		}
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	if err != nil {
+		return nil, err
+	}
+	ski, err := SubjectKeyID(pub)
+	if err != nil {
+		return nil, err
+	}
+	notBefore := time.Now().UTC().Truncate(time.Second)
+	if spec.NotBefore != "" {
+		if notBefore, err = time.Parse(time.RFC3339, spec.NotBefore); err != nil {
+			return nil, fmt.Errorf("notBefore: %w", err)
+		}
+	}
+	days := spec.ValidityDays
+	if days <= 0 {
+		days = DefaultValidityDays
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:   serial.Add(serial, big.NewInt(1)),
+		Subject:        subject,
+		RawSubject:     spec.RawSubject,
+		NotBefore:      notBefore,
+		NotAfter:       notBefore.AddDate(0, 0, days),
+		SubjectKeyId:   ski,
+		DNSNames:       spec.DNSNames,
+		EmailAddresses: spec.Emails,
+	}
+	if spec.IsCA {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		if spec.MaxPathLen != nil {
+			tmpl.MaxPathLen = *spec.MaxPathLen
+			tmpl.MaxPathLenZero = *spec.MaxPathLen == 0
+		} else {
+			tmpl.MaxPathLen = -1
+		}
+	}
+	if tmpl.KeyUsage, err = ParseKeyUsage(spec.KeyUsage); err != nil {
+		return nil, err
+	}
+	if tmpl.KeyUsage == 0 {
+		if spec.IsCA {
+			tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+		} else {
+			tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+		}
+	}
+	if tmpl.ExtKeyUsage, err = ParseExtKeyUsage(spec.ExtKeyUsage); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// ParseSubject разбирает DN: формат OpenSSL "/CN=a/O=b" или RFC 4514 "CN=a,O=b".
+// Поддерживаются атрибуты CN, O, OU, C, ST, L, STREET, postalCode, serialNumber, emailAddress.
+func ParseSubject(s string) (pkix.Name, error) {
+	var name pkix.Name
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return name, fmt.Errorf("empty subject")
+	}
+	var parts []string
+	if strings.HasPrefix(s, "/") {
+		parts = strings.Split(strings.TrimPrefix(s, "/"), "/")
+	} else {
+		parts = strings.Split(s, ",")
+	}
+	for _, p := range parts {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 {
+			return name, fmt.Errorf("invalid subject component %q", p)
+		}
+		k, v := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		switch strings.ToLower(k) {
+		case "cn":
+			name.CommonName = v
+		case "o":
+			name.Organization = append(name.Organization, v)
+		case "ou":
+			name.OrganizationalUnit = append(name.OrganizationalUnit, v)
+		case "c":
+			name.Country = append(name.Country, v)
+		case "st":
+			name.Province = append(name.Province, v)
+		case "l":
+			name.Locality = append(name.Locality, v)
+		case "street":
+			name.StreetAddress = append(name.StreetAddress, v)
+		case "postalcode":
+			name.PostalCode = append(name.PostalCode, v)
+		case "serialnumber":
+			name.SerialNumber = v
+		case "emailaddress", "email":
+			name.ExtraNames = append(name.ExtraNames, pkix.AttributeTypeAndValue{
+				Type: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 1}, Value: v,
+			})
+		default:
+			return name, fmt.Errorf("unsupported subject attribute %q", k)
+		}
+	}
+	return name, nil
+}
+
+// ParseKeyUsage преобразует имена битов KeyUsage (как в OpenSSL) в x509.KeyUsage.
+func ParseKeyUsage(names []string) (x509.KeyUsage, error) {
+	var ku x509.KeyUsage
+	for _, n := range names {
+		switch n {
+		case "digitalSignature":
+			ku |= x509.KeyUsageDigitalSignature
+		case "nonRepudiation", "contentCommitment":
+			ku |= x509.KeyUsageContentCommitment
+		case "keyEncipherment":
+			ku |= x509.KeyUsageKeyEncipherment
+		case "dataEncipherment":
+			ku |= x509.KeyUsageDataEncipherment
+		case "keyAgreement":
+			ku |= x509.KeyUsageKeyAgreement
+		case "keyCertSign":
+			ku |= x509.KeyUsageCertSign
+		case "cRLSign":
+			ku |= x509.KeyUsageCRLSign
+		case "encipherOnly":
+			ku |= x509.KeyUsageEncipherOnly
+		case "decipherOnly":
+			ku |= x509.KeyUsageDecipherOnly
+		default:
+			return 0, fmt.Errorf("unknown keyUsage %q", n)
+		}
+	}
+	return ku, nil
+}
+
+// ParseExtKeyUsage преобразует имена ExtKeyUsage (serverAuth, clientAuth, codeSigning, ...) в x509.ExtKeyUsage.
+func ParseExtKeyUsage(names []string) ([]x509.ExtKeyUsage, error) {
+	var out []x509.ExtKeyUsage
+	for _, n := range names {
+		switch n {
+		case "any", "anyExtendedKeyUsage":
+			out = append(out, x509.ExtKeyUsageAny)
+		case "serverAuth":
+			out = append(out, x509.ExtKeyUsageServerAuth)
+		case "clientAuth":
+			out = append(out, x509.ExtKeyUsageClientAuth)
+		case "codeSigning":
+			out = append(out, x509.ExtKeyUsageCodeSigning)
+		case "emailProtection":
+			out = append(out, x509.ExtKeyUsageEmailProtection)
+		case "timeStamping":
+			out = append(out, x509.ExtKeyUsageTimeStamping)
+		case "OCSPSigning", "ocspSigning":
+			out = append(out, x509.ExtKeyUsageOCSPSigning)
+		default:
+			return nil, fmt.Errorf("unknown extKeyUsage %q", n)
+		}
+	}
+	return out, nil
+}
+
+// LoadIssuer загружает сертификат и ключ CA из PEM-файлов и проверяет, что ключ соответствует сертификату.
+func LoadIssuer(certPath, keyPath string) (*Issuer, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("CA cert: %w", err)
+	}
+	cert, err := ParseCertificatePEM(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("CA cert %s: %w", certPath, err)
+	}
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("CA key: %w", err)
+	}
+	key, err := ParsePrivateKeyPEM(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("CA key %s: %w", keyPath, err)
+	}
+	if !publicKeysEqual(cert.PublicKey, key.Public()) {
+		return nil, fmt.Errorf("CA key %s does not match certificate %s", keyPath, certPath)
+	}
+	return &Issuer{Cert: cert, Key: key}, nil
+}
+
+// publicKeysEqual сравнивает открытые ключи (все ключи стандартной библиотеки реализуют Equal).
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	ea, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && ea.Equal(b)
+}
+
+// ParseCertificatePEM разбирает первый блок CERTIFICATE из PEM (или DER, если PEM не найден).
+func ParseCertificatePEM(data []byte) (*x509.Certificate, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		return x509.ParseCertificate(block.Bytes)
+	}
+	return x509.ParseCertificate(data)
+}
+
+// ParsePrivateKeyPEM разбирает приватный ключ: SEC 1 ("EC PRIVATE KEY"), PKCS#1 ("RSA PRIVATE KEY") или PKCS#8 ("PRIVATE KEY").
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block")
+	}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := k.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", k)
+		}
+		return signer, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
+
+// MarshalPrivateKeyPEM кодирует ключ в PEM: ECDSA — SEC 1 ("EC PRIVATE KEY", как openssl ecparam -genkey -noout,
+// этот формат читает registry-builder), остальные — PKCS#8 ("PRIVATE KEY").
+func MarshalPrivateKeyPEM(key crypto.Signer) ([]byte, error) {
+	if ec, ok := key.(*ecdsa.PrivateKey); ok {
+		der, err := x509.MarshalECPrivateKey(ec)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// ParseCSR разбирает запрос на сертификат (PEM "CERTIFICATE REQUEST" или DER) и проверяет его подпись.
+func ParseCSR(data []byte) (*x509.CertificateRequest, error) {
+	der := data
+	if block, _ := pem.Decode(data); block != nil {
+		der = block.Bytes
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, err
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("CSR signature: %w", err)
+	}
+	return csr, nil
+}
+
+// WriteCertPEM записывает сертификат в PEM-файл.
+func WriteCertPEM(path string, cert *x509.Certificate) error {
+	return os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0644)
+}
+
+// WriteKeyPEM записывает приватный ключ в PEM-файл с правами 0600.
+func WriteKeyPEM(path string, key crypto.Signer) error {
+	b, err := MarshalPrivateKeyPEM(key)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0600)
+}
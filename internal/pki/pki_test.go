@@ -0,0 +1,173 @@
+package pki
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+)
+
+// TestIssueChain проверяет выпуск цепочки root → leaf: SKID/AKID, KeyUsage, проверку цепочки и ограничение срока сроком CA.
+func TestIssueChain(t *testing.T) {
+	rootKey, err := GenerateKey(KeyECDSAP256)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	root, err := SelfSigned(CertSpec{Subject: "/CN=Test Root CA", IsCA: true, ValidityDays: 10}, rootKey)
+	if err != nil {
+		t.Fatalf("SelfSigned: %v", err)
+	}
+	if root.KeyUsage&x509.KeyUsageCertSign == 0 || !root.IsCA {
+		t.Errorf("root: ожидается CA с keyCertSign")
+	}
+
+	leafKey, err := GenerateKey(KeyEd25519)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	issuer := &Issuer{Cert: root, Key: rootKey}
+	leaf, err := issuer.Issue(CertSpec{Subject: "CN=Driver-Certificate,O=ATOM", ValidityDays: 365, ExtKeyUsage: []string{"clientAuth"}}, leafKey.Public())
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if leaf.Subject.CommonName != "Driver-Certificate" || len(leaf.Subject.Organization) != 1 {
+		t.Errorf("subject = %s", leaf.Subject)
+	}
+	if !bytes.Equal(leaf.AuthorityKeyId, root.SubjectKeyId) {
+		t.Errorf("AKID %x != SKID издателя %x", leaf.AuthorityKeyId, root.SubjectKeyId)
+	}
+	ski, _ := SubjectKeyID(leafKey.Public())
+	if !bytes.Equal(leaf.SubjectKeyId, ski) {
+		t.Errorf("SKID %x != %x", leaf.SubjectKeyId, ski)
+	}
+	if leaf.NotAfter.After(root.NotAfter) {
+		t.Errorf("срок leaf %v выходит за срок CA %v", leaf.NotAfter, root.NotAfter)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(root)
+	if _, err := leaf.Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+
+	if _, err := (&Issuer{Cert: leaf, Key: leafKey}).Issue(CertSpec{Subject: "/CN=x"}, leafKey.Public()); err == nil {
+		t.Error("ожидается ошибка выпуска от не-CA сертификата")
+	}
+}
+
+// TestKeyPEMRoundTrip проверяет, что ECDSA пишется в SEC 1 (формат, который читает registry-builder), а остальные — в PKCS#8.
+func TestKeyPEMRoundTrip(t *testing.T) {
+	for _, kt := range []string{KeyECDSAP256, KeyECDSAP384, KeyRSA2048, KeyEd25519} {
+		key, err := GenerateKey(kt)
+		if err != nil {
+			t.Fatalf("%s: %v", kt, err)
+		}
+		b, err := MarshalPrivateKeyPEM(key)
+		if err != nil {
+			t.Fatalf("%s: %v", kt, err)
+		}
+		if kt == KeyECDSAP256 && !bytes.Contains(b, []byte("EC PRIVATE KEY")) {
+			t.Errorf("%s: ожидается блок EC PRIVATE KEY", kt)
+		}
+		back, err := ParsePrivateKeyPEM(b)
+		if err != nil {
+			t.Fatalf("%s: ParsePrivateKeyPEM: %v", kt, err)
+		}
+		if !publicKeysEqual(key.Public(), back.Public()) {
+			t.Errorf("%s: ключ после PEM не совпадает", kt)
+		}
+	}
+}
+
+// TestParseSubject проверяет оба формата DN и отказ на неизвестных атрибутах.
+func TestParseSubject(t *testing.T) {
+	a, err := ParseSubject("/CN=Owner Registry Signer/O=KAMA/C=RU")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ParseSubject("CN=Owner Registry Signer, O=KAMA, C=RU")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.String() != b.String() {
+		t.Errorf("%s != %s", a, b)
+	}
+	if _, err := ParseSubject("/XX=1"); err == nil {
+		t.Error("ожидается ошибка для неизвестного атрибута")
+	}
+}
+
+// TestSubjectHash сверяет SubjectHash со значениями openssl x509 -subject_hash для тех же имён: регистр и пробелы
+// приводятся к каноническому виду, многозначный RDN (OU=A+OU=b) хешируется как один SET.
+func TestSubjectHash(t *testing.T) {
+	oidC, oidO, oidOU, oidCN := asn1.ObjectIdentifier{2, 5, 4, 6}, asn1.ObjectIdentifier{2, 5, 4, 10}, asn1.ObjectIdentifier{2, 5, 4, 11}, asn1.ObjectIdentifier{2, 5, 4, 3}
+	tests := []struct {
+		name string
+		rdns pkix.RDNSequence
+		want string
+	}{
+		{"CN=Keystore Test", pkix.RDNSequence{{{Type: oidCN, Value: "Keystore Test"}}}, "998f4063"},
+		{"C=RU, O=  ACME   Corp , CN=Driver-Certificate", pkix.RDNSequence{
+			{{Type: oidC, Value: "RU"}}, {{Type: oidO, Value: "  ACME   Corp "}}, {{Type: oidCN, Value: "Driver-Certificate"}},
+		}, "0d862632"},
+		{"CN=Водитель Тест, OU=A+OU=b", pkix.RDNSequence{
+			{{Type: oidCN, Value: "Водитель Тест"}}, {{Type: oidOU, Value: "A"}, {Type: oidOU, Value: "b"}},
+		}, "70744a16"},
+	}
+	for _, tt := range tests {
+		raw, err := asn1.Marshal(tt.rdns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := SubjectHash(raw)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: SubjectHash = %s, %v; ожидается %s", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+// TestIssueRawSubjectFromCSR проверяет выпуск по DN из CSR (RawSubject): запятая в CN и emailAddress, которые
+// ParseSubject не разбирает из строки Subject.String(), переносятся в сертификат без изменений.
+func TestIssueRawSubjectFromCSR(t *testing.T) {
+	caKey, err := GenerateKey(KeyECDSAP256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := SelfSigned(CertSpec{Subject: "/CN=Test CA", IsCA: true}, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafKey, err := GenerateKey(KeyECDSAP256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oidEmail := asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 1}
+	csrDER, err := x509.CreateCertificateRequest(nil, &x509.CertificateRequest{Subject: pkix.Name{
+		CommonName:   "Doe, John",
+		Organization: []string{"ATOM"},
+		ExtraNames:   []pkix.AttributeTypeAndValue{{Type: oidEmail, Value: "john.doe@example.com"}},
+	}}, leafKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr, err := ParseCSR(csrDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := (&Issuer{Cert: ca, Key: caKey}).Issue(CertSpec{RawSubject: csr.RawSubject}, csr.PublicKey)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if !bytes.Equal(cert.RawSubject, csr.RawSubject) || cert.Subject.CommonName != "Doe, John" {
+		t.Errorf("subject = %s, ожидается %s", cert.Subject, csr.Subject)
+	}
+	var email string
+	for _, n := range cert.Subject.Names {
+		if n.Type.Equal(oidEmail) {
+			email, _ = n.Value.(string)
+		}
+	}
+	if email != "john.doe@example.com" {
+		t.Errorf("emailAddress = %q", email)
+	}
+}
@@ -9,7 +9,7 @@ import "encoding/asn1"
 type PFX struct {
 	Version  int
 	AuthSafe ContentInfo
-	MacData  *MacData `asn1:"optional"`
+	MacData  MacData `asn1:"optional"` // нулевое значение — macData нет (encoding/asn1 не разбирает указатели)
 }
 
 // ContentInfo — обёртка содержимого CMS (registry.asn1).
@@ -72,8 +72,8 @@ type SignerInfo struct {
 // SafeContents — последовательность мешков SafeBag (PKCS#12), хранящаяся в eContent.
 type SafeContents []SafeBag
 
-// SafeBag — один мешок PKCS#12: идентификатор типа, значение (CertBag) и опциональные атрибуты.
-// bagValue [0] IMPLICIT CertBag.
+// SafeBag — один мешок PKCS#12: идентификатор типа, значение и опциональные атрибуты.
+// bagValue [0] — CertBag в реестре; тип значения задаёт bagId (остальные типы RFC 7292 — bags.go).
 type SafeBag struct {
 	BagId         asn1.ObjectIdentifier
 	BagValue      asn1.RawValue `asn1:"tag:0"`
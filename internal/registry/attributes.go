@@ -1,10 +1,13 @@
-// attributes.go — расшифровка атрибутов SignerInfo.authenticatedAttributes и SafeBag.bagAttributes.
+// attributes.go — расшифровка атрибутов SignerInfo.authenticatedAttributes и SafeBag.bagAttributes
+// (ATOM, PKCS#9, signingTime, CMSAlgorithmProtection и произвольные типизированные атрибуты).
 package registry
 
 import (
 	"encoding/asn1"
 	"encoding/hex"
 	"fmt"
+	"math/big"
+	"time"
 )
 
 // AttrValue — одно расшифрованное значение атрибута для вывода.
@@ -100,18 +103,69 @@ func decodeSingleAttrValue(oid asn1.ObjectIdentifier, raw []byte, name string) A
 		}
 	case oid.Equal(OIDPKCS9LocalKeyID):
 		av.Value = hex.EncodeToString(raw)
-	default:
-		// UTF8String или OCTET STRING: пробуем как строку, иначе выводим hex.
-		var s string
-		if _, err := asn1.Unmarshal(raw, &s); err == nil {
-			av.Value = s
+	case oid.Equal(OIDPKCS9SigningTime):
+		// signingTime — UTCTime (1950–2049) или GeneralizedTime.
+		var t time.Time
+		if _, err := asn1.Unmarshal(raw, &t); err == nil {
+			av.Value = t.UTC().Format("2006-01-02 15:04:05")
 		} else {
-			av.Value = hex.EncodeToString(raw)
+			av.Raw = hex.EncodeToString(raw)
+		}
+	case oid.Equal(OIDCMSAlgorithmProtection):
+		var ap CMSAlgorithmProtection
+		if _, err := asn1.Unmarshal(raw, &ap); err != nil {
+			av.Raw = hex.EncodeToString(raw)
+			break
+		}
+		av.Value = "digest=" + AlgorithmName(ap.DigestAlgorithm.Algorithm)
+		if len(ap.SignatureAlgorithm.Algorithm) > 0 {
+			av.Value += ", signature=" + AlgorithmName(ap.SignatureAlgorithm.Algorithm)
+		}
+		if len(ap.MACAlgorithm.Algorithm) > 0 {
+			av.Value += ", mac=" + AlgorithmName(ap.MACAlgorithm.Algorithm)
 		}
+	default:
+		av.Value = decodeTypedValue(raw)
 	}
 	return av
 }
 
+// decodeTypedValue расшифровывает значение произвольного атрибута по его universal-тегу
+// (строки, INTEGER, BOOLEAN, время, OID); прочие значения, в том числе OCTET STRING, выводятся в hex целиком (TLV).
+func decodeTypedValue(raw []byte) string {
+	var rv asn1.RawValue
+	if _, err := asn1.Unmarshal(raw, &rv); err != nil || rv.Class != asn1.ClassUniversal {
+		return hex.EncodeToString(raw)
+	}
+	switch rv.Tag {
+	case asn1.TagUTF8String, asn1.TagPrintableString, asn1.TagIA5String, asn1.TagT61String:
+		return string(rv.Bytes)
+	case asn1.TagBMPString:
+		return decodeBMPString(rv.Bytes)
+	case asn1.TagInteger:
+		var n *big.Int
+		if _, err := asn1.Unmarshal(raw, &n); err == nil {
+			return n.String()
+		}
+	case asn1.TagBoolean:
+		var b bool
+		if _, err := asn1.Unmarshal(raw, &b); err == nil {
+			return fmt.Sprintf("%t", b)
+		}
+	case asn1.TagUTCTime, asn1.TagGeneralizedTime:
+		var t time.Time
+		if _, err := asn1.Unmarshal(raw, &t); err == nil {
+			return t.UTC().Format("2006-01-02 15:04:05")
+		}
+	case asn1.TagOID:
+		var o asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(raw, &o); err == nil {
+			return o.String()
+		}
+	}
+	return hex.EncodeToString(raw)
+}
+
 // SignerRoleName возвращает значение атрибута roleName из authenticatedAttributes данного SignerInfo.
 // Используется для имени файла при выгрузке сертификата подписанта (например -export-certs-dir).
 func SignerRoleName(si *SignerInfo) string {
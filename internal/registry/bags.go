@@ -0,0 +1,239 @@
+// bags.go — разбор мешков PKCS#12, отличных от CertBag: keyBag, pkcs8ShroudedKeyBag, crlBag, secretBag, safeContentsBag (RFC 7292, 4.2).
+package registry
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+)
+
+// KeyBagSummary — сводка по закрытому ключу из keyBag (PrivateKeyInfo) или pkcs8ShroudedKeyBag (EncryptedPrivateKeyInfo).
+// Зашифрованный ключ без подходящего пароля не расшифровывается: известны только алгоритм шифрования и длина шифртекста.
+type KeyBagSummary struct {
+	Algorithm           string `json:"algorithm,omitempty"` // «RSA 2048», «ECDSA P-256», «Ed25519» или OID
+	Encrypted           bool   `json:"encrypted"`
+	EncryptionAlgorithm string `json:"encryptionAlgorithm,omitempty"` // DescribeEncryptionAlgorithm
+	EncryptedLen        int    `json:"encryptedLen,omitempty"`
+	Decrypted           bool   `json:"decrypted,omitempty"` // зашифрованный ключ расшифрован паролем хранилища (ParseWithPassword)
+}
+
+// CRLSummary — сводка по списку отзыва из crlBag.
+type CRLSummary struct {
+	Type       string    `json:"type"` // crlId
+	Issuer     string    `json:"issuer,omitempty"`
+	ThisUpdate time.Time `json:"thisUpdate"`
+	NextUpdate time.Time `json:"nextUpdate"` // нулевое значение — nextUpdate нет
+	Number     string    `json:"number,omitempty"`
+	Revoked    int       `json:"revoked"`
+	Len        int       `json:"len"`
+}
+
+// SecretBagSummary — тип и длина секрета из secretBag; значение секрета не выводится.
+type SecretBagSummary struct {
+	TypeID string `json:"typeId"`
+	Len    int    `json:"len"`
+}
+
+// crlBag — CRLBag (RFC 7292, 4.2.4): crlValue [0] EXPLICIT OCTET STRING.
+type crlBag struct {
+	CRLId    asn1.ObjectIdentifier
+	CRLValue asn1.RawValue `asn1:"tag:0"`
+}
+
+// secretBag — SecretBag (RFC 7292, 4.2.5): secretValue [0] EXPLICIT ANY.
+type secretBag struct {
+	SecretTypeId asn1.ObjectIdentifier
+	SecretValue  asn1.RawValue `asn1:"tag:0"`
+}
+
+// privateKeyInfo — PrivateKeyInfo (RFC 5208) для ключей, которые не разбирает x509.ParsePKCS8PrivateKey.
+type privateKeyInfo struct {
+	Version    int
+	Algorithm  AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// encryptedPrivateKeyInfo — EncryptedPrivateKeyInfo (RFC 5208, 6).
+type encryptedPrivateKeyInfo struct {
+	EncryptionAlgorithm AlgorithmIdentifier
+	EncryptedData       []byte
+}
+
+// safeBagValueDER возвращает DER значения мешка (SEQUENCE) из bagValue [0]:
+// при EXPLICIT в Bytes лежит полный TLV, при IMPLICIT — содержимое без тега 0x30.
+func safeBagValueDER(bag SafeBag) []byte {
+	v := bag.BagValue.Bytes
+	if len(v) == 0 && len(bag.BagValue.FullBytes) > 0 {
+		v = bag.BagValue.FullBytes
+	}
+	if len(v) > 0 && v[0] != 0x30 {
+		v = derPrependTLV(0x30, bag.BagValue.Bytes)
+	}
+	return v
+}
+
+// parseCertBagValue заполняет CertId, CertType и данные сертификата из CertBag.
+func parseCertBagValue(info *SafeBagInfo, value []byte) error {
+	var cb CertBag
+	if _, err := asn1.Unmarshal(value, &cb); err != nil {
+		return err
+	}
+	info.CertId = cb.CertId
+	info.CertType = CertTypeName(cb.CertId)
+	// CertValue: [0] IMPLICIT OCTET STRING → Bytes = cert DER; иначе может быть 04 ll ... (EXPLICIT)
+	certDER := unwrapOctetStringIfPresent(cb.CertValue.Bytes)
+	info.CertValueLen = len(certDER)
+	if cert, err := x509.ParseCertificate(certDER); err == nil {
+		info.CertSummary = &CertSummary{
+			Subject:   cert.Subject.String(),
+			Issuer:    cert.Issuer.String(),
+			Serial:    cert.SerialNumber.Text(16),
+			NotBefore: cert.NotBefore.Format("2006-01-02"),
+			NotAfter:  cert.NotAfter.Format("2006-01-02"),
+			KeyAlg:    cert.PublicKeyAlgorithm.String(),
+		}
+		info.CertValueDER = append([]byte(nil), certDER...) // копия для выгрузки в PEM
+	}
+	return nil
+}
+
+// parseKeyBagValue разбирает PrivateKeyInfo: алгоритм и размер ключа.
+func parseKeyBagValue(value []byte) (*KeyBagSummary, error) {
+	var pki privateKeyInfo
+	if _, err := asn1.Unmarshal(value, &pki); err != nil {
+		return nil, fmt.Errorf("keyBag: %w", err)
+	}
+	s := &KeyBagSummary{Algorithm: pki.Algorithm.Algorithm.String()}
+	if key, err := x509.ParsePKCS8PrivateKey(value); err == nil {
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			s.Algorithm = fmt.Sprintf("RSA %d", k.N.BitLen())
+		case *ecdsa.PrivateKey:
+			s.Algorithm = "ECDSA " + k.Curve.Params().Name
+		case ed25519.PrivateKey:
+			s.Algorithm = "Ed25519"
+		}
+	}
+	return s, nil
+}
+
+// parseShroudedKeyBagValue разбирает EncryptedPrivateKeyInfo без расшифровки.
+func parseShroudedKeyBagValue(value []byte) (*KeyBagSummary, error) {
+	var epki encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(value, &epki); err != nil {
+		return nil, fmt.Errorf("pkcs8ShroudedKeyBag: %w", err)
+	}
+	return &KeyBagSummary{
+		Encrypted:           true,
+		EncryptionAlgorithm: DescribeEncryptionAlgorithm(epki.EncryptionAlgorithm),
+		EncryptedLen:        len(epki.EncryptedData),
+	}, nil
+}
+
+// parseCRLBagValue разбирает CRLBag; для x509CRL — издатель, даты, номер и число отозванных сертификатов.
+func parseCRLBagValue(value []byte) (*CRLSummary, error) {
+	var cb crlBag
+	if _, err := asn1.Unmarshal(value, &cb); err != nil {
+		return nil, fmt.Errorf("crlBag: %w", err)
+	}
+	crlDER := unwrapOctetStringIfPresent(cb.CRLValue.Bytes)
+	s := &CRLSummary{Type: CertTypeName(cb.CRLId), Len: len(crlDER)}
+	if !cb.CRLId.Equal(OIDX509CRL) {
+		return s, nil
+	}
+	crl, err := x509.ParseRevocationList(crlDER)
+	if err != nil {
+		return s, fmt.Errorf("crlBag: %w", err)
+	}
+	s.Issuer = crl.Issuer.String()
+	s.ThisUpdate, s.NextUpdate = crl.ThisUpdate.UTC(), crl.NextUpdate.UTC()
+	if crl.Number != nil {
+		s.Number = crl.Number.Text(16)
+	}
+	s.Revoked = len(crl.RevokedCertificateEntries)
+	return s, nil
+}
+
+// parseSecretBagValue разбирает SecretBag: тип секрета и длину значения.
+func parseSecretBagValue(value []byte) (*SecretBagSummary, error) {
+	var sb secretBag
+	if _, err := asn1.Unmarshal(value, &sb); err != nil {
+		return nil, fmt.Errorf("secretBag: %w", err)
+	}
+	return &SecretBagSummary{TypeID: sb.SecretTypeId.String(), Len: len(unwrapOctetStringIfPresent(sb.SecretValue.Bytes))}, nil
+}
+
+// parseNestedSafeContents разбирает вложенные SafeContents мешка safeContentsBag в пределах budget.
+// Вложенные мешки с ошибкой разбора сохраняются с заполненным Error; превышение лимита возвращается ошибкой.
+func parseNestedSafeContents(value []byte, budget *bagBudget) ([]SafeBagInfo, error) {
+	bags, err := budget.safeContents(value)
+	if err != nil {
+		return nil, fmt.Errorf("safeContentsBag: %w", err)
+	}
+	nested := make([]SafeBagInfo, 0, len(bags))
+	for i, b := range bags {
+		info, err := budget.bagInfo(fmt.Sprintf("safeContentsBag[%d].bagAttributes", i+1), b)
+		if err != nil {
+			if errors.Is(err, ErrLimitExceeded) {
+				return nil, err
+			}
+			info.Error = err.Error()
+		}
+		nested = append(nested, info)
+	}
+	return nested, nil
+}
+
+// BagValueFields возвращает сводку значения мешка, отличного от CertBag, в виде пар имя–значение для вывода
+// (сертификаты CertBag выводятся по CertSummary), и ошибку разбора, если она есть.
+// Вложенные мешки safeContentsBag не раскрываются (см. Nested).
+func (info *SafeBagInfo) BagValueFields() []BagAttributeValue {
+	var out []BagAttributeValue
+	add := func(name, format string, args ...interface{}) {
+		out = append(out, BagAttributeValue{Name: name, Value: fmt.Sprintf(format, args...)})
+	}
+	switch {
+	case info.Key != nil && info.Key.Encrypted:
+		add("encryption", "%s", info.Key.EncryptionAlgorithm)
+		if info.Key.Decrypted {
+			add("keyAlg", "%s", info.Key.Algorithm)
+			add("encryptedKey", i18n.T("%d bytes (расшифрован паролем)"), info.Key.EncryptedLen)
+		} else {
+			add("encryptedKey", i18n.T("%d bytes (не расшифрован: пароль не задан или не подошёл)"), info.Key.EncryptedLen)
+		}
+	case info.Key != nil:
+		add("keyAlg", "%s", info.Key.Algorithm)
+	case info.CRL != nil:
+		add("crlId", "%s", info.CRL.Type)
+		if info.CRL.Issuer == "" {
+			add("crlValue", "%d bytes", info.CRL.Len)
+			break
+		}
+		add("Issuer", "%s", info.CRL.Issuer)
+		next := "—"
+		if !info.CRL.NextUpdate.IsZero() {
+			next = info.CRL.NextUpdate.Format("2006-01-02")
+		}
+		add("Updates", "%s — %s", info.CRL.ThisUpdate.Format("2006-01-02"), next)
+		if info.CRL.Number != "" {
+			add("Number", "%s", info.CRL.Number)
+		}
+		add("Revoked", "%d", info.CRL.Revoked)
+	case info.Secret != nil:
+		add("secretTypeId", "%s", info.Secret.TypeID)
+		add("secretValue", "%d bytes", info.Secret.Len)
+	case info.Nested != nil || info.BagId.Equal(OIDSafeContentsBag):
+		add("safeBags", "%d", len(info.Nested))
+	}
+	if info.Error != "" {
+		add("error", "%s", info.Error)
+	}
+	return out
+}
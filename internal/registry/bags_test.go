@@ -0,0 +1,147 @@
+package registry
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testBag собирает SafeBag с bagValue [0] EXPLICIT value.
+func testBag(t *testing.T, id asn1.ObjectIdentifier, value interface{}) SafeBag {
+	t.Helper()
+	b, err := asn1.Marshal(value)
+	if err != nil {
+		t.Fatalf("marshal %v: %v", id, err)
+	}
+	return SafeBag{BagId: id, BagValue: explicit0(b)}
+}
+
+// explicit0 — [0] EXPLICIT вокруг готового DER.
+func explicit0(der []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: der}
+}
+
+// TestParseSafeBagInfoBagTypes проверяет сводки keyBag, pkcs8ShroudedKeyBag (PBES2), crlBag, secretBag
+// и вложенного safeContentsBag, а также то, что ошибка разбора значения не теряет мешок.
+func TestParseSafeBagInfoBagTypes(t *testing.T) {
+	cert, key := newTestSigner(t)
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyBag := SafeBag{BagId: OIDKeyBag, BagValue: explicit0(pkcs8)}
+
+	kdf, _ := asn1.Marshal(pbkdf2Params{
+		Salt:           make([]byte, 8),
+		IterationCount: 2048,
+		KeyLength:      32,
+		PRF:            AlgorithmIdentifier{Algorithm: OIDHMACWithSHA256, Parameters: asn1.NullRawValue},
+	})
+	iv, _ := asn1.Marshal(make([]byte, 16))
+	pbes2, _ := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: AlgorithmIdentifier{Algorithm: OIDPBKDF2, Parameters: asn1.RawValue{FullBytes: kdf}},
+		EncryptionScheme:  AlgorithmIdentifier{Algorithm: OIDAES256CBC, Parameters: asn1.RawValue{FullBytes: iv}},
+	})
+	shrouded := testBag(t, OIDPKCS8ShroudedKeyBag, encryptedPrivateKeyInfo{
+		EncryptionAlgorithm: AlgorithmIdentifier{Algorithm: OIDPBES2, Parameters: asn1.RawValue{FullBytes: pbes2}},
+		EncryptedData:       make([]byte, 48),
+	})
+
+	// Издатель CRL должен иметь keyUsage cRLSign: отдельный самоподписанный сертификат с тем же ключом.
+	now := time.Now().UTC().Truncate(time.Second)
+	caDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      cert.Subject,
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		KeyUsage:     x509.KeyUsageCRLSign,
+		SubjectKeyId: []byte{1, 2, 3, 4},
+	}, &x509.Certificate{Subject: cert.Subject}, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+	crlDER, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(7),
+		ThisUpdate: now,
+		NextUpdate: now.Add(24 * time.Hour),
+		RevokedCertificateEntries: []x509.RevocationListEntry{
+			{SerialNumber: big.NewInt(42), RevocationTime: now},
+		},
+	}, ca, key)
+	if err != nil {
+		t.Fatalf("CreateRevocationList: %v", err)
+	}
+	crlValue, _ := asn1.Marshal(crlDER)
+	crl := testBag(t, OIDCRLBag, struct {
+		CRLId    asn1.ObjectIdentifier
+		CRLValue asn1.RawValue
+	}{OIDX509CRL, explicit0(crlValue)})
+
+	secretValue, _ := asn1.Marshal([]byte("0123456789abcdef"))
+	secret := testBag(t, OIDSecretBag, struct {
+		TypeID asn1.ObjectIdentifier
+		Value  asn1.RawValue
+	}{asn1.ObjectIdentifier{1, 2, 3, 4}, explicit0(secretValue)})
+
+	certValue, _ := asn1.Marshal(cert.Raw)
+	certBag := testBag(t, OIDCertBag, struct {
+		CertId    asn1.ObjectIdentifier
+		CertValue asn1.RawValue
+	}{OIDX509Certificate, explicit0(certValue)})
+	broken := SafeBag{BagId: OIDKeyBag, BagValue: explicit0([]byte{0x30, 0x01, 0x02})}
+	nestedDER, err := asn1.Marshal([]SafeBag{certBag, broken})
+	if err != nil {
+		t.Fatalf("marshal SafeContents: %v", err)
+	}
+	nested := SafeBag{BagId: OIDSafeContentsBag, BagValue: explicit0(nestedDER)}
+
+	info, err := ParseSafeBagInfo(keyBag)
+	if err != nil || info.BagType != "keyBag" || info.Key == nil || info.Key.Algorithm != "ECDSA P-256" || info.Key.Encrypted {
+		t.Errorf("keyBag: %+v, %v; ожидается ECDSA P-256 без шифрования", info.Key, err)
+	}
+
+	info, err = ParseSafeBagInfo(shrouded)
+	if err != nil || info.Key == nil || !info.Key.Encrypted || info.Key.EncryptedLen != 48 {
+		t.Fatalf("pkcs8ShroudedKeyBag: %+v, %v", info.Key, err)
+	}
+	if want := "PBES2 (PBKDF2-HMAC-SHA256, 2048 iterations, AES-256-CBC)"; info.Key.EncryptionAlgorithm != want {
+		t.Errorf("encryptionAlgorithm = %q, ожидается %q", info.Key.EncryptionAlgorithm, want)
+	}
+
+	info, err = ParseSafeBagInfo(crl)
+	if err != nil || info.CRL == nil {
+		t.Fatalf("crlBag: %v", err)
+	}
+	if info.CRL.Type != "X.509 CRL" || info.CRL.Issuer != cert.Subject.String() || info.CRL.Revoked != 1 || info.CRL.Number != "7" {
+		t.Errorf("crlBag: %+v", info.CRL)
+	}
+
+	info, err = ParseSafeBagInfo(secret)
+	if err != nil || info.Secret == nil || info.Secret.TypeID != "1.2.3.4" || info.Secret.Len != 16 {
+		t.Errorf("secretBag: %+v, %v", info.Secret, err)
+	}
+
+	info, err = ParseSafeBagInfo(nested)
+	if err != nil || len(info.Nested) != 2 {
+		t.Fatalf("safeContentsBag: %d вложенных, %v; ожидается 2", len(info.Nested), err)
+	}
+	if info.Nested[0].CertSummary == nil || info.Nested[0].CertSummary.Subject != cert.Subject.String() {
+		t.Errorf("вложенный certBag: %+v", info.Nested[0].CertSummary)
+	}
+	if info.Nested[1].BagType != "keyBag" || info.Nested[1].Error == "" {
+		t.Errorf("вложенный повреждённый keyBag: %+v; ожидается Error", info.Nested[1])
+	}
+	var fields []string
+	for _, f := range info.Nested[1].BagValueFields() {
+		fields = append(fields, f.Name)
+	}
+	if !strings.Contains(strings.Join(fields, ","), "error") {
+		t.Errorf("BagValueFields повреждённого мешка = %v, ожидается error", fields)
+	}
+}
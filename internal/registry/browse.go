@@ -0,0 +1,388 @@
+// browse.go — дерево контейнера для интерактивного просмотра (registry-analyzer -interactive): PFX → SignedData →
+// certificates / SafeContents / SignerInfos (у хранилища PKCS#12 — AuthenticatedSafe → мешки) с расшифрованными
+// полями, DER узла и результатом проверки подписи.
+package registry
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+)
+
+// Статус узла BrowseNode по результату проверки подписи.
+const (
+	BrowseStatusNone   = ""       // проверка к узлу не относится
+	BrowseStatusOK     = "ok"     // подпись (или MAC хранилища) действительна
+	BrowseStatusFailed = "failed" // проверка не пройдена
+)
+
+// BrowseField — строка панели подробностей: имя поля и значение.
+type BrowseField struct {
+	Name  string
+	Value string
+}
+
+// BrowseNode — узел дерева просмотра. DER — TLV узла, Offset — его смещение во входном файле (-1, если байты
+// во входе не найдены: расшифрованный мешок хранилища или IMPLICIT-вариант, перекодированный при разборе).
+// ASN1Type — тип registry.asn1 для подписей полей дерева ASN.1 (пусто — без подписей). Cert — сертификат
+// узла для выгрузки в PEM, ExportName — базовое имя файла (без .pem), как у -export-*-dir.
+type BrowseNode struct {
+	Title      string
+	Fields     []BrowseField
+	DER        []byte
+	Offset     int
+	ASN1Type   string
+	Cert       *x509.Certificate
+	ExportName string
+	Status     string
+	Children   []*BrowseNode
+}
+
+// BrowseTree строит дерево просмотра контейнера. results — результат Verify (для хранилища не используется:
+// статус корня — проверка MAC); статус подписанта переносится на его сертификат и на корень.
+func (c *Container) BrowseTree(results []SignerVerification) *BrowseNode {
+	root := &BrowseNode{
+		Title:    "PFX — " + KindName(c.Kind),
+		DER:      c.raw,
+		Offset:   0,
+		ASN1Type: "PFX",
+		Fields: []BrowseField{
+			{"Kind", KindName(c.Kind)},
+			{"Version", fmt.Sprint(c.PFXVersion)},
+			{"ContentType", c.ContentType.String()},
+			{"Size", i18n.Sprintf("%d байт", len(c.raw))},
+		},
+	}
+	if c.Kind == KindKeystore {
+		root.Fields = append(root.Fields, BrowseField{"MAC", c.macText(false)})
+		if c.Mac != nil && c.Mac.Status != MacStatusSkipped {
+			root.Status = BrowseStatusFailed
+			if c.MacVerified() {
+				root.Status = BrowseStatusOK
+			}
+		}
+		root.Children = c.browseAuthSafe()
+		return root
+	}
+	if c.SignedData == nil {
+		return root
+	}
+
+	signerStatus := make(map[*x509.Certificate]string)
+	for _, r := range results {
+		if r.SignerIndex < 1 || r.SignerIndex > len(c.Signers) {
+			continue
+		}
+		if cert := c.SignerCert(&c.Signers[r.SignerIndex-1]); cert != nil {
+			signerStatus[cert] = statusOf(r.OK)
+		}
+	}
+	if len(results) > 0 {
+		root.Status = statusOf(VerifyOK(results))
+	}
+
+	sd := c.SignedData
+	var digestAlgs []string
+	for _, a := range sd.DigestAlgorithms {
+		digestAlgs = append(digestAlgs, a.Algorithm.String())
+	}
+	sdNode := c.browseDER(&BrowseNode{
+		Title:    "SignedData",
+		ASN1Type: "SignedData",
+		Status:   root.Status,
+		Fields: []BrowseField{
+			{"Version", fmt.Sprint(sd.Version)},
+			{"DigestAlgorithms", strings.Join(digestAlgs, ", ")},
+			{"eContentType", sd.EncapContentInfo.EContentType.String()},
+			{"eContentSize", i18n.Sprintf("%d байт", len(c.EContent()))},
+			{"Certificates", fmt.Sprint(len(c.Certificates))},
+			{"SafeBags", fmt.Sprint(len(c.SafeBagInfos))},
+			{"SignerInfos", fmt.Sprint(len(c.Signers))},
+		},
+	}, c.signedDataDER())
+	root.Children = append(root.Children, sdNode)
+
+	certs := c.browseDER(&BrowseNode{Title: fmt.Sprintf("Certificates (%d)", len(c.Certificates))}, sd.Certificates.FullBytes)
+	for i, cert := range c.Certificates {
+		title := fmt.Sprintf("[%d] %s", i+1, certTitle(cert))
+		if c.isSignerCert(cert) {
+			title += " (" + i18n.T("подписант контейнера") + ")"
+		}
+		certs.Children = append(certs.Children, c.browseDER(&BrowseNode{
+			Title:      title,
+			Fields:     certFields(cert),
+			Cert:       cert,
+			ExportName: c.CertExportBasename(cert, i),
+			Status:     signerStatus[cert],
+		}, cert.Raw))
+	}
+	sdNode.Children = append(sdNode.Children, certs)
+
+	contents := c.browseDER(&BrowseNode{
+		Title:    fmt.Sprintf("SafeContents (eContent, %d)", len(c.SafeBagInfos)),
+		ASN1Type: "SafeContents",
+	}, c.EContent())
+	bagTLVs := safeContentsTLV(c.EContent())
+	for i := range c.SafeBagInfos {
+		bagDER := safeBagDER(c.SafeBags, i)
+		if len(bagTLVs) == len(c.SafeBagInfos) {
+			bagDER = bagTLVs[i]
+		}
+		contents.Children = append(contents.Children, c.browseBag(i, &c.SafeBagInfos[i], bagDER))
+	}
+	sdNode.Children = append(sdNode.Children, contents)
+
+	signers := &BrowseNode{Title: fmt.Sprintf("SignerInfos (%d)", len(c.Signers)), Offset: -1}
+	for i := range c.Signers {
+		signers.Children = append(signers.Children, c.browseSigner(i, results))
+	}
+	sdNode.Children = append(sdNode.Children, signers)
+	return root
+}
+
+// browseAuthSafe — узлы ContentInfo хранилища PKCS#12 с мешками: SafeBags идут подряд по числу AuthSafe[i].SafeBags.
+func (c *Container) browseAuthSafe() []*BrowseNode {
+	var out []*BrowseNode
+	next := 0
+	for i, as := range c.AuthSafe {
+		n := &BrowseNode{Title: fmt.Sprintf("AuthenticatedSafe [%d] %s", i+1, as.ContentType), Offset: -1}
+		n.Fields = append(n.Fields, BrowseField{"ContentType", as.ContentType})
+		if as.EncryptionAlgorithm != "" {
+			n.Fields = append(n.Fields, BrowseField{"Encryption", as.EncryptionAlgorithm})
+		}
+		n.Fields = append(n.Fields, BrowseField{"SafeBags", fmt.Sprint(as.SafeBags)})
+		if as.Error != "" {
+			n.Fields = append(n.Fields, BrowseField{"Error", as.Error})
+			n.Status = BrowseStatusFailed
+		}
+		for j := 0; j < as.SafeBags && next < len(c.SafeBagInfos); j++ {
+			n.Children = append(n.Children, c.browseBag(next, &c.SafeBagInfos[next], safeBagDER(c.SafeBags, next)))
+			next++
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+// browseBag — узел мешка: тип, сертификат (если X.509), значение по типу мешка, атрибуты и вложенные мешки.
+func (c *Container) browseBag(index int, info *SafeBagInfo, bagDER []byte) *BrowseNode {
+	title := fmt.Sprintf("[%d] %s", index+1, info.BagType)
+	if role := SafeBagRoleName(info); role != "" {
+		title = fmt.Sprintf("[%d] %s", index+1, role)
+	}
+	n := &BrowseNode{ASN1Type: "SafeBag"}
+	n.Fields = append(n.Fields, BrowseField{"bagId", fmt.Sprintf("%s (%s)", info.BagId, info.BagType)})
+	if len(info.CertId) > 0 {
+		n.Fields = append(n.Fields, BrowseField{"certId", fmt.Sprintf("%s (%s)", info.CertId, info.CertType)})
+	}
+	if len(info.CertValueDER) > 0 {
+		if cert, err := x509.ParseCertificate(info.CertValueDER); err == nil {
+			n.Cert = cert
+			n.ExportName = SafeBagExportBasename(info, index)
+			title += " — " + certTitle(cert)
+			n.Fields = append(n.Fields, certFields(cert)...)
+		}
+	} else if len(info.CertId) > 0 {
+		n.Fields = append(n.Fields, BrowseField{"certValue", i18n.Sprintf("%d байт (не X.509)", info.CertValueLen)})
+	}
+	for _, f := range info.BagValueFields() {
+		n.Fields = append(n.Fields, BrowseField{f.Name, f.Value})
+	}
+	for _, a := range info.BagAttributes {
+		n.Fields = append(n.Fields, BrowseField{a.Name, a.Value})
+	}
+	if st := info.RoleStatus(time.Now()); st != RoleStatusNone {
+		n.Fields = append(n.Fields, BrowseField{"roleStatus", st})
+		if st != RoleStatusActive {
+			n.Status = BrowseStatusFailed
+		}
+	}
+	if info.Error != "" {
+		n.Fields = append(n.Fields, BrowseField{"Error", info.Error})
+		n.Status = BrowseStatusFailed
+	}
+	n.Title = title
+	for j := range info.Nested {
+		n.Children = append(n.Children, c.browseBag(j, &info.Nested[j], nil))
+	}
+	return c.browseDER(n, bagDER)
+}
+
+// browseSigner — узел SignerInfo: алгоритмы, sid, сертификат подписанта, расшифрованные атрибуты и проверки Verify.
+func (c *Container) browseSigner(i int, results []SignerVerification) *BrowseNode {
+	si := &c.Signers[i]
+	n := &BrowseNode{Title: fmt.Sprintf("Signer [%d]", i+1), ASN1Type: "SignerInfo"}
+	n.Fields = []BrowseField{
+		{"Version", fmt.Sprint(si.Version)},
+		{"SID", fmt.Sprintf("%x", si.SID.Bytes)},
+		{"DigestAlgorithm", si.DigestAlgorithm.Algorithm.String()},
+		{"SignatureAlgorithm", si.DigestEncryptionAlgorithm.Algorithm.String()},
+		{"Signature", i18n.Sprintf("%d байт", len(si.EncryptedDigest))},
+	}
+	if cert := c.SignerCert(si); cert != nil {
+		n.Cert = cert
+		n.ExportName = c.CertExportBasename(cert, certIndex(c.Certificates, cert))
+		n.Title += " — " + certTitle(cert)
+		n.Fields = append(n.Fields, BrowseField{"Subject", cert.Subject.String()}, BrowseField{"Serial", cert.SerialNumber.Text(16)})
+	} else {
+		n.Title += " (" + i18n.T("сертификат не найден") + ")"
+	}
+	if attrs, err := SignerAttributes(si); err != nil {
+		n.Fields = append(n.Fields, BrowseField{"Error", err.Error()})
+	} else {
+		for _, a := range attrs {
+			for _, v := range DecodeAttributeValues(a) {
+				disp := v.Value
+				if disp == "" {
+					disp = v.Raw
+				}
+				n.Fields = append(n.Fields, BrowseField{v.Name, disp})
+			}
+		}
+	}
+	for _, r := range results {
+		if r.SignerIndex != i+1 {
+			continue
+		}
+		n.Status = statusOf(r.OK)
+		for _, ch := range r.Checks {
+			value := "OK"
+			if !ch.OK {
+				value = "FAILED"
+			}
+			if ch.Detail != "" {
+				value += " — " + ch.Detail
+			}
+			n.Fields = append(n.Fields, BrowseField{"verify: " + ch.Name, value})
+		}
+	}
+	der, err := asn1.Marshal(*si)
+	if err != nil {
+		der = nil
+	}
+	return c.browseDER(n, der)
+}
+
+// browseDER задаёт узлу DER и его смещение во входном файле.
+func (c *Container) browseDER(n *BrowseNode, der []byte) *BrowseNode {
+	n.DER = der
+	n.Offset = -1
+	if len(der) > 0 {
+		n.Offset = bytes.Index(c.raw, der)
+	}
+	return n
+}
+
+// signedDataDER возвращает TLV SignedData из content [0] (IMPLICIT-вариант — с восстановленным тегом SEQUENCE).
+func (c *Container) signedDataDER() []byte {
+	var pfx PFX
+	if _, err := asn1.Unmarshal(c.raw, &pfx); err != nil {
+		return nil
+	}
+	if b := pfx.AuthSafe.Content.Bytes; len(b) > 0 && b[0] == 0x30 {
+		var rv asn1.RawValue
+		if _, err := asn1.Unmarshal(b, &rv); err == nil {
+			return rv.FullBytes
+		}
+	}
+	return derPrependTLV(0x30, pfx.AuthSafe.Content.Bytes)
+}
+
+// safeBagDER перекодирует мешок i (мешки хранилища после расшифровки во входе не встречаются).
+func safeBagDER(bags []SafeBag, i int) []byte {
+	if i >= len(bags) {
+		return nil
+	}
+	der, err := asn1.Marshal(bags[i])
+	if err != nil {
+		return nil
+	}
+	return der
+}
+
+// safeContentsTLV возвращает исходные TLV мешков из SafeContents (SEQUENCE OF SafeBag) без перекодирования:
+// asn1.Marshal упорядочивает SET OF bagAttributes по DER и может не совпасть со входом.
+func safeContentsTLV(safeContents []byte) [][]byte {
+	var raws []asn1.RawValue
+	if _, err := asn1.Unmarshal(safeContents, &raws); err != nil {
+		return nil
+	}
+	out := make([][]byte, len(raws))
+	for i, rv := range raws {
+		out[i] = rv.FullBytes
+	}
+	return out
+}
+
+// certFields — подробности сертификата для панели: имена, сроки, алгоритмы, расширения, SAN и отпечаток.
+func certFields(cert *x509.Certificate) []BrowseField {
+	f := []BrowseField{
+		{"Subject", cert.Subject.String()},
+		{"Issuer", cert.Issuer.String()},
+		{"Serial", cert.SerialNumber.Text(16)},
+		{"NotBefore", cert.NotBefore.UTC().Format(time.RFC3339)},
+		{"NotAfter", cert.NotAfter.UTC().Format(time.RFC3339)},
+		{"Version", fmt.Sprint(cert.Version)},
+		{"SignatureAlgorithm", cert.SignatureAlgorithm.String()},
+		{"PublicKeyAlgorithm", cert.PublicKeyAlgorithm.String()},
+	}
+	if len(cert.SubjectKeyId) > 0 {
+		f = append(f, BrowseField{"SubjectKeyId", hexEncode(cert.SubjectKeyId)})
+	}
+	if len(cert.AuthorityKeyId) > 0 {
+		f = append(f, BrowseField{"AuthorityKeyId", hexEncode(cert.AuthorityKeyId)})
+	}
+	if cert.BasicConstraintsValid {
+		f = append(f, BrowseField{"CA", fmt.Sprint(cert.IsCA)})
+	}
+	if cert.KeyUsage != 0 {
+		f = append(f, BrowseField{"KeyUsage", strings.Join(keyUsageStrings(cert.KeyUsage), ", ")})
+	}
+	if len(cert.ExtKeyUsage) > 0 {
+		f = append(f, BrowseField{"ExtKeyUsage", strings.Join(extKeyUsageStrings(cert.ExtKeyUsage), ", ")})
+	}
+	var san []string
+	san = append(san, cert.DNSNames...)
+	san = append(san, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		san = append(san, ip.String())
+	}
+	for _, u := range cert.URIs {
+		san = append(san, u.String())
+	}
+	if len(san) > 0 {
+		f = append(f, BrowseField{"SAN", strings.Join(san, ", ")})
+	}
+	sum := sha256.Sum256(cert.Raw)
+	return append(f, BrowseField{"SHA-256", hexEncode(sum[:])})
+}
+
+// certTitle — краткое имя сертификата для строки дерева: CN или полный subject.
+func certTitle(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return "CN=" + cert.Subject.CommonName
+	}
+	return cert.Subject.String()
+}
+
+func certIndex(certs []*x509.Certificate, cert *x509.Certificate) int {
+	for i, c := range certs {
+		if c == cert {
+			return i
+		}
+	}
+	return 0
+}
+
+func statusOf(ok bool) string {
+	if ok {
+		return BrowseStatusOK
+	}
+	return BrowseStatusFailed
+}
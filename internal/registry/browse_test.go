@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestBrowseTree проверяет дерево просмотра реестра: PFX → SignedData → Certificates, SafeContents, SignerInfos,
+// DER узлов по найденным смещениям совпадает со входом, статус подписи переносится на подписанта и его сертификат.
+func TestBrowseTree(t *testing.T) {
+	data := buildMarshalTestRegistry(t)
+	c, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	root := c.BrowseTree(c.Verify(VerifyOptions{}))
+	if root.Status != BrowseStatusOK || len(root.Children) != 1 {
+		t.Fatalf("корень: статус %q, потомков %d", root.Status, len(root.Children))
+	}
+	sd := root.Children[0]
+	if sd.Title != "SignedData" || len(sd.Children) != 3 {
+		t.Fatalf("SignedData: %q, потомков %d", sd.Title, len(sd.Children))
+	}
+	certs, bags, signers := sd.Children[0], sd.Children[1], sd.Children[2]
+	if len(certs.Children) != 1 || len(bags.Children) != 2 || len(signers.Children) != 1 {
+		t.Fatalf("сертификатов %d, мешков %d, подписантов %d", len(certs.Children), len(bags.Children), len(signers.Children))
+	}
+
+	var walk func(n *BrowseNode)
+	walk = func(n *BrowseNode) {
+		if n.Offset >= 0 && !bytes.Equal(data[n.Offset:n.Offset+len(n.DER)], n.DER) {
+			t.Errorf("%s: DER не совпадает со входом по смещению %d", n.Title, n.Offset)
+		}
+		for _, ch := range n.Children {
+			walk(ch)
+		}
+	}
+	walk(root)
+	for _, n := range []*BrowseNode{sd, certs.Children[0], bags.Children[0], bags.Children[1], signers.Children[0]} {
+		if n.Offset < 0 {
+			t.Errorf("%s: DER не найден во входе", n.Title)
+		}
+	}
+
+	signerCert := certs.Children[0]
+	if signerCert.Status != BrowseStatusOK || signerCert.Cert == nil || signerCert.ExportName == "" {
+		t.Errorf("сертификат подписанта: статус %q, сертификат %v, имя %q", signerCert.Status, signerCert.Cert != nil, signerCert.ExportName)
+	}
+	signer := signers.Children[0]
+	if signer.Status != BrowseStatusOK || signer.Cert != signerCert.Cert || !hasField(signer, "VIN", "TESTVIN123") {
+		t.Errorf("подписант: статус %q, поля %v", signer.Status, signer.Fields)
+	}
+	bag := bags.Children[0]
+	if bag.Cert == nil || bag.ExportName != SafeBagExportBasename(&c.SafeBagInfos[0], 0) || !hasField(bag, "roleName", "delegate") {
+		t.Errorf("мешок: %q, поля %v", bag.ExportName, bag.Fields)
+	}
+
+	// Без результатов Verify статус подписи не показывается.
+	if st := c.BrowseTree(nil).Status; st != BrowseStatusNone {
+		t.Errorf("статус без проверки: %q", st)
+	}
+}
+
+// TestBrowseTreeKeystore проверяет дерево хранилища PKCS#12: AuthenticatedSafe с мешками и статус MAC.
+func TestBrowseTreeKeystore(t *testing.T) {
+	for _, tt := range []struct {
+		password, status string
+	}{{"changeit", BrowseStatusOK}, {"wrong", BrowseStatusFailed}} {
+		c, err := ParseWithPassword(readKeystore(t, "modern"), DefaultLimits, tt.password)
+		if err != nil {
+			t.Fatalf("ParseWithPassword: %v", err)
+		}
+		root := c.BrowseTree(nil)
+		if root.Status != tt.status || len(root.Children) != len(c.AuthSafe) {
+			t.Errorf("%s: статус %q, AuthenticatedSafe %d", tt.password, root.Status, len(root.Children))
+		}
+		if tt.status != BrowseStatusOK {
+			continue
+		}
+		bags := 0
+		for _, as := range root.Children {
+			for _, bag := range as.Children {
+				bags++
+				if bag.Cert != nil && bag.Cert.Subject.CommonName != "Keystore Test" {
+					t.Errorf("сертификат мешка: %s", bag.Cert.Subject)
+				}
+			}
+		}
+		if bags != len(c.SafeBagInfos) {
+			t.Errorf("мешков в дереве %d, в контейнере %d", bags, len(c.SafeBagInfos))
+		}
+	}
+}
+
+func hasField(n *BrowseNode, name, value string) bool {
+	for _, f := range n.Fields {
+		if f.Name == name && f.Value == value {
+			return true
+		}
+	}
+	return false
+}
@@ -25,11 +25,16 @@ type SafeBagInput struct {
 
 // SignerAttrs — атрибуты подписанта для SignerInfo.authenticatedAttributes [0].
 // Хранятся на уровне подписанта (ADR-007), не в eContent или SafeBag.
+// SigningTime и AlgorithmProtection добавляют стандартные атрибуты CMS (signingTime, CMSAlgorithmProtection по RFC 6211);
+// Custom — произвольные подписанные атрибуты (бизнес-метаданные, покрываемые подписью).
 type SignerAttrs struct {
-	VIN          string
-	VERTimestamp time.Time
-	VERVersion   int
-	UID          string
+	VIN                 string
+	VERTimestamp        time.Time
+	VERVersion          int
+	UID                 string
+	SigningTime         time.Time
+	AlgorithmProtection bool
+	Custom              []CustomAttribute
 }
 
 // BuildRegistry собирает реестр ATOM-PKCS12-REGISTRY в формате, совместимом с эталоном (ADR-011).
@@ -38,7 +43,7 @@ type SignerAttrs struct {
 //  1. marshalSafeContents — SafeContents (SEQUENCE OF SafeBag) с roleName, roleValidityPeriod, localKeyID
 //  2. encapContentInfo — eContentType=pkcs7-data, eContent [0]=EXPLICIT OCTET STRING
 //  3. messageDigest — SHA-256 над eContent (safeContentsDER)
-//  4. authenticatedAttributes — contentType, VIN, VER, UID, messageDigest (сортировка по DER)
+//  4. authenticatedAttributes — contentType, VIN, VER, UID, messageDigest, signingTime, CMSAlgorithmProtection и Custom (сортировка по DER)
 //  5. signAuthenticatedAttributes — ECDSA P-256 над DER(authenticatedAttributes)
 //  6. certificates [0] — полный SET TLV; SignerInfo с sid=[0] EXPLICIT OCTET STRING (SubjectKeyId)
 //
@@ -70,8 +75,10 @@ func BuildRegistry(signerCert *x509.Certificate, signerKey *ecdsa.PrivateKey, sa
 	contentToDigest := safeContentsDER
 	digest := sha256.Sum256(contentToDigest)
 
-	// 4. Собрать authenticatedAttributes (SET OF Attribute): contentType, messageDigest, VIN, VER, UID
-	authAttrsDER, err := marshalAuthenticatedAttributes(digest[:], attrs)
+	// 4. Собрать authenticatedAttributes (SET OF Attribute): contentType, messageDigest, VIN, VER, UID и дополнительные
+	digestAlg := AlgorithmIdentifier{Algorithm: OIDSHA256}
+	sigAlg := AlgorithmIdentifier{Algorithm: OIDECDSAWithSHA256}
+	authAttrsDER, err := marshalAuthenticatedAttributes(digest[:], attrs, digestAlg, sigAlg)
 	if err != nil {
 		return nil, fmt.Errorf("authenticatedAttributes: %w", err)
 	}
@@ -101,9 +108,9 @@ func BuildRegistry(signerCert *x509.Certificate, signerKey *ecdsa.PrivateKey, sa
 	signerInfo := SignerInfo{
 		Version:                    1,
 		SID:                        asn1.RawValue{FullBytes: sidDER},
-		DigestAlgorithm:            AlgorithmIdentifier{Algorithm: OIDSHA256},
+		DigestAlgorithm:            digestAlg,
 		AuthenticatedAttributes:    authAttrsRaw,
-		DigestEncryptionAlgorithm:  AlgorithmIdentifier{Algorithm: OIDECDSAWithSHA256},
+		DigestEncryptionAlgorithm:  sigAlg,
 		EncryptedDigest:            sigDER,
 		UnauthenticatedAttributes:  emptyUnauthSet,
 	}
@@ -171,13 +178,7 @@ func marshalSafeContents(inputs []SafeBagInput) ([]byte, error) {
 			na := in.RoleNotAfter.UTC().Format("20060102150405Z")
 			bagAttrs = append(bagAttrs, attrRoleValidityPeriod(nb, na))
 		}
-		localKeyID := in.LocalKeyID
-		if len(localKeyID) == 0 {
-			if cert, err := x509.ParseCertificate(in.CertDER); err == nil && len(cert.SubjectKeyId) > 0 {
-				localKeyID = cert.SubjectKeyId
-			}
-		}
-		if len(localKeyID) > 0 {
+		if localKeyID, _ := effectiveLocalKeyID(in); len(localKeyID) > 0 {
 			bagAttrs = append(bagAttrs, attrOctetString(OIDPKCS9LocalKeyID, localKeyID))
 		}
 		bagAttrs = sortAttributesByDER(bagAttrs)
@@ -191,6 +192,25 @@ func marshalSafeContents(inputs []SafeBagInput) ([]byte, error) {
 	return asn1.Marshal(SafeContents(bags))
 }
 
+// Источник значения localKeyID мешка (см. effectiveLocalKeyID).
+const (
+	LocalKeyIDFromConfig = "config"               // задан явно в SafeBagInput.LocalKeyID
+	LocalKeyIDFromSKID   = "subjectKeyIdentifier" // взят из SubjectKeyId сертификата мешка
+	LocalKeyIDNone       = "none"                 // атрибут localKeyID не записывается
+)
+
+// effectiveLocalKeyID возвращает localKeyID, который попадёт в мешок, и его источник:
+// явное значение из входных данных, иначе SubjectKeyId сертификата, иначе атрибут не пишется.
+func effectiveLocalKeyID(in SafeBagInput) ([]byte, string) {
+	if len(in.LocalKeyID) > 0 {
+		return in.LocalKeyID, LocalKeyIDFromConfig
+	}
+	if cert, err := x509.ParseCertificate(in.CertDER); err == nil && len(cert.SubjectKeyId) > 0 {
+		return cert.SubjectKeyId, LocalKeyIDFromSKID
+	}
+	return nil, LocalKeyIDNone
+}
+
 // marshalUTF8StringValue кодирует строку как ASN.1 UTF8String (тег 0x0C). Go asn1.Marshal(string)
 // по умолчанию даёт PrintableString (0x13), а registry.asn1 требует UTF8String для RoleName, VIN, UID.
 func marshalUTF8StringValue(s string) []byte {
@@ -231,8 +251,9 @@ func attrRoleValidityPeriod(notBefore, notAfter string) Attribute {
 	}
 }
 
-func marshalAuthenticatedAttributes(contentDigest []byte, attrs SignerAttrs) ([]byte, error) {
-	// Собираем атрибуты: contentType, messageDigest, VIN, VER, UID; затем сортируем по DER (SET OF Attribute, X.690).
+func marshalAuthenticatedAttributes(contentDigest []byte, attrs SignerAttrs, digestAlg, sigAlg AlgorithmIdentifier) ([]byte, error) {
+	// Собираем атрибуты: contentType, messageDigest, VIN, VER, UID, signingTime, CMSAlgorithmProtection, Custom;
+	// затем сортируем по DER (SET OF Attribute, X.690).
 	contentTypeVal, _ := asn1.Marshal(OIDPKCS7Data)
 	messageDigestVal, _ := asn1.Marshal(contentDigest)
 	list := []Attribute{
@@ -255,6 +276,32 @@ func marshalAuthenticatedAttributes(contentDigest []byte, attrs SignerAttrs) ([]
 		list = append(list, attrUTF8String(OIDAtomUID, attrs.UID))
 	}
 	list = append(list, Attribute{AttrType: OIDPKCS9MessageDigest, AttrValues: []asn1.RawValue{{FullBytes: messageDigestVal}}})
+	if !attrs.SigningTime.IsZero() {
+		a, err := attrSigningTime(attrs.SigningTime)
+		if err != nil {
+			return nil, fmt.Errorf("signingTime: %w", err)
+		}
+		list = append(list, a)
+	}
+	if attrs.AlgorithmProtection {
+		a, err := attrAlgorithmProtection(digestAlg, sigAlg)
+		if err != nil {
+			return nil, fmt.Errorf("CMSAlgorithmProtection: %w", err)
+		}
+		list = append(list, a)
+	}
+	seen := make(map[string]bool)
+	for _, ca := range attrs.Custom {
+		a, err := ca.Attribute()
+		if err != nil {
+			return nil, err
+		}
+		if seen[a.AttrType.String()] {
+			return nil, fmt.Errorf("attribute %s declared more than once", a.AttrType)
+		}
+		seen[a.AttrType.String()] = true
+		list = append(list, a)
+	}
 	list = sortAttributesByDER(list)
 	return marshalAttributeSet(list)
 }
@@ -0,0 +1,108 @@
+// capath.go — раскладка сертификатов реестра для директории OpenSSL -CApath: PEM-файлы и имена ссылок
+// subject_hash.N, как у c_rehash / openssl rehash, с манифестом «ссылка → роли».
+package registry
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/sgw-registry/registry-analyzer/internal/pki"
+)
+
+// Источники сертификата в CAPathEntry.Source.
+const (
+	CAPathSourceSafeBag = "safeBag" // сертификат мешка SafeBag
+	CAPathSourceSigner  = "signer"  // подписант контейнера
+	CAPathSourceChain   = "chain"   // остальные сертификаты SignedData.certificates
+)
+
+// CAPathEntry — сертификат директории -CApath: файл PEM, ссылка subject_hash.N на него и роли из мешков
+// (один сертификат в нескольких мешках — одна запись со всеми roleName). Сериализуется как запись манифеста.
+type CAPathEntry struct {
+	Link    string   `json:"link"`    // имя ссылки: 8 hex-цифр subject_hash, точка, номер среди сертификатов с тем же хешем
+	File    string   `json:"file"`    // имя PEM-файла: roleName_Serial.pem, signer_Serial.pem или ca_Serial.pem
+	Subject string   `json:"subject"` // subject сертификата
+	Serial  string   `json:"serial"`  // серийный номер, hex
+	Source  string   `json:"source"`  // safeBag, signer или chain (первое вхождение)
+	Roles   []string `json:"roles,omitempty"`
+	CertDER []byte   `json:"-"`
+}
+
+// CAPathEntries возвращает сертификаты для -CApath: сначала мешки SafeBags, затем подписант и цепочка SignedData.
+// Повторяющиеся сертификаты (по SHA-256 DER) объединяются, как c_rehash пропускает дубликаты; сертификаты
+// с одинаковым хешем subject получают номера .0, .1, … в порядке появления.
+func (c *Container) CAPathEntries() ([]CAPathEntry, error) {
+	var out []CAPathEntry
+	byCert := make(map[[32]byte]int)
+	hashCount := make(map[string]int)
+	usedNames := make(map[string]int)
+	add := func(der []byte, name, source, role string) error {
+		sum := sha256.Sum256(der)
+		if n, ok := byCert[sum]; ok {
+			if role != "" {
+				out[n].Roles = appendUnique(out[n].Roles, role)
+			}
+			return nil
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return err
+		}
+		hash, err := pki.SubjectHash(cert.RawSubject)
+		if err != nil {
+			return fmt.Errorf("%s: subject hash: %w", cert.Subject, err)
+		}
+		if n := usedNames[name]; n > 0 {
+			usedNames[name] = n + 1
+			name = fmt.Sprintf("%s-%d", name, n+1)
+		} else {
+			usedNames[name] = 1
+		}
+		e := CAPathEntry{
+			Link:    fmt.Sprintf("%s.%d", hash, hashCount[hash]),
+			File:    name + ".pem",
+			Subject: cert.Subject.String(),
+			Serial:  cert.SerialNumber.Text(16),
+			Source:  source,
+			CertDER: der,
+		}
+		if role != "" {
+			e.Roles = []string{role}
+		}
+		hashCount[hash]++
+		byCert[sum] = len(out)
+		out = append(out, e)
+		return nil
+	}
+	for i := range c.SafeBagInfos {
+		info := &c.SafeBagInfos[i]
+		if len(info.CertValueDER) == 0 {
+			continue
+		}
+		if err := add(info.CertValueDER, SafeBagExportBasename(info, i), CAPathSourceSafeBag, SafeBagRoleName(info)); err != nil {
+			return nil, fmt.Errorf("SafeBag[%d]: %w", i+1, err)
+		}
+	}
+	for i, cert := range c.Certificates {
+		serial := sanitizeExportBasename(cert.SerialNumber.Text(16))
+		name, source := "ca_"+serial, CAPathSourceChain
+		if c.isSignerCert(cert) {
+			name, source = "signer_"+serial, CAPathSourceSigner
+		}
+		if err := add(cert.Raw, name, source, ""); err != nil {
+			return nil, fmt.Errorf("certificate %d: %w", i+1, err)
+		}
+	}
+	return out, nil
+}
+
+// appendUnique добавляет s в list, если его там ещё нет.
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
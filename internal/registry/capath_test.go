@@ -0,0 +1,66 @@
+package registry
+
+import (
+	"crypto/x509"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sgw-registry/registry-analyzer/internal/pki"
+)
+
+// TestCAPathEntries проверяет раскладку для -CApath: повторный сертификат — одна запись со всеми ролями,
+// сертификаты с одинаковым subject — ссылки .0 и .1, подписант — signer_Serial.
+func TestCAPathEntries(t *testing.T) {
+	cert, key := newTestSigner(t)
+	a := newTestLeaf(t, cert, key, "Driver", 10)
+	b := newTestLeaf(t, cert, key, "Driver", 11)
+	now := time.Now().UTC().Truncate(time.Second)
+	der, err := BuildRegistry(cert, key, []SafeBagInput{
+		{CertDER: a, RoleName: "delegate"},
+		{CertDER: a, RoleName: "not_delegate"},
+		{CertDER: b, RoleName: "delegate"},
+	}, SignerAttrs{VIN: "TESTVIN123", VERTimestamp: now, VERVersion: 1, UID: "CN=Test"})
+	if err != nil {
+		t.Fatalf("BuildRegistry: %v", err)
+	}
+	c, err := Parse(der)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	entries, err := c.CAPathEntries()
+	if err != nil {
+		t.Fatalf("CAPathEntries: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("записей %d, ожидается 3 (два сертификата мешков и подписант): %+v", len(entries), entries)
+	}
+	leaf, err := x509.ParseCertificate(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := pki.SubjectHash(leaf.RawSubject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range entries[:2] {
+		if e.Source != CAPathSourceSafeBag || e.Link != fmt.Sprintf("%s.%d", hash, i) {
+			t.Errorf("мешок %d: %+v, ожидается ссылка %s.%d", i, e, hash, i)
+		}
+		want := "delegate"
+		if e.Serial == "a" {
+			want = "delegate,not_delegate"
+		}
+		roles := append([]string(nil), e.Roles...)
+		sort.Strings(roles)
+		if got := strings.Join(roles, ","); got != want {
+			t.Errorf("роли сертификата %s: %s, ожидается %s", e.Serial, got, want)
+		}
+	}
+	signer := entries[2]
+	if signer.Source != CAPathSourceSigner || signer.File != "signer_"+cert.SerialNumber.Text(16)+".pem" || len(signer.Roles) != 0 {
+		t.Errorf("подписант: %+v", signer)
+	}
+}
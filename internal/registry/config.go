@@ -0,0 +1,258 @@
+// config.go — JSON-конфиг registry-builder (Config) и обратное построение конфига из готового реестра (BuilderConfig).
+package registry
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"fmt"
+	"time"
+
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+	"github.com/sgw-registry/registry-analyzer/internal/pki"
+)
+
+// Значения-заглушки для полей подписанта в конфиге, восстановленном из реестра: ключ в контейнере не хранится.
+const (
+	PlaceholderSignerCert = "<signer-cert.pem>"
+	PlaceholderSignerKey  = "<signer-key.pem>"
+)
+
+// Config — конфигурация сборки реестра registry-builder (JSON).
+type Config struct {
+	SignerCert   string          `json:"signerCert"`
+	SignerKey    string          `json:"signerKey"`
+	VIN          string          `json:"vin"`
+	VERTimestamp string          `json:"verTimestamp"`
+	VERVersion   int             `json:"verVersion"`
+	UID          string          `json:"uid"`
+	SafeBags     []SafeBagConfig `json:"safeBags"`
+
+	// Дополнительные подписанные атрибуты SignerInfo.authenticatedAttributes [0].
+	SigningTime         string                  `json:"signingTime,omitempty"`         // RFC3339 или "now"
+	AlgorithmProtection bool                    `json:"algorithmProtection,omitempty"` // атрибут CMSAlgorithmProtection (RFC 6211)
+	SignedAttributes    []SignedAttributeConfig `json:"signedAttributes,omitempty"`
+
+	// Встроенный мини-CA: выпуск сертификатов ролей для мешков с полем issue (без OpenSSL).
+	CA       *CAConfig `json:"ca,omitempty"`
+	IssueDir string    `json:"issueDir,omitempty"` // куда записываются выпущенные ключи и сертификаты (по умолчанию issued/)
+}
+
+// SignedAttributeConfig — произвольный подписанный атрибут: OID, тип значения и значение (или несколько значений).
+// Name — справочное имя для читаемости конфига (в контейнер не записывается).
+type SignedAttributeConfig struct {
+	Name   string   `json:"name,omitempty"`
+	OID    string   `json:"oid"`
+	Type   string   `json:"type"` // utf8String, printableString, ia5String, integer, boolean, generalizedTime, utcTime, octetString, oid
+	Value  string   `json:"value,omitempty"`
+	Values []string `json:"values,omitempty"`
+}
+
+// SafeBagConfig — один мешок в конфиге: путь к сертификату (или описание выпуска issue) и атрибуты.
+// Cert — PEM сертификата или набор .p7b: тогда каждый сертификат набора становится мешком с этими атрибутами.
+type SafeBagConfig struct {
+	Cert          string       `json:"cert"`
+	RoleName      string       `json:"roleName"`
+	RoleNotBefore string       `json:"roleNotBefore"`
+	RoleNotAfter  string       `json:"roleNotAfter"`
+	LocalKeyID    string       `json:"localKeyID"` // hex
+	Issue         *IssueConfig `json:"issue,omitempty"`
+}
+
+// CAConfig — CA устройства, от которого builder выпускает сертификаты ролей (пути к PEM сертификата и ключа).
+type CAConfig struct {
+	Cert string `json:"cert"`
+	Key  string `json:"key"`
+}
+
+// IssueConfig — описание сертификата роли, выпускаемого при сборке (поле safeBags[].issue).
+// Subject — шаблон text/template с полями {{.RoleName}}, {{.Index}}, {{.VIN}}, {{.UID}}.
+// CSR — путь к запросу на сертификат: открытый ключ (и subject, если шаблон не задан) берутся из CSR, ключ не генерируется.
+// Name — базовое имя файлов в issueDir (<name>.pem, <name>-key.pem); по умолчанию — roleName и номер мешка.
+type IssueConfig struct {
+	pki.CertSpec
+	CSR  string `json:"csr,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// BuilderExport — конфиг registry-builder, восстановленный из реестра, и данные для выгрузки сертификатов мешков.
+// CertDER[i] и CertNames[i] (базовое имя PEM без расширения) соответствуют Config.SafeBags[i]; путь Cert заполняет вызывающий.
+// Warnings — то, что конфиг не передаёт (eContent при пересборке будет отличаться, атрибуты без аналога в конфиге).
+type BuilderExport struct {
+	Config    Config
+	CertDER   [][]byte
+	CertNames []string
+	Warnings  []string
+}
+
+// BuilderConfig строит конфиг registry-builder по содержимому реестра: roleName, roleNotBefore/roleNotAfter (RFC3339),
+// localKeyID (hex исходных байт) каждого мешка и VIN, VER, UID, signingTime, CMSAlgorithmProtection и прочие
+// подписанные атрибуты первого подписанта. Поля signerCert/signerKey — заглушки.
+// Конфиг проверяется пересборкой SafeContents: при расхождении с eContent добавляется предупреждение.
+func (c *Container) BuilderConfig() (*BuilderExport, error) {
+	exp := &BuilderExport{Config: Config{
+		SignerCert: PlaceholderSignerCert,
+		SignerKey:  PlaceholderSignerKey,
+		SafeBags:   []SafeBagConfig{},
+	}}
+	warn := func(format string, args ...interface{}) {
+		exp.Warnings = append(exp.Warnings, i18n.Sprintf(format, args...))
+	}
+
+	var inputs []SafeBagInput
+	usedNames := make(map[string]int)
+	for i, bag := range c.SafeBags {
+		info, err := ParseSafeBagInfo(bag)
+		if err != nil || len(info.CertValueDER) == 0 {
+			warn("safeBags[%d]: мешок %s без сертификата X.509 пропущен", i+1, info.BagType)
+			continue
+		}
+		sc, in := safeBagConfigFromBag(bag, i, warn)
+		in.CertDER = info.CertValueDER
+		exp.Config.SafeBags = append(exp.Config.SafeBags, sc)
+		inputs = append(inputs, in)
+
+		name := SafeBagExportBasename(&info, i)
+		if n := usedNames[name]; n > 0 {
+			usedNames[name] = n + 1
+			name = fmt.Sprintf("%s-%d", name, n+1)
+		} else {
+			usedNames[name] = 1
+		}
+		exp.CertDER = append(exp.CertDER, info.CertValueDER)
+		exp.CertNames = append(exp.CertNames, name)
+	}
+
+	if len(c.Signers) > 1 {
+		warn("в реестре %d подписантов: атрибуты берутся у первого", len(c.Signers))
+	}
+	if len(c.Signers) > 0 {
+		if err := signerConfigFromAttrs(&c.Signers[0], &exp.Config, warn); err != nil {
+			return nil, err
+		}
+	}
+
+	// Самопроверка: SafeContents из восстановленных значений должны совпасть с eContent байт в байт.
+	rebuilt, err := marshalSafeContents(inputs)
+	if err != nil {
+		return nil, fmt.Errorf("marshal SafeContents: %w", err)
+	}
+	if !bytes.Equal(rebuilt, c.EContent()) {
+		warn("eContent при пересборке по этому конфигу будет отличаться от исходного (порядок или кодирование атрибутов мешков)")
+	}
+	return exp, nil
+}
+
+// safeBagConfigFromBag переводит атрибуты мешка в SafeBagConfig и параллельно в SafeBagInput (для самопроверки).
+func safeBagConfigFromBag(bag SafeBag, index int, warn func(string, ...interface{})) (SafeBagConfig, SafeBagInput) {
+	var sc SafeBagConfig
+	var in SafeBagInput
+	for i, a := range sortAttributesByDER(bag.BagAttributes) {
+		if !a.AttrType.Equal(bag.BagAttributes[i].AttrType) {
+			warn("safeBags[%d]: атрибуты мешка не отсортированы по DER, builder их отсортирует (ADR-011)", index+1)
+			break
+		}
+	}
+	for _, a := range bag.BagAttributes {
+		if len(a.AttrValues) != 1 {
+			warn("safeBags[%d]: атрибут %s содержит %d значений, переносится первое", index+1, a.AttrType, len(a.AttrValues))
+		}
+		if len(a.AttrValues) == 0 {
+			continue
+		}
+		raw := a.AttrValues[0].FullBytes
+		switch {
+		case a.AttrType.Equal(OIDAtomRoleName):
+			var s string
+			if _, err := asn1.Unmarshal(raw, &s); err != nil {
+				warn("safeBags[%d]: roleName: %v", index+1, err)
+				continue
+			}
+			sc.RoleName, in.RoleName = s, s
+		case a.AttrType.Equal(OIDAtomRoleValidityPeriod):
+			var period struct {
+				NotBefore time.Time `asn1:"generalized"`
+				NotAfter  time.Time `asn1:"generalized"`
+			}
+			if _, err := asn1.Unmarshal(raw, &period); err != nil {
+				warn("safeBags[%d]: roleValidityPeriod: %v", index+1, err)
+				continue
+			}
+			in.RoleNotBefore, in.RoleNotAfter = period.NotBefore.UTC(), period.NotAfter.UTC()
+			sc.RoleNotBefore = in.RoleNotBefore.Format(time.RFC3339)
+			sc.RoleNotAfter = in.RoleNotAfter.Format(time.RFC3339)
+		case a.AttrType.Equal(OIDPKCS9LocalKeyID):
+			var id []byte
+			if _, err := asn1.Unmarshal(raw, &id); err != nil {
+				warn("safeBags[%d]: localKeyID: %v", index+1, err)
+				continue
+			}
+			sc.LocalKeyID, in.LocalKeyID = hexEncode(id), id
+		default:
+			name := OIDToAtomName(a.AttrType)
+			if name == "" {
+				name = a.AttrType.String()
+			}
+			warn("safeBags[%d]: атрибут мешка %s не задаётся конфигом и при пересборке не сохранится", index+1, name)
+		}
+	}
+	return sc, in
+}
+
+// signerConfigFromAttrs заполняет VIN, VER, UID, signingTime, algorithmProtection и signedAttributes из подписанных атрибутов.
+func signerConfigFromAttrs(si *SignerInfo, cfg *Config, warn func(string, ...interface{})) error {
+	attrs, err := SignerAttributes(si)
+	if err != nil {
+		return fmt.Errorf("signer attributes: %w", err)
+	}
+	for _, a := range attrs {
+		if len(a.AttrValues) == 0 {
+			continue
+		}
+		raw := a.AttrValues[0].FullBytes
+		switch {
+		case a.AttrType.Equal(OIDPKCS9ContentType), a.AttrType.Equal(OIDPKCS9MessageDigest):
+			// формируются builder'ом
+		case a.AttrType.Equal(OIDAtomVIN):
+			asn1.Unmarshal(raw, &cfg.VIN)
+		case a.AttrType.Equal(OIDAtomUID):
+			asn1.Unmarshal(raw, &cfg.UID)
+		case a.AttrType.Equal(OIDAtomVER):
+			var ver struct {
+				Ts time.Time `asn1:"generalized"`
+				V  int
+			}
+			if _, err := asn1.Unmarshal(raw, &ver); err != nil {
+				warn("VER: %v", err)
+				continue
+			}
+			cfg.VERTimestamp = ver.Ts.UTC().Format(time.RFC3339)
+			cfg.VERVersion = ver.V
+		case a.AttrType.Equal(OIDPKCS9SigningTime):
+			var t time.Time
+			if _, err := asn1.Unmarshal(raw, &t); err != nil {
+				warn("signingTime: %v", err)
+				continue
+			}
+			cfg.SigningTime = t.UTC().Format(time.RFC3339)
+		case a.AttrType.Equal(OIDCMSAlgorithmProtection):
+			cfg.AlgorithmProtection = true
+		default:
+			sa := SignedAttributeConfig{Name: OIDToAtomName(a.AttrType), OID: a.AttrType.String()}
+			for _, v := range a.AttrValues {
+				typ, value, ok := typedValueConfig(v.FullBytes)
+				if !ok || (sa.Type != "" && sa.Type != typ) {
+					sa.Type = ""
+					break
+				}
+				sa.Type = typ
+				sa.Values = append(sa.Values, value)
+			}
+			if sa.Type == "" {
+				warn("подписанный атрибут %s: тип значения не поддерживается конфигом, атрибут не перенесён", a.AttrType)
+				continue
+			}
+			cfg.SignedAttributes = append(cfg.SignedAttributes, sa)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+// TestBuilderConfigRoundTrip проверяет, что конфиг, восстановленный из реестра, при пересборке тем же ключом
+// даёт идентичный eContent, а атрибуты подписанта переносятся в поля конфига.
+func TestBuilderConfigRoundTrip(t *testing.T) {
+	cert, key := newTestSigner(t)
+	now := time.Date(2026, 1, 15, 17, 40, 20, 0, time.UTC)
+	safeBags := []SafeBagInput{
+		{CertDER: cert.Raw, RoleName: "delegate", RoleNotBefore: now, RoleNotAfter: now.AddDate(1, 0, 0), LocalKeyID: []byte{0x01, 0x93, 0x3b}},
+		{CertDER: cert.Raw, RoleName: "not_delegate"}, // localKeyID из SKID
+	}
+	attrs := SignerAttrs{
+		VIN: "TESTVIN123", VERTimestamp: now, VERVersion: 7, UID: "CN=Test",
+		SigningTime: now, AlgorithmProtection: true,
+		Custom: []CustomAttribute{{OID: "1.3.6.1.4.1.99999.2.1", Type: AttrTypeInteger, Values: []string{"42"}}},
+	}
+	der, err := BuildRegistry(cert, key, safeBags, attrs)
+	if err != nil {
+		t.Fatalf("BuildRegistry: %v", err)
+	}
+	c, err := Parse(der)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	exp, err := c.BuilderConfig()
+	if err != nil {
+		t.Fatalf("BuilderConfig: %v", err)
+	}
+	if len(exp.Warnings) != 0 {
+		t.Errorf("неожиданные предупреждения: %v", exp.Warnings)
+	}
+	cfg := exp.Config
+	if cfg.SignerKey != PlaceholderSignerKey || cfg.VIN != "TESTVIN123" || cfg.VERVersion != 7 || cfg.UID != "CN=Test" {
+		t.Errorf("атрибуты подписанта: %+v", cfg)
+	}
+	if cfg.SigningTime != "2026-01-15T17:40:20Z" || !cfg.AlgorithmProtection {
+		t.Errorf("signingTime=%q algorithmProtection=%v", cfg.SigningTime, cfg.AlgorithmProtection)
+	}
+	if len(cfg.SignedAttributes) != 1 || cfg.SignedAttributes[0].Type != AttrTypeInteger || cfg.SignedAttributes[0].Values[0] != "42" {
+		t.Errorf("signedAttributes: %+v", cfg.SignedAttributes)
+	}
+
+	// Пересборка по значениям конфига (как loadSafeBags в registry-builder).
+	var rebuilt []SafeBagInput
+	for i, sb := range cfg.SafeBags {
+		in := SafeBagInput{CertDER: exp.CertDER[i], RoleName: sb.RoleName}
+		in.LocalKeyID, _ = hex.DecodeString(sb.LocalKeyID)
+		if sb.RoleNotBefore != "" {
+			in.RoleNotBefore, _ = time.Parse(time.RFC3339, sb.RoleNotBefore)
+			in.RoleNotAfter, _ = time.Parse(time.RFC3339, sb.RoleNotAfter)
+		}
+		rebuilt = append(rebuilt, in)
+	}
+	der2, err := BuildRegistry(cert, key, rebuilt, attrs)
+	if err != nil {
+		t.Fatalf("BuildRegistry (rebuild): %v", err)
+	}
+	c2, err := Parse(der2)
+	if err != nil {
+		t.Fatalf("Parse (rebuild): %v", err)
+	}
+	if !bytes.Equal(c.EContent(), c2.EContent()) {
+		t.Error("eContent после пересборки отличается")
+	}
+}
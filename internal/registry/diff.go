@@ -0,0 +1,255 @@
+// diff.go — семантическое сравнение двух реестров (registry-diff): атрибуты подписанта и SafeBags.
+package registry
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+)
+
+// FieldChange — изменённое значение поля: атрибута подписанта или мешка.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// BagRef — мешок в одном из сравниваемых реестров: номер (с 1, как в отчёте), отпечаток сертификата и атрибуты.
+type BagRef struct {
+	Index       int    `json:"index"`
+	Fingerprint string `json:"fingerprint,omitempty"` // SHA-256 DER сертификата, hex
+	Subject     string `json:"subject,omitempty"`
+	Serial      string `json:"serial,omitempty"`
+	RoleName    string `json:"roleName,omitempty"`
+	LocalKeyID  string `json:"localKeyID,omitempty"`
+}
+
+// BagChange — мешок, найденный в обоих реестрах, с отличающимися полями.
+// MatchedBy — «fingerprint» (тот же сертификат) или «localKeyID» (тот же ключ, сертификат мог смениться).
+type BagChange struct {
+	Old       BagRef        `json:"old"`
+	New       BagRef        `json:"new"`
+	MatchedBy string        `json:"matchedBy"`
+	Changes   []FieldChange `json:"changes"`
+}
+
+// RegistryDiff — результат Diff: изменённые атрибуты подписанта, добавленные, удалённые и изменённые мешки.
+type RegistryDiff struct {
+	Attributes []FieldChange `json:"attributes"`
+	Added      []BagRef      `json:"addedBags"`
+	Removed    []BagRef      `json:"removedBags"`
+	Changed    []BagChange   `json:"changedBags"`
+}
+
+// Empty сообщает, что реестры семантически не различаются.
+func (d *RegistryDiff) Empty() bool {
+	return len(d.Attributes) == 0 && len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// diffBag — мешок с разобранным сертификатом для сопоставления.
+type diffBag struct {
+	ref                BagRef
+	roleValidityPeriod string
+	bagType            string // localKeyID сопоставляется только между мешками одного типа (ключ и сертификат делят его)
+	cert               *x509.Certificate
+}
+
+// Diff сравнивает реестры old и new семантически, без учёта порядка мешков и байтового представления.
+// Сравниваются VIN, VER, UID и сертификат первого подписанта. Мешки сопоставляются сначала по отпечатку сертификата,
+// оставшиеся — по localKeyID; для пары сравниваются roleName, roleValidityPeriod и сертификат, остальные мешки
+// попадают в добавленные или удалённые.
+func Diff(old, new *Container) *RegistryDiff {
+	d := &RegistryDiff{Attributes: []FieldChange{}, Added: []BagRef{}, Removed: []BagRef{}, Changed: []BagChange{}}
+	oldSum, newSum := old.Summary(), new.Summary()
+	for _, f := range []FieldChange{
+		{"VIN", oldSum.VIN, newSum.VIN},
+		{"VER", oldSum.VER, newSum.VER},
+		{"UID", oldSum.UID, newSum.UID},
+		{"signer", oldSum.Signer, newSum.Signer},
+		{"signerFingerprint", signerFingerprint(old), signerFingerprint(new)},
+	} {
+		if f.Old != f.New {
+			d.Attributes = append(d.Attributes, f)
+		}
+	}
+
+	oldBags, newBags := diffBags(old), diffBags(new)
+	matchedOld := make([]bool, len(oldBags))
+	matchedNew := make([]bool, len(newBags))
+	match := func(by string, key func(*diffBag) string) {
+		for i := range oldBags {
+			if matchedOld[i] || key(&oldBags[i]) == "" {
+				continue
+			}
+			for j := range newBags {
+				if matchedNew[j] || key(&oldBags[i]) != key(&newBags[j]) {
+					continue
+				}
+				matchedOld[i], matchedNew[j] = true, true
+				if changes := compareBags(&oldBags[i], &newBags[j]); len(changes) > 0 {
+					d.Changed = append(d.Changed, BagChange{Old: oldBags[i].ref, New: newBags[j].ref, MatchedBy: by, Changes: changes})
+				}
+				break
+			}
+		}
+	}
+	match("fingerprint", func(b *diffBag) string { return b.ref.Fingerprint })
+	match("localKeyID", func(b *diffBag) string {
+		if b.ref.LocalKeyID == "" {
+			return ""
+		}
+		return b.bagType + "/" + b.ref.LocalKeyID
+	})
+	for i, b := range oldBags {
+		if !matchedOld[i] {
+			d.Removed = append(d.Removed, b.ref)
+		}
+	}
+	for j, b := range newBags {
+		if !matchedNew[j] {
+			d.Added = append(d.Added, b.ref)
+		}
+	}
+	return d
+}
+
+// certFingerprint возвращает SHA-256 от DER сертификата в hex (пусто для пустого DER).
+func certFingerprint(der []byte) string {
+	if len(der) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+func signerFingerprint(c *Container) string {
+	if len(c.Signers) == 0 {
+		return ""
+	}
+	if cert := c.SignerCert(&c.Signers[0]); cert != nil {
+		return certFingerprint(cert.Raw)
+	}
+	return ""
+}
+
+func diffBags(c *Container) []diffBag {
+	bags := make([]diffBag, len(c.SafeBagInfos))
+	for i := range c.SafeBagInfos {
+		info := &c.SafeBagInfos[i]
+		b := &bags[i]
+		b.ref = BagRef{
+			Index:       i + 1,
+			Fingerprint: certFingerprint(info.CertValueDER),
+			RoleName:    SafeBagRoleName(info),
+			LocalKeyID:  safeBagAttr(info, "localKeyID"),
+		}
+		b.roleValidityPeriod = safeBagAttr(info, "roleValidityPeriod")
+		b.bagType = info.BagType
+		if info.CertSummary != nil {
+			b.ref.Subject, b.ref.Serial = info.CertSummary.Subject, info.CertSummary.Serial
+		}
+		if cert, err := x509.ParseCertificate(info.CertValueDER); err == nil {
+			b.cert = cert
+		}
+	}
+	return bags
+}
+
+// safeBagAttr возвращает первое значение атрибута мешка с данным именем (см. OIDToAtomName).
+func safeBagAttr(info *SafeBagInfo, name string) string {
+	for _, a := range info.BagAttributes {
+		if a.Name == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func compareBags(o, n *diffBag) []FieldChange {
+	var changes []FieldChange
+	add := func(field, ov, nv string) {
+		if ov != nv {
+			changes = append(changes, FieldChange{field, ov, nv})
+		}
+	}
+	add("roleName", o.ref.RoleName, n.ref.RoleName)
+	add("roleValidityPeriod", o.roleValidityPeriod, n.roleValidityPeriod)
+	add("localKeyID", o.ref.LocalKeyID, n.ref.LocalKeyID)
+	if o.ref.Fingerprint != n.ref.Fingerprint {
+		add("certificate", o.ref.Fingerprint, n.ref.Fingerprint)
+		add("subject", o.ref.Subject, n.ref.Subject)
+		add("serial", o.ref.Serial, n.ref.Serial)
+		if o.cert != nil && n.cert != nil {
+			add("notAfter", o.cert.NotAfter.UTC().Format("2006-01-02 15:04:05"), n.cert.NotAfter.UTC().Format("2006-01-02 15:04:05"))
+		}
+	}
+	return changes
+}
+
+// String — краткое описание мешка для текстового отчёта: номер, roleName, subject и serial.
+func (b BagRef) String() string {
+	parts := []string{fmt.Sprintf("#%d", b.Index)}
+	if b.RoleName != "" {
+		parts = append(parts, "roleName="+b.RoleName)
+	}
+	if b.Subject != "" {
+		parts = append(parts, b.Subject)
+	}
+	if b.Serial != "" {
+		parts = append(parts, "serial "+b.Serial)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// TextOutput выводит результат сравнения в sb; при useColor — с цветами и иконками (как VerificationTextOutput).
+func (d *RegistryDiff) TextOutput(sb *strings.Builder, useColor bool) {
+	c := func(code string) string {
+		if useColor {
+			return code
+		}
+		return ""
+	}
+	if useColor {
+		sb.WriteString("\n" + Bold + IconPFX + " " + i18n.T("Сравнение реестров") + Reset + "\n")
+	} else {
+		sb.WriteString("\n=== " + i18n.T("Сравнение реестров") + " ===\n")
+	}
+	if d.Empty() {
+		sb.WriteString("  " + c(Green) + i18n.T("отличий нет") + c(Reset) + "\n")
+		return
+	}
+	change := func(indent string, f FieldChange) {
+		sb.WriteString(fmt.Sprintf("%s%s: %s%s%s → %s%s%s\n", indent, f.Field, c(Red), f.Old, c(Reset), c(Green), f.New, c(Reset)))
+	}
+	if len(d.Attributes) > 0 {
+		sb.WriteString("  " + i18n.T("Подписант и атрибуты:") + "\n")
+		for _, f := range d.Attributes {
+			change("    ~ ", f)
+		}
+	}
+	if len(d.Removed) > 0 {
+		sb.WriteString(i18n.Sprintf("  Удалённые мешки: %d\n", len(d.Removed)))
+		for _, b := range d.Removed {
+			sb.WriteString(fmt.Sprintf("    %s- %s%s\n", c(Red), b, c(Reset)))
+		}
+	}
+	if len(d.Added) > 0 {
+		sb.WriteString(i18n.Sprintf("  Добавленные мешки: %d\n", len(d.Added)))
+		for _, b := range d.Added {
+			sb.WriteString(fmt.Sprintf("    %s+ %s%s\n", c(Green), b, c(Reset)))
+		}
+	}
+	if len(d.Changed) > 0 {
+		sb.WriteString(i18n.Sprintf("  Изменённые мешки: %d\n", len(d.Changed)))
+		for _, ch := range d.Changed {
+			sb.WriteString(i18n.Sprintf("    %s~ %s%s → #%d (по %s)\n", c(Yellow), ch.Old, c(Reset), ch.New.Index, ch.MatchedBy))
+			for _, f := range ch.Changes {
+				change("        ", f)
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package registry
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestLeaf выпускает сертификат роли от подписанта (DER).
+func newTestLeaf(t *testing.T, issuer *x509.Certificate, issuerKey *ecdsa.PrivateKey, cn string, serial int64) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		Subject:      pkix.Name{CommonName: cn},
+		SerialNumber: big.NewInt(serial),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}, issuer, &key.PublicKey, issuerKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	return der
+}
+
+// TestDiff проверяет сопоставление мешков по отпечатку и localKeyID, добавленные/удалённые мешки и смену VER.
+func TestDiff(t *testing.T) {
+	cert, key := newTestSigner(t)
+	driver := newTestLeaf(t, cert, key, "Driver", 10)
+	ivi := newTestLeaf(t, cert, key, "IVI", 11)
+	iviRenewed := newTestLeaf(t, cert, key, "IVI", 12)
+	passenger := newTestLeaf(t, cert, key, "Passenger", 13)
+	mobile := newTestLeaf(t, cert, key, "Mobile", 14)
+	nb := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	na := nb.AddDate(1, 0, 0)
+	build := func(version int, bags []SafeBagInput) *Container {
+		t.Helper()
+		ts := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		der, err := BuildRegistry(cert, key, bags, SignerAttrs{VIN: "TESTVIN123", VERTimestamp: ts, VERVersion: version, UID: "CN=Test"})
+		if err != nil {
+			t.Fatalf("BuildRegistry: %v", err)
+		}
+		c, err := Parse(der)
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		return c
+	}
+	old := build(7, []SafeBagInput{
+		{CertDER: driver, RoleName: "delegate", RoleNotBefore: nb, RoleNotAfter: na, LocalKeyID: []byte{1}},
+		{CertDER: ivi, RoleName: "delegate", LocalKeyID: []byte{2}},
+		{CertDER: passenger, RoleName: "not_delegate", LocalKeyID: []byte{3}},
+	})
+	// Порядок мешков меняется: сопоставление не должно от него зависеть.
+	new := build(8, []SafeBagInput{
+		{CertDER: mobile, RoleName: "driver-mobile", LocalKeyID: []byte{4}},
+		{CertDER: iviRenewed, RoleName: "delegate", LocalKeyID: []byte{2}},
+		{CertDER: driver, RoleName: "delegate", RoleNotBefore: nb, RoleNotAfter: na.AddDate(1, 0, 0), LocalKeyID: []byte{1}},
+	})
+
+	if d := Diff(old, old); !d.Empty() {
+		t.Fatalf("реестр отличается сам от себя: %+v", d)
+	}
+	d := Diff(old, new)
+	if len(d.Attributes) != 1 || d.Attributes[0].Field != "VER" {
+		t.Errorf("атрибуты: %+v, ожидается только VER", d.Attributes)
+	}
+	if len(d.Removed) != 1 || d.Removed[0].Subject != "CN=Passenger" {
+		t.Errorf("удалённые: %+v", d.Removed)
+	}
+	if len(d.Added) != 1 || d.Added[0].Subject != "CN=Mobile" || d.Added[0].Index != 1 {
+		t.Errorf("добавленные: %+v", d.Added)
+	}
+	if len(d.Changed) != 2 {
+		t.Fatalf("изменённые: %+v, ожидается 2", d.Changed)
+	}
+	byFP, byKey := d.Changed[0], d.Changed[1]
+	if byFP.MatchedBy != "fingerprint" || byFP.Old.Index != 1 || byFP.New.Index != 3 ||
+		len(byFP.Changes) != 1 || byFP.Changes[0].Field != "roleValidityPeriod" {
+		t.Errorf("Driver: %+v", byFP)
+	}
+	fields := make([]string, 0, len(byKey.Changes))
+	for _, f := range byKey.Changes {
+		fields = append(fields, f.Field)
+	}
+	if byKey.MatchedBy != "localKeyID" || strings.Join(fields, ",") != "certificate,serial" {
+		t.Errorf("IVI: сопоставлен по %s, изменены %v; ожидается localKeyID и certificate,serial", byKey.MatchedBy, fields)
+	}
+
+	var sb strings.Builder
+	d.TextOutput(&sb, false)
+	for _, want := range []string{"=== Сравнение реестров ===", "- #3, roleName=not_delegate, CN=Passenger", "+ #1, roleName=driver-mobile", "(по localKeyID)"} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("в выводе нет %q:\n%s", want, sb.String())
+		}
+	}
+}
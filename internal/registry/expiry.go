@@ -0,0 +1,82 @@
+// expiry.go — сроки для мониторинга (-expiring-within): сертификаты подписанта, цепочки и мешков
+// и roleValidityPeriod, истёкшие или истекающие в пределах окна.
+package registry
+
+import (
+	"crypto/x509"
+	"sort"
+	"time"
+)
+
+// Источники сроков для ExpiryItem.Kind.
+const (
+	ExpirySigner  = "signer"  // сертификат подписанта контейнера
+	ExpiryChain   = "chain"   // остальные сертификаты SignedData.certificates (CA)
+	ExpirySafeBag = "safeBag" // сертификат мешка
+	ExpiryRole    = "role"    // roleValidityPeriod мешка
+)
+
+// Статусы ExpiryItem.Status.
+const (
+	ExpiryExpired  = "expired"  // срок уже истёк
+	ExpiryExpiring = "expiring" // срок истекает в пределах окна
+)
+
+// ExpiryItem — сертификат или период роли, срок которого истёк или истекает в пределах окна (Expiring).
+type ExpiryItem struct {
+	Kind     string    `json:"kind"`
+	Index    int       `json:"index"` // номер сертификата в SignedData или мешка (с 1)
+	Subject  string    `json:"subject,omitempty"`
+	Serial   string    `json:"serial,omitempty"`
+	Role     string    `json:"role,omitempty"` // roleName мешка (для safeBag и role)
+	NotAfter time.Time `json:"notAfter"`
+	Status   string    `json:"status"`
+}
+
+// Expiring возвращает сертификат подписанта, цепочку, сертификаты мешков и roleValidityPeriod, срок которых
+// истекает не позже now+within (истёкшие — со статусом ExpiryExpired), по возрастанию даты.
+func (c *Container) Expiring(now time.Time, within time.Duration) []ExpiryItem {
+	deadline := now.Add(within)
+	var out []ExpiryItem
+	add := func(item ExpiryItem) {
+		if item.NotAfter.After(deadline) {
+			return
+		}
+		item.Status = ExpiryExpiring
+		if item.NotAfter.Before(now) {
+			item.Status = ExpiryExpired
+		}
+		out = append(out, item)
+	}
+	certItem := func(kind string, index int, cert *x509.Certificate) ExpiryItem {
+		return ExpiryItem{Kind: kind, Index: index, Subject: cert.Subject.String(), Serial: cert.SerialNumber.Text(16), NotAfter: cert.NotAfter}
+	}
+	for i, cert := range c.Certificates {
+		if c.Kind == KindKeystore {
+			break // сертификаты хранилища — это сертификаты мешков, они учтены ниже
+		}
+		kind := ExpiryChain
+		if c.isSignerCert(cert) {
+			kind = ExpirySigner
+		}
+		add(certItem(kind, i+1, cert))
+	}
+	for i := range c.SafeBagInfos {
+		info := &c.SafeBagInfos[i]
+		role := SafeBagRoleName(info)
+		var subject string
+		if len(info.CertValueDER) > 0 {
+			if cert, err := x509.ParseCertificate(info.CertValueDER); err == nil {
+				item := certItem(ExpirySafeBag, i+1, cert)
+				item.Role = role
+				add(item)
+				subject = item.Subject
+			}
+		}
+		if !info.RoleNotAfter.IsZero() {
+			add(ExpiryItem{Kind: ExpiryRole, Index: i + 1, Subject: subject, Role: role, NotAfter: info.RoleNotAfter})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].NotAfter.Before(out[j].NotAfter) })
+	return out
+}
@@ -0,0 +1,47 @@
+package registry
+
+import (
+	"testing"
+	"time"
+)
+
+// TestExpiring проверяет отбор по окну, статусы и сортировку: истёкшая роль, сертификаты подписанта и мешка
+// (истекают через сутки), роль за пределами окна не попадает.
+func TestExpiring(t *testing.T) {
+	cert, key := newTestSigner(t)
+	driver := newTestLeaf(t, cert, key, "Driver", 10)
+	now := time.Now().UTC().Truncate(time.Second)
+	der, err := BuildRegistry(cert, key, []SafeBagInput{
+		{CertDER: driver, RoleName: "driver", RoleNotBefore: now.Add(-48 * time.Hour), RoleNotAfter: now.Add(-time.Hour)},
+		{CertDER: driver, RoleName: "later", RoleNotBefore: now, RoleNotAfter: now.Add(90 * 24 * time.Hour)},
+	}, SignerAttrs{VIN: "TESTVIN123", VERTimestamp: now, VERVersion: 1, UID: "CN=Test"})
+	if err != nil {
+		t.Fatalf("BuildRegistry: %v", err)
+	}
+	c, err := Parse(der)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	items := c.Expiring(now, 30*24*time.Hour)
+	want := []struct{ kind, status, role string }{
+		{ExpiryRole, ExpiryExpired, "driver"},
+		{ExpirySigner, ExpiryExpiring, ""},
+		{ExpirySafeBag, ExpiryExpiring, "driver"},
+		{ExpirySafeBag, ExpiryExpiring, "later"},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("элементов %d, ожидается %d: %+v", len(items), len(want), items)
+	}
+	for i, w := range want {
+		if items[i].Kind != w.kind || items[i].Status != w.status || items[i].Role != w.role {
+			t.Errorf("[%d] = %+v, ожидается %+v", i, items[i], w)
+		}
+		if i > 0 && items[i].NotAfter.Before(items[i-1].NotAfter) {
+			t.Errorf("[%d] не отсортирован по дате", i)
+		}
+	}
+	if items := c.Expiring(now, time.Hour); len(items) != 1 || items[0].Kind != ExpiryRole {
+		t.Errorf("окно 1h: %+v", items)
+	}
+}
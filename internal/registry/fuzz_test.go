@@ -0,0 +1,122 @@
+package registry
+
+import (
+	"encoding/asn1"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Fuzz-цели разборщика. Корпус засевается образцами .p12 из корня репозитория и их частями (мешки, атрибуты).
+// Запуск: go test -run='^$' -fuzz=FuzzParse -fuzztime=30s ./internal/registry
+
+// sampleContainers разбирает образцы .p12 из корня репозитория (отсутствующие пропускаются).
+func sampleContainers(f *testing.F) (raw [][]byte, containers []*Container) {
+	paths, _ := filepath.Glob(filepath.Join("..", "..", "*.p12"))
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		raw = append(raw, data)
+		if c, err := Parse(data); err == nil {
+			containers = append(containers, c)
+		}
+	}
+	return raw, containers
+}
+
+// FuzzParse: Parse не должен паниковать, а разобранный контейнер — ронять вывод анализатора.
+func FuzzParse(f *testing.F) {
+	raw, _ := sampleContainers(f)
+	for _, data := range raw {
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		c, err := Parse(data)
+		if err != nil {
+			return
+		}
+		var sb strings.Builder
+		c.TextOutput(&sb, false)
+		_, _ = c.ToJSON()
+		_ = c.Verify(VerifyOptions{})
+		_, _ = c.BuilderConfig()
+	})
+}
+
+// FuzzParseSafeBagInfo засевается DER отдельных мешков SafeBag из образцов.
+func FuzzParseSafeBagInfo(f *testing.F) {
+	_, containers := sampleContainers(f)
+	for _, c := range containers {
+		for _, bag := range c.SafeBags {
+			if b, err := asn1.Marshal(bag); err == nil {
+				f.Add(b)
+			}
+		}
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var bag SafeBag
+		if _, err := asn1.Unmarshal(data, &bag); err != nil {
+			return
+		}
+		info, err := ParseSafeBagInfo(bag)
+		if err != nil {
+			return
+		}
+		_ = SafeBagRoleName(&info)
+		_ = SafeBagExportBasename(&info, 1)
+	})
+}
+
+// FuzzSignerAttributes засевается authenticatedAttributes подписантов из образцов.
+func FuzzSignerAttributes(f *testing.F) {
+	_, containers := sampleContainers(f)
+	for _, c := range containers {
+		for _, si := range c.Signers {
+			f.Add(si.AuthenticatedAttributes.Bytes)
+		}
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		si := &SignerInfo{AuthenticatedAttributes: asn1.RawValue{Bytes: data}}
+		attrs, err := SignerAttributes(si)
+		if err != nil {
+			return
+		}
+		for _, a := range attrs {
+			_ = DecodeAttributeValues(a)
+		}
+		_ = SignerRoleName(si)
+	})
+}
+
+// FuzzDecodeAttributeValues засевается DER отдельных атрибутов подписантов и мешков из образцов.
+func FuzzDecodeAttributeValues(f *testing.F) {
+	_, containers := sampleContainers(f)
+	for _, c := range containers {
+		for i := range c.Signers {
+			attrs, _ := SignerAttributes(&c.Signers[i])
+			for _, a := range attrs {
+				if b, err := asn1.Marshal(a); err == nil {
+					f.Add(b)
+				}
+			}
+		}
+		for _, bag := range c.SafeBags {
+			for _, a := range bag.BagAttributes {
+				if b, err := asn1.Marshal(a); err == nil {
+					f.Add(b)
+				}
+			}
+		}
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var a Attribute
+		if _, err := asn1.Unmarshal(data, &a); err != nil {
+			return
+		}
+		_ = DecodeAttributeValues(a)
+		_ = DecodeBagAttributeValues(a)
+	})
+}
@@ -0,0 +1,273 @@
+// html.go — модель HTML-отчёта по контейнеру (-format html): те же секции, что в TextOutput, плюс статус ролей и проверка подписи.
+package registry
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sgw-registry/registry-analyzer/internal/htmlreport"
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+)
+
+// roleStatusHTML — подпись и статус значка для SafeBagInfo.RoleStatus.
+var roleStatusHTML = map[string]struct{ label, status string }{
+	RoleStatusNone:    {"—", htmlreport.StatusNone},
+	RoleStatusActive:  {"действует", htmlreport.StatusOK},
+	RoleStatusNotYet:  {"ещё не действует", htmlreport.StatusWarn},
+	RoleStatusExpired: {"истекла", htmlreport.StatusFail},
+}
+
+// HTMLReport собирает страницу отчёта: PFX, SignedData, сертификаты, мешки со статусом роли на момент now,
+// подписанты с расшифрованными атрибутами (у хранилища PKCS#12 вместо подписантов — MAC и AuthenticatedSafe) и, если verification не nil, результаты проверки подписи (-verify).
+func (c *Container) HTMLReport(title string, verification []SignerVerification, now time.Time) *htmlreport.Page {
+	p := &htmlreport.Page{
+		Title:     i18n.T("Реестр ATOM-PKCS12-REGISTRY"),
+		Subtitle:  title,
+		Generated: now.Format("2006-01-02 15:04:05 MST"),
+	}
+	pfx := htmlreport.Section{
+		Title: "PFX",
+		Open:  true,
+		Fields: []htmlreport.Field{
+			{Name: "Kind", Value: KindName(c.Kind)},
+			{Name: "Version", Value: fmt.Sprint(c.PFXVersion)},
+			{Name: "ContentType", Value: c.ContentType.String(), Mono: true},
+		},
+	}
+	if c.Kind == KindKeystore {
+		p.Title = i18n.T("Хранилище PKCS#12")
+		mac := htmlreport.Field{Name: "MAC", Value: c.macText(false), Status: htmlreport.StatusOK}
+		switch {
+		case c.Mac != nil && c.Mac.Status == MacStatusSkipped:
+			mac.Status = htmlreport.StatusNone
+		case !c.MacVerified():
+			mac.Status = htmlreport.StatusFail
+		}
+		pfx.Fields = append(pfx.Fields, mac)
+	}
+	p.Sections = append(p.Sections, pfx)
+	if c.SignedData != nil {
+		p.Sections = append(p.Sections, c.signedDataHTML())
+	}
+	if c.Kind == KindKeystore {
+		p.Sections = append(p.Sections, c.authSafeHTML(), c.certificatesHTML(now), c.safeBagsHTML(now))
+	} else {
+		p.Sections = append(p.Sections, c.certificatesHTML(now), c.safeBagsHTML(now), c.signersHTML())
+	}
+	if verification != nil {
+		p.Sections = append(p.Sections, verificationHTML(verification))
+	}
+	return p
+}
+
+func (c *Container) signedDataHTML() htmlreport.Section {
+	sd := c.SignedData
+	s := htmlreport.Section{Title: "SignedData", Fields: []htmlreport.Field{{Name: "Version", Value: fmt.Sprint(sd.Version)}}}
+	for _, alg := range sd.DigestAlgorithms {
+		s.Fields = append(s.Fields, htmlreport.Field{Name: "DigestAlgorithm", Value: alg.Algorithm.String(), Mono: true})
+	}
+	s.Fields = append(s.Fields,
+		htmlreport.Field{Name: "EContentType", Value: sd.EncapContentInfo.EContentType.String(), Mono: true},
+		htmlreport.Field{Name: "EContent", Value: i18n.Sprintf("%d байт", len(sd.EncapContentInfo.EContent.Bytes))},
+		htmlreport.Field{Name: "Certificates", Value: fmt.Sprint(len(c.Certificates))},
+		htmlreport.Field{Name: "SafeBags", Value: fmt.Sprint(len(c.SafeBagInfos))},
+		htmlreport.Field{Name: "SignerInfos", Value: fmt.Sprint(len(sd.SignerInfos))},
+	)
+	return s
+}
+
+// certValidityStatus — подсветка срока действия сертификата на момент now.
+func certValidityStatus(cert *x509.Certificate, now time.Time) string {
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return htmlreport.StatusFail
+	}
+	return htmlreport.StatusNone
+}
+
+// authSafeHTML — ContentInfo из AuthenticatedSafe хранилища PKCS#12.
+func (c *Container) authSafeHTML() htmlreport.Section {
+	s := htmlreport.Section{Title: "AuthenticatedSafe", Badge: fmt.Sprint(len(c.AuthSafe)), Open: true}
+	for i, as := range c.AuthSafe {
+		sub := htmlreport.Section{
+			Title:  fmt.Sprintf("[%d] %s", i+1, as.ContentType),
+			Fields: []htmlreport.Field{{Name: "SafeBags", Value: fmt.Sprint(as.SafeBags)}},
+		}
+		if as.EncryptionAlgorithm != "" {
+			sub.Fields = append(sub.Fields, htmlreport.Field{Name: "Encryption", Value: as.EncryptionAlgorithm})
+		}
+		if as.Error != "" {
+			sub.Badge, sub.Status = i18n.T("не расшифровано"), htmlreport.StatusFail
+			sub.Fields = append(sub.Fields, htmlreport.Field{Name: "error", Value: as.Error, Status: htmlreport.StatusFail})
+		}
+		s.Sections = append(s.Sections, sub)
+	}
+	return s
+}
+
+func (c *Container) certificatesHTML(now time.Time) htmlreport.Section {
+	s := htmlreport.Section{Title: "Certificates", Badge: fmt.Sprint(len(c.Certificates))}
+	for i, cert := range c.Certificates {
+		sub := htmlreport.Section{
+			Title: fmt.Sprintf("[%d] %s", i+1, cert.Subject.String()),
+			Fields: []htmlreport.Field{
+				{Name: "Issuer", Value: cert.Issuer.String()},
+				{Name: "Serial", Value: cert.SerialNumber.Text(16), Mono: true},
+				{Name: "Valid", Value: cert.NotBefore.Format("2006-01-02") + " — " + cert.NotAfter.Format("2006-01-02"), Status: certValidityStatus(cert, now)},
+				{Name: "KeyAlg", Value: cert.PublicKeyAlgorithm.String()},
+			},
+		}
+		if len(cert.SubjectKeyId) > 0 {
+			sub.Fields = append(sub.Fields, htmlreport.Field{Name: "SubjectKeyId", Value: hexEncode(cert.SubjectKeyId), Mono: true})
+		}
+		if c.isSignerCert(cert) {
+			sub.Badge, sub.Status = i18n.T("подписант контейнера"), htmlreport.StatusOK
+		}
+		s.Sections = append(s.Sections, sub)
+	}
+	return s
+}
+
+func (c *Container) safeBagsHTML(now time.Time) htmlreport.Section {
+	s := htmlreport.Section{Title: "SafeContents (eContent)", Badge: fmt.Sprint(len(c.SafeBagInfos)), Open: true}
+	if len(c.SafeBagInfos) == 0 {
+		return s
+	}
+	// Сводная таблица ролей: статус по roleValidityPeriod на момент now.
+	roles := htmlreport.Table{
+		Caption: i18n.T("Роли на") + " " + now.Format("2006-01-02 15:04"),
+		Header:  []string{"#", "roleName", i18n.T("Статус"), "roleValidityPeriod", "Subject", "Serial"},
+	}
+	for i := range c.SafeBagInfos {
+		info := &c.SafeBagInfos[i]
+		st := roleStatusHTML[info.RoleStatus(now)]
+		period := "—"
+		if !info.RoleNotAfter.IsZero() {
+			period = info.RoleNotBefore.Format("2006-01-02 15:04:05") + " — " + info.RoleNotAfter.Format("2006-01-02 15:04:05")
+		}
+		subject, serial := "—", "—"
+		if info.CertSummary != nil {
+			subject, serial = info.CertSummary.Subject, info.CertSummary.Serial
+		}
+		roles.Rows = append(roles.Rows, htmlreport.Row{
+			Cells:  []string{fmt.Sprint(i + 1), SafeBagRoleName(info), i18n.T(st.label), period, subject, serial},
+			Status: st.status,
+		})
+	}
+	s.Tables = append(s.Tables, roles)
+
+	for i := range c.SafeBagInfos {
+		s.Sections = append(s.Sections, safeBagHTML(fmt.Sprintf("[%d]", i+1), &c.SafeBagInfos[i], now))
+	}
+	return s
+}
+
+// safeBagHTML — подсекция одного мешка; вложенные мешки safeContentsBag — вложенными подсекциями.
+func safeBagHTML(label string, info *SafeBagInfo, now time.Time) htmlreport.Section {
+	title := SafeBagRoleName(info)
+	if len(info.CertId) == 0 {
+		title = strings.TrimSpace(info.BagType + " " + title)
+	}
+	sub := htmlreport.Section{
+		Title:  label + " " + title,
+		Fields: []htmlreport.Field{{Name: "bagId", Value: info.BagId.String(), Mono: true}},
+	}
+	if st := roleStatusHTML[info.RoleStatus(now)]; st.status != htmlreport.StatusNone {
+		sub.Badge, sub.Status = i18n.T(st.label), st.status
+	}
+	if info.Error != "" {
+		sub.Badge, sub.Status = i18n.T("ошибка разбора"), htmlreport.StatusFail
+	}
+	if len(info.CertId) > 0 {
+		sub.Fields = append(sub.Fields, htmlreport.Field{Name: "certId", Value: fmt.Sprintf("%s (%s)", info.CertId, info.CertType), Mono: true})
+		if cs := info.CertSummary; cs != nil {
+			sub.Fields = append(sub.Fields,
+				htmlreport.Field{Name: "Subject", Value: cs.Subject},
+				htmlreport.Field{Name: "Issuer", Value: cs.Issuer},
+				htmlreport.Field{Name: "Serial", Value: cs.Serial, Mono: true},
+				htmlreport.Field{Name: "Valid", Value: cs.NotBefore + " — " + cs.NotAfter},
+				htmlreport.Field{Name: "KeyAlg", Value: cs.KeyAlg},
+			)
+		} else {
+			sub.Fields = append(sub.Fields, htmlreport.Field{Name: "certValue", Value: i18n.Sprintf("%d байт (не X.509)", info.CertValueLen)})
+		}
+	} else {
+		sub.Fields = append(sub.Fields, htmlreport.Field{Name: "bagType", Value: info.BagType})
+		for _, f := range info.BagValueFields() {
+			field := htmlreport.Field{Name: f.Name, Value: f.Value}
+			if f.Name == "error" {
+				field.Status = htmlreport.StatusFail
+			}
+			sub.Fields = append(sub.Fields, field)
+		}
+	}
+	for _, attr := range info.BagAttributes {
+		sub.Fields = append(sub.Fields, htmlreport.Field{Name: attr.Name, Value: attr.Value, Mono: true})
+	}
+	for j := range info.Nested {
+		sub.Sections = append(sub.Sections, safeBagHTML(fmt.Sprintf("%s[%d]", label, j+1), &info.Nested[j], now))
+	}
+	return sub
+}
+
+func (c *Container) signersHTML() htmlreport.Section {
+	s := htmlreport.Section{Title: "Signers and ATOM attributes", Badge: fmt.Sprint(len(c.Signers)), Open: true}
+	for i := range c.Signers {
+		si := &c.Signers[i]
+		sub := htmlreport.Section{Title: fmt.Sprintf("Signer [%d]", i+1), Open: true}
+		if cert := c.SignerCert(si); cert != nil {
+			sub.Title += " " + cert.Subject.String()
+			sub.Fields = append(sub.Fields,
+				htmlreport.Field{Name: "Serial", Value: cert.SerialNumber.Text(16), Mono: true},
+				htmlreport.Field{Name: "KeyAlg", Value: cert.PublicKeyAlgorithm.String()},
+			)
+		} else {
+			sub.Badge, sub.Status = i18n.T("сертификат не найден"), htmlreport.StatusFail
+		}
+		sub.Fields = append(sub.Fields,
+			htmlreport.Field{Name: "DigestAlgorithm", Value: si.DigestAlgorithm.Algorithm.String(), Mono: true},
+			htmlreport.Field{Name: "SignatureAlgorithm", Value: si.DigestEncryptionAlgorithm.Algorithm.String(), Mono: true},
+		)
+		attrs, err := SignerAttributes(si)
+		if err != nil {
+			sub.Fields = append(sub.Fields, htmlreport.Field{Name: "attributes", Value: i18n.T("ошибка разбора") + ": " + err.Error(), Status: htmlreport.StatusFail})
+		}
+		for _, a := range attrs {
+			for _, v := range DecodeAttributeValues(a) {
+				disp := v.Value
+				if disp == "" {
+					disp = v.Raw
+				}
+				if disp != "" {
+					sub.Fields = append(sub.Fields, htmlreport.Field{Name: v.Name, Value: disp, Mono: true})
+				}
+			}
+		}
+		s.Sections = append(s.Sections, sub)
+	}
+	return s
+}
+
+func verificationHTML(results []SignerVerification) htmlreport.Section {
+	s := htmlreport.Section{Title: i18n.T("Проверка подписи"), Open: true, Badge: "OK", Status: htmlreport.StatusOK}
+	if !VerifyOK(results) {
+		s.Badge, s.Status = "FAILED", htmlreport.StatusFail
+	}
+	if len(results) == 0 {
+		s.Fields = append(s.Fields, htmlreport.Field{Name: i18n.T("Подписанты"), Value: i18n.T("отсутствуют"), Status: htmlreport.StatusFail})
+		return s
+	}
+	for _, r := range results {
+		t := htmlreport.Table{Caption: fmt.Sprintf("Signer [%d] %s", r.SignerIndex, r.SignerSubject), Header: []string{i18n.T("Проверка"), i18n.T("Результат"), i18n.T("Подробности")}}
+		for _, ch := range r.Checks {
+			row := htmlreport.Row{Cells: []string{ch.Name, "ok", ch.Detail}, Status: htmlreport.StatusOK}
+			if !ch.OK {
+				row.Cells[1], row.Status = "FAIL", htmlreport.StatusFail
+			}
+			t.Rows = append(t.Rows, row)
+		}
+		s.Tables = append(s.Tables, t)
+	}
+	return s
+}
@@ -0,0 +1,58 @@
+package registry
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sgw-registry/registry-analyzer/internal/htmlreport"
+)
+
+// TestHTMLReport проверяет статус ролей по roleValidityPeriod и секцию проверки подписи в HTML-отчёте.
+func TestHTMLReport(t *testing.T) {
+	cert, key := newTestSigner(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	der, err := BuildRegistry(cert, key, []SafeBagInput{
+		{CertDER: cert.Raw, RoleName: "active", RoleNotBefore: now.Add(-time.Hour), RoleNotAfter: now.Add(time.Hour)},
+		{CertDER: cert.Raw, RoleName: "expired", RoleNotBefore: now.Add(-2 * time.Hour), RoleNotAfter: now.Add(-time.Hour)},
+		{CertDER: cert.Raw, RoleName: "future", RoleNotBefore: now.Add(time.Hour), RoleNotAfter: now.Add(2 * time.Hour)},
+		{CertDER: cert.Raw, RoleName: "<script>"},
+	}, SignerAttrs{VIN: "TESTVIN123", VERTimestamp: now, VERVersion: 1, UID: "CN=Test"})
+	if err != nil {
+		t.Fatalf("BuildRegistry: %v", err)
+	}
+	c, err := Parse(der)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := []string{RoleStatusActive, RoleStatusExpired, RoleStatusNotYet, RoleStatusNone}
+	for i, st := range want {
+		if got := c.SafeBagInfos[i].RoleStatus(now); got != st {
+			t.Errorf("мешок %d: RoleStatus = %q, ожидается %q", i+1, got, st)
+		}
+	}
+	if nb := c.SafeBagInfos[0].RoleNotBefore; !nb.Equal(now.Add(-time.Hour)) {
+		t.Errorf("RoleNotBefore = %v", nb)
+	}
+
+	page := c.HTMLReport("test.p12", c.Verify(VerifyOptions{}), now)
+	var buf bytes.Buffer
+	if err := htmlreport.Write(&buf, page); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.String()
+	for _, s := range []string{"TESTVIN123", `<tr class="fail"><td>2</td><td>expired</td><td>истекла`, `<tr class="warn"><td>3</td><td>future</td>`, "Проверка подписи", "&lt;script&gt;"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("в отчёте нет %q", s)
+		}
+	}
+	if strings.Contains(out, "<script>") {
+		t.Error("значение атрибута не экранировано")
+	}
+	for _, s := range c.HTMLReport("test.p12", nil, now).Sections {
+		if s.Title == "Проверка подписи" {
+			t.Error("секция проверки подписи без -verify")
+		}
+	}
+}
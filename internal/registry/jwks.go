@@ -0,0 +1,142 @@
+// jwks.go — выгрузка ключей сертификатов SafeBags для сервисов: JWK Set (RFC 7517) с x5c и x5t#S256
+// и пины SHA-256 SubjectPublicKeyInfo по ролям (-format jwks, -format pins).
+package registry
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// JWK — открытый ключ сертификата мешка (RFC 7517, 7518, 8037). kid — localKeyID мешка в виде из отчёта
+// (UUID для 16 байт, иначе hex), без атрибута — x5t#S256. roleName, roleNotBefore, roleNotAfter — собственные
+// члены JWK с ролью мешка и roleValidityPeriod (RFC 3339, UTC).
+type JWK struct {
+	Kty           string   `json:"kty"`
+	Crv           string   `json:"crv,omitempty"`
+	X             string   `json:"x,omitempty"`
+	Y             string   `json:"y,omitempty"`
+	N             string   `json:"n,omitempty"`
+	E             string   `json:"e,omitempty"`
+	Kid           string   `json:"kid"`
+	X5c           []string `json:"x5c"`
+	X5tS256       string   `json:"x5t#S256"`
+	RoleName      string   `json:"roleName,omitempty"`
+	RoleNotBefore string   `json:"roleNotBefore,omitempty"`
+	RoleNotAfter  string   `json:"roleNotAfter,omitempty"`
+}
+
+// JWKSet — JWK Set: {"keys": [...]}.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// RolePins — пины роли: base64 SHA-256 от DER SubjectPublicKeyInfo сертификатов мешков с этим roleName
+// (формат pin-sha256 из RFC 7469), без повторов.
+type RolePins struct {
+	RoleName  string   `json:"roleName"`
+	PinSHA256 []string `json:"pin-sha256"`
+}
+
+// PinSet — пины по ролям в порядке первого появления роли в SafeBags.
+type PinSet struct {
+	Roles []RolePins `json:"roles"`
+}
+
+// JWKS возвращает открытые ключи сертификатов SafeBags в виде JWK Set. Сертификаты с ключом, для которого
+// нет представления JWK, пропускаются и перечисляются в warnings.
+func (c *Container) JWKS() (set JWKSet, warnings []string) {
+	set.Keys = []JWK{}
+	for i := range c.SafeBagInfos {
+		info := &c.SafeBagInfos[i]
+		if len(info.CertValueDER) == 0 {
+			continue
+		}
+		cert, err := x509.ParseCertificate(info.CertValueDER)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("SafeBag[%d]: %v", i+1, err))
+			continue
+		}
+		jwk, err := publicKeyJWK(cert.PublicKey)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("SafeBag[%d] %s: %v", i+1, cert.Subject, err))
+			continue
+		}
+		thumb := sha256.Sum256(cert.Raw)
+		jwk.X5c = []string{base64.StdEncoding.EncodeToString(cert.Raw)}
+		jwk.X5tS256 = base64.RawURLEncoding.EncodeToString(thumb[:])
+		jwk.Kid = jwk.X5tS256
+		for _, a := range info.BagAttributes {
+			if a.Name == "localKeyID" && a.Value != "" {
+				jwk.Kid = a.Value
+				break
+			}
+		}
+		jwk.RoleName = SafeBagRoleName(info)
+		if !info.RoleNotAfter.IsZero() {
+			jwk.RoleNotBefore = info.RoleNotBefore.UTC().Format(time.RFC3339)
+			jwk.RoleNotAfter = info.RoleNotAfter.UTC().Format(time.RFC3339)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set, warnings
+}
+
+// Pins возвращает пины SHA-256 SubjectPublicKeyInfo сертификатов SafeBags, сгруппированные по roleName.
+func (c *Container) Pins() PinSet {
+	set := PinSet{Roles: []RolePins{}}
+	index := make(map[string]int)
+	seen := make(map[string]bool)
+	for i := range c.SafeBagInfos {
+		info := &c.SafeBagInfos[i]
+		if len(info.CertValueDER) == 0 {
+			continue
+		}
+		cert, err := x509.ParseCertificate(info.CertValueDER)
+		if err != nil {
+			continue
+		}
+		role := SafeBagRoleName(info)
+		sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		pin := base64.StdEncoding.EncodeToString(sum[:])
+		if seen[role+"\x00"+pin] {
+			continue
+		}
+		seen[role+"\x00"+pin] = true
+		n, ok := index[role]
+		if !ok {
+			n = len(set.Roles)
+			index[role] = n
+			set.Roles = append(set.Roles, RolePins{RoleName: role})
+		}
+		set.Roles[n].PinSHA256 = append(set.Roles[n].PinSHA256, pin)
+	}
+	return set
+}
+
+// publicKeyJWK заполняет параметры ключа JWK: EC (P-256, P-384, P-521), RSA, OKP (Ed25519).
+func publicKeyJWK(pub interface{}) (JWK, error) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		ek, err := k.ECDH()
+		if err != nil {
+			return JWK{}, err
+		}
+		// Несжатая точка 0x04 || X || Y: координаты фиксированной длины, как требует RFC 7518 6.2.1.
+		point := ek.Bytes()
+		size := (len(point) - 1) / 2
+		return JWK{Kty: "EC", Crv: k.Curve.Params().Name, X: b64(point[1 : 1+size]), Y: b64(point[1+size:])}, nil
+	case *rsa.PublicKey:
+		return JWK{Kty: "RSA", N: b64(k.N.Bytes()), E: b64(big.NewInt(int64(k.E)).Bytes())}, nil
+	case ed25519.PublicKey:
+		return JWK{Kty: "OKP", Crv: "Ed25519", X: b64(k)}, nil
+	}
+	return JWK{}, fmt.Errorf("unsupported public key type %T", pub)
+}
@@ -0,0 +1,92 @@
+package registry
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+// TestJWKS проверяет JWK Set и пины: kid из localKeyID (без атрибута — x5t#S256), координаты EC, x5c и x5t#S256,
+// роль и её срок; пины SPKI группируются по roleName без повторов.
+func TestJWKS(t *testing.T) {
+	cert, key := newTestSigner(t)
+	driver := newTestLeaf(t, cert, key, "Driver", 10)
+	ivi := newTestLeaf(t, cert, key, "IVI", 11)
+	now := time.Now().UTC().Truncate(time.Second)
+	der, err := BuildRegistry(cert, key, []SafeBagInput{
+		{CertDER: driver, RoleName: "delegate", RoleNotBefore: now, RoleNotAfter: now.Add(time.Hour), LocalKeyID: []byte{0xab, 0xcd}},
+		{CertDER: ivi, RoleName: "delegate"},
+		{CertDER: driver, RoleName: "delegate"},
+		{CertDER: ivi, RoleName: "not_delegate"},
+	}, SignerAttrs{VIN: "TESTVIN123", VERTimestamp: now, VERVersion: 1, UID: "CN=Test"})
+	if err != nil {
+		t.Fatalf("BuildRegistry: %v", err)
+	}
+	c, err := Parse(der)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	set, warnings := c.JWKS()
+	if len(warnings) != 0 || len(set.Keys) != 4 {
+		t.Fatalf("ключей %d, предупреждения %v; ожидается 4 без предупреждений", len(set.Keys), warnings)
+	}
+	var k *JWK
+	for i := range set.Keys {
+		if set.Keys[i].Kid == "abcd" {
+			k = &set.Keys[i]
+		}
+	}
+	if k == nil {
+		t.Fatalf("нет ключа с kid = localKeyID abcd: %+v", set.Keys)
+	}
+	leaf, _ := x509.ParseCertificate(driver)
+	pub := leaf.PublicKey.(*ecdsa.PublicKey)
+	thumb := sha256.Sum256(driver)
+	b64 := base64.RawURLEncoding.EncodeToString
+	if k.Kty != "EC" || k.Crv != "P-256" || k.X != b64(pub.X.FillBytes(make([]byte, 32))) || k.Y != b64(pub.Y.FillBytes(make([]byte, 32))) {
+		t.Errorf("ключ EC: %+v", k)
+	}
+	if len(k.X5c) != 1 || k.X5c[0] != base64.StdEncoding.EncodeToString(driver) || k.X5tS256 != b64(thumb[:]) {
+		t.Errorf("x5c/x5t#S256: %+v", k)
+	}
+	if k.RoleName != "delegate" || k.RoleNotBefore != now.Format(time.RFC3339) || k.RoleNotAfter != now.Add(time.Hour).Format(time.RFC3339) {
+		t.Errorf("роль: %q %q %q", k.RoleName, k.RoleNotBefore, k.RoleNotAfter)
+	}
+	for _, key := range set.Keys {
+		if key.Kid == "" || key.RoleName == "" {
+			t.Errorf("ключ без kid или roleName: %+v", key)
+		}
+	}
+
+	pin := func(certDER []byte) string {
+		cert, _ := x509.ParseCertificate(certDER)
+		sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		return base64.StdEncoding.EncodeToString(sum[:])
+	}
+	pins := c.Pins()
+	want := map[string][]string{"delegate": {pin(driver), pin(ivi)}, "not_delegate": {pin(ivi)}}
+	if len(pins.Roles) != len(want) {
+		t.Fatalf("ролей %d, ожидается %d: %+v", len(pins.Roles), len(want), pins.Roles)
+	}
+	for _, r := range pins.Roles {
+		w := want[r.RoleName]
+		if len(r.PinSHA256) != len(w) {
+			t.Errorf("%s: пинов %d, ожидается %d", r.RoleName, len(r.PinSHA256), len(w))
+			continue
+		}
+		// Порядок мешков задаёт сборщик (сортировка DER), поэтому пины сравниваются как множество.
+		got := make(map[string]bool)
+		for _, p := range r.PinSHA256 {
+			got[p] = true
+		}
+		for _, p := range w {
+			if !got[p] {
+				t.Errorf("%s: нет пина %s", r.RoleName, p)
+			}
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// keystore.go — разбор обычных хранилищ PKCS#12 (RFC 7292): authSafe = ContentInfo(data) с AuthenticatedSafe
+// из ContentInfo data и encryptedData, парольное шифрование (PBES2, pbeWithSHAAnd*) и проверка macData.
+package registry
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+)
+
+// Виды контейнера (Container.Kind).
+const (
+	KindRegistry = "atom-registry"   // ATOM-PKCS12-REGISTRY: authSafe = SignedData
+	KindKeystore = "pkcs12-keystore" // обычное хранилище PKCS#12: authSafe = data (AuthenticatedSafe)
+)
+
+// KindName возвращает читаемое название вида контейнера для отчётов.
+func KindName(kind string) string {
+	switch kind {
+	case KindRegistry:
+		return "ATOM-PKCS12-REGISTRY (PFX + CMS SignedData)"
+	case KindKeystore:
+		return "PKCS#12 keystore (RFC 7292)"
+	}
+	return kind
+}
+
+// OIDPKCS7EncryptedData — contentType encryptedData (RFC 5652, 8) в AuthenticatedSafe.
+var OIDPKCS7EncryptedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+
+// AuthSafeInfo — один ContentInfo из AuthenticatedSafe хранилища: тип, шифрование и число мешков.
+type AuthSafeInfo struct {
+	ContentType         string `json:"contentType"` // data, encryptedData или OID
+	Encrypted           bool   `json:"encrypted"`
+	EncryptionAlgorithm string `json:"encryptionAlgorithm,omitempty"` // DescribeEncryptionAlgorithm
+	SafeBags            int    `json:"safeBags"`
+	Error               string `json:"error,omitempty"` // не расшифровано или не разобрано — мешки не извлечены
+}
+
+// encryptedData — EncryptedData (RFC 5652, 8) с EncryptedContentInfo.
+type encryptedData struct {
+	Version              int
+	EncryptedContentInfo struct {
+		ContentType                asn1.ObjectIdentifier
+		ContentEncryptionAlgorithm AlgorithmIdentifier
+		EncryptedContent           asn1.RawValue `asn1:"optional,tag:0"`
+	}
+}
+
+// pathKeystoreAuthSafe — путь AuthenticatedSafe в ошибках разбора хранилища.
+const pathKeystoreAuthSafe = "PFX.authSafe.content.authenticatedSafe"
+
+// parseKeystore разбирает authSafe = data: проверяет MAC паролем, извлекает мешки из ContentInfo data
+// и расшифровывает encryptedData. Ошибки расшифровки не прерывают разбор — они записываются в AuthSafe[i].Error.
+// Без пароля (pw == nil) MAC не проверяется и ничего не расшифровывается. Итерации KDF и число расшифровок
+// ограничены одним pbeBudget на всё хранилище.
+func parseKeystore(c *Container, pfx *PFX, lim Limits, pw *pbePassword) (*Container, error) {
+	c.Kind = KindKeystore
+	content := unwrapOctetStringIfPresent(pfx.AuthSafe.Content.Bytes)
+	pbe := &pbeBudget{lim: lim}
+	if len(pfx.MacData.Mac.DigestAlgorithm.Algorithm) > 0 {
+		mac, err := verifyMac(&pfx.MacData, content, pw, pbe)
+		if err != nil {
+			return nil, err
+		}
+		c.Mac = mac
+	}
+
+	var cis []ContentInfo
+	rest, err := asn1.Unmarshal(content, &cis)
+	if err == nil && len(rest) > 0 {
+		err = fmt.Errorf("trailing bytes after AuthenticatedSafe")
+	}
+	if err != nil {
+		return nil, &der.ParseError{Offset: -1, Path: pathKeystoreAuthSafe, Err: err}
+	}
+	if err := lim.check("AuthenticatedSafe ContentInfos", len(cis), lim.MaxSafeBags); err != nil {
+		return nil, err
+	}
+
+	budget := &bagBudget{lim: lim}
+	for i, ci := range cis {
+		path := fmt.Sprintf("%s[%d]", pathKeystoreAuthSafe, i)
+		as := AuthSafeInfo{ContentType: ci.ContentType.String()}
+		var safeContents []byte
+		switch {
+		case ci.ContentType.Equal(OIDPKCS7Data):
+			as.ContentType = "data"
+			safeContents = unwrapOctetStringIfPresent(ci.Content.Bytes)
+		case ci.ContentType.Equal(OIDPKCS7EncryptedData):
+			as.ContentType, as.Encrypted = "encryptedData", true
+			var ed encryptedData
+			if _, err := asn1.Unmarshal(ci.Content.Bytes, &ed); err != nil {
+				return nil, &der.ParseError{Offset: -1, Path: path + ".encryptedData", Err: err}
+			}
+			eci := &ed.EncryptedContentInfo
+			as.EncryptionAlgorithm = DescribeEncryptionAlgorithm(eci.ContentEncryptionAlgorithm)
+			if pw == nil {
+				as.Error = i18n.T("не расшифровано: пароль хранилища не задан")
+				break
+			}
+			safeContents, err = decryptPBE(eci.ContentEncryptionAlgorithm, encryptedContentBytes(eci.EncryptedContent), pw, pbe)
+			if errors.Is(err, ErrLimitExceeded) {
+				return nil, err
+			}
+			if err != nil {
+				as.Error = err.Error()
+			}
+		default:
+			as.Error = i18n.T("contentType не поддерживается (ожидается data или encryptedData)")
+		}
+		if as.Error == "" {
+			n, err := c.addKeystoreBags(safeContents, budget, pw, pbe)
+			if err != nil {
+				if errors.Is(err, ErrLimitExceeded) {
+					return nil, err
+				}
+				// Расшифровка без ошибки дополнения, но не SafeContents — как правило, неверный пароль.
+				if as.Encrypted {
+					err = fmt.Errorf("%w: %v", ErrDecrypt, err)
+				}
+				as.Error = err.Error()
+			}
+			as.SafeBags = n
+		}
+		c.AuthSafe = append(c.AuthSafe, as)
+	}
+	return c, nil
+}
+
+// encryptedContentBytes возвращает шифртекст encryptedContent [0] IMPLICIT OCTET STRING:
+// примитивная форма — байты значения, составная (BER) — склейка вложенных OCTET STRING.
+func encryptedContentBytes(rv asn1.RawValue) []byte {
+	if !rv.IsCompound {
+		return rv.Bytes
+	}
+	var out []byte
+	rest := rv.Bytes
+	for len(rest) > 0 {
+		var chunk []byte
+		var err error
+		if rest, err = asn1.Unmarshal(rest, &chunk); err != nil {
+			return out
+		}
+		out = append(out, chunk...)
+	}
+	return out
+}
+
+// addKeystoreBags разбирает SafeContents хранилища в пределах budget (общего для всех AuthenticatedSafe)
+// и добавляет мешки в SafeBags/SafeBagInfos, сертификаты X.509 из certBag — в Certificates. Возвращает число мешков.
+// pkcs8ShroudedKeyBag расшифровываются паролем pw (nil — не расшифровываются) в пределах pbe.
+func (c *Container) addKeystoreBags(safeContents []byte, budget *bagBudget, pw *pbePassword, pbe *pbeBudget) (int, error) {
+	if err := der.CheckDepth(safeContents, budget.lim.MaxDepth); err != nil {
+		return 0, err
+	}
+	bags, err := budget.safeContents(safeContents)
+	if err != nil {
+		return 0, err
+	}
+	for _, bag := range bags {
+		info, err := budget.bagInfo(fmt.Sprintf("SafeBag[%d].bagAttributes", len(c.SafeBags)+1), bag)
+		if err != nil {
+			if errors.Is(err, ErrLimitExceeded) {
+				return 0, err
+			}
+			info.Error = err.Error()
+		}
+		if info.Key != nil && info.Key.Encrypted && pw != nil {
+			if err := decryptShroudedKey(&info, bag, pw, pbe); err != nil {
+				return 0, err
+			}
+		}
+		if len(info.CertValueDER) > 0 {
+			if cert, err := x509.ParseCertificate(info.CertValueDER); err == nil {
+				c.Certificates = append(c.Certificates, cert) // число уже ограничено MaxSafeBags
+			}
+		}
+		c.SafeBags = append(c.SafeBags, bag)
+		c.SafeBagInfos = append(c.SafeBagInfos, info)
+	}
+	return len(bags), nil
+}
+
+// decryptShroudedKey расшифровывает pkcs8ShroudedKeyBag паролем и дополняет сводку алгоритмом ключа.
+// Неподошедший пароль не ошибка: ключ остаётся в сводке как зашифрованный. Ошибка — только ErrLimitExceeded.
+func decryptShroudedKey(info *SafeBagInfo, bag SafeBag, pw *pbePassword, budget *pbeBudget) error {
+	var epki encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(safeBagValueDER(bag), &epki); err != nil {
+		return nil
+	}
+	plain, err := decryptPBE(epki.EncryptionAlgorithm, epki.EncryptedData, pw, budget)
+	if errors.Is(err, ErrLimitExceeded) {
+		return err
+	}
+	if err != nil {
+		return nil
+	}
+	if key, err := parseKeyBagValue(plain); err == nil {
+		info.Key.Algorithm, info.Key.Decrypted = key.Algorithm, true
+	}
+	return nil
+}
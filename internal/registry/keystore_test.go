@@ -0,0 +1,218 @@
+package registry
+
+import (
+	"encoding/asn1"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+)
+
+// Хранилища testdata/keystore-*.p12 созданы OpenSSL 3 (openssl pkcs12 -export) из одного самоподписанного
+// сертификата CN=Keystore Test и ключа ECDSA P-256, пароль changeit:
+//
+//	modern — PBES2 (PBKDF2-HMAC-SHA256, AES-256-CBC), MAC HMAC-SHA-256 (по умолчанию OpenSSL 3);
+//	legacy — сертификаты pbeWithSHAAnd40BitRC2-CBC, ключ pbeWithSHAAnd3-KeyTripleDES-CBC, MAC HMAC-SHA-1 (-legacy);
+//	des    — сертификаты и ключ pbeWithSHAAnd3-KeyTripleDES-CBC, MAC HMAC-SHA-1;
+//	trust  — только сертификат без шифрования (-nokeys -certpbe NONE) и пустой пароль.
+func readKeystore(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "keystore-"+name+".p12"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// TestParseKeystore проверяет разбор хранилищ PKCS#12: вид контейнера, MAC, расшифровку encryptedData
+// и pkcs8ShroudedKeyBag верным паролем и поведение при неверном пароле.
+func TestParseKeystore(t *testing.T) {
+	tests := []struct {
+		name, password string
+		mac            string // ожидаемый Mac.Algorithm
+		encryption     string // алгоритм encryptedData, пусто — сертификаты не зашифрованы
+		keyEncryption  string // алгоритм pkcs8ShroudedKeyBag, пусто — ключа нет
+	}{
+		{"modern", "changeit", "HMAC-SHA-256", "PBES2 (PBKDF2-HMAC-SHA256, 2048 iterations, AES-256-CBC)", "PBES2 (PBKDF2-HMAC-SHA256, 2048 iterations, AES-256-CBC)"},
+		{"legacy", "changeit", "HMAC-SHA-1", "pbeWithSHAAnd40BitRC2-CBC (2048 iterations)", "pbeWithSHAAnd3-KeyTripleDES-CBC (2048 iterations)"},
+		{"des", "changeit", "HMAC-SHA-1", "pbeWithSHAAnd3-KeyTripleDES-CBC (2048 iterations)", "pbeWithSHAAnd3-KeyTripleDES-CBC (2048 iterations)"},
+		{"trust", "", "HMAC-SHA-256", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := readKeystore(t, tt.name)
+			parse := func(data []byte, lim Limits) (*Container, error) { return ParseWithPassword(data, lim, tt.password) }
+			if tt.password == "" {
+				parse = ParseWithEmptyPassword
+			}
+			c, err := parse(data, DefaultLimits)
+			if err != nil {
+				t.Fatalf("ParseWithPassword: %v", err)
+			}
+			if c.Kind != KindKeystore || c.SignedData != nil {
+				t.Fatalf("Kind = %q, SignedData = %v; ожидается хранилище без SignedData", c.Kind, c.SignedData != nil)
+			}
+			if c.Mac == nil || c.Mac.Algorithm != tt.mac || c.Mac.Status != MacStatusOK || !c.MacVerified() {
+				t.Errorf("Mac = %+v, ожидается %s ok", c.Mac, tt.mac)
+			}
+			if len(c.Certificates) != 1 || c.Certificates[0].Subject.CommonName != "Keystore Test" {
+				t.Fatalf("сертификатов %d, ожидается CN=Keystore Test", len(c.Certificates))
+			}
+			if n := len(c.BundleCertificates()); n != 1 {
+				t.Errorf("BundleCertificates: %d, ожидается 1 (сертификат мешка и Certificates совпадают)", n)
+			}
+			if len(c.SafeBagInfos) != len(c.SafeBags) {
+				t.Fatalf("SafeBagInfos %d, SafeBags %d", len(c.SafeBagInfos), len(c.SafeBags))
+			}
+			var encryption string
+			for _, as := range c.AuthSafe {
+				if as.Error != "" {
+					t.Errorf("AuthSafe %s: %s", as.ContentType, as.Error)
+				}
+				if as.Encrypted {
+					encryption = as.EncryptionAlgorithm
+				}
+			}
+			if encryption != tt.encryption {
+				t.Errorf("encryptedData = %q, ожидается %q", encryption, tt.encryption)
+			}
+			var key *KeyBagSummary
+			for _, info := range c.SafeBagInfos {
+				if info.Key != nil {
+					key = info.Key
+				}
+			}
+			if tt.keyEncryption == "" {
+				if key != nil {
+					t.Errorf("неожиданный ключ: %+v", key)
+				}
+				return
+			}
+			if key == nil || !key.Decrypted || key.Algorithm != "ECDSA P-256" || key.EncryptionAlgorithm != tt.keyEncryption {
+				t.Errorf("ключ = %+v, ожидается расшифрованный ECDSA P-256 (%s)", key, tt.keyEncryption)
+			}
+
+			// Тот же файл с неверным паролем: разбор не прерывается, MAC не совпадает, зашифрованное не извлекается.
+			c, err = ParseWithPassword(data, DefaultLimits, "wrong")
+			if err != nil {
+				t.Fatalf("ParseWithPassword (неверный пароль): %v", err)
+			}
+			if c.Mac == nil || c.Mac.Status != MacStatusFailed {
+				t.Errorf("Mac = %+v, ожидается failed", c.Mac)
+			}
+			for _, as := range c.AuthSafe {
+				if as.Encrypted && (as.SafeBags != 0 || !strings.Contains(as.Error, ErrDecrypt.Error())) {
+					t.Errorf("encryptedData с неверным паролем: %+v", as)
+				}
+			}
+			for _, info := range c.SafeBagInfos {
+				if info.Key != nil && info.Key.Decrypted {
+					t.Errorf("ключ расшифрован неверным паролем: %+v", info.Key)
+				}
+			}
+		})
+	}
+}
+
+// TestParseKeystoreKind проверяет, что реестр ATOM и хранилище различаются по виду, а отчёты показывают вид и MAC.
+func TestParseKeystoreKind(t *testing.T) {
+	cert, key := newTestSigner(t)
+	der, err := BuildRegistry(cert, key, nil, SignerAttrs{VIN: "TESTVIN123", VERVersion: 1, UID: "CN=Test"})
+	if err != nil {
+		t.Fatalf("BuildRegistry: %v", err)
+	}
+	c, err := Parse(der)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if c.Kind != KindRegistry || c.Mac != nil || c.AuthSafe != nil {
+		t.Errorf("реестр: Kind = %q, Mac = %+v, AuthSafe = %+v", c.Kind, c.Mac, c.AuthSafe)
+	}
+
+	// Без пароля хранилище разбирается: незашифрованные мешки видны, MAC не проверяется, encryptedData не расшифровывается.
+	c, err = Parse(readKeystore(t, "modern"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if c.Mac == nil || c.Mac.Status != MacStatusSkipped {
+		t.Errorf("Mac = %+v, ожидается skipped", c.Mac)
+	}
+	var sb strings.Builder
+	c.TextOutput(&sb, false)
+	for _, want := range []string{"PKCS#12 keystore (RFC 7292)", "=== AuthenticatedSafe ===", i18n.T("пароль не задан"), "pkcs8ShroudedKeyBag"} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("TextOutput не содержит %q", want)
+		}
+	}
+	if strings.Contains(sb.String(), "Signers") {
+		t.Error("TextOutput хранилища содержит секцию подписантов")
+	}
+	out := c.JSONOutput()
+	if out.Kind != KindKeystore || out.MAC == nil || len(out.AuthSafe) == 0 {
+		t.Errorf("JSONOutput: kind = %v, mac = %v, authSafe = %v", out.Kind, out.MAC, out.AuthSafe)
+	}
+	if len(c.AuthSafe) == 0 || !c.AuthSafe[0].Encrypted || c.AuthSafe[0].Error != i18n.T("не расшифровано: пароль хранилища не задан") {
+		t.Errorf("encryptedData без пароля: %+v, ожидается «пароль не задан»", c.AuthSafe)
+	}
+}
+
+// TestKeystorePBELimits проверяет, что число итераций KDF ограничено MaxPBEIterations до выработки ключа
+// (macData.iterations = 2^31-1 раньше подвешивало Parse), сумма итераций — MaxPBEIterationsTotal, число
+// расшифровок — MaxPBEDecryptions, без пароля KDF не вызывается, а keyLength PBKDF2 должен совпадать с длиной ключа шифра.
+func TestKeystorePBELimits(t *testing.T) {
+	var pfx PFX
+	if _, err := asn1.Unmarshal(readKeystore(t, "trust"), &pfx); err != nil {
+		t.Fatal(err)
+	}
+	pfx.MacData.Iterations = math.MaxInt32
+	patched, err := asn1.Marshal(pfx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseWithEmptyPassword(patched, DefaultLimits); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("macData.iterations 2^31-1: err = %v, ожидается ErrLimitExceeded", err)
+	}
+	if c, err := Parse(patched); err != nil {
+		t.Errorf("macData.iterations 2^31-1 без пароля: %v", err)
+	} else if c.Mac == nil || c.Mac.Status != MacStatusSkipped {
+		t.Errorf("macData.iterations 2^31-1 без пароля: Mac = %+v, ожидается skipped", c.Mac)
+	}
+	modern := readKeystore(t, "modern") // macData, encryptedData и ключ — по 2048 итераций PBKDF2
+	for name, lim := range map[string]Limits{
+		"MaxPBEIterations":      {MaxPBEIterations: 1000},
+		"MaxPBEIterationsTotal": {MaxPBEIterationsTotal: 4000},
+		"MaxPBEDecryptions":     {MaxPBEDecryptions: 1},
+	} {
+		if _, err := ParseWithPassword(modern, lim, "changeit"); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("%s: err = %v, ожидается ErrLimitExceeded", name, err)
+		}
+	}
+	if _, err := ParseWithPassword(modern, Limits{MaxPBEIterationsTotal: 3 * 2048, MaxPBEDecryptions: 2}, "changeit"); err != nil {
+		t.Errorf("лимиты по размеру хранилища: %v", err)
+	}
+
+	pbes2 := func(iterations, keyLength int) AlgorithmIdentifier {
+		kdf, _ := asn1.Marshal(pbkdf2Params{Salt: make([]byte, 8), IterationCount: iterations, KeyLength: keyLength})
+		iv, _ := asn1.Marshal(make([]byte, 16))
+		params, _ := asn1.Marshal(pbes2Params{
+			KeyDerivationFunc: AlgorithmIdentifier{Algorithm: OIDPBKDF2, Parameters: asn1.RawValue{FullBytes: kdf}},
+			EncryptionScheme:  AlgorithmIdentifier{Algorithm: OIDAES256CBC, Parameters: asn1.RawValue{FullBytes: iv}},
+		})
+		return AlgorithmIdentifier{Algorithm: OIDPBES2, Parameters: asn1.RawValue{FullBytes: params}}
+	}
+	pw := newPBEPassword("changeit")
+	budget := func() *pbeBudget { return &pbeBudget{lim: DefaultLimits} }
+	if _, err := decryptPBE(pbes2(math.MaxInt32, 0), make([]byte, 16), pw, budget()); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("PBKDF2 2^31-1 итераций: err = %v, ожидается ErrLimitExceeded", err)
+	}
+	if _, err := decryptPBE(pbes2(1, 1<<30), make([]byte, 16), pw, budget()); err == nil || !strings.Contains(err.Error(), "keyLength") {
+		t.Errorf("keyLength 2^30: err = %v, ожидается ошибка keyLength", err)
+	}
+	if _, err := decryptPBE(pbes2(1, 32), make([]byte, 16), pw, budget()); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("keyLength 32: err = %v, ожидается ErrDecrypt", err)
+	}
+}
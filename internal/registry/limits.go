@@ -0,0 +1,119 @@
+// limits.go — лимиты разбора контейнера: размер входа, глубина вложенности, число мешков, сертификатов, подписантов и атрибутов,
+// число итераций парольного KDF и расшифровок хранилища PKCS#12.
+// Разборщик работает на SGW: вредоносный реестр не должен ни ронять процесс, ни исчерпывать память.
+package registry
+
+import (
+	"fmt"
+
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+)
+
+// ErrLimitExceeded — превышен один из лимитов Limits (проверяется через errors.Is).
+var ErrLimitExceeded = der.ErrLimitExceeded
+
+// Limits — ограничения ParseWithLimits. Нулевое или отрицательное поле — без ограничения.
+type Limits struct {
+	MaxInputSize     int // размер DER-файла, байт
+	MaxDepth         int // глубина вложенности constructed-элементов (PFX и отдельно SafeContents)
+	MaxSafeBags      int // мешков в SafeContents
+	MaxCertificates  int // сертификатов в SignedData.certificates
+	MaxSigners       int // SignerInfo в SignedData.signerInfos
+	MaxAttributes    int // атрибутов в одном SET (bagAttributes, authenticatedAttributes) и значений в одном атрибуте
+	MaxPBEIterations int // итераций KDF в PBE, PBES2 и macData хранилища PKCS#12
+
+	MaxPBEIterationsTotal int // итераций KDF за разбор хранилища: сумма по macData, encryptedData и ключам
+	MaxPBEDecryptions     int // расшифровок encryptedData и pkcs8ShroudedKeyBag за разбор хранилища
+}
+
+// DefaultLimits — лимиты Parse: с запасом покрывают реальные реестры (единицы мешков, глубина ~12).
+var DefaultLimits = Limits{
+	MaxInputSize:     8 << 20,
+	MaxDepth:         32,
+	MaxSafeBags:      1024,
+	MaxCertificates:  64,
+	MaxSigners:       16,
+	MaxAttributes:    64,
+	MaxPBEIterations: 1 << 20,
+
+	MaxPBEIterationsTotal: 4 << 20,
+	MaxPBEDecryptions:     64,
+}
+
+// check возвращает ошибку ErrLimitExceeded, если n превышает max (при max > 0).
+func (lim Limits) check(what string, n, max int) error {
+	if max > 0 && n > max {
+		return fmt.Errorf("%w: %d %s, limit %d", ErrLimitExceeded, n, what, max)
+	}
+	return nil
+}
+
+// checkAttributes проверяет число атрибутов в SET и значений в каждом атрибуте.
+func (lim Limits) checkAttributes(where string, attrs []Attribute) error {
+	if err := lim.check(where, len(attrs), lim.MaxAttributes); err != nil {
+		return err
+	}
+	for i, a := range attrs {
+		if err := lim.check(fmt.Sprintf("%s[%d] values", where, i+1), len(a.AttrValues), lim.MaxAttributes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bagBudget — лимиты разбора мешков одного контейнера: мешки верхнего уровня и вложенные в safeContentsBag
+// расходуют общий бюджет MaxSafeBags, а атрибуты каждого мешка ограничены MaxAttributes.
+type bagBudget struct {
+	lim  Limits
+	used int // мешков уже разобрано
+}
+
+// safeContents разбирает SafeContents, если бюджет MaxSafeBags ещё не исчерпан, и учитывает разобранные мешки.
+func (b *bagBudget) safeContents(content []byte) ([]SafeBag, error) {
+	max := 0
+	if b.lim.MaxSafeBags > 0 {
+		if max = b.lim.MaxSafeBags - b.used; max <= 0 {
+			return nil, fmt.Errorf("%w: more than %d SafeBags", ErrLimitExceeded, b.lim.MaxSafeBags)
+		}
+	}
+	bags, err := parseSafeContents(content, max)
+	if err != nil {
+		return nil, err
+	}
+	b.used += len(bags)
+	return bags, nil
+}
+
+// bagInfo проверяет атрибуты мешка (where — место для сообщения) и разбирает мешок с вложенными в пределах бюджета.
+func (b *bagBudget) bagInfo(where string, bag SafeBag) (SafeBagInfo, error) {
+	if err := b.lim.checkAttributes(where, bag.BagAttributes); err != nil {
+		return SafeBagInfo{}, err
+	}
+	return parseSafeBagInfo(bag, b)
+}
+
+// pbeBudget — лимиты парольной криптографии одного хранилища: итерации каждого вызова KDF ограничены
+// MaxPBEIterations, их сумма по macData, encryptedData и ключам — MaxPBEIterationsTotal,
+// число расшифровок — MaxPBEDecryptions. Проверки выполняются до выработки ключа.
+type pbeBudget struct {
+	lim         Limits
+	iterations  int // итераций KDF уже учтено
+	decryptions int // расшифровок уже начато
+}
+
+// kdf учитывает вызов KDF с n итерациями: what — алгоритм для сообщения.
+func (b *pbeBudget) kdf(what string, n int) error {
+	if err := b.lim.check(what+" iterations", n, b.lim.MaxPBEIterations); err != nil {
+		return err
+	}
+	if n > 0 {
+		b.iterations += n
+	}
+	return b.lim.check("PBE KDF iterations in total", b.iterations, b.lim.MaxPBEIterationsTotal)
+}
+
+// decryption учитывает одну расшифровку encryptedData или pkcs8ShroudedKeyBag.
+func (b *pbeBudget) decryption() error {
+	b.decryptions++
+	return b.lim.check("PBE decryptions", b.decryptions, b.lim.MaxPBEDecryptions)
+}
@@ -0,0 +1,68 @@
+package registry
+
+import (
+	"encoding/asn1"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestParseWithLimits проверяет, что превышение лимитов даёт ErrLimitExceeded, а DefaultLimits пропускают обычный реестр.
+func TestParseWithLimits(t *testing.T) {
+	cert, key := newTestSigner(t)
+	now := time.Date(2026, 1, 15, 17, 40, 20, 0, time.UTC)
+	safeBags := []SafeBagInput{
+		{CertDER: cert.Raw, RoleName: "delegate"},
+		{CertDER: cert.Raw, RoleName: "not_delegate"},
+	}
+	der, err := BuildRegistry(cert, key, safeBags, SignerAttrs{VIN: "TESTVIN123", VERTimestamp: now, VERVersion: 1})
+	if err != nil {
+		t.Fatalf("BuildRegistry: %v", err)
+	}
+	if _, err := Parse(der); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	for name, lim := range map[string]Limits{
+		"MaxInputSize":  {MaxInputSize: len(der) - 1},
+		"MaxDepth":      {MaxDepth: 4},
+		"MaxSafeBags":   {MaxSafeBags: 1},
+		"MaxAttributes": {MaxAttributes: 2},
+	} {
+		if _, err := ParseWithLimits(der, lim); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("%s: err = %v, ожидается ErrLimitExceeded", name, err)
+		}
+	}
+	if _, err := ParseWithLimits(der, Limits{}); err != nil {
+		t.Errorf("без лимитов: %v", err)
+	}
+}
+
+// TestNestedSafeBagLimits проверяет, что мешки safeContentsBag расходуют общий с верхним уровнем лимит MaxSafeBags,
+// а их атрибуты проверяются по MaxAttributes.
+func TestNestedSafeBagLimits(t *testing.T) {
+	name, _ := asn1.Marshal("bag")
+	attr := Attribute{AttrType: OIDPKCS9FriendlyName, AttrValues: []asn1.RawValue{{FullBytes: name}}}
+	inner := SafeBag{BagId: OIDSecretBag, BagValue: explicit0([]byte{0x30, 0x00}), BagAttributes: []Attribute{attr, attr, attr}}
+	innerDER, err := asn1.Marshal([]SafeBag{inner, inner})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nested := SafeBag{BagId: OIDSafeContentsBag, BagValue: explicit0(innerDER)}
+
+	if info, err := ParseSafeBagInfo(nested); err != nil || len(info.Nested) != 2 {
+		t.Fatalf("ParseSafeBagInfo: %d вложенных, %v", len(info.Nested), err)
+	}
+	for name, budget := range map[string]*bagBudget{
+		"MaxSafeBags":   {lim: Limits{MaxSafeBags: 2}, used: 1},
+		"MaxAttributes": {lim: Limits{MaxAttributes: 2}},
+	} {
+		if _, err := budget.bagInfo("SafeBag[1].bagAttributes", nested); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("%s: err = %v, ожидается ErrLimitExceeded", name, err)
+		}
+	}
+	budget := &bagBudget{lim: Limits{MaxSafeBags: 3}, used: 1}
+	if _, err := budget.bagInfo("SafeBag[1].bagAttributes", nested); err != nil || budget.used != 3 {
+		t.Errorf("MaxSafeBags 3: used = %d, %v", budget.used, err)
+	}
+}
@@ -0,0 +1,313 @@
+// locate.go — поиск места ошибки разбора: структурный обход PFX по схеме registry.asn1 с абсолютными смещениями
+// и ASN.1-путём. Вызывается только когда Parse уже отказал, поэтому проверяет ровно то, что требует Parse.
+package registry
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/sgw-registry/registry-analyzer/internal/der"
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+)
+
+// Пути основных узлов контейнера (registry.asn1).
+const (
+	pathPFX        = "PFX"
+	pathAuthSafe   = "PFX.authSafe"
+	pathSignedData = "PFX.authSafe.content.signedData"
+	pathEContent   = "PFX.authSafe.content.signedData.encapContentInfo.eContent"
+)
+
+// parseFailure превращает ошибку разбора err на этапе path в *der.ParseError: если структурный обход находит
+// место нарушения, возвращается оно, иначе err с путём этапа (смещение неизвестно, если err его не содержит).
+func parseFailure(data []byte, maxDepth int, path string, err error) error {
+	if located := locate(data, maxDepth); located != nil {
+		return located
+	}
+	var perr *der.ParseError
+	if errors.As(err, &perr) {
+		if perr.Path == "" {
+			perr.Path = path
+		}
+		return err
+	}
+	return &der.ParseError{Offset: -1, Path: path, Err: err}
+}
+
+// locate обходит PFX и возвращает первое структурное нарушение (*der.ParseError) или nil.
+// maxDepth > 0 ограничивает глубину вложенности всего PFX и отдельно SafeContents (как ParseWithLimits).
+func locate(data []byte, maxDepth int) error {
+	if err := der.CheckDepth(data, maxDepth); err != nil {
+		return withPath(err, pathPFX, 0)
+	}
+	top := der.NewCursor("", data, 0)
+	pfxTLV, err := top.Expect(pathPFX, der.ClassUniversal, der.TagSequence)
+	if err != nil {
+		return err
+	}
+	if !top.Done() {
+		return &der.ParseError{Offset: top.Offset(), Path: pathPFX, Expected: "end of data", Actual: "trailing bytes after PFX"}
+	}
+	pfx := top.Enter(pathPFX, pfxTLV)
+	version, vt, err := pfx.Int("version")
+	if err != nil {
+		return err
+	}
+	if version != 3 {
+		return &der.ParseError{Offset: vt.Offset, Path: pfx.Path + ".version", Expected: "3", Actual: strconv.Itoa(version)}
+	}
+	authSafeTLV, err := pfx.Expect("authSafe", der.ClassUniversal, der.TagSequence)
+	if err != nil {
+		return err
+	}
+	pfx.Optional(der.ClassUniversal, der.TagSequence) // macData
+	if err := pfx.End(); err != nil {
+		return err
+	}
+
+	authSafe := pfx.Enter("authSafe", authSafeTLV)
+	ct, ctTLV, err := authSafe.OID("contentType")
+	if err != nil {
+		return err
+	}
+	if ct.Equal(OIDPKCS7Data) {
+		return nil // хранилище PKCS#12: структура AuthenticatedSafe проверяется в parseKeystore
+	}
+	if !ct.Equal(OIDPKCS7SignedData) {
+		return &der.ParseError{Offset: ctTLV.Offset, Path: pathAuthSafe + ".contentType", Expected: "pkcs7-signedData (" + OIDPKCS7SignedData.String() + ") " + i18n.T("или") + " pkcs7-data", Actual: ct.String()}
+	}
+	contentTLV, err := authSafe.Expect("content", der.ClassContextSpecific, 0)
+	if err != nil {
+		return err
+	}
+	// content [0]: EXPLICIT (внутри SEQUENCE) или IMPLICIT (сразу поля SignedData).
+	content := authSafe.Enter("content", contentTLV)
+	sd := content.Enter("signedData", contentTLV)
+	if first, ok := content.Peek(); ok && first.Is(der.ClassUniversal, der.TagSequence) {
+		content.Optional(der.ClassUniversal, der.TagSequence)
+		sd = content.Enter("signedData", first)
+	}
+	return locateSignedData(sd, maxDepth)
+}
+
+// withPath дополняет ошибку der.Walk путём и сдвигает смещение на base (Walk считает от начала своего среза).
+func withPath(err error, path string, base int) error {
+	var perr *der.ParseError
+	if errors.As(err, &perr) {
+		perr.Path = path
+		perr.Offset += base
+	}
+	return err
+}
+
+// locateSignedData проверяет поля SignedData: version, digestAlgorithms, encapContentInfo (с SafeContents),
+// certificates, crls и signerInfos.
+func locateSignedData(sd *der.Cursor, maxDepth int) error {
+	if _, _, err := sd.Int("version"); err != nil {
+		return err
+	}
+	algsTLV, err := sd.Expect("digestAlgorithms", der.ClassUniversal, der.TagSet)
+	if err != nil {
+		return err
+	}
+	if err := locateAlgorithms(sd.Enter("digestAlgorithms", algsTLV), "digestAlgorithm"); err != nil {
+		return err
+	}
+	eciTLV, err := sd.Expect("encapContentInfo", der.ClassUniversal, der.TagSequence)
+	if err != nil {
+		return err
+	}
+	if err := locateEncapContentInfo(sd.Enter("encapContentInfo", eciTLV), maxDepth); err != nil {
+		return err
+	}
+	if certsTLV, ok := sd.Optional(der.ClassContextSpecific, 0); ok {
+		if err := locateCertificates(sd.Enter("certificates", certsTLV)); err != nil {
+			return err
+		}
+	}
+	sd.Optional(der.ClassContextSpecific, 1) // crls
+	siTLV, err := sd.Expect("signerInfos", der.ClassUniversal, der.TagSet)
+	if err != nil {
+		return err
+	}
+	sis := sd.Enter("signerInfos", siTLV)
+	for i := 1; !sis.Done(); i++ {
+		field := fmt.Sprintf("signerInfo[%d]", i)
+		t, err := sis.Expect(field, der.ClassUniversal, der.TagSequence)
+		if err != nil {
+			return err
+		}
+		if err := locateSignerInfo(sis.Enter(field, t)); err != nil {
+			return err
+		}
+	}
+	return sd.End()
+}
+
+// locateAlgorithms проверяет SET OF AlgorithmIdentifier.
+func locateAlgorithms(set *der.Cursor, name string) error {
+	for i := 1; !set.Done(); i++ {
+		field := fmt.Sprintf("%s[%d]", name, i)
+		t, err := set.Expect(field, der.ClassUniversal, der.TagSequence)
+		if err != nil {
+			return err
+		}
+		if err := locateAlgorithm(set.Enter(field, t)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// locateAlgorithm проверяет AlgorithmIdentifier: algorithm OID и необязательные параметры.
+func locateAlgorithm(alg *der.Cursor) error {
+	if _, _, err := alg.OID("algorithm"); err != nil {
+		return err
+	}
+	if !alg.Done() {
+		if _, err := alg.Next("parameters"); err != nil {
+			return err
+		}
+	}
+	return alg.End()
+}
+
+// locateAlgorithmField читает поле AlgorithmIdentifier из c и проверяет его.
+func locateAlgorithmField(c *der.Cursor, field string) error {
+	t, err := c.Expect(field, der.ClassUniversal, der.TagSequence)
+	if err != nil {
+		return err
+	}
+	return locateAlgorithm(c.Enter(field, t))
+}
+
+// locateEncapContentInfo проверяет eContentType и, для pkcs7-data, SafeContents внутри eContent.
+func locateEncapContentInfo(eci *der.Cursor, maxDepth int) error {
+	ct, _, err := eci.OID("eContentType")
+	if err != nil {
+		return err
+	}
+	t, ok := eci.Optional(der.ClassContextSpecific, 0)
+	if !ok || !ct.Equal(OIDPKCS7Data) {
+		return eci.End()
+	}
+	// eContent [0]: EXPLICIT OCTET STRING (04 ll SafeContents) или IMPLICIT (сразу SafeContents).
+	content, base := t.Content, t.Offset+t.HeaderLen
+	if unwrapped := unwrapOctetStringIfPresent(content); len(unwrapped) != len(content) {
+		if octet, _, err := der.Read(content); err == nil {
+			content, base = octet.Content, base+octet.HeaderLen
+		}
+	}
+	if len(content) > 0 {
+		if err := der.CheckDepth(content, maxDepth); err != nil {
+			return withPath(err, pathEContent, base)
+		}
+		if err := locateSafeContents(der.NewCursor(pathEContent, content, base)); err != nil {
+			return err
+		}
+	}
+	return eci.End()
+}
+
+// locateSafeContents проверяет SafeContents (SEQUENCE OF SafeBag): bagId, bagValue [0] и bagAttributes.
+func locateSafeContents(ec *der.Cursor) error {
+	seqTLV, err := ec.Expect("", der.ClassUniversal, der.TagSequence)
+	if err != nil {
+		return err
+	}
+	bags := der.NewCursor(ec.Path, seqTLV.Content, seqTLV.Offset+seqTLV.HeaderLen)
+	for i := 1; !bags.Done(); i++ {
+		field := fmt.Sprintf("safeBag[%d]", i)
+		t, err := bags.Expect(field, der.ClassUniversal, der.TagSequence)
+		if err != nil {
+			return err
+		}
+		bag := bags.Enter(field, t)
+		if _, _, err := bag.OID("bagId"); err != nil {
+			return err
+		}
+		if _, err := bag.Expect("bagValue", der.ClassContextSpecific, 0); err != nil {
+			return err
+		}
+		if attrsTLV, ok := bag.Optional(der.ClassUniversal, der.TagSet); ok {
+			if err := locateAttributes(bag.Enter("bagAttributes", attrsTLV)); err != nil {
+				return err
+			}
+		}
+		if err := bag.End(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// locateAttributes проверяет элементы SET OF Attribute: SEQUENCE { attrType OID, attrValues SET }.
+// Путь элемента — путь SET с индексом: ...bagAttributes[1].
+func locateAttributes(set *der.Cursor) error {
+	parent := der.NewCursor("", nil, 0)
+	for i := 1; !set.Done(); i++ {
+		path := fmt.Sprintf("%s[%d]", set.Path, i)
+		t, err := set.Expect("", der.ClassUniversal, der.TagSequence)
+		if err != nil {
+			return withPath(err, path, 0)
+		}
+		attr := parent.Enter(path, t)
+		if _, _, err := attr.OID("attrType"); err != nil {
+			return err
+		}
+		if _, err := attr.Expect("attrValues", der.ClassUniversal, der.TagSet); err != nil {
+			return err
+		}
+		if err := attr.End(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// locateCertificates проверяет certificates [0]: SET (EXPLICIT) или сразу элементы (IMPLICIT);
+// элементы OCTET STRING должны содержать корректный X.509 (как в parseCertificateSet).
+func locateCertificates(certs *der.Cursor) error {
+	if t, ok := certs.Peek(); ok && t.Is(der.ClassUniversal, der.TagSet) {
+		certs.Optional(der.ClassUniversal, der.TagSet)
+		certs = der.NewCursor(certs.Path, t.Content, t.Offset+t.HeaderLen)
+	}
+	for i := 1; !certs.Done(); i++ {
+		field := fmt.Sprintf("certificate[%d]", i)
+		t, err := certs.Next(field)
+		if err != nil {
+			return err
+		}
+		if !t.Is(der.ClassUniversal, der.TagOctetString) {
+			continue
+		}
+		if _, err := x509.ParseCertificate(t.Content); err != nil {
+			return certs.Wrap(field, t, err)
+		}
+	}
+	return nil
+}
+
+// locateSignerInfo проверяет поля SignerInfo (атрибуты подписанта разбираются отдельно и Parse не ломают).
+func locateSignerInfo(si *der.Cursor) error {
+	if _, _, err := si.Int("version"); err != nil {
+		return err
+	}
+	if _, err := si.Next("sid"); err != nil {
+		return err
+	}
+	if err := locateAlgorithmField(si, "digestAlgorithm"); err != nil {
+		return err
+	}
+	si.Optional(der.ClassContextSpecific, 0) // authenticatedAttributes
+	if err := locateAlgorithmField(si, "signatureAlgorithm"); err != nil {
+		return err
+	}
+	if _, err := si.Expect("signature", der.ClassUniversal, der.TagOctetString); err != nil {
+		return err
+	}
+	si.Optional(der.ClassContextSpecific, 1) // unauthenticatedAttributes
+	return si.End()
+}
@@ -0,0 +1,196 @@
+// marshal.go — повторное кодирование разобранного контейнера: исходные байты без изменений или каноническая
+// форма реестра ATOM по ADR-011 (как у BuildRegistry) с отчётом об изменениях и о действительности подписи.
+package registry
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"fmt"
+)
+
+// MarshalMode — режим Container.Marshal.
+type MarshalMode int
+
+const (
+	// MarshalOriginal — исходная DER-кодировка контейнера байт в байт.
+	MarshalOriginal MarshalMode = iota
+	// MarshalCanonical — опорная форма ADR-011: полный SignedData в content [0], eContent [0] EXPLICIT OCTET STRING,
+	// certificates [0] — полный SET из OCTET STRING с сортировкой по DER, sid [0] EXPLICIT OCTET STRING,
+	// authenticatedAttributes [0] — полный SET с сортировкой по DER, unauthenticatedAttributes [1] — SET (пустой,
+	// если атрибутов не было), PFX без macData.
+	MarshalCanonical
+)
+
+// MarshalResult — результат Container.Marshal. Changes перечисляет нормализованные элементы (пусто, если
+// каноническая форма совпала с исходной). SignatureValid — все подписанты результата проходят Verify,
+// OriginalSignatureValid — то же для исходного контейнера; у хранилища PKCS#12 подписи нет, оба поля false.
+type MarshalResult struct {
+	DER                    []byte
+	Changed                bool
+	Changes                []string
+	SignatureValid         bool
+	OriginalSignatureValid bool
+}
+
+// Marshal кодирует разобранный контейнер заново. MarshalOriginal возвращает копию входа Parse; MarshalCanonical
+// определён только для реестра ATOM (Kind = KindRegistry) и приводит IMPLICIT-варианты, которые допускает Parse,
+// к опорной форме. Подпись над authenticatedAttributes остаётся действительной, если атрибуты уже отсортированы
+// по DER: перестановка меняет подписанные байты, и SignatureValid это показывает.
+func (c *Container) Marshal(mode MarshalMode) (*MarshalResult, error) {
+	if len(c.raw) == 0 {
+		return nil, fmt.Errorf("original encoding is not available: container was not produced by Parse")
+	}
+	res := &MarshalResult{}
+	if c.Kind == KindRegistry {
+		res.OriginalSignatureValid = VerifyOK(c.Verify(VerifyOptions{}))
+	}
+	switch mode {
+	case MarshalOriginal:
+		res.DER = append([]byte(nil), c.raw...)
+		res.SignatureValid = res.OriginalSignatureValid
+		return res, nil
+	case MarshalCanonical:
+	default:
+		return nil, fmt.Errorf("unknown marshal mode %d", mode)
+	}
+	if c.Kind != KindRegistry || c.SignedData == nil {
+		return nil, fmt.Errorf("canonical form is defined only for ATOM-PKCS12-REGISTRY (kind %s)", c.Kind)
+	}
+
+	out, changes, err := c.marshalCanonical()
+	if err != nil {
+		return nil, err
+	}
+	res.DER = out
+	res.Changed = !bytes.Equal(out, c.raw)
+	res.Changes = changes
+	if res.Changed && len(changes) == 0 {
+		res.Changes = []string{"DER re-encoding (lengths, tags or element order)"}
+	}
+	nc, err := Parse(out)
+	if err != nil {
+		return nil, fmt.Errorf("re-parse canonical form: %w", err)
+	}
+	res.SignatureValid = VerifyOK(nc.Verify(VerifyOptions{}))
+	return res, nil
+}
+
+// marshalCanonical собирает PFX реестра в опорной форме и перечисляет отличия от исходной кодировки.
+func (c *Container) marshalCanonical() ([]byte, []string, error) {
+	var changes []string
+	var orig PFX
+	if _, err := asn1.Unmarshal(c.raw, &orig); err != nil {
+		return nil, nil, fmt.Errorf("original PFX: %w", err)
+	}
+	if orig.MacData.Mac.DigestAlgorithm.Algorithm != nil {
+		changes = append(changes, "PFX.macData removed")
+	}
+	if b := orig.AuthSafe.Content.Bytes; len(b) > 0 && b[0] != 0x30 {
+		changes = append(changes, "content [0]: IMPLICIT SignedData → full SignedData TLV")
+	}
+
+	sd := *c.SignedData
+	if eContent := c.EContent(); len(eContent) > 0 {
+		octet, err := asn1.Marshal(eContent)
+		if err != nil {
+			return nil, nil, fmt.Errorf("eContent: %w", err)
+		}
+		ec := asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: octet}
+		if !sd.EncapContentInfo.EContent.IsCompound || !bytes.Equal(sd.EncapContentInfo.EContent.Bytes, octet) {
+			changes = append(changes, "eContent [0]: IMPLICIT OCTET STRING → [0] EXPLICIT OCTET STRING")
+		}
+		sd.EncapContentInfo.EContent = ec
+	}
+
+	if len(sd.Certificates.Bytes) > 0 || len(c.Certificates) > 0 {
+		raws := make([][]byte, len(c.Certificates))
+		for i, cert := range c.Certificates {
+			raws[i] = cert.Raw
+		}
+		set, err := marshalCertificateSet(raws)
+		if err != nil {
+			return nil, nil, fmt.Errorf("certificates: %w", err)
+		}
+		if !bytes.Equal(sd.Certificates.Bytes, set) {
+			changes = append(changes, "certificates [0]: full SET OF OCTET STRING sorted by DER")
+		}
+		sd.Certificates = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: set}
+	}
+
+	sd.SignerInfos = make([]SignerInfo, len(c.SignedData.SignerInfos))
+	for i := range c.SignedData.SignerInfos {
+		si, siChanges, err := canonicalSignerInfo(c.SignedData.SignerInfos[i])
+		if err != nil {
+			return nil, nil, fmt.Errorf("SignerInfo[%d]: %w", i+1, err)
+		}
+		for _, ch := range siChanges {
+			changes = append(changes, fmt.Sprintf("SignerInfo[%d].%s", i+1, ch))
+		}
+		sd.SignerInfos[i] = si
+	}
+
+	sdDER, err := asn1.Marshal(sd)
+	if err != nil {
+		return nil, nil, fmt.Errorf("SignedData: %w", err)
+	}
+	pfx := PFX{
+		Version: 3,
+		AuthSafe: ContentInfo{
+			ContentType: OIDPKCS7SignedData,
+			Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sdDER},
+		},
+	}
+	out, err := asn1.Marshal(pfx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("PFX: %w", err)
+	}
+	return out, changes, nil
+}
+
+// canonicalSignerInfo приводит sid, authenticatedAttributes и unauthenticatedAttributes подписанта к опорной форме.
+func canonicalSignerInfo(si SignerInfo) (SignerInfo, []string, error) {
+	var changes []string
+	// sid: [0] IMPLICIT SubjectKeyIdentifier (примитивный 0x80) → [0] EXPLICIT OCTET STRING.
+	// issuerAndSerialNumber (SEQUENCE) остаётся как есть.
+	if si.SID.Class == asn1.ClassContextSpecific && si.SID.Tag == 0 && !si.SID.IsCompound {
+		sid, err := marshalSubjectKeyIdentifier(si.SID.Bytes)
+		if err != nil {
+			return si, nil, fmt.Errorf("sid: %w", err)
+		}
+		si.SID = asn1.RawValue{FullBytes: sid}
+		changes = append(changes, "sid: [0] IMPLICIT → [0] EXPLICIT OCTET STRING")
+	}
+
+	if len(si.AuthenticatedAttributes.Bytes) > 0 {
+		signed := signedAttributesDER(&si)
+		attrs, err := ParseAuthenticatedAttributes(signed)
+		if err != nil {
+			return si, nil, fmt.Errorf("authenticatedAttributes: %w", err)
+		}
+		set, err := marshalAttributeSet(sortAttributesByDER(attrs))
+		if err != nil {
+			return si, nil, fmt.Errorf("authenticatedAttributes: %w", err)
+		}
+		if !bytes.Equal(set, signed) {
+			changes = append(changes, "authenticatedAttributes: reordered by DER (signed bytes changed)")
+		} else if !bytes.Equal(si.AuthenticatedAttributes.Bytes, set) {
+			changes = append(changes, "authenticatedAttributes: [0] IMPLICIT → full SET TLV")
+		}
+		si.AuthenticatedAttributes = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: set}
+	}
+
+	unauth := si.UnauthenticatedAttributes.Bytes
+	switch {
+	case len(si.UnauthenticatedAttributes.FullBytes) == 0:
+		unauth = []byte{0x31, 0x00}
+		changes = append(changes, "unauthenticatedAttributes: empty SET added")
+	case len(unauth) > 0 && unauth[0] != 0x31:
+		unauth = derPrependTLV(0x31, unauth)
+		changes = append(changes, "unauthenticatedAttributes: [1] IMPLICIT → full SET TLV")
+	case len(unauth) == 0:
+		unauth = []byte{0x31, 0x00}
+		changes = append(changes, "unauthenticatedAttributes: empty [1] → empty SET")
+	}
+	si.UnauthenticatedAttributes = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 1, IsCompound: true, Bytes: unauth}
+	return si, changes, nil
+}
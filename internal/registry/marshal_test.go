@@ -0,0 +1,153 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"testing"
+	"time"
+)
+
+// buildMarshalTestRegistry собирает реестр BuildRegistry с двумя мешками и подписантом из newTestSigner.
+func buildMarshalTestRegistry(t *testing.T) []byte {
+	t.Helper()
+	cert, key := newTestSigner(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	der, err := BuildRegistry(cert, key, []SafeBagInput{
+		{CertDER: newTestLeaf(t, cert, key, "Driver", 10), RoleName: "delegate", RoleNotBefore: now, RoleNotAfter: now.Add(time.Hour)},
+		{CertDER: newTestLeaf(t, cert, key, "IVI", 11), RoleName: "not_delegate"},
+	}, SignerAttrs{VIN: "TESTVIN123", VERTimestamp: now, VERVersion: 1, UID: "CN=Test"})
+	if err != nil {
+		t.Fatalf("BuildRegistry: %v", err)
+	}
+	return der
+}
+
+// implicitVariant перекодирует реестр опорной формы в IMPLICIT-варианты, которые допускает Parse: SignedData,
+// SET сертификатов и атрибутов без внешнего тега, eContent и sid — примитивный [0], без unauthenticatedAttributes.
+func implicitVariant(t *testing.T, der []byte) []byte {
+	t.Helper()
+	var pfx PFX
+	if _, err := asn1.Unmarshal(der, &pfx); err != nil {
+		t.Fatal(err)
+	}
+	var sd SignedData
+	if _, err := asn1.Unmarshal(pfx.AuthSafe.Content.Bytes, &sd); err != nil {
+		t.Fatal(err)
+	}
+	contentOf := func(tlv []byte) []byte {
+		var v asn1.RawValue
+		if _, err := asn1.Unmarshal(tlv, &v); err != nil {
+			t.Fatal(err)
+		}
+		return v.Bytes
+	}
+	sd.EncapContentInfo.EContent = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: contentOf(sd.EncapContentInfo.EContent.Bytes)}
+	sd.Certificates = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: contentOf(sd.Certificates.Bytes)}
+	for i := range sd.SignerInfos {
+		si := &sd.SignerInfos[i]
+		si.SID = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: contentOf(si.SID.Bytes)}
+		si.AuthenticatedAttributes = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: contentOf(si.AuthenticatedAttributes.Bytes)}
+		si.UnauthenticatedAttributes = asn1.RawValue{}
+	}
+	sdDER, err := asn1.Marshal(sd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pfx.AuthSafe.Content = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: contentOf(sdDER)}
+	out, err := asn1.Marshal(pfx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// TestMarshalRoundTrip проверяет parse(build(x)) == x: исходный режим возвращает вход байт в байт, а выход
+// BuildRegistry уже в канонической форме — канонический режим ничего не меняет и подпись действительна.
+func TestMarshalRoundTrip(t *testing.T) {
+	der := buildMarshalTestRegistry(t)
+	c, err := Parse(der)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	for _, mode := range []MarshalMode{MarshalOriginal, MarshalCanonical} {
+		res, err := c.Marshal(mode)
+		if err != nil {
+			t.Fatalf("Marshal(%d): %v", mode, err)
+		}
+		if !bytes.Equal(res.DER, der) || res.Changed || len(res.Changes) != 0 {
+			t.Errorf("Marshal(%d): изменения %v, совпадение с входом %v", mode, res.Changes, bytes.Equal(res.DER, der))
+		}
+		if !res.SignatureValid || !res.OriginalSignatureValid {
+			t.Errorf("Marshal(%d): подпись %v, исходная %v", mode, res.SignatureValid, res.OriginalSignatureValid)
+		}
+	}
+}
+
+// TestMarshalOriginalCopiesInput проверяет, что изменение буфера после Parse не меняет исходную кодировку контейнера.
+func TestMarshalOriginalCopiesInput(t *testing.T) {
+	der := buildMarshalTestRegistry(t)
+	buf := append([]byte(nil), der...)
+	c, err := Parse(buf)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	for i := range buf {
+		buf[i] = 0
+	}
+	res, err := c.Marshal(MarshalOriginal)
+	if err != nil || !bytes.Equal(res.DER, der) || !res.OriginalSignatureValid {
+		t.Errorf("Marshal(MarshalOriginal) после изменения буфера: %v, совпадение %v", err, res != nil && bytes.Equal(res.DER, der))
+	}
+}
+
+// TestMarshalCanonicalImplicit проверяет нормализацию IMPLICIT-варианта: результат совпадает с выходом
+// BuildRegistry, подпись остаётся действительной, изменения перечислены.
+func TestMarshalCanonicalImplicit(t *testing.T) {
+	der := buildMarshalTestRegistry(t)
+	implicit := implicitVariant(t, der)
+	if bytes.Equal(implicit, der) {
+		t.Fatal("IMPLICIT-вариант совпадает с опорной формой")
+	}
+	c, err := Parse(implicit)
+	if err != nil {
+		t.Fatalf("Parse IMPLICIT: %v", err)
+	}
+	orig, err := c.Marshal(MarshalOriginal)
+	if err != nil || !bytes.Equal(orig.DER, implicit) {
+		t.Fatalf("Marshal(MarshalOriginal): %v, совпадение %v", err, orig != nil && bytes.Equal(orig.DER, implicit))
+	}
+	res, err := c.Marshal(MarshalCanonical)
+	if err != nil {
+		t.Fatalf("Marshal(MarshalCanonical): %v", err)
+	}
+	if !bytes.Equal(res.DER, der) {
+		t.Errorf("каноническая форма не совпадает с выходом BuildRegistry")
+	}
+	if !res.Changed || !res.SignatureValid || !res.OriginalSignatureValid {
+		t.Errorf("Changed %v, подпись %v, исходная %v", res.Changed, res.SignatureValid, res.OriginalSignatureValid)
+	}
+	// content, eContent, certificates, sid, authenticatedAttributes, unauthenticatedAttributes.
+	if len(res.Changes) != 6 {
+		t.Errorf("изменений %d, ожидается 6: %v", len(res.Changes), res.Changes)
+	}
+}
+
+// TestMarshalKeystore проверяет, что хранилище PKCS#12 отдаётся в исходной кодировке, а каноническая форма
+// для него не определена.
+func TestMarshalKeystore(t *testing.T) {
+	data := readKeystore(t, "trust")
+	c, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	res, err := c.Marshal(MarshalOriginal)
+	if err != nil || !bytes.Equal(res.DER, data) {
+		t.Fatalf("Marshal(MarshalOriginal): %v", err)
+	}
+	if _, err := c.Marshal(MarshalCanonical); err == nil {
+		t.Error("Marshal(MarshalCanonical) для хранилища: ожидается ошибка")
+	}
+	if _, err := (&Container{Kind: KindRegistry}).Marshal(MarshalOriginal); err == nil {
+		t.Error("Marshal без исходной кодировки: ожидается ошибка")
+	}
+}
@@ -1,4 +1,5 @@
-// oid.go — OID из registry.asn1: PKCS#7, PKCS#9, CertBag, ATOM (VIN, VER, UID, roleName, roleValidityPeriod).
+// oid.go — OID из registry.asn1: PKCS#7, PKCS#9, CertBag, ATOM (VIN, VER, UID, roleName, roleValidityPeriod), RFC 6211;
+// алгоритмы хеширования и подписи, поддерживаемые при проверке подписи.
 package registry
 
 import "encoding/asn1"
@@ -11,10 +12,22 @@ var (
 	OIDPKCS9MessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
 	OIDPKCS9LocalKeyID    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
 	OIDPKCS9FriendlyName  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+	OIDPKCS9SigningTime   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+	// CMSAlgorithmProtection (RFC 6211): защита алгоритмов подписи от подмены
+	OIDCMSAlgorithmProtection = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 52}
 	// Типы мешков сертификатов PKCS#12
 	OIDX509Certificate = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
 	OIDSdsiCertificate = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 2}
 	OIDCertBag         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	// Остальные типы мешков PKCS#12 (RFC 7292, 4.2) и тип CRL в CRLBag
+	OIDKeyBag              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 1}
+	OIDPKCS8ShroudedKeyBag = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	OIDCRLBag              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 4}
+	OIDSecretBag           = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 5}
+	OIDSafeContentsBag     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 6}
+	OIDX509CRL             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 23, 1}
+	// Атрибут мешка Oracle/OpenJDK: без него keytool не считает сертификат без ключа доверенным (trustedCertEntry)
+	OIDJavaTrustedKeyUsage = asn1.ObjectIdentifier{2, 16, 840, 1, 113894, 746875, 1, 1}
 
 	// Атрибуты ATOM (1.3.6.1.4.1.99999.1.x): VIN, версия, UID, роль, период действия роли
 	OIDAtomVIN                = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1, 1}
@@ -24,6 +37,21 @@ var (
 	OIDAtomRoleValidityPeriod = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1, 5}
 )
 
+// OID алгоритмов хеширования, подписи и MAC для проверки подписи и AlgorithmName (OIDSHA256 и OIDECDSAWithSHA256 — в builder.go).
+var (
+	OIDSHA384          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	OIDSHA512          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+	OIDECDSAWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}
+	OIDECDSAWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 4}
+	OIDRSAEncryption   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	OIDSHA256WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	OIDSHA384WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 12}
+	OIDSHA512WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 13}
+	OIDEd25519         = asn1.ObjectIdentifier{1, 3, 101, 112}
+	OIDECPublicKey     = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	OIDHMACWithSHA256  = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+)
+
 // AtomOIDs — список всех OID атрибутов ATOM для поиска и итерации.
 var AtomOIDs = []asn1.ObjectIdentifier{
 	OIDAtomVIN, OIDAtomVER, OIDAtomUID, OIDAtomRoleName, OIDAtomRoleValidityPeriod,
@@ -50,12 +78,30 @@ func OIDToAtomName(oid asn1.ObjectIdentifier) string {
 		return "localKeyID"
 	case oid.Equal(OIDPKCS9FriendlyName):
 		return "friendlyName"
+	case oid.Equal(OIDPKCS9SigningTime):
+		return "signingTime"
+	case oid.Equal(OIDCMSAlgorithmProtection):
+		return "cmsAlgorithmProtection"
 	case oid.Equal(OIDX509Certificate):
 		return "x509Certificate"
 	case oid.Equal(OIDSdsiCertificate):
 		return "sdsiCertificate"
 	case oid.Equal(OIDCertBag):
 		return "certBag"
+	case oid.Equal(OIDKeyBag):
+		return "keyBag"
+	case oid.Equal(OIDPKCS8ShroudedKeyBag):
+		return "pkcs8ShroudedKeyBag"
+	case oid.Equal(OIDCRLBag):
+		return "crlBag"
+	case oid.Equal(OIDSecretBag):
+		return "secretBag"
+	case oid.Equal(OIDSafeContentsBag):
+		return "safeContentsBag"
+	case oid.Equal(OIDX509CRL):
+		return "x509CRL"
+	case oid.Equal(OIDJavaTrustedKeyUsage):
+		return "trustedKeyUsage"
 	default:
 		return ""
 	}
@@ -69,6 +115,9 @@ func CertTypeName(oid asn1.ObjectIdentifier) string {
 	if oid.Equal(OIDSdsiCertificate) {
 		return "SDSI Certificate"
 	}
+	if oid.Equal(OIDX509CRL) {
+		return "X.509 CRL"
+	}
 	if n := OIDToAtomName(oid); n != "" {
 		return n
 	}
@@ -3,13 +3,15 @@ package registry
 
 import (
 	"crypto/x509"
-	"encoding/base64"
 	"encoding/hex"
 	"encoding/pem"
 	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+	"github.com/sgw-registry/registry-analyzer/report"
 )
 
 // hexEncode кодирует байты в hex-строку для вывода (например, SubjectKeyId).
@@ -87,6 +89,8 @@ const (
 	FormatJSON             = "json"
 	FormatJSONCertificates = "json-certificates" // только реальные данные сертификатов в JSON
 	FormatPEM              = "pem"               // все сертификаты в PEM для криптоопераций
+	FormatJWKS             = "jwks"              // ключи сертификатов SafeBags как JWK Set
+	FormatPins             = "pins"              // пины SHA-256 SPKI сертификатов SafeBags по ролям
 )
 
 // TextOutput формирует человекочитаемый отчёт и записывает его в strings.Builder.
@@ -101,8 +105,32 @@ func (c *Container) TextOutput(sb *strings.Builder, useColor bool) {
 		sb.WriteString(reset)
 		sb.WriteString("=== PFX ===\n")
 	}
+	sb.WriteString(fmt.Sprintf("  %sKind:%s       %s%s%s\n", dim, reset, val, KindName(c.Kind), reset))
 	sb.WriteString(fmt.Sprintf("  %sVersion:%s    %s%d%s\n", dim, reset, val, c.PFXVersion, reset))
 	sb.WriteString(fmt.Sprintf("  %sContentType:%s %s%s%s\n", dim, reset, val, c.ContentType, reset))
+	if c.Kind == KindKeystore {
+		sb.WriteString(fmt.Sprintf("  %sMAC:%s        %s%s%s\n", dim, reset, val, c.macText(useColor), reset))
+
+		// Секция: AuthenticatedSafe хранилища — ContentInfo data/encryptedData, шифрование и число мешков.
+		if useColor {
+			sb.WriteString("\n" + head + IconSafeBag + " AuthenticatedSafe" + reset + "\n")
+		} else {
+			sb.WriteString("\n=== AuthenticatedSafe ===\n")
+		}
+		for i, as := range c.AuthSafe {
+			sb.WriteString(i18n.Sprintf("  %s[%d]%s %s%s%s, мешков: %d\n", bold, i+1, reset, val, as.ContentType, reset, as.SafeBags))
+			if as.EncryptionAlgorithm != "" {
+				sb.WriteString(fmt.Sprintf("       %sencryption:%s %s%s%s\n", dim, reset, val, as.EncryptionAlgorithm, reset))
+			}
+			if as.Error != "" {
+				errColor := ""
+				if useColor {
+					errColor = Red
+				}
+				sb.WriteString(fmt.Sprintf("       %serror:%s %s%s%s\n", dim, reset, errColor, as.Error, reset))
+			}
+		}
+	}
 
 	// Секция: сертификаты из SignedData (subject, issuer, serial, срок действия, KeyAlg, SubjectKeyId).
 	if useColor {
@@ -114,7 +142,7 @@ func (c *Container) TextOutput(sb *strings.Builder, useColor bool) {
 		isSigner := c.isSignerCert(cert)
 		mark := ""
 		if useColor && isSigner {
-			mark = " " + IconSigner + " " + Bold + Green + "(подписант контейнера)" + reset
+			mark = " " + IconSigner + " " + Bold + Green + "(" + i18n.T("подписант контейнера") + ")" + reset
 		}
 		if useColor {
 			sb.WriteString(fmt.Sprintf("  %s[%d]%s %sSubject:%s %s%s%s\n", bold, i+1, reset, dim, reset, certHead, cert.Subject.String(), reset))
@@ -129,7 +157,7 @@ func (c *Container) TextOutput(sb *strings.Builder, useColor bool) {
 			sb.WriteString(fmt.Sprintf("       %sSubjectKeyId:%s %s%x\n", dim, reset, val, cert.SubjectKeyId))
 		}
 		if !useColor && isSigner {
-			sb.WriteString("       (подписант контейнера)\n")
+			sb.WriteString("       (" + i18n.T("подписант контейнера") + ")\n")
 		}
 		sb.WriteString(reset)
 	}
@@ -137,9 +165,9 @@ func (c *Container) TextOutput(sb *strings.Builder, useColor bool) {
 	// Секция: подписант контейнера — кто подписал SignedData (Subject, Serial, KeyAlg по сертификату из SID).
 	if len(c.Signers) > 0 {
 		if useColor {
-			sb.WriteString("\n" + head + IconSigner + " Подписант контейнера" + reset + "\n")
+			sb.WriteString("\n" + head + IconSigner + " " + i18n.T("Подписант контейнера") + reset + "\n")
 		} else {
-			sb.WriteString("\n=== Подписант контейнера ===\n")
+			sb.WriteString("\n=== " + i18n.T("Подписант контейнера") + " ===\n")
 		}
 		for i, si := range c.Signers {
 			signerCert := c.SignerCert(&si)
@@ -153,42 +181,62 @@ func (c *Container) TextOutput(sb *strings.Builder, useColor bool) {
 					sb.WriteString(fmt.Sprintf("  KeyAlg:  %s\n", signerCert.PublicKeyAlgorithm.String()))
 				}
 			} else {
-				sb.WriteString(fmt.Sprintf("  Signer [%d] (сертификат не найден в списке)\n", i+1))
+				sb.WriteString(i18n.Sprintf("  Signer [%d] (сертификат не найден в списке)\n", i+1))
 			}
 		}
 	}
 
-	// Секция: SafeContents (eContent) — мешки SafeBag с certId, данными сертификата и атрибутами мешка.
+	// Секция: SafeContents (eContent) — мешки SafeBag: certId и данные сертификата или сводка по типу мешка, атрибуты мешка.
 	if len(c.SafeBagInfos) > 0 {
 		if useColor {
 			sb.WriteString("\n" + head + IconSafeBag + " SafeContents (eContent)" + reset + "\n")
 		} else {
 			sb.WriteString("\n=== SafeContents (eContent) ===\n")
 		}
-		for i, info := range c.SafeBagInfos {
+		// writeBag выводит один мешок; вложенные мешки safeContentsBag — рекурсивно с большим отступом.
+		var writeBag func(label, indent string, info *SafeBagInfo)
+		writeBag = func(label, indent string, info *SafeBagInfo) {
 			if useColor {
-				sb.WriteString(fmt.Sprintf("  %s[%d]%s %sbagId:%s %s%s\n", bold, i+1, reset, dim, reset, val, info.BagId))
+				sb.WriteString(fmt.Sprintf("%s%s%s%s %sbagId:%s %s%s\n", indent, bold, label, reset, dim, reset, val, info.BagId))
 			} else {
-				sb.WriteString(fmt.Sprintf("  [%d] bagId: %s\n", i+1, info.BagId))
+				sb.WriteString(fmt.Sprintf("%s%s bagId: %s\n", indent, label, info.BagId))
 			}
-			sb.WriteString(fmt.Sprintf("       %scertId:%s   %s%s%s (%s)\n", dim, reset, val, info.CertId, reset, info.CertType))
-			if info.CertSummary != nil {
-				sb.WriteString(fmt.Sprintf("       %sSubject:%s  %s%s\n", dim, reset, val, info.CertSummary.Subject))
-				sb.WriteString(fmt.Sprintf("       %sIssuer:%s   %s%s\n", dim, reset, val, info.CertSummary.Issuer))
-				sb.WriteString(fmt.Sprintf("       %sSerial:%s   %s%s\n", dim, reset, val, info.CertSummary.Serial))
-				sb.WriteString(fmt.Sprintf("       %sValid:%s    %s%s — %s\n", dim, reset, val, info.CertSummary.NotBefore, info.CertSummary.NotAfter))
-				sb.WriteString(fmt.Sprintf("       %sKeyAlg:%s   %s%s\n", dim, reset, val, info.CertSummary.KeyAlg))
+			in := indent + "     "
+			if len(info.CertId) > 0 {
+				sb.WriteString(fmt.Sprintf("%s%scertId:%s   %s%s%s (%s)\n", in, dim, reset, val, info.CertId, reset, info.CertType))
+				if info.CertSummary != nil {
+					sb.WriteString(fmt.Sprintf("%s%sSubject:%s  %s%s\n", in, dim, reset, val, info.CertSummary.Subject))
+					sb.WriteString(fmt.Sprintf("%s%sIssuer:%s   %s%s\n", in, dim, reset, val, info.CertSummary.Issuer))
+					sb.WriteString(fmt.Sprintf("%s%sSerial:%s   %s%s\n", in, dim, reset, val, info.CertSummary.Serial))
+					sb.WriteString(fmt.Sprintf("%s%sValid:%s    %s%s — %s\n", in, dim, reset, val, info.CertSummary.NotBefore, info.CertSummary.NotAfter))
+					sb.WriteString(fmt.Sprintf("%s%sKeyAlg:%s   %s%s\n", in, dim, reset, val, info.CertSummary.KeyAlg))
+				} else {
+					sb.WriteString(fmt.Sprintf("%s%scertValue:%s %s%d bytes (not X.509)\n", in, dim, reset, val, info.CertValueLen))
+				}
 			} else {
-				sb.WriteString(fmt.Sprintf("       %scertValue:%s %s%d bytes (not X.509)\n", dim, reset, val, info.CertValueLen))
+				sb.WriteString(fmt.Sprintf("%s%sbagType:%s  %s%s%s\n", in, dim, reset, val, info.BagType, reset))
+				for _, f := range info.BagValueFields() {
+					sb.WriteString(fmt.Sprintf("%s%s%s:%s %s%s%s\n", in, dim, f.Name, reset, val, f.Value, reset))
+				}
 			}
 			for _, attr := range info.BagAttributes {
-				sb.WriteString(fmt.Sprintf("       %s%s:%s %s%s\n", nameColor, attr.Name, reset, val, attr.Value))
+				sb.WriteString(fmt.Sprintf("%s%s%s:%s %s%s\n", in, nameColor, attr.Name, reset, val, attr.Value))
 			}
 			sb.WriteString(reset)
+			for j := range info.Nested {
+				writeBag(fmt.Sprintf("[%d]", j+1), in, &info.Nested[j])
+			}
+		}
+		for i := range c.SafeBagInfos {
+			writeBag(fmt.Sprintf("[%d]", i+1), "  ", &c.SafeBagInfos[i])
 		}
 	}
 
 	// Секция: подписанты и ATOM-атрибуты (алгоритмы подписи и расшифрованные атрибуты: VIN, VER, UID и т.д.).
+	// У хранилища PKCS#12 подписантов нет.
+	if c.Kind == KindKeystore {
+		return
+	}
 	if useColor {
 		sb.WriteString("\n" + head + IconSignerInfo + " Signers and ATOM attributes" + reset + "\n")
 	} else {
@@ -219,6 +267,68 @@ func (c *Container) TextOutput(sb *strings.Builder, useColor bool) {
 	}
 }
 
+// macText — строка MAC хранилища для текстового отчёта: алгоритм, итерации и результат проверки паролем.
+func (c *Container) macText(useColor bool) string {
+	if c.Mac == nil {
+		return i18n.T("отсутствует")
+	}
+	okColor, failColor, reset := "", "", ""
+	if useColor {
+		okColor, failColor, reset = Bold+Green, Bold+Red, Reset
+	}
+	status := okColor + "OK" + reset
+	switch c.Mac.Status {
+	case MacStatusFailed:
+		status = failColor + "FAILED" + reset + " (" + i18n.T("неверный пароль или изменённое содержимое") + ")"
+	case MacStatusUnsupported:
+		status = failColor + i18n.T("не проверен") + reset + " (" + i18n.T("алгоритм не поддерживается") + ")"
+	case MacStatusSkipped:
+		status = i18n.T("не проверен") + " (" + i18n.T("пароль не задан") + ")"
+	}
+	return i18n.Sprintf("%s, %d iterations — %s", c.Mac.Algorithm, c.Mac.Iterations, status)
+}
+
+// MacVerified сообщает, что macData хранилища PKCS#12 есть и совпал с паролем.
+func (c *Container) MacVerified() bool {
+	return c.Mac != nil && c.Mac.Status == MacStatusOK
+}
+
+// VerificationTextOutput дописывает в отчёт секцию с результатами проверки подписи (флаг -verify).
+// Оформление совпадает с TextOutput: при useColor — иконка и цвета, иначе заголовок «=== ... ===».
+func VerificationTextOutput(sb *strings.Builder, results []SignerVerification, useColor bool) {
+	bold, dim, val, head, okColor, failColor, reset := "", "", "", "", "", "", ""
+	if useColor {
+		bold, dim, val, head, okColor, failColor, reset = Bold, Dim, Cyan, Bold+Yellow, Bold+Green, Bold+Red, Reset
+		sb.WriteString("\n" + head + IconKey + " " + i18n.T("Проверка подписи") + reset + "\n")
+	} else {
+		sb.WriteString("\n=== " + i18n.T("Проверка подписи") + " ===\n")
+	}
+	if len(results) == 0 {
+		sb.WriteString(i18n.Sprintf("  %s(подписанты отсутствуют)%s\n", failColor, reset))
+		return
+	}
+	for _, r := range results {
+		status := okColor + "OK" + reset
+		if !r.OK {
+			status = failColor + "FAILED" + reset
+		}
+		sb.WriteString(fmt.Sprintf("  %sSigner [%d]%s %s\n", bold, r.SignerIndex, reset, status))
+		for _, ch := range r.Checks {
+			mark := okColor + "✓" + reset
+			if !ch.OK {
+				mark = failColor + "✗" + reset
+			}
+			if !useColor {
+				mark = "ok"
+				if !ch.OK {
+					mark = "FAIL"
+				}
+			}
+			sb.WriteString(fmt.Sprintf("    %s %s%s:%s %s%s%s\n", mark, dim, ch.Name, reset, val, ch.Detail, reset))
+		}
+	}
+}
+
 // isSignerCert возвращает true, если сертификат cert используется подписантом контейнера (совпадает с SID любого SignerInfo).
 func (c *Container) isSignerCert(cert *x509.Certificate) bool {
 	for i := range c.Signers {
@@ -229,58 +339,6 @@ func (c *Container) isSignerCert(cert *x509.Certificate) bool {
 	return false
 }
 
-// certToJSONMap формирует полную JSON-структуру реальных данных сертификата (subject, issuer, serial, сроки, алгоритмы, расширения, SAN, raw DER).
-func certToJSONMap(cert *x509.Certificate, isSigner bool) map[string]interface{} {
-	m := map[string]interface{}{
-		"subject":             cert.Subject.String(),
-		"issuer":              cert.Issuer.String(),
-		"serialNumber":        cert.SerialNumber.String(),
-		"serialNumberHex":     cert.SerialNumber.Text(16),
-		"notBefore":           cert.NotBefore.Format("2006-01-02T15:04:05Z07:00"),
-		"notAfter":            cert.NotAfter.Format("2006-01-02T15:04:05Z07:00"),
-		"version":             cert.Version,
-		"signatureAlgorithm":  cert.SignatureAlgorithm.String(),
-		"publicKeyAlgorithm":  cert.PublicKeyAlgorithm.String(),
-		"isSigner":            isSigner,
-	}
-	if len(cert.SubjectKeyId) > 0 {
-		m["subjectKeyId"] = hexEncode(cert.SubjectKeyId)
-	}
-	if len(cert.AuthorityKeyId) > 0 {
-		m["authorityKeyId"] = hexEncode(cert.AuthorityKeyId)
-	}
-	if cert.KeyUsage != 0 {
-		m["keyUsage"] = keyUsageStrings(cert.KeyUsage)
-	}
-	if len(cert.ExtKeyUsage) > 0 {
-		m["extKeyUsage"] = extKeyUsageStrings(cert.ExtKeyUsage)
-	}
-	if len(cert.DNSNames) > 0 {
-		m["dnsNames"] = cert.DNSNames
-	}
-	if len(cert.EmailAddresses) > 0 {
-		m["emailAddresses"] = cert.EmailAddresses
-	}
-	if len(cert.IPAddresses) > 0 {
-		ips := make([]string, 0, len(cert.IPAddresses))
-		for _, ip := range cert.IPAddresses {
-			ips = append(ips, ip.String())
-		}
-		m["ipAddresses"] = ips
-	}
-	if len(cert.URIs) > 0 {
-		uris := make([]string, 0, len(cert.URIs))
-		for _, u := range cert.URIs {
-			uris = append(uris, u.String())
-		}
-		m["uris"] = uris
-	}
-	if len(cert.Raw) > 0 {
-		m["raw"] = base64.StdEncoding.EncodeToString(cert.Raw)
-	}
-	return m
-}
-
 // keyUsageStrings возвращает человекочитаемые имена битов KeyUsage (для выгрузки в JSON).
 func keyUsageStrings(ku x509.KeyUsage) []string {
 	var s []string
@@ -341,67 +399,33 @@ func extKeyUsageStrings(eku []x509.ExtKeyUsage) []string {
 	return out
 }
 
-// JSONOutput возвращает структуру, пригодную для json.Marshal (карта с ключами pfxVersion, certificates, safeBags, signers и т.д.).
-// Сертификаты выгружаются в виде полных реальных данных (subject, issuer, serial, сроки, алгоритмы, расширения, SAN, raw DER в base64).
-func (c *Container) JSONOutput() interface{} {
-	type safeBagInfo struct {
-		BagID         string              `json:"bagId"`
-		CertID        string              `json:"certId"`
-		CertType      string              `json:"certType"`
-		CertSummary   *CertSummary        `json:"certSummary,omitempty"`
-		CertValueLen  int                 `json:"certValueLen,omitempty"`
-		BagAttributes []BagAttributeValue `json:"bagAttributes,omitempty"`
-	}
-	type attrOut struct {
-		Name  string `json:"name"`
-		Value string `json:"value"`
-	}
-	type signerOut struct {
-		DigestAlgorithm    string    `json:"digestAlgorithm"`
-		SignatureAlgorithm string    `json:"signatureAlgorithm"`
-		Attributes         []attrOut `json:"attributes"`
-	}
-	certs := make([]map[string]interface{}, 0, len(c.Certificates))
-	for _, cert := range c.Certificates {
-		certs = append(certs, certToJSONMap(cert, c.isSignerCert(cert)))
+// JSONOutput возвращает типизированный отчёт о контейнере (-format json) в формате пакета report: сертификаты с отпечатками,
+// мешки, подписанты с расшифрованными атрибутами; у хранилища PKCS#12 — также authSafe и mac.
+// Verification и SchemaValidation заполняет вызывающий код (см. ReportVerification).
+func (c *Container) JSONOutput() *report.Report {
+	r := &report.Report{
+		SchemaVersion: report.SchemaVersion,
+		Kind:          c.Kind,
+		PFXVersion:    c.PFXVersion,
+		ContentType:   c.ContentType.String(),
+		Certificates:  c.reportCertificates(),
+		SafeBags:      c.reportSafeBags(c.SafeBagInfos),
+		Signers:       make([]report.Signer, 0, len(c.Signers)),
 	}
-	bags := make([]safeBagInfo, 0, len(c.SafeBagInfos))
-	for _, info := range c.SafeBagInfos {
-		bags = append(bags, safeBagInfo{
-			BagID:         info.BagId.String(),
-			CertID:        info.CertId.String(),
-			CertType:      info.CertType,
-			CertSummary:   info.CertSummary,
-			CertValueLen:  info.CertValueLen,
-			BagAttributes: info.BagAttributes,
-		})
-	}
-	signers := make([]signerOut, 0, len(c.Signers))
-	for _, si := range c.Signers {
-		so := signerOut{
-			DigestAlgorithm:    si.DigestAlgorithm.Algorithm.String(),
-			SignatureAlgorithm: si.DigestEncryptionAlgorithm.Algorithm.String(),
-			Attributes:         nil,
+	for i := range c.Signers {
+		r.Signers = append(r.Signers, reportSigner(&c.Signers[i]))
+	}
+	if c.Kind == KindKeystore {
+		r.AuthSafe = make([]report.AuthSafe, 0, len(c.AuthSafe))
+		for _, a := range c.AuthSafe {
+			r.AuthSafe = append(r.AuthSafe, report.AuthSafe(a))
 		}
-		attrs, _ := SignerAttributes(&si)
-		for _, a := range attrs {
-			for _, v := range DecodeAttributeValues(a) {
-				val := v.Value
-				if val == "" {
-					val = v.Raw
-				}
-				so.Attributes = append(so.Attributes, attrOut{Name: v.Name, Value: val})
-			}
+		if c.Mac != nil {
+			m := report.MAC(*c.Mac)
+			r.MAC = &m
 		}
-		signers = append(signers, so)
-	}
-	return map[string]interface{}{
-		"pfxVersion":   c.PFXVersion,
-		"contentType":  c.ContentType.String(),
-		"certificates": certs,
-		"safeBags":     bags,
-		"signers":      signers,
 	}
+	return r
 }
 
 // ToJSON возвращает отформатированные JSON-байты контейнера.
@@ -409,14 +433,10 @@ func (c *Container) ToJSON() ([]byte, error) {
 	return json.MarshalIndent(c.JSONOutput(), "", "  ")
 }
 
-// CertificatesJSONOutput возвращает структуру только с реальными данными сертификатов (subject, issuer, serial, сроки, алгоритмы, расширения, SAN, raw DER).
-// Удобно для выгрузки в файл или потребления другими инструментами.
-func (c *Container) CertificatesJSONOutput() interface{} {
-	certs := make([]map[string]interface{}, 0, len(c.Certificates))
-	for _, cert := range c.Certificates {
-		certs = append(certs, certToJSONMap(cert, c.isSignerCert(cert)))
-	}
-	return map[string]interface{}{"certificates": certs}
+// CertificatesJSONOutput возвращает отчёт только с реальными данными сертификатов (-format json-certificates):
+// subject, issuer, serial, сроки, алгоритмы, расширения, SAN, отпечатки, raw DER. Удобно для потребления другими инструментами.
+func (c *Container) CertificatesJSONOutput() *report.CertificatesReport {
+	return &report.CertificatesReport{SchemaVersion: report.SchemaVersion, Certificates: c.reportCertificates()}
 }
 
 // ToCertificatesJSON возвращает отформатированные JSON-байты только с массивом сертификатов.
@@ -451,6 +471,26 @@ func (c *Container) ToSafeBagsPEM() ([]byte, error) {
 	return b, nil
 }
 
+// BundleCertificates возвращает DER сертификатов для набора .p7b: сначала сертификаты SafeBags (X.509),
+// затем SignedData.certificates; повторяющиеся сертификаты (у хранилища PKCS#12 это все) включаются один раз.
+func (c *Container) BundleCertificates() [][]byte {
+	var out [][]byte
+	seen := make(map[string]bool)
+	add := func(der []byte) {
+		if len(der) > 0 && !seen[string(der)] {
+			seen[string(der)] = true
+			out = append(out, der)
+		}
+	}
+	for _, info := range c.SafeBagInfos {
+		add(info.CertValueDER)
+	}
+	for _, cert := range c.Certificates {
+		add(cert.Raw)
+	}
+	return out
+}
+
 // SignerCertPEM возвращает PEM сертификата подписанта контейнера (первый SignerInfo).
 // Если подписант не найден среди c.Certificates, возвращает nil, nil.
 func (c *Container) SignerCertPEM() ([]byte, error) {
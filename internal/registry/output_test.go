@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+)
+
+// hasCyrillic возвращает первую строку s с кириллицей.
+func hasCyrillic(s string) (string, bool) {
+	for _, line := range strings.Split(s, "\n") {
+		for _, r := range line {
+			if unicode.Is(unicode.Cyrillic, r) {
+				return line, true
+			}
+		}
+	}
+	return "", false
+}
+
+// TestTextOutputEnglish проверяет, что при языке EN текстовые отчёты реестра, проверки подписи, плана сборки
+// и хранилища (в том числе с неверным паролем) не содержат непереведённых русских строк.
+func TestTextOutputEnglish(t *testing.T) {
+	i18n.Set(i18n.EN)
+	t.Cleanup(func() { i18n.Set(i18n.RU) })
+
+	c, err := Parse(buildMarshalTestRegistry(t))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	var sb strings.Builder
+	c.TextOutput(&sb, false)
+	VerificationTextOutput(&sb, c.Verify(VerifyOptions{}), false)
+	VerificationTextOutput(&sb, nil, true)
+
+	cert, _ := newTestSigner(t)
+	plan, err := BuildPlan(cert, []SafeBagInput{{CertDER: cert.Raw}, {CertDER: cert.Raw}}, SignerAttrs{}, PlanOptions{})
+	if err != nil {
+		t.Fatalf("BuildPlan: %v", err)
+	}
+	plan.TextOutput(&sb, false)
+
+	for _, password := range []string{"changeit", "wrong"} {
+		ks, err := ParseWithPassword(readKeystore(t, "modern"), DefaultLimits, password)
+		if err != nil {
+			t.Fatalf("ParseWithPassword(%s): %v", password, err)
+		}
+		ks.TextOutput(&sb, true)
+	}
+
+	if line, ok := hasCyrillic(sb.String()); ok {
+		t.Errorf("непереведённая строка: %q", line)
+	}
+	if !strings.Contains(sb.String(), "Container signer") || !strings.Contains(sb.String(), "Warnings") {
+		t.Error("в отчёте нет английских заголовков")
+	}
+}
@@ -1,13 +1,17 @@
 // Package registry обеспечивает разбор и сборку контейнеров ATOM-PKCS12-REGISTRY (.p12).
 // Контейнер — PFX (PKCS#12) с authSafe = ContentInfo(SignedData). eContent декодируется как SafeContents (SEQUENCE OF SafeBag).
 // Подписант идентифицируется по SubjectKeyIdentifier в SignerInfo.sid.
+// Обычные хранилища PKCS#12 (authSafe = ContentInfo(data)) разбираются тем же Parse в Container с Kind = KindKeystore.
 package registry
 
 import (
 	"encoding/asn1"
+	"errors"
 	"fmt"
 
 	"crypto/x509"
+
+	"github.com/sgw-registry/registry-analyzer/internal/der"
 )
 
 // Container — результат разбора контейнера ATOM-PKCS12-REGISTRY.
@@ -18,14 +22,22 @@ import (
 //   - Certificates — сертификаты из SignedData.certificates (подписант + CA)
 //   - SafeBags, SafeBagInfos — мешки из eContent (сертификаты ролей Driver, IVI и т.д.)
 //   - Signers — SignerInfo с атрибутами (VIN, VER, UID в authenticatedAttributes)
+//
+// Для хранилища PKCS#12 (Kind = KindKeystore) SignedData и Signers пусты, SafeBags собраны из всех ContentInfo
+// AuthenticatedSafe (AuthSafe), Certificates — сертификаты X.509 из certBag, Mac — результат проверки macData.
 type Container struct {
+	Kind         string // KindRegistry или KindKeystore
 	PFXVersion   int
 	ContentType  asn1.ObjectIdentifier
 	SignedData   *SignedData
 	Certificates []*x509.Certificate
 	SafeBags     []SafeBag
-	SafeBagInfos []SafeBagInfo // расшифрованные SafeBag: CertBag и атрибуты
+	SafeBagInfos []SafeBagInfo // расшифрованные SafeBag (по одному на каждый SafeBags[i]): значение по типу мешка и атрибуты
 	Signers      []SignerInfo
+	AuthSafe     []AuthSafeInfo // только хранилище: ContentInfo из AuthenticatedSafe
+	Mac          *MacInfo       // только хранилище: macData и результат проверки; nil — macData нет
+
+	raw []byte // копия входа Parse: исходная кодировка для Marshal(MarshalOriginal)
 }
 
 // derPrependTLV добавляет DER-тег и длину к content.
@@ -35,43 +47,78 @@ func derPrependTLV(tag byte, content []byte) []byte {
 	if len(content) == 0 {
 		return nil
 	}
-	l := len(content)
-	var lenBytes []byte
-	if l < 128 {
-		lenBytes = []byte{byte(l)}
-	} else if l <= 255 {
-		lenBytes = []byte{0x81, byte(l)}
-	} else {
-		lenBytes = []byte{0x82, byte(l >> 8), byte(l)}
-	}
-	return append(append([]byte{tag}, lenBytes...), content...)
+	return der.PrependHeader(tag, content)
 }
 
-// Parse разбирает DER-кодированный файл .p12 (PFX с authSafe = ContentInfo(SignedData)).
+// Parse разбирает DER-кодированный файл .p12 (PFX с authSafe = ContentInfo(SignedData)) с лимитами DefaultLimits.
 //
 // Этапы:
-//  1. Разбор PFX, проверка version=3 и contentType=pkcs7-signedData
-//  2. Разбор SignedData (с восстановлением TLV при IMPLICIT content [0])
-//  3. Извлечение сертификатов из certificates [0] (SET OF Certificate)
-//  4. Декодирование eContent как SafeContents, разбор SafeBag и атрибутов
+//  1. Проверка лимитов: размер входа и глубина вложенности (в т.ч. внутри eContent)
+//  2. Разбор PFX, проверка version=3 и contentType=pkcs7-signedData (pkcs7-data — хранилище PKCS#12, см. ParseWithPassword)
+//  3. Разбор SignedData (с восстановлением TLV при IMPLICIT content [0])
+//  4. Извлечение сертификатов из certificates [0] (SET OF Certificate)
+//  5. Декодирование eContent как SafeContents, разбор SafeBag и атрибутов
 //
 // Поддерживается как полный TLV в content [0], так и IMPLICIT (только содержимое без тега).
-func Parse(der []byte) (*Container, error) {
+func Parse(data []byte) (*Container, error) {
+	return ParseWithLimits(data, DefaultLimits)
+}
+
+// ParseWithLimits — Parse с заданными лимитами (нулевое поле Limits — без ограничения).
+// При превышении лимита возвращается ошибка, оборачивающая ErrLimitExceeded.
+// Ошибки разбора структуры — *der.ParseError со смещением, ASN.1-путём и ожидаемым/фактическим тегом.
+// Хранилище PKCS#12 разбирается без пароля: MAC не проверяется, зашифрованное не расшифровывается.
+func ParseWithLimits(data []byte, lim Limits) (*Container, error) {
+	return ParseWithPassword(data, lim, "")
+}
+
+// ParseWithPassword — ParseWithLimits с паролем хранилища PKCS#12: им проверяется macData и расшифровываются
+// encryptedData и pkcs8ShroudedKeyBag. Неверный пароль не ошибка разбора: Mac.Status = MacStatusFailed,
+// у нерасшифрованных ContentInfo заполнен AuthSafe[i].Error. Пустой пароль — пароль не задан:
+// Mac.Status = MacStatusSkipped, KDF не вызывается (пустым паролем проверяет ParseWithEmptyPassword).
+// Для реестра ATOM пароль не используется.
+func ParseWithPassword(data []byte, lim Limits, password string) (*Container, error) {
+	if password == "" {
+		return parseContainer(data, lim, nil)
+	}
+	return parseContainer(data, lim, newPBEPassword(password))
+}
+
+// ParseWithEmptyPassword — ParseWithPassword с явно заданным пустым паролем: хранилища keytool и OpenSSL
+// без пароля (-nodes, -passout pass:) защищены MAC от пустого пароля.
+func ParseWithEmptyPassword(data []byte, lim Limits) (*Container, error) {
+	return parseContainer(data, lim, newPBEPassword(""))
+}
+
+// parseContainer — общий разбор Parse*: pw == nil — пароль хранилища не задан.
+func parseContainer(data []byte, lim Limits, pw *pbePassword) (*Container, error) {
+	if lim.MaxInputSize > 0 && len(data) > lim.MaxInputSize {
+		return nil, fmt.Errorf("%w: input is %d bytes, limit %d", ErrLimitExceeded, len(data), lim.MaxInputSize)
+	}
+	// Контейнер (raw, RawValue полей, сертификаты) ссылается на вход: копия отвязывает его от буфера вызывающего.
+	data = append([]byte(nil), data...)
+	if err := der.CheckDepth(data, lim.MaxDepth); err != nil {
+		return nil, parseFailure(data, lim.MaxDepth, pathPFX, err)
+	}
+
 	var pfx PFX
-	rest, err := asn1.Unmarshal(der, &pfx)
+	rest, err := asn1.Unmarshal(data, &pfx)
 	if err != nil {
-		return nil, fmt.Errorf("PFX unmarshal: %w", err)
+		return nil, parseFailure(data, lim.MaxDepth, pathPFX, err)
 	}
 	if len(rest) > 0 {
-		return nil, fmt.Errorf("trailing bytes after PFX")
+		return nil, parseFailure(data, lim.MaxDepth, pathPFX, fmt.Errorf("trailing bytes after PFX"))
 	}
 	if pfx.Version != 3 {
-		return nil, fmt.Errorf("unsupported PFX version: %d", pfx.Version)
+		return nil, parseFailure(data, lim.MaxDepth, pathPFX+".version", fmt.Errorf("unsupported PFX version: %d", pfx.Version))
 	}
 
 	ci := pfx.AuthSafe
+	if ci.ContentType.Equal(OIDPKCS7Data) {
+		return parseKeystore(&Container{PFXVersion: pfx.Version, ContentType: ci.ContentType, raw: data}, &pfx, lim, pw)
+	}
 	if !ci.ContentType.Equal(OIDPKCS7SignedData) {
-		return nil, fmt.Errorf("authSafe contentType is not pkcs7-signedData: %v", ci.ContentType)
+		return nil, parseFailure(data, lim.MaxDepth, pathAuthSafe+".contentType", fmt.Errorf("authSafe contentType is neither pkcs7-signedData nor pkcs7-data: %v", ci.ContentType))
 	}
 
 	// [0] IMPLICIT SignedData: при записи в Content только content SEQUENCE без 0x30 — восстанавливаем TLV
@@ -82,41 +129,65 @@ func Parse(der []byte) (*Container, error) {
 	var sd SignedData
 	_, err = asn1.Unmarshal(signedDataDER, &sd)
 	if err != nil {
-		return nil, fmt.Errorf("SignedData unmarshal: %w", err)
+		return nil, parseFailure(data, lim.MaxDepth, pathSignedData, err)
+	}
+	if err := lim.check("SignerInfos", len(sd.SignerInfos), lim.MaxSigners); err != nil {
+		return nil, err
+	}
+	for i := range sd.SignerInfos {
+		attrs, err := SignerAttributes(&sd.SignerInfos[i])
+		if err != nil {
+			continue // ошибку атрибутов показывает вывод по конкретному подписанту
+		}
+		if err := lim.checkAttributes(fmt.Sprintf("SignerInfo[%d].authenticatedAttributes", i+1), attrs); err != nil {
+			return nil, err
+		}
 	}
 
 	c := &Container{
+		Kind:        KindRegistry,
 		PFXVersion:  pfx.Version,
 		ContentType: ci.ContentType,
 		SignedData:  &sd,
 		Signers:     sd.SignerInfos,
+		raw:         data,
 	}
 
-	// Сертификаты: [0] IMPLICIT — в Bytes может быть SET без тега 0x31, восстанавливаем только тег.
+	// Сертификаты: [0] IMPLICIT — RawValue.Bytes содержит только содержимое SET (без тега и длины),
+	// поэтому полный TLV SET восстанавливается через derPrependTLV.
 	setBytes := sd.Certificates.Bytes
 	if len(setBytes) > 0 && setBytes[0] != 0x31 {
-		setBytes = append([]byte{0x31}, sd.Certificates.Bytes...)
+		setBytes = derPrependTLV(0x31, sd.Certificates.Bytes)
 	}
 	if len(setBytes) > 0 {
-		certs, err := parseCertificateSet(setBytes)
+		certs, err := parseCertificateSet(setBytes, lim.MaxCertificates)
 		if err != nil {
-			return nil, fmt.Errorf("certificates: %w", err)
+			return nil, parseFailure(data, lim.MaxDepth, pathSignedData+".certificates", err)
 		}
 		c.Certificates = certs
 	}
 
 	// eContent: [0] IMPLICIT OCTET STRING → Bytes = SafeContents; иначе EXPLICIT → 04 ll ...
+	// Содержимое OCTET STRING при первом обходе не проверялось — проверяем глубину отдельно.
 	eContent := unwrapOctetStringIfPresent(sd.EncapContentInfo.EContent.Bytes)
 	if sd.EncapContentInfo.EContentType.Equal(OIDPKCS7Data) && len(eContent) > 0 {
-		bags, err := parseSafeContents(eContent)
+		if err := der.CheckDepth(eContent, lim.MaxDepth); err != nil {
+			return nil, parseFailure(data, lim.MaxDepth, pathEContent, err)
+		}
+		budget := &bagBudget{lim: lim}
+		bags, err := budget.safeContents(eContent)
 		if err != nil {
-			return nil, fmt.Errorf("SafeContents: %w", err)
+			return nil, parseFailure(data, lim.MaxDepth, pathEContent, err)
 		}
 		c.SafeBags = bags
-		for _, bag := range bags {
-			info, err := ParseSafeBagInfo(bag)
+		for i, bag := range bags {
+			// Мешок с ошибкой разбора значения остаётся в SafeBagInfos (с Error): индексы совпадают с SafeBags.
+			info, err := budget.bagInfo(fmt.Sprintf("SafeBag[%d].bagAttributes", i+1), bag)
 			if err != nil {
-				continue
+				if errors.Is(err, ErrLimitExceeded) {
+					return nil, err
+				}
+				info.Error = err.Error()
 			}
 			c.SafeBagInfos = append(c.SafeBagInfos, info)
 		}
@@ -126,7 +197,8 @@ func Parse(der []byte) (*Container, error) {
 }
 
 // parseCertificateSet разбирает SET OF Certificate (каждый элемент — OCTET STRING с DER-сертификатом X.509).
-func parseCertificateSet(setBytes []byte) ([]*x509.Certificate, error) {
+// max > 0 ограничивает число элементов SET.
+func parseCertificateSet(setBytes []byte, max int) ([]*x509.Certificate, error) {
 	var raw asn1.RawValue
 	_, err := asn1.Unmarshal(setBytes, &raw)
 	if err != nil {
@@ -137,7 +209,10 @@ func parseCertificateSet(setBytes []byte) ([]*x509.Certificate, error) {
 	}
 	var certs []*x509.Certificate
 	rest := raw.Bytes
-	for len(rest) > 0 {
+	for n := 1; len(rest) > 0; n++ {
+		if max > 0 && n > max {
+			return nil, fmt.Errorf("%w: more than %d certificates", ErrLimitExceeded, max)
+		}
 		var certOctet asn1.RawValue
 		rest, err = asn1.Unmarshal(rest, &certOctet)
 		if err != nil {
@@ -154,8 +229,8 @@ func parseCertificateSet(setBytes []byte) ([]*x509.Certificate, error) {
 	return certs, nil
 }
 
-// parseSafeContents разбирает SEQUENCE OF SafeBag из байтов eContent.
-func parseSafeContents(content []byte) ([]SafeBag, error) {
+// parseSafeContents разбирает SEQUENCE OF SafeBag из байтов eContent. max > 0 ограничивает число мешков.
+func parseSafeContents(content []byte, max int) ([]SafeBag, error) {
 	var seq asn1.RawValue
 	_, err := asn1.Unmarshal(content, &seq)
 	if err != nil {
@@ -167,6 +242,9 @@ func parseSafeContents(content []byte) ([]SafeBag, error) {
 	var bags []SafeBag
 	rest := seq.Bytes
 	for len(rest) > 0 {
+		if max > 0 && len(bags) >= max {
+			return nil, fmt.Errorf("%w: more than %d SafeBags", ErrLimitExceeded, max)
+		}
 		var bag SafeBag
 		var err error
 		rest, err = asn1.Unmarshal(rest, &bag)
@@ -199,29 +277,10 @@ func ParseAuthenticatedAttributes(raw []byte) ([]Attribute, error) {
 }
 
 // unwrapOctetStringIfPresent возвращает payload OCTET STRING.
-// Если d начинается с 0x04 (тег OCTET STRING), снимает обёртку и возвращает значение; иначе возвращает d как есть.
+// Если d начинается с корректного TLV OCTET STRING (0x04), снимает обёртку и возвращает значение; иначе возвращает d как есть.
 // Нужно для eContent [0] и CertBag.certValue [0]: могут быть EXPLICIT (04 ll val) или IMPLICIT (сырые байты).
 func unwrapOctetStringIfPresent(d []byte) []byte {
-	if len(d) < 2 || d[0] != 0x04 {
-		return d
-	}
-	skip := 2
-	l := int(d[1])
-	if d[1]&0x80 != 0 {
-		nlen := int(d[1] & 0x7f)
-		if 2+nlen > len(d) {
-			return d
-		}
-		l = 0
-		for i := 0; i < nlen; i++ {
-			l = l<<8 + int(d[2+i])
-		}
-		skip = 2 + nlen
-	}
-	if skip+l <= len(d) {
-		return d[skip : skip+l]
-	}
-	return d
+	return der.UnwrapOctetString(d)
 }
 
 // SignerAttributes возвращает расшифрованные атрибуты из SignerInfo.authenticatedAttributes [0].
@@ -230,10 +289,11 @@ func SignerAttributes(si *SignerInfo) ([]Attribute, error) {
 	if len(si.AuthenticatedAttributes.Bytes) == 0 {
 		return nil, nil
 	}
-	// [0] IMPLICIT: в Bytes лежит (length+content) SET, тег 0x31 был срезан; восстанавливаем только тег.
+	// [0] IMPLICIT: RawValue.Bytes содержит только содержимое SET (без тега и длины),
+	// поэтому полный TLV SET восстанавливается через derPrependTLV.
 	attrsBytes := si.AuthenticatedAttributes.Bytes
 	if attrsBytes[0] != 0x31 {
-		attrsBytes = append([]byte{0x31}, si.AuthenticatedAttributes.Bytes...)
+		attrsBytes = derPrependTLV(0x31, si.AuthenticatedAttributes.Bytes)
 	}
 	return ParseAuthenticatedAttributes(attrsBytes)
 }
@@ -1,9 +1,14 @@
 package registry
 
 import (
+	"bytes"
+	"encoding/asn1"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/sgw-registry/registry-analyzer/internal/der"
 )
 
 // TestParse проверяет разбор контейнера: загружает owner_registry.p12 (из текущей директории или выше по дереву)
@@ -79,3 +84,45 @@ func TestParseFile(t *testing.T) {
 		t.Error("ожидается атрибут VIN у первого подписанта")
 	}
 }
+
+// TestParseErrorLocation проверяет, что испорченный атрибут мешка даёт der.ParseError с ASN.1-путём и смещением.
+func TestParseErrorLocation(t *testing.T) {
+	cert, key := newTestSigner(t)
+	data, err := BuildRegistry(cert, key, []SafeBagInput{{CertDER: cert.Raw, RoleName: "delegate"}}, SignerAttrs{VIN: "TESTVIN123"})
+	if err != nil {
+		t.Fatalf("BuildRegistry: %v", err)
+	}
+	c, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	// Первый атрибут мешка — ищем его DER в файле и портим тег SEQUENCE.
+	attr, err := asn1.Marshal(c.SafeBags[0].BagAttributes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	off := bytes.Index(data, attr)
+	if off < 0 {
+		t.Fatal("атрибут мешка не найден в DER")
+	}
+	bad := append([]byte(nil), data...)
+	bad[off] = 0x5c
+
+	_, err = Parse(bad)
+	var perr *der.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("err = %v, ожидается *der.ParseError", err)
+	}
+	wantPath := "PFX.authSafe.content.signedData.encapContentInfo.eContent.safeBag[1].bagAttributes[1]"
+	if perr.Path != wantPath || perr.Offset != off || perr.Expected != "SEQUENCE" || perr.Actual != "[APPLICATION 28]" {
+		t.Errorf("ParseError = %+v, ожидается путь %s, смещение %d", perr, wantPath, off)
+	}
+
+	// Версия PFX: значение, а не тег.
+	bad = append([]byte(nil), data...)
+	v := bytes.Index(bad, []byte{0x02, 0x01, 0x03})
+	bad[v+2] = 0x04
+	if _, err := Parse(bad); !errors.As(err, &perr) || perr.Path != "PFX.version" || perr.Actual != "4" {
+		t.Errorf("версия PFX: %v", err)
+	}
+}
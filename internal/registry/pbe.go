@@ -0,0 +1,444 @@
+// pbe.go — парольное шифрование и MAC PKCS#12: описание и расшифровка PBE из RFC 7292 (приложения B, C)
+// и PBES2 из RFC 8018, проверка macData; шифрование PBES2 и MAC для выгрузки хранилища доверенных сертификатов.
+package registry
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/rc4"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+	"unicode/utf16"
+)
+
+// OID схем шифрования и их параметров.
+var (
+	OIDPBES2     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	OIDPBKDF2    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	OIDAES256CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42} // aes256-CBC-PAD (RFC 8018, B.2.5)
+)
+
+// ErrDecrypt — расшифровка не удалась: неверный пароль или повреждённые данные (проверяется через errors.Is).
+var ErrDecrypt = errors.New("decryption failed: wrong password or corrupted data")
+
+// pbeNames — читаемые имена алгоритмов PBE, PRF и шифров, встречающихся в EncryptedPrivateKeyInfo и EncryptedData.
+var pbeNames = map[string]string{
+	"1.2.840.113549.1.12.1.1": "pbeWithSHAAnd128BitRC4",
+	"1.2.840.113549.1.12.1.2": "pbeWithSHAAnd40BitRC4",
+	"1.2.840.113549.1.12.1.3": "pbeWithSHAAnd3-KeyTripleDES-CBC",
+	"1.2.840.113549.1.12.1.4": "pbeWithSHAAnd2-KeyTripleDES-CBC",
+	"1.2.840.113549.1.12.1.5": "pbeWithSHAAnd128BitRC2-CBC",
+	"1.2.840.113549.1.12.1.6": "pbeWithSHAAnd40BitRC2-CBC",
+	"1.2.840.113549.1.5.13":   "PBES2",
+	"1.2.840.113549.1.5.12":   "PBKDF2",
+	"1.2.840.113549.1.5.14":   "PBMAC1",
+	"1.2.840.113549.2.7":      "HMAC-SHA1",
+	"1.2.840.113549.2.9":      "HMAC-SHA256",
+	"1.2.840.113549.2.10":     "HMAC-SHA384",
+	"1.2.840.113549.2.11":     "HMAC-SHA512",
+	"2.16.840.1.101.3.4.1.2":  "AES-128-CBC",
+	"2.16.840.1.101.3.4.1.22": "AES-192-CBC",
+	"2.16.840.1.101.3.4.1.42": "AES-256-CBC",
+	"1.2.840.113549.3.7":      "DES-EDE3-CBC",
+}
+
+// pbeName возвращает имя алгоритма из pbeNames или строку OID.
+func pbeName(oid asn1.ObjectIdentifier) string {
+	if name, ok := pbeNames[oid.String()]; ok {
+		return name
+	}
+	return oid.String()
+}
+
+// pbkdf2Params — PBKDF2-params (RFC 8018, A.2); salt — только вариант specified (OCTET STRING).
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                 `asn1:"optional"`
+	PRF            AlgorithmIdentifier `asn1:"optional"`
+}
+
+// pbes2Params — PBES2-params (RFC 8018, A.4).
+type pbes2Params struct {
+	KeyDerivationFunc AlgorithmIdentifier
+	EncryptionScheme  AlgorithmIdentifier
+}
+
+// pbeParams — pkcs-12PbeParams (RFC 7292, C): соль и число итераций.
+type pbeParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+// DescribeEncryptionAlgorithm возвращает описание алгоритма шифрования с параметрами,
+// например «PBES2 (PBKDF2-HMAC-SHA256, 2048 iterations, AES-256-CBC)» или
+// «pbeWithSHAAnd3-KeyTripleDES-CBC (2048 iterations)». Нераспознанные параметры не описываются.
+func DescribeEncryptionAlgorithm(alg AlgorithmIdentifier) string {
+	name := pbeName(alg.Algorithm)
+	if alg.Algorithm.Equal(OIDPBES2) {
+		var p pbes2Params
+		if _, err := asn1.Unmarshal(alg.Parameters.FullBytes, &p); err != nil {
+			return name
+		}
+		kdf := pbeName(p.KeyDerivationFunc.Algorithm)
+		if p.KeyDerivationFunc.Algorithm.Equal(OIDPBKDF2) {
+			var kp pbkdf2Params
+			if _, err := asn1.Unmarshal(p.KeyDerivationFunc.Parameters.FullBytes, &kp); err == nil {
+				prf := "HMAC-SHA1" // значение по умолчанию
+				if len(kp.PRF.Algorithm) > 0 {
+					prf = pbeName(kp.PRF.Algorithm)
+				}
+				kdf = fmt.Sprintf("PBKDF2-%s, %d iterations", prf, kp.IterationCount)
+			}
+		}
+		return fmt.Sprintf("%s (%s, %s)", name, kdf, pbeName(p.EncryptionScheme.Algorithm))
+	}
+	var p pbeParams
+	if _, err := asn1.Unmarshal(alg.Parameters.FullBytes, &p); err == nil && p.Iterations > 0 {
+		return fmt.Sprintf("%s (%d iterations)", name, p.Iterations)
+	}
+	return name
+}
+
+// pbePassword — пароль в двух кодировках: BMPString с завершающим нулём для KDF PKCS#12 (RFC 7292, B.1)
+// и UTF-8 для PBKDF2 (как в OpenSSL).
+type pbePassword struct {
+	bmp  []byte
+	utf8 []byte
+}
+
+// newPBEPassword кодирует пароль; пустой пароль — два нулевых байта BMP (как в OpenSSL и Java).
+func newPBEPassword(password string) *pbePassword {
+	u := utf16.Encode([]rune(password))
+	bmp := make([]byte, 0, 2*len(u)+2)
+	for _, r := range u {
+		bmp = append(bmp, byte(r>>8), byte(r))
+	}
+	return &pbePassword{bmp: append(bmp, 0, 0), utf8: []byte(password)}
+}
+
+// pkcs12KDF — функция выработки ключа PKCS#12 (RFC 7292, B.2): id 1 — ключ, 2 — IV, 3 — ключ MAC;
+// v — размер блока хеш-функции в байтах.
+func pkcs12KDF(h func() hash.Hash, v int, id byte, salt, password []byte, iterations, size int) []byte {
+	u := h().Size()
+	fill := func(b []byte) []byte {
+		if len(b) == 0 {
+			return nil
+		}
+		out := make([]byte, v*((len(b)+v-1)/v))
+		for i := range out {
+			out[i] = b[i%len(b)]
+		}
+		return out
+	}
+	d := bytes.Repeat([]byte{id}, v)
+	in := append(fill(salt), fill(password)...)
+	var out []byte
+	for len(out) < size {
+		hh := h()
+		hh.Write(d)
+		hh.Write(in)
+		a := hh.Sum(make([]byte, 0, u))
+		for i := 1; i < iterations; i++ {
+			hh.Reset()
+			hh.Write(a)
+			a = hh.Sum(a[:0]) // без выделения памяти на каждой итерации
+		}
+		out = append(out, a...)
+		if len(out) >= size {
+			break
+		}
+		// I_j = (I_j + B + 1) mod 2^(8v), где B — A, повторённый до v байт.
+		b := make([]byte, v)
+		for i := range b {
+			b[i] = a[i%u]
+		}
+		for j := 0; j < len(in); j += v {
+			carry := 1
+			for k := v - 1; k >= 0; k-- {
+				sum := int(in[j+k]) + int(b[k]) + carry
+				in[j+k], carry = byte(sum), sum>>8
+			}
+		}
+	}
+	return out[:size]
+}
+
+// pkcs12PBE — параметры схемы pbeWithSHAAnd* (RFC 7292, C): длина ключа и конструктор шифра (nil — RC4).
+type pkcs12PBE struct {
+	keyLen int
+	block  func(key []byte) (cipher.Block, error)
+}
+
+var pkcs12PBEs = map[string]pkcs12PBE{
+	"1.2.840.113549.1.12.1.1": {16, nil},
+	"1.2.840.113549.1.12.1.2": {5, nil},
+	"1.2.840.113549.1.12.1.3": {24, des.NewTripleDESCipher},
+	"1.2.840.113549.1.12.1.4": {16, func(k []byte) (cipher.Block, error) {
+		return des.NewTripleDESCipher(append(k[:16:16], k[:8]...)) // двухключевой 3DES: K1 K2 K1
+	}},
+	"1.2.840.113549.1.12.1.5": {16, func(k []byte) (cipher.Block, error) { return newRC2Cipher(k, 128) }},
+	"1.2.840.113549.1.12.1.6": {5, func(k []byte) (cipher.Block, error) { return newRC2Cipher(k, 40) }},
+}
+
+// pbes2Ciphers — шифры PBES2: длина ключа и конструктор блока.
+var pbes2Ciphers = map[string]struct {
+	keyLen int
+	block  func(key []byte) (cipher.Block, error)
+}{
+	"2.16.840.1.101.3.4.1.2":  {16, aes.NewCipher},
+	"2.16.840.1.101.3.4.1.22": {24, aes.NewCipher},
+	"2.16.840.1.101.3.4.1.42": {32, aes.NewCipher},
+	"1.2.840.113549.3.7":      {24, des.NewTripleDESCipher},
+}
+
+// pkcs12Hash — хеш-функция с размером блока v для pkcs12KDF.
+type pkcs12Hash struct {
+	name string
+	h    func() hash.Hash
+	v    int
+}
+
+// macDigests — алгоритмы хеширования macData.
+var macDigests = map[string]pkcs12Hash{
+	"1.3.14.3.2.26":          {"SHA-1", sha1.New, 64},
+	"2.16.840.1.101.3.4.2.4": {"SHA-224", sha256.New224, 64},
+	"2.16.840.1.101.3.4.2.1": {"SHA-256", sha256.New, 64},
+	"2.16.840.1.101.3.4.2.2": {"SHA-384", sha512.New384, 128},
+	"2.16.840.1.101.3.4.2.3": {"SHA-512", sha512.New, 128},
+}
+
+// pbkdf2PRFs — псевдослучайные функции PBKDF2.
+var pbkdf2PRFs = map[string]pkcs12Hash{
+	"1.2.840.113549.2.7":  {"HMAC-SHA1", sha1.New, 64},
+	"1.2.840.113549.2.8":  {"HMAC-SHA224", sha256.New224, 64},
+	"1.2.840.113549.2.9":  {"HMAC-SHA256", sha256.New, 64},
+	"1.2.840.113549.2.10": {"HMAC-SHA384", sha512.New384, 128},
+	"1.2.840.113549.2.11": {"HMAC-SHA512", sha512.New, 128},
+}
+
+// decryptPBE расшифровывает data по алгоритму alg (pbeWithSHAAnd* или PBES2) паролем pw.
+// Неверный пароль обычно обнаруживается по дополнению PKCS#7 — ошибка ErrDecrypt; исчерпание budget
+// (итерации, число расшифровок) — ErrLimitExceeded до выработки ключа.
+func decryptPBE(alg AlgorithmIdentifier, data []byte, pw *pbePassword, budget *pbeBudget) ([]byte, error) {
+	if err := budget.decryption(); err != nil {
+		return nil, err
+	}
+	if alg.Algorithm.Equal(OIDPBES2) {
+		return decryptPBES2(alg, data, pw, budget)
+	}
+	scheme, ok := pkcs12PBEs[alg.Algorithm.String()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported encryption algorithm %s", pbeName(alg.Algorithm))
+	}
+	var p pbeParams
+	if _, err := asn1.Unmarshal(alg.Parameters.FullBytes, &p); err != nil {
+		return nil, fmt.Errorf("%s parameters: %w", pbeName(alg.Algorithm), err)
+	}
+	if err := budget.kdf(pbeName(alg.Algorithm), p.Iterations); err != nil {
+		return nil, err
+	}
+	key := pkcs12KDF(sha1.New, 64, 1, p.Salt, pw.bmp, p.Iterations, scheme.keyLen)
+	if scheme.block == nil {
+		c, err := rc4.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, len(data))
+		c.XORKeyStream(out, data)
+		return out, nil
+	}
+	block, err := scheme.block(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := budget.kdf(pbeName(alg.Algorithm)+" IV", p.Iterations); err != nil {
+		return nil, err
+	}
+	iv := pkcs12KDF(sha1.New, 64, 2, p.Salt, pw.bmp, p.Iterations, block.BlockSize())
+	return decryptCBC(block, iv, data)
+}
+
+// decryptPBES2 — PBES2 с PBKDF2 (RFC 8018, 6.2): пароль в UTF-8, IV — параметр схемы шифрования.
+// keyLength, если задан, должен совпадать с длиной ключа шифра.
+func decryptPBES2(alg AlgorithmIdentifier, data []byte, pw *pbePassword, budget *pbeBudget) ([]byte, error) {
+	var p pbes2Params
+	if _, err := asn1.Unmarshal(alg.Parameters.FullBytes, &p); err != nil {
+		return nil, fmt.Errorf("PBES2 parameters: %w", err)
+	}
+	if !p.KeyDerivationFunc.Algorithm.Equal(OIDPBKDF2) {
+		return nil, fmt.Errorf("unsupported PBES2 key derivation function %s", pbeName(p.KeyDerivationFunc.Algorithm))
+	}
+	var kp pbkdf2Params
+	if _, err := asn1.Unmarshal(p.KeyDerivationFunc.Parameters.FullBytes, &kp); err != nil {
+		return nil, fmt.Errorf("PBKDF2 parameters: %w", err)
+	}
+	prf := pbkdf2PRFs["1.2.840.113549.2.7"] // hmacWithSHA1 по умолчанию
+	if len(kp.PRF.Algorithm) > 0 {
+		var ok bool
+		if prf, ok = pbkdf2PRFs[kp.PRF.Algorithm.String()]; !ok {
+			return nil, fmt.Errorf("unsupported PBKDF2 PRF %s", pbeName(kp.PRF.Algorithm))
+		}
+	}
+	scheme, ok := pbes2Ciphers[p.EncryptionScheme.Algorithm.String()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported PBES2 encryption scheme %s", pbeName(p.EncryptionScheme.Algorithm))
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(p.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, fmt.Errorf("PBES2 IV: %w", err)
+	}
+	if kp.KeyLength != 0 && kp.KeyLength != scheme.keyLen {
+		return nil, fmt.Errorf("PBKDF2 keyLength %d does not match %s key size %d", kp.KeyLength, pbeName(p.EncryptionScheme.Algorithm), scheme.keyLen)
+	}
+	if err := budget.kdf("PBKDF2", kp.IterationCount); err != nil {
+		return nil, err
+	}
+	key, err := pbkdf2.Key(prf.h, string(pw.utf8), kp.Salt, kp.IterationCount, scheme.keyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := scheme.block(key)
+	if err != nil {
+		return nil, err
+	}
+	return decryptCBC(block, iv, data)
+}
+
+// decryptCBC расшифровывает CBC и снимает дополнение PKCS#7.
+func decryptCBC(block cipher.Block, iv, data []byte) ([]byte, error) {
+	bs := block.BlockSize()
+	if len(iv) != bs {
+		return nil, fmt.Errorf("IV is %d bytes, block size %d", len(iv), bs)
+	}
+	if len(data) == 0 || len(data)%bs != 0 {
+		return nil, fmt.Errorf("%w: ciphertext is %d bytes, not a multiple of block size %d", ErrDecrypt, len(data), bs)
+	}
+	out := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, data)
+	n := int(out[len(out)-1])
+	if n == 0 || n > bs {
+		return nil, ErrDecrypt
+	}
+	for _, b := range out[len(out)-n:] {
+		if int(b) != n {
+			return nil, ErrDecrypt
+		}
+	}
+	return out[:len(out)-n], nil
+}
+
+// Статусы проверки MAC (MacInfo.Status).
+const (
+	MacStatusOK          = "ok"          // MAC совпал
+	MacStatusFailed      = "failed"      // MAC не совпал: неверный пароль или изменённое содержимое
+	MacStatusUnsupported = "unsupported" // алгоритм MAC не поддерживается (например PBMAC1)
+	MacStatusSkipped     = "skipped"     // пароль не задан: MAC не проверялся
+)
+
+// MacInfo — macData PKCS#12 (RFC 7292, 4) и результат его проверки паролем.
+type MacInfo struct {
+	Algorithm  string `json:"algorithm"`
+	Iterations int    `json:"iterations"`
+	SaltLen    int    `json:"saltLen"`
+	Status     string `json:"status"`
+}
+
+// verifyMac проверяет HMAC над содержимым authSafe (RFC 7292, 5.1). Для пустого пароля пробуется и вариант
+// без завершающего нуля BMP (пустая строка байт), как делают OpenSSL и Java; pw получает подошедший вариант.
+// Без пароля (pw == nil) MAC только описывается — MacStatusSkipped. Исчерпание budget — ErrLimitExceeded
+// до выработки ключа MAC.
+func verifyMac(md *MacData, content []byte, pw *pbePassword, budget *pbeBudget) (*MacInfo, error) {
+	info := &MacInfo{Algorithm: md.Mac.DigestAlgorithm.Algorithm.String(), Iterations: md.Iterations, SaltLen: len(md.MacSalt)}
+	hf, ok := macDigests[md.Mac.DigestAlgorithm.Algorithm.String()]
+	if !ok {
+		info.Algorithm = pbeName(md.Mac.DigestAlgorithm.Algorithm)
+		info.Status = MacStatusUnsupported
+		return info, nil
+	}
+	info.Algorithm = "HMAC-" + hf.name
+	if pw == nil {
+		info.Status = MacStatusSkipped
+		return info, nil
+	}
+	candidates := [][]byte{pw.bmp}
+	if len(pw.utf8) == 0 {
+		candidates = append(candidates, nil)
+	}
+	info.Status = MacStatusFailed
+	for _, p := range candidates {
+		if err := budget.kdf("macData "+info.Algorithm, md.Iterations); err != nil {
+			return nil, err
+		}
+		if hmac.Equal(pkcs12Mac(hf, md.MacSalt, p, md.Iterations, content), md.Mac.Digest) {
+			pw.bmp = p
+			info.Status = MacStatusOK
+			break
+		}
+	}
+	return info, nil
+}
+
+// pkcs12Mac вычисляет HMAC содержимого authSafe ключом из pkcs12KDF (id 3) по паролю в BMP.
+func pkcs12Mac(hf pkcs12Hash, salt, bmpPassword []byte, iterations int, content []byte) []byte {
+	key := pkcs12KDF(hf.h, hf.v, 3, salt, bmpPassword, iterations, hf.h().Size())
+	m := hmac.New(hf.h, key)
+	m.Write(content)
+	return m.Sum(nil)
+}
+
+// encryptPBES2 шифрует data схемой PBES2 по умолчанию OpenSSL 3: PBKDF2-HMAC-SHA256 с солью 16 байт,
+// AES-256-CBC со случайным IV и дополнением PKCS#7. Возвращает AlgorithmIdentifier для EncryptedData.
+func encryptPBES2(data []byte, pw *pbePassword, iterations int) (AlgorithmIdentifier, []byte, error) {
+	var alg AlgorithmIdentifier
+	salt := make([]byte, 16)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(salt); err != nil {
+		return alg, nil, err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return alg, nil, err
+	}
+	key, err := pbkdf2.Key(sha256.New, string(pw.utf8), salt, iterations, 32)
+	if err != nil {
+		return alg, nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return alg, nil, err
+	}
+	kdf, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: iterations,
+		PRF:            AlgorithmIdentifier{Algorithm: OIDHMACWithSHA256, Parameters: asn1.NullRawValue},
+	})
+	if err != nil {
+		return alg, nil, err
+	}
+	ivDER, err := asn1.Marshal(iv)
+	if err != nil {
+		return alg, nil, err
+	}
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: AlgorithmIdentifier{Algorithm: OIDPBKDF2, Parameters: asn1.RawValue{FullBytes: kdf}},
+		EncryptionScheme:  AlgorithmIdentifier{Algorithm: OIDAES256CBC, Parameters: asn1.RawValue{FullBytes: ivDER}},
+	})
+	if err != nil {
+		return alg, nil, err
+	}
+	n := aes.BlockSize - len(data)%aes.BlockSize
+	padded := append(append([]byte(nil), data...), bytes.Repeat([]byte{byte(n)}, n)...)
+	out := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(out, padded)
+	return AlgorithmIdentifier{Algorithm: OIDPBES2, Parameters: asn1.RawValue{FullBytes: params}}, out, nil
+}
@@ -0,0 +1,294 @@
+// plan.go — предварительный просмотр сборки (registry-builder -plan): что попадёт в реестр, без доступа к ключу подписанта.
+package registry
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+)
+
+// DefaultPlanExpiryWarning — порог предупреждения об истечении сертификатов в плане сборки.
+const DefaultPlanExpiryWarning = 30 * 24 * time.Hour
+
+// PlanOptions — параметры построения плана: момент проверки сроков (по умолчанию time.Now) и порог предупреждения.
+type PlanOptions struct {
+	Now           time.Time
+	ExpiryWarning time.Duration
+}
+
+// Plan — структурированный предварительный просмотр реестра: подписант, атрибуты подписанта, мешки и предупреждения.
+// EContentSHA256 — будущее значение messageDigest; пусто, если часть сертификатов будет выпущена при сборке.
+type Plan struct {
+	Signer              PlanCert          `json:"signer"`
+	VIN                 string            `json:"vin,omitempty"`
+	VERTimestamp        string            `json:"verTimestamp,omitempty"`
+	VERVersion          int               `json:"verVersion,omitempty"`
+	UID                 string            `json:"uid,omitempty"`
+	SigningTime         string            `json:"signingTime,omitempty"`
+	AlgorithmProtection bool              `json:"algorithmProtection,omitempty"`
+	CustomAttributes    []CustomAttribute `json:"customAttributes,omitempty"`
+	SafeBags            []PlanSafeBag     `json:"safeBags"`
+	EContentSHA256      string            `json:"eContentSHA256,omitempty"`
+	Warnings            []PlanWarning     `json:"warnings"`
+}
+
+// PlanCert — данные сертификата в плане (RFC3339 для сроков, serial и SKID в hex).
+type PlanCert struct {
+	Subject   string `json:"subject"`
+	Issuer    string `json:"issuer,omitempty"`
+	Serial    string `json:"serial,omitempty"`
+	NotBefore string `json:"notBefore,omitempty"`
+	NotAfter  string `json:"notAfter,omitempty"`
+	KeyAlg    string `json:"keyAlgorithm,omitempty"`
+	SKID      string `json:"subjectKeyId,omitempty"`
+}
+
+// PlanSafeBag — один мешок в плане: сертификат, роль, период роли и итоговый localKeyID с указанием источника.
+// Issue — описание выпуска, если сертификат будет выпущен при сборке (поля сертификата тогда заполнены частично).
+type PlanSafeBag struct {
+	Index            int      `json:"index"`
+	Cert             PlanCert `json:"cert"`
+	RoleName         string   `json:"roleName,omitempty"`
+	RoleNotBefore    string   `json:"roleNotBefore,omitempty"`
+	RoleNotAfter     string   `json:"roleNotAfter,omitempty"`
+	LocalKeyID       string   `json:"localKeyID,omitempty"`
+	LocalKeyIDSource string   `json:"localKeyIDSource"`
+	Issue            string   `json:"issue,omitempty"`
+}
+
+// PlanWarning — предупреждение плана: к чему относится (signer, safeBags[N], registry) и текст.
+type PlanWarning struct {
+	Scope   string `json:"scope"`
+	Message string `json:"message"`
+}
+
+// BuildPlan строит план сборки по тем же входным данным, что и BuildRegistry, но без ключа подписанта.
+// Мешки с пустым CertDER считаются выпускаемыми при сборке: для них проверяются только атрибуты.
+func BuildPlan(signerCert *x509.Certificate, safeBags []SafeBagInput, attrs SignerAttrs, opts PlanOptions) (*Plan, error) {
+	if signerCert == nil {
+		return nil, fmt.Errorf("signer cert required")
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.ExpiryWarning == 0 {
+		opts.ExpiryWarning = DefaultPlanExpiryWarning
+	}
+
+	p := &Plan{
+		Signer:              planCert(signerCert),
+		VIN:                 attrs.VIN,
+		VERVersion:          attrs.VERVersion,
+		UID:                 attrs.UID,
+		AlgorithmProtection: attrs.AlgorithmProtection,
+		CustomAttributes:    attrs.Custom,
+		SafeBags:            []PlanSafeBag{},
+		Warnings:            []PlanWarning{},
+	}
+	if !attrs.VERTimestamp.IsZero() {
+		p.VERTimestamp = attrs.VERTimestamp.UTC().Format(time.RFC3339)
+	}
+	if !attrs.SigningTime.IsZero() {
+		p.SigningTime = attrs.SigningTime.UTC().Format(time.RFC3339)
+	}
+	warn := func(scope, format string, args ...interface{}) {
+		p.Warnings = append(p.Warnings, PlanWarning{Scope: scope, Message: i18n.Sprintf(format, args...)})
+	}
+
+	// Подписант: SKID (sid), тип ключа, keyUsage и сроки.
+	if len(signerCert.SubjectKeyId) == 0 {
+		warn("signer", "нет SubjectKeyIdentifier: SignerInfo.sid будет пустым, подписанта не найти по SID")
+	}
+	if pub, ok := signerCert.PublicKey.(*ecdsa.PublicKey); !ok || pub.Curve != elliptic.P256() {
+		warn("signer", "ключ подписанта не ECDSA P-256 (%s): сборка поддерживает только ecdsa-with-SHA256", signerCert.PublicKeyAlgorithm)
+	}
+	if signerCert.KeyUsage != 0 && signerCert.KeyUsage&x509.KeyUsageDigitalSignature == 0 {
+		warn("signer", "в keyUsage нет digitalSignature")
+	}
+	checkValidity(signerCert, opts, func(msg string) { warn("signer", "%s", msg) })
+
+	if len(safeBags) == 0 {
+		warn("registry", "нет ни одного SafeBag: eContent будет пустым")
+	}
+	pending := false
+	seen := make(map[string]int)
+	for i, in := range safeBags {
+		scope := fmt.Sprintf("safeBags[%d]", i+1)
+		bag := PlanSafeBag{Index: i + 1, RoleName: in.RoleName}
+		if !in.RoleNotBefore.IsZero() {
+			bag.RoleNotBefore = in.RoleNotBefore.UTC().Format(time.RFC3339)
+		}
+		if !in.RoleNotAfter.IsZero() {
+			bag.RoleNotAfter = in.RoleNotAfter.UTC().Format(time.RFC3339)
+		}
+
+		var cert *x509.Certificate
+		if len(in.CertDER) == 0 {
+			pending = true
+		} else if c, err := x509.ParseCertificate(in.CertDER); err != nil {
+			warn(scope, "сертификат не разбирается как X.509: %v", err)
+		} else {
+			cert = c
+			bag.Cert = planCert(c)
+			checkValidity(c, opts, func(msg string) { warn(scope, "%s", msg) })
+		}
+
+		localKeyID, source := effectiveLocalKeyID(in)
+		bag.LocalKeyID, bag.LocalKeyIDSource = hexEncode(localKeyID), source
+		switch {
+		case source == LocalKeyIDNone && len(in.CertDER) > 0:
+			warn(scope, "localKeyID не задан и у сертификата нет SubjectKeyIdentifier: атрибут не будет записан")
+		case source == LocalKeyIDFromConfig && cert != nil && len(cert.SubjectKeyId) > 0 && !bytes.Equal(localKeyID, cert.SubjectKeyId):
+			warn(scope, "localKeyID %s не совпадает с SubjectKeyIdentifier сертификата %x", bag.LocalKeyID, cert.SubjectKeyId)
+		}
+		if bag.LocalKeyID != "" {
+			if prev, dup := seen[bag.LocalKeyID]; dup {
+				warn(scope, "localKeyID %s совпадает с safeBags[%d]", bag.LocalKeyID, prev)
+			}
+			seen[bag.LocalKeyID] = i + 1
+		}
+
+		if in.RoleName == "" {
+			warn(scope, "roleName не задан")
+		}
+		if !in.RoleNotBefore.IsZero() && !in.RoleNotAfter.IsZero() && !in.RoleNotBefore.Before(in.RoleNotAfter) {
+			warn(scope, "roleNotBefore %s не раньше roleNotAfter %s", bag.RoleNotBefore, bag.RoleNotAfter)
+		}
+		if !in.RoleNotAfter.IsZero() {
+			if in.RoleNotAfter.Before(opts.Now) {
+				warn(scope, "период роли истёк %s", bag.RoleNotAfter)
+			} else if cert != nil && in.RoleNotAfter.After(cert.NotAfter) {
+				warn(scope, "период роли (до %s) дольше срока сертификата (до %s)", bag.RoleNotAfter, bag.Cert.NotAfter)
+			}
+		}
+		p.SafeBags = append(p.SafeBags, bag)
+	}
+
+	// messageDigest известен заранее, только если все сертификаты уже есть.
+	if !pending {
+		safeContentsDER, err := marshalSafeContents(safeBags)
+		if err != nil {
+			return nil, fmt.Errorf("marshal SafeContents: %w", err)
+		}
+		sum := sha256.Sum256(safeContentsDER)
+		p.EContentSHA256 = hexEncode(sum[:])
+	}
+	return p, nil
+}
+
+func planCert(cert *x509.Certificate) PlanCert {
+	return PlanCert{
+		Subject:   cert.Subject.String(),
+		Issuer:    cert.Issuer.String(),
+		Serial:    cert.SerialNumber.Text(16),
+		NotBefore: cert.NotBefore.UTC().Format(time.RFC3339),
+		NotAfter:  cert.NotAfter.UTC().Format(time.RFC3339),
+		KeyAlg:    cert.PublicKeyAlgorithm.String(),
+		SKID:      hexEncode(cert.SubjectKeyId),
+	}
+}
+
+// checkValidity сообщает об истёкших, ещё не действующих и скоро истекающих сертификатах.
+func checkValidity(cert *x509.Certificate, opts PlanOptions, warn func(string)) {
+	switch {
+	case opts.Now.After(cert.NotAfter):
+		warn(i18n.Sprintf("сертификат истёк %s", cert.NotAfter.UTC().Format(time.RFC3339)))
+	case opts.Now.Before(cert.NotBefore):
+		warn(i18n.Sprintf("сертификат ещё не действует (с %s)", cert.NotBefore.UTC().Format(time.RFC3339)))
+	case cert.NotAfter.Sub(opts.Now) < opts.ExpiryWarning:
+		days := int(cert.NotAfter.Sub(opts.Now).Hours() / 24)
+		warn(i18n.Sprintf("сертификат истекает через %d дн. (%s)", days, cert.NotAfter.UTC().Format(time.RFC3339)))
+	}
+}
+
+// TextOutput формирует текстовый план сборки. Оформление совпадает с (*Container).TextOutput.
+func (p *Plan) TextOutput(sb *strings.Builder, useColor bool) {
+	bold, dim, val, head, warnColor, reset := "", "", "", "", "", ""
+	if useColor {
+		bold, dim, val, head, warnColor, reset = Bold, Dim, Cyan, Bold+Yellow, Bold+Yellow, Reset
+		sb.WriteString(head + IconSigner + " " + i18n.T("План сборки: подписант") + reset + "\n")
+	} else {
+		sb.WriteString("=== " + i18n.T("План сборки: подписант") + " ===\n")
+	}
+	writeCert := func(indent string, c PlanCert) {
+		sb.WriteString(fmt.Sprintf("%s%sSubject:%s %s%s%s\n", indent, dim, reset, val, c.Subject, reset))
+		if c.Issuer != "" {
+			sb.WriteString(fmt.Sprintf("%s%sIssuer:%s  %s%s%s\n", indent, dim, reset, val, c.Issuer, reset))
+			sb.WriteString(fmt.Sprintf("%s%sSerial:%s  %s%s%s\n", indent, dim, reset, val, c.Serial, reset))
+			sb.WriteString(fmt.Sprintf("%s%sValid:%s   %s%s — %s%s\n", indent, dim, reset, val, c.NotBefore, c.NotAfter, reset))
+			sb.WriteString(fmt.Sprintf("%s%sKeyAlg:%s  %s%s%s\n", indent, dim, reset, val, c.KeyAlg, reset))
+		}
+		if c.SKID != "" {
+			sb.WriteString(fmt.Sprintf("%s%sSubjectKeyId:%s %s%s%s\n", indent, dim, reset, val, c.SKID, reset))
+		}
+	}
+	writeCert("  ", p.Signer)
+
+	if useColor {
+		sb.WriteString("\n" + head + IconSignerInfo + " " + i18n.T("Атрибуты подписанта") + reset + "\n")
+	} else {
+		sb.WriteString("\n=== " + i18n.T("Атрибуты подписанта") + " ===\n")
+	}
+	field := func(name, value string) {
+		if value != "" {
+			sb.WriteString(fmt.Sprintf("  %s%s:%s %s%s%s\n", dim, name, reset, val, value, reset))
+		}
+	}
+	field("VIN", p.VIN)
+	if p.VERTimestamp != "" || p.VERVersion != 0 {
+		field("VER", fmt.Sprintf("%s, version %d", p.VERTimestamp, p.VERVersion))
+	}
+	field("UID", p.UID)
+	field("signingTime", p.SigningTime)
+	if p.AlgorithmProtection {
+		field("cmsAlgorithmProtection", "digest=sha256, signature=ecdsa-with-SHA256")
+	}
+	for _, ca := range p.CustomAttributes {
+		field(ca.OID, fmt.Sprintf("%s %s", ca.Type, strings.Join(ca.Values, ", ")))
+	}
+
+	if useColor {
+		sb.WriteString("\n" + head + IconSafeBag + " SafeContents (eContent)" + reset + "\n")
+	} else {
+		sb.WriteString("\n=== SafeContents (eContent) ===\n")
+	}
+	for _, b := range p.SafeBags {
+		sb.WriteString(fmt.Sprintf("  %s[%d]%s %sroleName:%s %s%s%s\n", bold, b.Index, reset, dim, reset, val, b.RoleName, reset))
+		if b.Issue != "" {
+			sb.WriteString(fmt.Sprintf("       %sissue:%s   %s%s%s\n", dim, reset, val, b.Issue, reset))
+		}
+		if b.Cert.Subject != "" {
+			writeCert("       ", b.Cert)
+		}
+		if b.RoleNotBefore != "" || b.RoleNotAfter != "" {
+			sb.WriteString(fmt.Sprintf("       %sroleValidityPeriod:%s %s%s — %s%s\n", dim, reset, val, b.RoleNotBefore, b.RoleNotAfter, reset))
+		}
+		lkid := b.LocalKeyID
+		if lkid == "" {
+			lkid = "—"
+		}
+		sb.WriteString(fmt.Sprintf("       %slocalKeyID:%s %s%s%s (%s)\n", dim, reset, val, lkid, reset, b.LocalKeyIDSource))
+	}
+	if p.EContentSHA256 != "" {
+		sb.WriteString(fmt.Sprintf("  %smessageDigest (SHA-256 eContent):%s %s%s%s\n", dim, reset, val, p.EContentSHA256, reset))
+	}
+
+	if useColor {
+		sb.WriteString("\n" + head + "⚠️ " + i18n.T("Предупреждения") + reset + "\n")
+	} else {
+		sb.WriteString("\n=== " + i18n.T("Предупреждения") + " ===\n")
+	}
+	if len(p.Warnings) == 0 {
+		sb.WriteString("  (" + i18n.T("нет") + ")\n")
+	}
+	for _, w := range p.Warnings {
+		sb.WriteString(fmt.Sprintf("  %s%s:%s %s\n", warnColor, w.Scope, reset, w.Message))
+	}
+}
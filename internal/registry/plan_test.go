@@ -0,0 +1,62 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestBuildPlan проверяет план сборки: fallback localKeyID на SKID, предупреждение об истечении
+// и совпадение EContentSHA256 с messageDigest реально собранного реестра.
+func TestBuildPlan(t *testing.T) {
+	cert, key := newTestSigner(t) // срок действия — сутки: попадает под порог предупреждения
+	now := time.Now().UTC().Truncate(time.Second)
+	safeBags := []SafeBagInput{
+		{CertDER: cert.Raw, RoleName: "delegate", RoleNotBefore: now, RoleNotAfter: now.Add(time.Hour)},
+		{RoleName: "not_delegate"}, // сертификат будет выпущен при сборке
+	}
+	attrs := SignerAttrs{VIN: "TESTVIN123", VERTimestamp: now, VERVersion: 1}
+
+	plan, err := BuildPlan(cert, safeBags, attrs, PlanOptions{})
+	if err != nil {
+		t.Fatalf("BuildPlan: %v", err)
+	}
+	if plan.Signer.SKID != hex.EncodeToString(cert.SubjectKeyId) {
+		t.Errorf("signer SKID = %s", plan.Signer.SKID)
+	}
+	bag := plan.SafeBags[0]
+	if bag.LocalKeyIDSource != LocalKeyIDFromSKID || bag.LocalKeyID != hex.EncodeToString(cert.SubjectKeyId) {
+		t.Errorf("localKeyID = %s (%s), ожидается SKID сертификата", bag.LocalKeyID, bag.LocalKeyIDSource)
+	}
+	if plan.EContentSHA256 != "" {
+		t.Error("EContentSHA256 не должен вычисляться, пока есть невыпущенные сертификаты")
+	}
+	var expiring bool
+	for _, w := range plan.Warnings {
+		if w.Scope == "signer" && strings.Contains(w.Message, "истекает") {
+			expiring = true
+		}
+	}
+	if !expiring {
+		t.Errorf("нет предупреждения об истечении подписанта: %+v", plan.Warnings)
+	}
+
+	plan, err = BuildPlan(cert, safeBags[:1], attrs, PlanOptions{})
+	if err != nil {
+		t.Fatalf("BuildPlan: %v", err)
+	}
+	der, err := BuildRegistry(cert, key, safeBags[:1], attrs)
+	if err != nil {
+		t.Fatalf("BuildRegistry: %v", err)
+	}
+	c, err := Parse(der)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	sum := sha256.Sum256(c.EContent())
+	if plan.EContentSHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("EContentSHA256 %s != SHA-256 eContent %x", plan.EContentSHA256, sum)
+	}
+}
@@ -0,0 +1,39 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// TestRC2 проверяет шифр по тестовым векторам RFC 2268, 5 и обратимость Decrypt.
+func TestRC2(t *testing.T) {
+	tests := []struct {
+		key, plain, cipher string
+		bits               int
+	}{
+		{"0000000000000000", "0000000000000000", "ebb773f993278eff", 63},
+		{"ffffffffffffffff", "ffffffffffffffff", "278b27e42e2f0d49", 64},
+		{"3000000000000000", "1000000000000001", "30649edf9be7d2c2", 64},
+		{"88", "0000000000000000", "61a8a244adacccf0", 64},
+		{"88bca90e90875a7f0f79c384627bafb2", "0000000000000000", "2269552ab0f85ca6", 128},
+	}
+	for _, tt := range tests {
+		key, _ := hex.DecodeString(tt.key)
+		plain, _ := hex.DecodeString(tt.plain)
+		want, _ := hex.DecodeString(tt.cipher)
+		c, err := newRC2Cipher(key, tt.bits)
+		if err != nil {
+			t.Fatalf("newRC2Cipher(%s): %v", tt.key, err)
+		}
+		got := make([]byte, 8)
+		c.Encrypt(got, plain)
+		if !bytes.Equal(got, want) {
+			t.Errorf("key %s/%d: Encrypt = %x, ожидается %x", tt.key, tt.bits, got, want)
+		}
+		c.Decrypt(got, want)
+		if !bytes.Equal(got, plain) {
+			t.Errorf("key %s/%d: Decrypt = %x, ожидается %x", tt.key, tt.bits, got, plain)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+// report.go — преобразование разобранного контейнера в типизированный JSON-отчёт (пакет report):
+// сертификаты с отпечатками, мешки и подписанты с типизированными атрибутами (время, VER, roleValidityPeriod).
+package registry
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"time"
+
+	"github.com/sgw-registry/registry-analyzer/report"
+)
+
+// reportCertificates возвращает сертификаты SignedData.certificates в формате отчёта.
+func (c *Container) reportCertificates() []report.Certificate {
+	certs := make([]report.Certificate, 0, len(c.Certificates))
+	for _, cert := range c.Certificates {
+		certs = append(certs, reportCertificate(cert, c.isSignerCert(cert)))
+	}
+	return certs
+}
+
+// reportCertificate формирует данные сертификата для отчёта: subject, issuer, serial, сроки в UTC, алгоритмы,
+// расширения, SAN, отпечатки и raw DER.
+func reportCertificate(cert *x509.Certificate, isSigner bool) report.Certificate {
+	sha1Sum := sha1.Sum(cert.Raw)
+	sha256Sum := sha256.Sum256(cert.Raw)
+	spkiSum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	rc := report.Certificate{
+		Subject:            cert.Subject.String(),
+		Issuer:             cert.Issuer.String(),
+		SerialNumber:       cert.SerialNumber.String(),
+		SerialNumberHex:    cert.SerialNumber.Text(16),
+		NotBefore:          cert.NotBefore.UTC(),
+		NotAfter:           cert.NotAfter.UTC(),
+		Version:            cert.Version,
+		SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+		PublicKeyAlgorithm: cert.PublicKeyAlgorithm.String(),
+		IsSigner:           isSigner,
+		IsCA:               cert.BasicConstraintsValid && cert.IsCA,
+		SubjectKeyID:       hexEncode(cert.SubjectKeyId),
+		AuthorityKeyID:     hexEncode(cert.AuthorityKeyId),
+		DNSNames:           cert.DNSNames,
+		EmailAddresses:     cert.EmailAddresses,
+		Fingerprints: report.Fingerprints{
+			SHA1:       hexEncode(sha1Sum[:]),
+			SHA256:     hexEncode(sha256Sum[:]),
+			SPKISHA256: hexEncode(spkiSum[:]),
+		},
+		Raw: cert.Raw,
+	}
+	if cert.KeyUsage != 0 {
+		rc.KeyUsage = keyUsageStrings(cert.KeyUsage)
+	}
+	if len(cert.ExtKeyUsage) > 0 {
+		rc.ExtKeyUsage = extKeyUsageStrings(cert.ExtKeyUsage)
+	}
+	for _, ip := range cert.IPAddresses {
+		rc.IPAddresses = append(rc.IPAddresses, ip.String())
+	}
+	for _, u := range cert.URIs {
+		rc.URIs = append(rc.URIs, u.String())
+	}
+	return rc
+}
+
+// reportSafeBags преобразует мешки (в том числе вложенные safeContentsBag) в формат отчёта.
+func (c *Container) reportSafeBags(infos []SafeBagInfo) []report.SafeBag {
+	bags := make([]report.SafeBag, 0, len(infos))
+	for i := range infos {
+		info := &infos[i]
+		b := report.SafeBag{
+			BagID:        info.BagId.String(),
+			BagType:      info.BagType,
+			CertType:     info.CertType,
+			CertValueLen: info.CertValueLen,
+			Error:        info.Error,
+			RoleName:     SafeBagRoleName(info),
+		}
+		if len(info.CertId) > 0 {
+			b.CertID = info.CertId.String()
+		}
+		if info.CertValueDER != nil {
+			if cert, err := x509.ParseCertificate(info.CertValueDER); err == nil {
+				rc := reportCertificate(cert, c.isSignerDER(cert.Raw))
+				b.Certificate = &rc
+			}
+		}
+		if info.Key != nil {
+			k := report.Key(*info.Key)
+			b.Key = &k
+		}
+		if info.CRL != nil {
+			b.CRL = reportCRL(info.CRL)
+		}
+		if info.Secret != nil {
+			s := report.Secret(*info.Secret)
+			b.Secret = &s
+		}
+		if info.Nested != nil {
+			b.Nested = c.reportSafeBags(info.Nested)
+		}
+		if !info.RoleNotBefore.IsZero() {
+			b.RoleValidity = &report.RoleValidity{NotBefore: info.RoleNotBefore, NotAfter: info.RoleNotAfter}
+		}
+		for _, a := range info.Attributes {
+			for _, rv := range a.AttrValues {
+				ra, typed := reportAttribute(a.AttrType, rv.FullBytes)
+				if !typed {
+					ra.Value, ra.Raw = decodeBagAttrValue(a.AttrType, rv.Bytes, rv.FullBytes), ""
+				}
+				b.Attributes = append(b.Attributes, ra)
+			}
+		}
+		bags = append(bags, b)
+	}
+	return bags
+}
+
+// isSignerDER возвращает true, если DER совпадает с сертификатом одного из подписантов (для сертификатов в мешках).
+func (c *Container) isSignerDER(raw []byte) bool {
+	for i := range c.Signers {
+		if cert := c.SignerCert(&c.Signers[i]); cert != nil && string(cert.Raw) == string(raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// reportCRL переводит сводку crlBag в формат отчёта; нулевые даты (не X.509 CRL, нет nextUpdate) опускаются.
+func reportCRL(s *CRLSummary) *report.CRL {
+	rc := &report.CRL{Type: s.Type, Issuer: s.Issuer, Number: s.Number, Revoked: s.Revoked, Len: s.Len}
+	if !s.ThisUpdate.IsZero() {
+		t := s.ThisUpdate
+		rc.ThisUpdate = &t
+	}
+	if !s.NextUpdate.IsZero() {
+		t := s.NextUpdate
+		rc.NextUpdate = &t
+	}
+	return rc
+}
+
+// reportSigner формирует подписанта отчёта; VIN, UID, roleName, VER и signingTime дублируются из атрибутов.
+func reportSigner(si *SignerInfo) report.Signer {
+	rs := report.Signer{
+		DigestAlgorithm:    si.DigestAlgorithm.Algorithm.String(),
+		SignatureAlgorithm: si.DigestEncryptionAlgorithm.Algorithm.String(),
+		Attributes:         []report.Attribute{},
+	}
+	attrs, _ := SignerAttributes(si)
+	for _, a := range attrs {
+		for _, rv := range a.AttrValues {
+			raw := rv.FullBytes
+			if len(raw) == 0 {
+				raw = rv.Bytes
+			}
+			ra, typed := reportAttribute(a.AttrType, raw)
+			if !typed {
+				av := decodeSingleAttrValue(a.AttrType, raw, ra.Name)
+				ra.Value, ra.Raw = av.Value, av.Raw
+			}
+			switch {
+			case a.AttrType.Equal(OIDAtomVIN):
+				rs.VIN = ra.Value
+			case a.AttrType.Equal(OIDAtomUID):
+				rs.UID = ra.Value
+			case a.AttrType.Equal(OIDAtomRoleName):
+				rs.RoleName = ra.Value
+			case a.AttrType.Equal(OIDAtomVER):
+				rs.VER = ra.VER
+			case a.AttrType.Equal(OIDPKCS9SigningTime):
+				rs.SigningTime = ra.Time
+			}
+			rs.Attributes = append(rs.Attributes, ra)
+		}
+	}
+	return rs
+}
+
+// reportAttribute разбирает значение атрибута по OID в типизированные поля отчёта: VER, roleValidityPeriod,
+// signingTime, contentType, CMSAlgorithmProtection и значения с тегом UTCTime/GeneralizedTime.
+// typed = false — значение не разобрано (в Raw hex), и Value заполняет вызывающий код расшифровкой текстового отчёта.
+func reportAttribute(oid asn1.ObjectIdentifier, raw []byte) (a report.Attribute, typed bool) {
+	a = report.Attribute{Name: OIDToAtomName(oid), OID: oid.String(), Raw: hex.EncodeToString(raw)}
+	if a.Name == "" {
+		a.Name = a.OID
+	}
+	switch {
+	case oid.Equal(OIDAtomVER):
+		if ver, ok := parseVER(raw); ok {
+			a.VER = ver
+		}
+	case oid.Equal(OIDAtomRoleValidityPeriod):
+		if nb, na := parseRoleValidityPeriod(raw); !nb.IsZero() {
+			a.RoleValidity = &report.RoleValidity{NotBefore: nb, NotAfter: na}
+		}
+	case oid.Equal(OIDPKCS9ContentType):
+		var o asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(raw, &o); err == nil {
+			a.Value = o.String()
+		}
+	case oid.Equal(OIDCMSAlgorithmProtection):
+		var ap CMSAlgorithmProtection
+		if _, err := asn1.Unmarshal(raw, &ap); err == nil {
+			a.AlgorithmProtection = &report.AlgorithmProtection{DigestAlgorithm: ap.DigestAlgorithm.Algorithm.String()}
+			if len(ap.SignatureAlgorithm.Algorithm) > 0 {
+				a.AlgorithmProtection.SignatureAlgorithm = ap.SignatureAlgorithm.Algorithm.String()
+			}
+			if len(ap.MACAlgorithm.Algorithm) > 0 {
+				a.AlgorithmProtection.MACAlgorithm = ap.MACAlgorithm.Algorithm.String()
+			}
+		}
+	case len(raw) > 0 && (raw[0] == asn1.TagUTCTime || raw[0] == asn1.TagGeneralizedTime):
+		// signingTime и любые другие атрибуты со значением-временем.
+		var t time.Time
+		if _, err := asn1.Unmarshal(raw, &t); err == nil {
+			t = t.UTC()
+			a.Time = &t
+		}
+	}
+	typed = a.Value != "" || a.Time != nil || a.VER != nil || a.RoleValidity != nil || a.AlgorithmProtection != nil
+	if typed {
+		a.Raw = ""
+	}
+	return a, typed
+}
+
+// parseVER разбирает VER — SEQUENCE { GeneralizedTime, INTEGER }.
+func parseVER(raw []byte) (*report.VER, bool) {
+	var seq struct {
+		Timestamp asn1.RawValue
+		Version   int
+	}
+	if _, err := asn1.Unmarshal(raw, &seq); err != nil {
+		return nil, false
+	}
+	ts, err := parseGeneralizedTime(string(seq.Timestamp.Bytes))
+	if err != nil {
+		return nil, false
+	}
+	return &report.VER{Timestamp: ts, Version: seq.Version}, true
+}
+
+// ReportVerification преобразует результаты Verify в формат отчёта (поле verification).
+func ReportVerification(results []SignerVerification) []report.SignerVerification {
+	out := make([]report.SignerVerification, 0, len(results))
+	for _, r := range results {
+		rv := report.SignerVerification{SignerIndex: r.SignerIndex, SignerSubject: r.SignerSubject, OK: r.OK, Checks: make([]report.VerifyCheck, 0, len(r.Checks))}
+		for _, ch := range r.Checks {
+			rv.Checks = append(rv.Checks, report.VerifyCheck(ch))
+		}
+		out = append(out, rv)
+	}
+	return out
+}
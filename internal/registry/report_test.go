@@ -0,0 +1,128 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sgw-registry/registry-analyzer/report"
+)
+
+// TestJSONOutputTyped проверяет типизированный отчёт: schemaVersion, VER и roleValidityPeriod объектами,
+// время в RFC 3339 (UTC), отпечатки сертификатов и сертификаты мешков вместо сводки.
+func TestJSONOutputTyped(t *testing.T) {
+	c, err := Parse(buildMarshalTestRegistry(t))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	r := c.JSONOutput()
+	if r.SchemaVersion != report.SchemaVersion || r.Kind != KindRegistry {
+		t.Fatalf("schemaVersion = %d, kind = %q", r.SchemaVersion, r.Kind)
+	}
+	if len(r.Certificates) != 1 || !r.Certificates[0].IsSigner {
+		t.Fatalf("certificates: %+v", r.Certificates)
+	}
+	sum := sha256.Sum256(c.Certificates[0].Raw)
+	if got := r.Certificates[0].Fingerprints.SHA256; got != hex.EncodeToString(sum[:]) {
+		t.Errorf("fingerprints.sha256 = %s", got)
+	}
+	if r.Certificates[0].Fingerprints.SHA1 == "" || r.Certificates[0].Fingerprints.SPKISHA256 == "" {
+		t.Errorf("fingerprints: %+v", r.Certificates[0].Fingerprints)
+	}
+
+	if len(r.Signers) != 1 {
+		t.Fatalf("signers: %d", len(r.Signers))
+	}
+	s := r.Signers[0]
+	if s.VIN != "TESTVIN123" || s.UID != "CN=Test" {
+		t.Errorf("vin = %q, uid = %q", s.VIN, s.UID)
+	}
+	if s.VER == nil || s.VER.Version != 1 || s.VER.Timestamp.IsZero() || s.VER.Timestamp.Location() != time.UTC {
+		t.Errorf("ver = %+v", s.VER)
+	}
+	for _, a := range s.Attributes {
+		if a.Name == "VER" && (a.VER == nil || a.Value != "" || a.Raw != "") {
+			t.Errorf("атрибут VER: %+v", a)
+		}
+		if a.Name == "contentType" && a.Value != OIDPKCS7Data.String() {
+			t.Errorf("атрибут contentType: %+v", a)
+		}
+	}
+
+	if len(r.SafeBags) != 2 {
+		t.Fatalf("safeBags: %d", len(r.SafeBags))
+	}
+	bag := r.SafeBags[0]
+	if bag.RoleName != "delegate" || bag.Certificate == nil || bag.Certificate.Subject == "" || bag.Certificate.IsSigner {
+		t.Errorf("safeBags[0]: %+v", bag)
+	}
+	if bag.RoleValidity == nil || !bag.RoleValidity.NotAfter.Equal(bag.RoleValidity.NotBefore.Add(time.Hour)) {
+		t.Errorf("safeBags[0].roleValidity = %+v", bag.RoleValidity)
+	}
+	if r.SafeBags[1].RoleValidity != nil {
+		t.Errorf("safeBags[1].roleValidity = %+v, атрибута нет", r.SafeBags[1].RoleValidity)
+	}
+
+	data, err := c.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw struct {
+		Signers []struct {
+			VER struct {
+				Timestamp string `json:"timestamp"`
+			} `json:"ver"`
+		} `json:"signers"`
+		SafeBags []struct {
+			Certificate struct {
+				NotBefore string `json:"notBefore"`
+			} `json:"certificate"`
+		} `json:"safeBags"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	for _, ts := range []string{raw.Signers[0].VER.Timestamp, raw.SafeBags[0].Certificate.NotBefore} {
+		if _, err := time.Parse(time.RFC3339, ts); err != nil || !strings.HasSuffix(ts, "Z") {
+			t.Errorf("время %q — не RFC 3339 в UTC", ts)
+		}
+	}
+}
+
+// TestCertificatesJSONOutput проверяет отчёт -format json-certificates.
+func TestCertificatesJSONOutput(t *testing.T) {
+	c, err := Parse(buildMarshalTestRegistry(t))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	r := c.CertificatesJSONOutput()
+	if r.SchemaVersion != report.SchemaVersion || len(r.Certificates) != 1 || string(r.Certificates[0].Raw) != string(c.Certificates[0].Raw) {
+		t.Errorf("CertificatesJSONOutput: %+v", r)
+	}
+}
+
+// TestReportAttribute проверяет разбор значений атрибутов в типизированные поля.
+func TestReportAttribute(t *testing.T) {
+	when := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	signingTime, _ := attrSigningTime(when)
+	a, typed := reportAttribute(OIDPKCS9SigningTime, signingTime.AttrValues[0].FullBytes)
+	if !typed || a.Time == nil || !a.Time.Equal(when) || a.Raw != "" {
+		t.Errorf("signingTime: %+v", a)
+	}
+	protection, _ := attrAlgorithmProtection(AlgorithmIdentifier{Algorithm: OIDSHA256}, AlgorithmIdentifier{Algorithm: OIDECDSAWithSHA256})
+	a, typed = reportAttribute(OIDCMSAlgorithmProtection, protection.AttrValues[0].FullBytes)
+	if ap := a.AlgorithmProtection; !typed || ap == nil || ap.DigestAlgorithm != OIDSHA256.String() || ap.SignatureAlgorithm != OIDECDSAWithSHA256.String() || ap.MACAlgorithm != "" {
+		t.Errorf("CMSAlgorithmProtection: %+v", a)
+	}
+	a, typed = reportAttribute(OIDAtomVIN, []byte{0x0c, 0x01, 'X'})
+	if typed || a.Name != "VIN" || a.Raw == "" {
+		t.Errorf("VIN: %+v, typed = %v", a, typed)
+	}
+	a, typed = reportAttribute(OIDAtomVER, []byte{0x30, 0x00})
+	if typed || a.VER != nil || a.Raw != "3000" {
+		t.Errorf("повреждённый VER: %+v", a)
+	}
+}
@@ -1,4 +1,5 @@
-// safebag.go — разбор SafeBag, CertBag и атрибутов мешка (roleName, roleValidityPeriod, localKeyID).
+// safebag.go — разбор SafeBag, CertBag и атрибутов мешка roleName, roleValidityPeriod и localKeyID.
+// Остальные типы мешков разбираются в bags.go.
 package registry
 
 import (
@@ -7,8 +8,6 @@ import (
 	"fmt"
 	"time"
 	"unicode/utf16"
-
-	"crypto/x509"
 )
 
 // formatUUID форматирует 16 байт как UUID с дефисами (xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx).
@@ -22,26 +21,63 @@ func formatUUID(b []byte) string {
 
 // formatGeneralizedTime преобразует ASN.1 GeneralizedTime (например "20260115174021Z") в формат "2006-01-02 15:04:05".
 func formatGeneralizedTime(s string) string {
-	t, err := time.Parse("20060102150405Z", s)
-	if err != nil {
-		t, err = time.Parse("20060102150405.000Z", s)
-	}
+	t, err := parseGeneralizedTime(s)
 	if err != nil {
 		return s
 	}
 	return t.Format("2006-01-02 15:04:05")
 }
 
+// parseGeneralizedTime разбирает GeneralizedTime в UTC (с долями секунды или без).
+func parseGeneralizedTime(s string) (time.Time, error) {
+	t, err := time.Parse("20060102150405Z", s)
+	if err != nil {
+		t, err = time.Parse("20060102150405.000Z", s)
+	}
+	return t, err
+}
+
 // SafeBagInfo — расшифрованный мешок SafeBag.
-// Содержит CertBag (CertId, CertValueDER), CertSummary при X.509, BagAttributes (roleName, roleValidityPeriod, localKeyID).
+// Для CertBag содержит CertId, CertValueDER и CertSummary при X.509; для остальных типов мешков RFC 7292 —
+// сводку по типу (Key, CRL, Secret, Nested). BagAttributes (roleName, roleValidityPeriod, localKeyID) — для любого типа.
 type SafeBagInfo struct {
 	BagId         asn1.ObjectIdentifier
+	BagType       string                // имя типа мешка (certBag, keyBag, ...) или OID для неизвестного
 	CertId        asn1.ObjectIdentifier // тип сертификата из CertBag
 	CertType      string                // читаемое описание типа
 	CertSummary   *CertSummary          // краткие данные сертификата, если certValue — X.509
 	CertValueLen  int                   // длина сырых байт, если не X.509
 	CertValueDER  []byte                // сырой DER сертификата (для X.509), для выгрузки в PEM
+	Key           *KeyBagSummary        // keyBag и pkcs8ShroudedKeyBag
+	CRL           *CRLSummary           // crlBag
+	Secret        *SecretBagSummary     // secretBag
+	Nested        []SafeBagInfo         // safeContentsBag: вложенные мешки
+	Error         string                // ошибка разбора значения мешка (мешок при этом не пропускается)
 	BagAttributes []BagAttributeValue   // расшифрованные атрибуты мешка (roleName, localKeyID и т.д.)
+	Attributes    []Attribute           // исходные bagAttributes (для типизированного JSON-отчёта)
+	RoleNotBefore time.Time             // roleValidityPeriod.notBeforeTime; нулевое значение — атрибута нет
+	RoleNotAfter  time.Time             // roleValidityPeriod.notAfterTime
+}
+
+// Статусы роли мешка по roleValidityPeriod (SafeBagInfo.RoleStatus).
+const (
+	RoleStatusNone    = ""        // атрибута roleValidityPeriod нет
+	RoleStatusActive  = "active"  // now внутри периода
+	RoleStatusNotYet  = "not-yet" // период ещё не начался
+	RoleStatusExpired = "expired" // период закончился
+)
+
+// RoleStatus возвращает статус роли мешка на момент now по roleValidityPeriod.
+func (info *SafeBagInfo) RoleStatus(now time.Time) string {
+	switch {
+	case info.RoleNotAfter.IsZero():
+		return RoleStatusNone
+	case now.Before(info.RoleNotBefore):
+		return RoleStatusNotYet
+	case now.After(info.RoleNotAfter):
+		return RoleStatusExpired
+	}
+	return RoleStatusActive
 }
 
 // CertSummary — краткая информация о сертификате X.509 (subject, issuer, serial, срок действия, алгоритм ключа).
@@ -60,44 +96,61 @@ type BagAttributeValue struct {
 	Value string `json:"value"`
 }
 
-// ParseSafeBagInfo разбирает один SafeBag в SafeBagInfo: извлекает CertBag, при возможности парсит сертификат как X.509 и расшифровывает атрибуты мешка.
-func ParseSafeBagInfo(bag SafeBag) (info SafeBagInfo, err error) {
+// ParseSafeBagInfo разбирает один SafeBag в SafeBagInfo: расшифровывает атрибуты мешка и значение по bagId
+// (CertBag с сертификатом X.509, keyBag, pkcs8ShroudedKeyBag без расшифровки, crlBag, secretBag, safeContentsBag).
+// Мешок с неизвестным bagId разбирается как CertBag. При ошибке разбора значения info заполнен всем, что удалось разобрать.
+// Вложенные мешки safeContentsBag ограничены DefaultLimits.
+func ParseSafeBagInfo(bag SafeBag) (SafeBagInfo, error) {
+	return parseSafeBagInfo(bag, &bagBudget{lim: DefaultLimits})
+}
+
+// parseSafeBagInfo — ParseSafeBagInfo с вложенными мешками в пределах budget; превышение лимита — ErrLimitExceeded.
+func parseSafeBagInfo(bag SafeBag, budget *bagBudget) (info SafeBagInfo, err error) {
 	info.BagId = bag.BagId
-	// BagValue — [0] IMPLICIT CertBag: в Bytes может быть content SEQUENCE без 0x30.
-	bagValueBytes := bag.BagValue.Bytes
-	if len(bagValueBytes) == 0 && len(bag.BagValue.FullBytes) > 0 {
-		bagValueBytes = bag.BagValue.FullBytes
-	}
-	if len(bagValueBytes) > 0 && bagValueBytes[0] != 0x30 {
-		bagValueBytes = derPrependTLV(0x30, bag.BagValue.Bytes)
-	}
-	var cb CertBag
-	_, err = asn1.Unmarshal(bagValueBytes, &cb)
-	if err != nil {
-		return info, err
-	}
-	info.CertId = cb.CertId
-	info.CertType = CertTypeName(cb.CertId)
-	// CertValue: [0] IMPLICIT OCTET STRING → Bytes = cert DER; иначе может быть 04 ll ... (EXPLICIT)
-	certDER := cb.CertValue.Bytes
-	certDER = unwrapOctetStringIfPresent(certDER)
-	info.CertValueLen = len(certDER)
-	if cert, err := x509.ParseCertificate(certDER); err == nil {
-		info.CertSummary = &CertSummary{
-			Subject:   cert.Subject.String(),
-			Issuer:    cert.Issuer.String(),
-			Serial:    cert.SerialNumber.Text(16),
-			NotBefore: cert.NotBefore.Format("2006-01-02"),
-			NotAfter:  cert.NotAfter.Format("2006-01-02"),
-			KeyAlg:    cert.PublicKeyAlgorithm.String(),
-		}
-		info.CertValueDER = append([]byte(nil), certDER...) // копия для выгрузки в PEM
+	info.BagType = OIDToAtomName(bag.BagId)
+	if info.BagType == "" {
+		info.BagType = bag.BagId.String()
 	}
+	info.Attributes = bag.BagAttributes
 	for _, a := range bag.BagAttributes {
 		vals := DecodeBagAttributeValues(a)
 		info.BagAttributes = append(info.BagAttributes, vals...)
+		if a.AttrType.Equal(OIDAtomRoleValidityPeriod) && len(a.AttrValues) > 0 {
+			info.RoleNotBefore, info.RoleNotAfter = parseRoleValidityPeriod(a.AttrValues[0].FullBytes)
+		}
 	}
-	return info, nil
+	value := safeBagValueDER(bag)
+	switch {
+	case bag.BagId.Equal(OIDKeyBag):
+		info.Key, err = parseKeyBagValue(value)
+	case bag.BagId.Equal(OIDPKCS8ShroudedKeyBag):
+		info.Key, err = parseShroudedKeyBagValue(value)
+	case bag.BagId.Equal(OIDCRLBag):
+		info.CRL, err = parseCRLBagValue(value)
+	case bag.BagId.Equal(OIDSecretBag):
+		info.Secret, err = parseSecretBagValue(value)
+	case bag.BagId.Equal(OIDSafeContentsBag):
+		info.Nested, err = parseNestedSafeContents(value, budget)
+	default:
+		err = parseCertBagValue(&info, value)
+	}
+	return info, err
+}
+
+// parseRoleValidityPeriod разбирает RoleValidityPeriod (SEQUENCE из двух GeneralizedTime); при ошибке — нулевые значения.
+func parseRoleValidityPeriod(der []byte) (notBefore, notAfter time.Time) {
+	var seq struct {
+		NotBefore, NotAfter asn1.RawValue
+	}
+	if _, err := asn1.Unmarshal(der, &seq); err != nil {
+		return time.Time{}, time.Time{}
+	}
+	nb, err1 := parseGeneralizedTime(string(seq.NotBefore.Bytes))
+	na, err2 := parseGeneralizedTime(string(seq.NotAfter.Bytes))
+	if err1 != nil || err2 != nil {
+		return time.Time{}, time.Time{}
+	}
+	return nb, na
 }
 
 // DecodeBagAttributeValues расшифровывает атрибуты мешка PKCS#12 (friendlyName, localKeyID, roleName, roleValidityPeriod и т.д.).
@@ -138,6 +191,16 @@ func decodeBagAttrValue(oid asn1.ObjectIdentifier, content, full []byte) string
 		return hex.EncodeToString(raw)
 	case oid.Equal(OIDAtomRoleName):
 		return string(content)
+	case oid.Equal(OIDJavaTrustedKeyUsage):
+		// Назначение доверия Java: OID расширенного назначения ключа, обычно anyExtendedKeyUsage.
+		var usage asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(full, &usage); err == nil {
+			if usage.Equal(oidAnyExtendedKeyUsage) {
+				return "anyExtendedKeyUsage (" + usage.String() + ")"
+			}
+			return usage.String()
+		}
+		return hex.EncodeToString(content)
 	case oid.Equal(OIDAtomRoleValidityPeriod):
 		// Период действия роли: SEQUENCE { notBeforeTime GeneralizedTime, notAfterTime GeneralizedTime }.
 		var seq struct {
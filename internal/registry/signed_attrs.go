@@ -0,0 +1,214 @@
+// signed_attrs.go — дополнительные подписанные атрибуты SignerInfo: signingTime, CMSAlgorithmProtection (RFC 6211)
+// и произвольные атрибуты с типизированными значениями (номер дилера, номер заказа и т.п.).
+package registry
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Типы значений произвольного подписанного атрибута (поле CustomAttribute.Type).
+const (
+	AttrTypeUTF8String      = "utf8String"
+	AttrTypePrintableString = "printableString"
+	AttrTypeIA5String       = "ia5String"
+	AttrTypeInteger         = "integer"
+	AttrTypeBoolean         = "boolean"
+	AttrTypeGeneralizedTime = "generalizedTime"
+	AttrTypeUTCTime         = "utcTime"
+	AttrTypeOctetString     = "octetString" // значение — hex
+	AttrTypeOID             = "oid"
+)
+
+// CustomAttribute — произвольный подписанный атрибут SignerInfo.authenticatedAttributes [0].
+// OID — тип атрибута (dotted-строка); Type — ASN.1-тип значения; Values — одно или несколько значений (SET OF).
+type CustomAttribute struct {
+	OID    string   `json:"oid"`
+	Type   string   `json:"type"`
+	Values []string `json:"values"`
+}
+
+// reservedSignedAttrOIDs — атрибуты, которые формирует сам builder; переопределять их через CustomAttribute нельзя.
+var reservedSignedAttrOIDs = []asn1.ObjectIdentifier{
+	OIDPKCS9ContentType, OIDPKCS9MessageDigest, OIDPKCS9SigningTime, OIDCMSAlgorithmProtection,
+	OIDAtomVIN, OIDAtomVER, OIDAtomUID,
+}
+
+// ParseOID разбирает OID в dotted-нотации ("1.3.6.1.4.1.99999.2.1").
+func ParseOID(s string) (asn1.ObjectIdentifier, error) {
+	parts := strings.Split(strings.TrimSpace(s), ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid OID %q", s)
+	}
+	oid := make(asn1.ObjectIdentifier, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid OID %q", s)
+		}
+		oid = append(oid, n)
+	}
+	return oid, nil
+}
+
+// Attribute кодирует CustomAttribute в Attribute (SET OF значений указанного типа).
+func (ca CustomAttribute) Attribute() (Attribute, error) {
+	oid, err := ParseOID(ca.OID)
+	if err != nil {
+		return Attribute{}, err
+	}
+	for _, r := range reservedSignedAttrOIDs {
+		if oid.Equal(r) {
+			return Attribute{}, fmt.Errorf("attribute %s is set by the builder and cannot be overridden", oid)
+		}
+	}
+	if len(ca.Values) == 0 {
+		return Attribute{}, fmt.Errorf("attribute %s: no values", oid)
+	}
+	a := Attribute{AttrType: oid}
+	for _, v := range ca.Values {
+		der, err := marshalTypedValue(ca.Type, v)
+		if err != nil {
+			return Attribute{}, fmt.Errorf("attribute %s: %w", oid, err)
+		}
+		a.AttrValues = append(a.AttrValues, asn1.RawValue{FullBytes: der})
+	}
+	// SET OF AttributeValue: значения сортируются по DER (X.690).
+	a.AttrValues = sortRawValuesByDER(a.AttrValues)
+	return a, nil
+}
+
+// marshalTypedValue кодирует строковое значение из конфига в DER указанного ASN.1-типа.
+func marshalTypedValue(typ, v string) ([]byte, error) {
+	switch typ {
+	case AttrTypeUTF8String, "":
+		return marshalUTF8StringValue(v), nil
+	case AttrTypePrintableString:
+		return asn1.MarshalWithParams(v, "printable")
+	case AttrTypeIA5String:
+		return asn1.MarshalWithParams(v, "ia5")
+	case AttrTypeInteger:
+		n, ok := new(big.Int).SetString(strings.TrimSpace(v), 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q", v)
+		}
+		return asn1.Marshal(n)
+	case AttrTypeBoolean:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", v)
+		}
+		return asn1.Marshal(b)
+	case AttrTypeGeneralizedTime, AttrTypeUTCTime:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q (RFC3339 expected)", v)
+		}
+		if typ == AttrTypeUTCTime {
+			return asn1.MarshalWithParams(t.UTC(), "utc")
+		}
+		return asn1.MarshalWithParams(t.UTC(), "generalized")
+	case AttrTypeOctetString:
+		b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(v), "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex %q", v)
+		}
+		return asn1.Marshal(b)
+	case AttrTypeOID:
+		oid, err := ParseOID(v)
+		if err != nil {
+			return nil, err
+		}
+		return asn1.Marshal(oid)
+	default:
+		return nil, fmt.Errorf("unsupported value type %q", typ)
+	}
+}
+
+// typedValueConfig — обратное к marshalTypedValue: по DER значения возвращает тип и строку для конфига.
+// ok=false, если тип не из списка AttrType* (значение нельзя задать в signedAttributes).
+func typedValueConfig(raw []byte) (typ, value string, ok bool) {
+	var rv asn1.RawValue
+	if _, err := asn1.Unmarshal(raw, &rv); err != nil || rv.Class != asn1.ClassUniversal {
+		return "", "", false
+	}
+	switch rv.Tag {
+	case asn1.TagUTF8String:
+		return AttrTypeUTF8String, string(rv.Bytes), true
+	case asn1.TagPrintableString:
+		return AttrTypePrintableString, string(rv.Bytes), true
+	case asn1.TagIA5String:
+		return AttrTypeIA5String, string(rv.Bytes), true
+	case asn1.TagInteger:
+		var n *big.Int
+		if _, err := asn1.Unmarshal(raw, &n); err == nil {
+			return AttrTypeInteger, n.String(), true
+		}
+	case asn1.TagBoolean:
+		var b bool
+		if _, err := asn1.Unmarshal(raw, &b); err == nil {
+			return AttrTypeBoolean, strconv.FormatBool(b), true
+		}
+	case asn1.TagGeneralizedTime, asn1.TagUTCTime:
+		var t time.Time
+		if _, err := asn1.Unmarshal(raw, &t); err == nil {
+			if rv.Tag == asn1.TagUTCTime {
+				return AttrTypeUTCTime, t.UTC().Format(time.RFC3339), true
+			}
+			return AttrTypeGeneralizedTime, t.UTC().Format(time.RFC3339), true
+		}
+	case asn1.TagOctetString:
+		return AttrTypeOctetString, hex.EncodeToString(rv.Bytes), true
+	case asn1.TagOID:
+		var o asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(raw, &o); err == nil {
+			return AttrTypeOID, o.String(), true
+		}
+	}
+	return "", "", false
+}
+
+// sortRawValuesByDER сортирует значения SET OF по DER-кодировке.
+func sortRawValuesByDER(vals []asn1.RawValue) []asn1.RawValue {
+	out := append([]asn1.RawValue(nil), vals...)
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i].FullBytes, out[j].FullBytes) < 0 })
+	return out
+}
+
+// attrSigningTime кодирует signingTime (PKCS#9): UTCTime для 1950–2049, иначе GeneralizedTime (RFC 5652, 11.3).
+func attrSigningTime(t time.Time) (Attribute, error) {
+	t = t.UTC()
+	params := "utc"
+	if t.Year() < 1950 || t.Year() > 2049 {
+		params = "generalized"
+	}
+	val, err := asn1.MarshalWithParams(t, params)
+	if err != nil {
+		return Attribute{}, err
+	}
+	return Attribute{AttrType: OIDPKCS9SigningTime, AttrValues: []asn1.RawValue{{FullBytes: val}}}, nil
+}
+
+// CMSAlgorithmProtection (RFC 6211) — алгоритмы хеширования и подписи, защищённые подписью.
+// SEQUENCE { digestAlgorithm, signatureAlgorithm [1] IMPLICIT OPTIONAL, macAlgorithm [2] IMPLICIT OPTIONAL }.
+type CMSAlgorithmProtection struct {
+	DigestAlgorithm    AlgorithmIdentifier
+	SignatureAlgorithm AlgorithmIdentifier `asn1:"optional,tag:1"`
+	MACAlgorithm       AlgorithmIdentifier `asn1:"optional,tag:2"`
+}
+
+// attrAlgorithmProtection кодирует атрибут CMSAlgorithmProtection для пары digest/signature SignerInfo.
+func attrAlgorithmProtection(digestAlg, sigAlg AlgorithmIdentifier) (Attribute, error) {
+	val, err := asn1.Marshal(CMSAlgorithmProtection{DigestAlgorithm: digestAlg, SignatureAlgorithm: sigAlg})
+	if err != nil {
+		return Attribute{}, err
+	}
+	return Attribute{AttrType: OIDCMSAlgorithmProtection, AttrValues: []asn1.RawValue{{FullBytes: val}}}, nil
+}
@@ -0,0 +1,67 @@
+// summary.go — сводка контейнера для пакетного анализа registry-analyzer (NDJSON/CSV): атрибуты подписанта,
+// число сертификатов и мешков, ближайшее истечение.
+package registry
+
+import (
+	"crypto/x509"
+	"time"
+)
+
+// Summary — сводка контейнера для пакетного анализа (одна запись NDJSON/CSV на файл).
+// VIN, VER, UID — атрибуты первого подписанта в виде отчёта (DecodeAttributeValues);
+// EarliestExpiry — наименьший notAfter среди сертификатов SignedData и SafeBags.
+type Summary struct {
+	VIN                   string
+	VER                   string
+	UID                   string
+	Signer                string // subject сертификата первого подписанта; пусто, если сертификат не найден
+	CertificateCount      int
+	SafeBagCount          int
+	EarliestExpiry        time.Time // нулевое значение — сертификатов нет
+	EarliestExpirySubject string
+}
+
+// Summary возвращает сводку контейнера: атрибуты подписанта, число сертификатов и мешков, ближайшее истечение срока.
+func (c *Container) Summary() Summary {
+	s := Summary{CertificateCount: len(c.Certificates), SafeBagCount: len(c.SafeBags)}
+	if len(c.Signers) > 0 {
+		si := &c.Signers[0]
+		if cert := c.SignerCert(si); cert != nil {
+			s.Signer = cert.Subject.String()
+		}
+		attrs, _ := SignerAttributes(si)
+		for _, a := range attrs {
+			var dst *string
+			switch {
+			case a.AttrType.Equal(OIDAtomVIN):
+				dst = &s.VIN
+			case a.AttrType.Equal(OIDAtomVER):
+				dst = &s.VER
+			case a.AttrType.Equal(OIDAtomUID):
+				dst = &s.UID
+			default:
+				continue
+			}
+			if vals := DecodeAttributeValues(a); len(vals) > 0 {
+				*dst = vals[0].Value
+			}
+		}
+	}
+	consider := func(cert *x509.Certificate) {
+		if s.EarliestExpiry.IsZero() || cert.NotAfter.Before(s.EarliestExpiry) {
+			s.EarliestExpiry, s.EarliestExpirySubject = cert.NotAfter, cert.Subject.String()
+		}
+	}
+	for _, cert := range c.Certificates {
+		consider(cert)
+	}
+	for _, info := range c.SafeBagInfos {
+		if len(info.CertValueDER) == 0 {
+			continue
+		}
+		if cert, err := x509.ParseCertificate(info.CertValueDER); err == nil {
+			consider(cert)
+		}
+	}
+	return s
+}
@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSummary проверяет сводку для пакетного анализа: атрибуты подписанта, счётчики и ближайшее истечение срока
+// (сертификат мешка истекает раньше сертификата подписанта).
+func TestSummary(t *testing.T) {
+	cert, key := newTestSigner(t)
+	bagKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	bagNotAfter := time.Now().Add(2 * time.Hour).UTC().Truncate(time.Second)
+	bagDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		Subject:      pkix.Name{CommonName: "Driver"},
+		SerialNumber: big.NewInt(2),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     bagNotAfter,
+	}, cert, &bagKey.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	ts := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	der, err := BuildRegistry(cert, key, []SafeBagInput{
+		{CertDER: cert.Raw, RoleName: "delegate"},
+		{CertDER: bagDER, RoleName: "driver"},
+	}, SignerAttrs{VIN: "TESTVIN123", VERTimestamp: ts, VERVersion: 7, UID: "CN=Test"})
+	if err != nil {
+		t.Fatalf("BuildRegistry: %v", err)
+	}
+	c, err := Parse(der)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	s := c.Summary()
+	if s.VIN != "TESTVIN123" || s.UID != "CN=Test" || !strings.Contains(s.VER, "7") {
+		t.Errorf("VIN/VER/UID = %q/%q/%q", s.VIN, s.VER, s.UID)
+	}
+	if s.Signer != "CN=Test Registry Signer" {
+		t.Errorf("Signer = %q", s.Signer)
+	}
+	if s.CertificateCount != 1 || s.SafeBagCount != 2 {
+		t.Errorf("сертификатов %d, мешков %d; ожидается 1 и 2", s.CertificateCount, s.SafeBagCount)
+	}
+	if !s.EarliestExpiry.Equal(bagNotAfter) || s.EarliestExpirySubject != "CN=Driver" {
+		t.Errorf("EarliestExpiry = %v (%s), ожидается %v (CN=Driver)", s.EarliestExpiry, s.EarliestExpirySubject, bagNotAfter)
+	}
+}
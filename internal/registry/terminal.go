@@ -14,6 +14,7 @@ var (
 	Dim     = "\033[2m"
 	Cyan    = "\033[36m"
 	Green   = "\033[32m"
+	Red     = "\033[31m"
 	Yellow  = "\033[33m"
 	Magenta = "\033[35m"
 	Blue    = "\033[34m"
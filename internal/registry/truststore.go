@@ -0,0 +1,202 @@
+// truststore.go — выгрузка сертификатов реестра в стандартное хранилище доверенных сертификатов PKCS#12 (RFC 7292)
+// для Java (keytool), .NET и OpenSSL: certBag с friendlyName и localKeyID, шифрование PBES2 и MAC HMAC-SHA-256.
+package registry
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"unicode/utf16"
+)
+
+// oidAnyExtendedKeyUsage — значение атрибута OIDJavaTrustedKeyUsage: доверие для любого назначения.
+var oidAnyExtendedKeyUsage = asn1.ObjectIdentifier{2, 5, 29, 37, 0}
+
+// DefaultTruststoreIterations — число итераций PBKDF2 и MAC (как у OpenSSL 3 по умолчанию).
+const DefaultTruststoreIterations = 2048
+
+// TruststoreEntry — сертификат хранилища: DER, псевдоним (friendlyName) и localKeyID (пустой — атрибут не пишется).
+type TruststoreEntry struct {
+	CertDER      []byte
+	FriendlyName string
+	LocalKeyID   []byte
+}
+
+// TruststoreOptions — какие сертификаты, кроме сертификатов мешков, попадают в хранилище.
+type TruststoreOptions struct {
+	IncludeSigner bool // сертификат подписанта контейнера
+	IncludeChain  bool // остальные сертификаты SignedData.certificates (CA)
+}
+
+// TruststoreEntries возвращает сертификаты для хранилища: сертификаты мешков с псевдонимом SafeBagExportBasename
+// (roleName_Serial) и исходным localKeyID, затем по opt — подписант (roleName подписанта или signer, _Serial)
+// и цепочка (ca_Serial). Повторяющиеся псевдонимы получают суффикс -2, -3: keytool перезаписывает записи с одним именем.
+func (c *Container) TruststoreEntries(opt TruststoreOptions) []TruststoreEntry {
+	var out []TruststoreEntry
+	used := make(map[string]int)
+	add := func(der []byte, name string, localKeyID []byte) {
+		if n := used[name]; n > 0 {
+			used[name] = n + 1
+			name = fmt.Sprintf("%s-%d", name, n+1)
+		} else {
+			used[name] = 1
+		}
+		out = append(out, TruststoreEntry{CertDER: der, FriendlyName: name, LocalKeyID: localKeyID})
+	}
+	for i := range c.SafeBagInfos {
+		info := &c.SafeBagInfos[i]
+		if len(info.CertValueDER) == 0 {
+			continue
+		}
+		var localKeyID []byte
+		if i < len(c.SafeBags) {
+			localKeyID = bagLocalKeyID(c.SafeBags[i])
+		}
+		add(info.CertValueDER, SafeBagExportBasename(info, i), localKeyID)
+	}
+	for i, cert := range c.Certificates {
+		if c.Kind == KindKeystore {
+			break // у хранилища PKCS#12 сертификаты — это сертификаты мешков
+		}
+		serial := sanitizeExportBasename(cert.SerialNumber.Text(16))
+		switch signer := c.isSignerCert(cert); {
+		case signer && opt.IncludeSigner:
+			name := c.CertExportBasename(cert, i)
+			if name == fmt.Sprintf("cert-%d", i+1) {
+				name = "signer"
+			}
+			add(cert.Raw, name+"_"+serial, nil)
+		case !signer && opt.IncludeChain:
+			add(cert.Raw, "ca_"+serial, nil)
+		}
+	}
+	return out
+}
+
+// bagLocalKeyID возвращает значение атрибута localKeyID мешка (OCTET STRING) или nil.
+func bagLocalKeyID(bag SafeBag) []byte {
+	for _, a := range bag.BagAttributes {
+		if !a.AttrType.Equal(OIDPKCS9LocalKeyID) || len(a.AttrValues) == 0 {
+			continue
+		}
+		var id []byte
+		if _, err := asn1.Unmarshal(a.AttrValues[0].FullBytes, &id); err == nil {
+			return id
+		}
+	}
+	return nil
+}
+
+// Truststore собирает хранилище PKCS#12 из TruststoreEntries(opt), см. BuildTruststore.
+func (c *Container) Truststore(password string, opt TruststoreOptions) ([]byte, error) {
+	entries := c.TruststoreEntries(opt)
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("truststore: no X.509 certificates to export")
+	}
+	return BuildTruststore(entries, password, DefaultTruststoreIterations)
+}
+
+// BuildTruststore кодирует хранилище PKCS#12 (RFC 7292) из сертификатов без ключей, как openssl pkcs12 -export -nokeys:
+// authSafe = data с AuthenticatedSafe из одного encryptedData (PBES2: PBKDF2-HMAC-SHA256, AES-256-CBC),
+// macData — HMAC-SHA-256. Каждый certBag несёт friendlyName (BMPString), localKeyID и признак доверия Java.
+func BuildTruststore(entries []TruststoreEntry, password string, iterations int) ([]byte, error) {
+	if iterations <= 0 {
+		iterations = DefaultTruststoreIterations
+	}
+	bags := make([]SafeBag, 0, len(entries))
+	for i, e := range entries {
+		if _, err := x509.ParseCertificate(e.CertDER); err != nil {
+			return nil, fmt.Errorf("truststore: certificate %d: %w", i+1, err)
+		}
+		certValue, err := asn1.Marshal(e.CertDER)
+		if err != nil {
+			return nil, err
+		}
+		cbDER, err := asn1.Marshal(CertBag{
+			CertId:    OIDX509Certificate,
+			CertValue: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: certValue, IsCompound: true},
+		})
+		if err != nil {
+			return nil, err
+		}
+		var attrs []Attribute
+		if e.FriendlyName != "" {
+			attrs = append(attrs, Attribute{AttrType: OIDPKCS9FriendlyName, AttrValues: []asn1.RawValue{{FullBytes: marshalBMPString(e.FriendlyName)}}})
+		}
+		if len(e.LocalKeyID) > 0 {
+			attrs = append(attrs, attrOctetString(OIDPKCS9LocalKeyID, e.LocalKeyID))
+		}
+		trusted, _ := asn1.Marshal(oidAnyExtendedKeyUsage)
+		attrs = append(attrs, Attribute{AttrType: OIDJavaTrustedKeyUsage, AttrValues: []asn1.RawValue{{FullBytes: trusted}}})
+		bags = append(bags, SafeBag{
+			BagId:         OIDCertBag,
+			BagValue:      asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: cbDER, IsCompound: true},
+			BagAttributes: sortAttributesByDER(attrs),
+		})
+	}
+	safeContents, err := asn1.Marshal(SafeContents(bags))
+	if err != nil {
+		return nil, err
+	}
+
+	pw := newPBEPassword(password)
+	alg, ciphertext, err := encryptPBES2(safeContents, pw, iterations)
+	if err != nil {
+		return nil, fmt.Errorf("truststore: %w", err)
+	}
+	var ed encryptedData
+	ed.EncryptedContentInfo.ContentType = OIDPKCS7Data
+	ed.EncryptedContentInfo.ContentEncryptionAlgorithm = alg
+	ed.EncryptedContentInfo.EncryptedContent = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: ciphertext}
+	edDER, err := asn1.Marshal(ed)
+	if err != nil {
+		return nil, err
+	}
+	authSafe, err := asn1.Marshal([]ContentInfo{{
+		ContentType: OIDPKCS7EncryptedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: edDER, IsCompound: true},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	authSafeOctets, err := asn1.Marshal(authSafe)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	mac := pkcs12Mac(pkcs12Hash{"SHA-256", sha256.New, 64}, salt, pw.bmp, iterations, authSafe)
+	return asn1.Marshal(PFX{
+		Version: 3,
+		AuthSafe: ContentInfo{
+			ContentType: OIDPKCS7Data,
+			Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: authSafeOctets, IsCompound: true},
+		},
+		MacData: MacData{
+			Mac: DigestInfo{
+				DigestAlgorithm: AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}, Parameters: asn1.NullRawValue},
+				Digest:          mac,
+			},
+			MacSalt:    salt,
+			Iterations: iterations,
+		},
+	})
+}
+
+// marshalBMPString кодирует строку как ASN.1 BMPString (тег 0x1E, UCS-2 big-endian) — кодировка friendlyName
+// в PKCS#12, которую ожидают keytool и OpenSSL.
+func marshalBMPString(s string) []byte {
+	var b bytes.Buffer
+	for _, r := range utf16.Encode([]rune(s)) {
+		b.WriteByte(byte(r >> 8))
+		b.WriteByte(byte(r))
+	}
+	rv, _ := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagBMPString, Bytes: b.Bytes()})
+	return rv
+}
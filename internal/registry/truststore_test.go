@@ -0,0 +1,88 @@
+package registry
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// TestTruststore проверяет выгрузку реестра в хранилище PKCS#12: хранилище открывается разборщиком хранилищ
+// с тем же паролем (MAC, PBES2), у сертификатов мешков — псевдонимы roleName_Serial и исходный localKeyID,
+// подписант и цепочка добавляются только по опциям, совпадающие псевдонимы получают суффикс.
+func TestTruststore(t *testing.T) {
+	cert, key := newTestSigner(t)
+	driver := newTestLeaf(t, cert, key, "Driver", 10)
+	now := time.Now().UTC().Truncate(time.Second)
+	der, err := BuildRegistry(cert, key, []SafeBagInput{
+		{CertDER: driver, RoleName: "driver", RoleNotBefore: now, RoleNotAfter: now.Add(time.Hour), LocalKeyID: []byte{1, 2, 3, 4}},
+		{CertDER: driver, RoleName: "driver"},
+	}, SignerAttrs{VIN: "TESTVIN123", VERTimestamp: now, VERVersion: 1, UID: "CN=Test"})
+	if err != nil {
+		t.Fatalf("BuildRegistry: %v", err)
+	}
+	c, err := Parse(der)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if n := len(c.TruststoreEntries(TruststoreOptions{})); n != 2 {
+		t.Errorf("без опций %d сертификатов, ожидается 2 (только мешки)", n)
+	}
+	p12, err := c.Truststore("changeit", TruststoreOptions{IncludeSigner: true, IncludeChain: true})
+	if err != nil {
+		t.Fatalf("Truststore: %v", err)
+	}
+	ts, err := ParseWithPassword(p12, DefaultLimits, "changeit")
+	if err != nil {
+		t.Fatalf("ParseWithPassword: %v", err)
+	}
+	if ts.Kind != KindKeystore || !ts.MacVerified() || ts.Mac.Algorithm != "HMAC-SHA-256" {
+		t.Fatalf("Kind = %q, Mac = %+v; ожидается хранилище с HMAC-SHA-256 ok", ts.Kind, ts.Mac)
+	}
+	if len(ts.AuthSafe) != 1 || ts.AuthSafe[0].Error != "" || ts.AuthSafe[0].EncryptionAlgorithm != "PBES2 (PBKDF2-HMAC-SHA256, 2048 iterations, AES-256-CBC)" {
+		t.Errorf("AuthSafe = %+v", ts.AuthSafe)
+	}
+
+	serial := c.SafeBagInfos[0].CertSummary.Serial
+	want := []struct {
+		name       string
+		localKeyID []byte
+		der        []byte
+	}{
+		{"driver_" + serial, []byte{1, 2, 3, 4}, driver},
+		{"driver_" + serial + "-2", bagLocalKeyID(c.SafeBags[1]), driver},
+		{"signer_" + cert.SerialNumber.Text(16), nil, cert.Raw},
+	}
+	if len(ts.SafeBagInfos) != len(want) {
+		t.Fatalf("мешков %d, ожидается %d", len(ts.SafeBagInfos), len(want))
+	}
+	for i, w := range want {
+		info := &ts.SafeBagInfos[i]
+		var friendlyName, trusted string
+		for _, a := range info.BagAttributes {
+			switch a.Name {
+			case "friendlyName":
+				friendlyName = a.Value
+			case "trustedKeyUsage":
+				trusted = a.Value
+			}
+		}
+		if friendlyName != w.name || trusted == "" {
+			t.Errorf("[%d] friendlyName = %q, trustedKeyUsage = %q; ожидается %q", i, friendlyName, trusted, w.name)
+		}
+		if got := bagLocalKeyID(ts.SafeBags[i]); !bytes.Equal(got, w.localKeyID) {
+			t.Errorf("[%d] localKeyID = %x, ожидается %x", i, got, w.localKeyID)
+		}
+		if !bytes.Equal(info.CertValueDER, w.der) {
+			t.Errorf("[%d] сертификат не совпадает", i)
+		}
+	}
+
+	ts, err = ParseWithPassword(p12, DefaultLimits, "wrong")
+	if err != nil {
+		t.Fatalf("ParseWithPassword (неверный пароль): %v", err)
+	}
+	if ts.MacVerified() || len(ts.Certificates) != 0 {
+		t.Error("хранилище открылось неверным паролем")
+	}
+}
@@ -0,0 +1,289 @@
+// verify.go — проверка подписи SignedData: сертификат подписанта, contentType, messageDigest,
+// CMSAlgorithmProtection (RFC 6211) и подпись над DER(authenticatedAttributes).
+package registry
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+)
+
+// AlgorithmName возвращает читаемое имя алгоритма хеширования/подписи по OID (например «sha256», «ecdsa-with-SHA256»).
+func AlgorithmName(oid asn1.ObjectIdentifier) string {
+	switch {
+	case oid.Equal(OIDSHA256):
+		return "sha256"
+	case oid.Equal(OIDSHA384):
+		return "sha384"
+	case oid.Equal(OIDSHA512):
+		return "sha512"
+	case oid.Equal(OIDECDSAWithSHA256):
+		return "ecdsa-with-SHA256"
+	case oid.Equal(OIDECDSAWithSHA384):
+		return "ecdsa-with-SHA384"
+	case oid.Equal(OIDECDSAWithSHA512):
+		return "ecdsa-with-SHA512"
+	case oid.Equal(OIDRSAEncryption):
+		return "rsaEncryption"
+	case oid.Equal(OIDSHA256WithRSA):
+		return "sha256WithRSAEncryption"
+	case oid.Equal(OIDSHA384WithRSA):
+		return "sha384WithRSAEncryption"
+	case oid.Equal(OIDSHA512WithRSA):
+		return "sha512WithRSAEncryption"
+	case oid.Equal(OIDEd25519):
+		return "ed25519"
+	case oid.Equal(OIDECPublicKey):
+		return "ecPublicKey"
+	case oid.Equal(OIDHMACWithSHA256):
+		return "hmacWithSHA256"
+	default:
+		return oid.String()
+	}
+}
+
+// digestHash сопоставляет OID алгоритма хеширования SignerInfo.digestAlgorithm с crypto.Hash.
+func digestHash(oid asn1.ObjectIdentifier) (crypto.Hash, bool) {
+	switch {
+	case oid.Equal(OIDSHA256):
+		return crypto.SHA256, true
+	case oid.Equal(OIDSHA384):
+		return crypto.SHA384, true
+	case oid.Equal(OIDSHA512):
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+// signatureAlgorithm сопоставляет пару digestAlgorithm/digestEncryptionAlgorithm с x509.SignatureAlgorithm.
+// Для rsaEncryption хеш берётся из digestAlgorithm (как в RFC 5652 для PKCS#1 v1.5).
+func signatureAlgorithm(digest, sig asn1.ObjectIdentifier) (x509.SignatureAlgorithm, bool) {
+	switch {
+	case sig.Equal(OIDECDSAWithSHA256):
+		return x509.ECDSAWithSHA256, true
+	case sig.Equal(OIDECDSAWithSHA384):
+		return x509.ECDSAWithSHA384, true
+	case sig.Equal(OIDECDSAWithSHA512):
+		return x509.ECDSAWithSHA512, true
+	case sig.Equal(OIDSHA256WithRSA):
+		return x509.SHA256WithRSA, true
+	case sig.Equal(OIDSHA384WithRSA):
+		return x509.SHA384WithRSA, true
+	case sig.Equal(OIDSHA512WithRSA):
+		return x509.SHA512WithRSA, true
+	case sig.Equal(OIDEd25519):
+		return x509.PureEd25519, true
+	case sig.Equal(OIDRSAEncryption):
+		switch {
+		case digest.Equal(OIDSHA256):
+			return x509.SHA256WithRSA, true
+		case digest.Equal(OIDSHA384):
+			return x509.SHA384WithRSA, true
+		case digest.Equal(OIDSHA512):
+			return x509.SHA512WithRSA, true
+		}
+	}
+	return x509.UnknownSignatureAlgorithm, false
+}
+
+// Имена проверок в VerifyCheck.Name.
+const (
+	CheckSignerCert          = "signerCert"
+	CheckContentType         = "contentType"
+	CheckMessageDigest       = "messageDigest"
+	CheckAlgorithmProtection = "algorithmProtection"
+	CheckSignature           = "signature"
+)
+
+// VerifyOptions — параметры проверки подписи.
+// RequireAlgorithmProtection — считать отсутствие атрибута CMSAlgorithmProtection ошибкой
+// (по умолчанию атрибут проверяется только при наличии).
+type VerifyOptions struct {
+	RequireAlgorithmProtection bool
+}
+
+// VerifyCheck — результат одной проверки подписанта.
+type VerifyCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// SignerVerification — результат проверки одного SignerInfo.
+type SignerVerification struct {
+	SignerIndex   int           `json:"signerIndex"`
+	SignerSubject string        `json:"signerSubject,omitempty"`
+	OK            bool          `json:"ok"`
+	Checks        []VerifyCheck `json:"checks"`
+}
+
+// EContent возвращает байты eContent (SafeContents), над которыми вычисляется messageDigest.
+func (c *Container) EContent() []byte {
+	if c.SignedData == nil {
+		return nil
+	}
+	return unwrapOctetStringIfPresent(c.SignedData.EncapContentInfo.EContent.Bytes)
+}
+
+// Verify проверяет всех подписантов контейнера. Каждая проверка выполняется независимо,
+// чтобы отчёт показывал все нарушения, а не только первое.
+func (c *Container) Verify(opts VerifyOptions) []SignerVerification {
+	out := make([]SignerVerification, 0, len(c.Signers))
+	for i := range c.Signers {
+		out = append(out, c.verifySigner(i, opts))
+	}
+	return out
+}
+
+// VerifyOK возвращает true, если все подписанты прошли проверку (и есть хотя бы один подписант).
+func VerifyOK(results []SignerVerification) bool {
+	if len(results) == 0 {
+		return false
+	}
+	for _, r := range results {
+		if !r.OK {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Container) verifySigner(index int, opts VerifyOptions) SignerVerification {
+	si := &c.Signers[index]
+	res := SignerVerification{SignerIndex: index + 1}
+	add := func(name string, ok bool, format string, args ...interface{}) {
+		res.Checks = append(res.Checks, VerifyCheck{Name: name, OK: ok, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	cert := c.SignerCert(si)
+	if cert != nil {
+		res.SignerSubject = cert.Subject.String()
+		add(CheckSignerCert, true, "%s", cert.Subject.String())
+	} else {
+		add(CheckSignerCert, false, "signer certificate not found in SignedData.certificates")
+	}
+
+	attrs, err := SignerAttributes(si)
+	if err != nil {
+		add(CheckSignature, false, "authenticatedAttributes malformed: %v", err)
+		return finishVerification(res)
+	}
+	if len(attrs) == 0 {
+		add(CheckSignature, false, "authenticatedAttributes missing")
+		return finishVerification(res)
+	}
+	var contentType, messageDigest []byte
+	var protection []byte
+	for _, a := range attrs {
+		if len(a.AttrValues) != 1 {
+			continue
+		}
+		switch {
+		case a.AttrType.Equal(OIDPKCS9ContentType):
+			contentType = a.AttrValues[0].FullBytes
+		case a.AttrType.Equal(OIDPKCS9MessageDigest):
+			messageDigest = a.AttrValues[0].FullBytes
+		case a.AttrType.Equal(OIDCMSAlgorithmProtection):
+			protection = a.AttrValues[0].FullBytes
+		}
+	}
+
+	// contentType должен совпадать с eContentType (RFC 5652, 11.1).
+	var ct asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(contentType, &ct); err != nil {
+		add(CheckContentType, false, "contentType attribute missing")
+	} else if !ct.Equal(c.SignedData.EncapContentInfo.EContentType) {
+		add(CheckContentType, false, "contentType %s != eContentType %s", ct, c.SignedData.EncapContentInfo.EContentType)
+	} else {
+		add(CheckContentType, true, "%s", ct)
+	}
+
+	// messageDigest — хеш eContent алгоритмом digestAlgorithm.
+	h, hashOK := digestHash(si.DigestAlgorithm.Algorithm)
+	var md []byte
+	if _, err := asn1.Unmarshal(messageDigest, &md); err != nil {
+		add(CheckMessageDigest, false, "messageDigest attribute missing")
+	} else if !hashOK {
+		add(CheckMessageDigest, false, "unsupported digest algorithm %s", si.DigestAlgorithm.Algorithm)
+	} else {
+		hw := h.New()
+		hw.Write(c.EContent())
+		if sum := hw.Sum(nil); bytes.Equal(sum, md) {
+			add(CheckMessageDigest, true, "%s", AlgorithmName(si.DigestAlgorithm.Algorithm))
+		} else {
+			add(CheckMessageDigest, false, "messageDigest %x != %s(eContent) %x", md, AlgorithmName(si.DigestAlgorithm.Algorithm), sum)
+		}
+	}
+
+	// CMSAlgorithmProtection: алгоритмы в атрибуте должны совпадать с алгоритмами SignerInfo (RFC 6211, 3).
+	if protection != nil {
+		var ap CMSAlgorithmProtection
+		if rest, err := asn1.Unmarshal(protection, &ap); err != nil || len(rest) > 0 {
+			add(CheckAlgorithmProtection, false, "malformed CMSAlgorithmProtection")
+		} else if len(ap.MACAlgorithm.Algorithm) > 0 || len(ap.SignatureAlgorithm.Algorithm) == 0 {
+			add(CheckAlgorithmProtection, false, "signatureAlgorithm must be present and macAlgorithm absent for SignedData")
+		} else if !algorithmIdentifierEqual(ap.DigestAlgorithm, si.DigestAlgorithm) {
+			add(CheckAlgorithmProtection, false, "digestAlgorithm %s != SignerInfo %s",
+				AlgorithmName(ap.DigestAlgorithm.Algorithm), AlgorithmName(si.DigestAlgorithm.Algorithm))
+		} else if !algorithmIdentifierEqual(ap.SignatureAlgorithm, si.DigestEncryptionAlgorithm) {
+			add(CheckAlgorithmProtection, false, "signatureAlgorithm %s != SignerInfo %s",
+				AlgorithmName(ap.SignatureAlgorithm.Algorithm), AlgorithmName(si.DigestEncryptionAlgorithm.Algorithm))
+		} else {
+			add(CheckAlgorithmProtection, true, "digest=%s, signature=%s",
+				AlgorithmName(ap.DigestAlgorithm.Algorithm), AlgorithmName(ap.SignatureAlgorithm.Algorithm))
+		}
+	} else if opts.RequireAlgorithmProtection {
+		add(CheckAlgorithmProtection, false, "CMSAlgorithmProtection attribute is required but absent")
+	}
+
+	// Подпись над DER(SET OF Attribute) с тегом SET (0x31), а не [0] (RFC 5652, 5.4).
+	if cert != nil {
+		alg, ok := signatureAlgorithm(si.DigestAlgorithm.Algorithm, si.DigestEncryptionAlgorithm.Algorithm)
+		if !ok {
+			add(CheckSignature, false, "unsupported signature algorithm %s", si.DigestEncryptionAlgorithm.Algorithm)
+		} else if err := cert.CheckSignature(alg, signedAttributesDER(si), si.EncryptedDigest); err != nil {
+			add(CheckSignature, false, "%v", err)
+		} else {
+			add(CheckSignature, true, "%s", alg)
+		}
+	}
+	return finishVerification(res)
+}
+
+func finishVerification(res SignerVerification) SignerVerification {
+	res.OK = true
+	for _, ch := range res.Checks {
+		if !ch.OK {
+			res.OK = false
+		}
+	}
+	return res
+}
+
+// algorithmIdentifierEqual сравнивает AlgorithmIdentifier по OID; отсутствующие параметры и NULL считаются равными.
+func algorithmIdentifierEqual(a, b AlgorithmIdentifier) bool {
+	if !a.Algorithm.Equal(b.Algorithm) {
+		return false
+	}
+	pa, pb := a.Parameters.FullBytes, b.Parameters.FullBytes
+	isNull := func(p []byte) bool { return len(p) == 0 || bytes.Equal(p, []byte{0x05, 0x00}) }
+	if isNull(pa) && isNull(pb) {
+		return true
+	}
+	return bytes.Equal(pa, pb)
+}
+
+// signedAttributesDER возвращает DER authenticatedAttributes в виде SET OF Attribute (тег 0x31) для проверки подписи.
+// [0] EXPLICIT (эталон): Bytes уже содержит полный SET; [0] IMPLICIT: Bytes — содержимое SET, восстанавливаем TLV.
+func signedAttributesDER(si *SignerInfo) []byte {
+	b := si.AuthenticatedAttributes.Bytes
+	if len(b) > 0 && b[0] == 0x31 {
+		var set asn1.RawValue
+		if rest, err := asn1.Unmarshal(b, &set); err == nil && len(rest) == 0 {
+			return b
+		}
+	}
+	return derPrependTLV(0x31, b)
+}
@@ -0,0 +1,140 @@
+package registry
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// newTestSigner создаёт ключ ECDSA P-256 и самоподписанный сертификат подписанта с SubjectKeyId.
+func newTestSigner(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	pubBytes, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	ski := sha1.Sum(pubBytes)
+	template := &x509.Certificate{
+		Subject:      pkix.Name{CommonName: "Test Registry Signer"},
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		SubjectKeyId: ski[:],
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+	return cert, key
+}
+
+// TestVerifyCustomSignedAttributes проверяет сборку с signingTime, CMSAlgorithmProtection и произвольными атрибутами,
+// их расшифровку DecodeAttributeValues и успешную проверку подписи.
+func TestVerifyCustomSignedAttributes(t *testing.T) {
+	cert, key := newTestSigner(t)
+	signingTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	attrs := SignerAttrs{
+		VIN:                 "TESTVIN123",
+		VERTimestamp:        signingTime,
+		VERVersion:          7,
+		UID:                 "CN=Test",
+		SigningTime:         signingTime,
+		AlgorithmProtection: true,
+		Custom: []CustomAttribute{
+			{OID: "1.3.6.1.4.1.99999.2.1", Type: AttrTypeUTF8String, Values: []string{"DEALER-42"}},
+			{OID: "1.3.6.1.4.1.99999.2.2", Type: AttrTypeInteger, Values: []string{"100500"}},
+		},
+	}
+	der, err := BuildRegistry(cert, key, []SafeBagInput{{CertDER: cert.Raw, RoleName: "delegate"}}, attrs)
+	if err != nil {
+		t.Fatalf("BuildRegistry: %v", err)
+	}
+	c, err := Parse(der)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	decoded := map[string]string{}
+	siAttrs, err := SignerAttributes(&c.Signers[0])
+	if err != nil {
+		t.Fatalf("SignerAttributes: %v", err)
+	}
+	for _, a := range siAttrs {
+		for _, v := range DecodeAttributeValues(a) {
+			decoded[v.Name] = v.Value
+		}
+	}
+	want := map[string]string{
+		"signingTime":            "2026-03-01 12:00:00",
+		"cmsAlgorithmProtection": "digest=sha256, signature=ecdsa-with-SHA256",
+		"1.3.6.1.4.1.99999.2.1":  "DEALER-42",
+		"1.3.6.1.4.1.99999.2.2":  "100500",
+	}
+	for name, v := range want {
+		if decoded[name] != v {
+			t.Errorf("%s = %q, ожидается %q", name, decoded[name], v)
+		}
+	}
+
+	res := c.Verify(VerifyOptions{RequireAlgorithmProtection: true})
+	if !VerifyOK(res) {
+		t.Fatalf("Verify: %+v", res)
+	}
+}
+
+// TestVerifyAlgorithmProtectionMismatch проверяет, что подмена digestAlgorithm в SignerInfo обнаруживается
+// атрибутом CMSAlgorithmProtection, а отсутствие атрибута — только при RequireAlgorithmProtection.
+func TestVerifyAlgorithmProtectionMismatch(t *testing.T) {
+	cert, key := newTestSigner(t)
+	der, err := BuildRegistry(cert, key, nil, SignerAttrs{VIN: "V", AlgorithmProtection: true})
+	if err != nil {
+		t.Fatalf("BuildRegistry: %v", err)
+	}
+	c, err := Parse(der)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	c.Signers[0].DigestAlgorithm = AlgorithmIdentifier{Algorithm: OIDSHA384}
+	for _, r := range c.Verify(VerifyOptions{}) {
+		for _, ch := range r.Checks {
+			if ch.Name == CheckAlgorithmProtection && ch.OK {
+				t.Errorf("algorithmProtection: ожидается ошибка при подмене digestAlgorithm")
+			}
+		}
+	}
+
+	der, err = BuildRegistry(cert, key, nil, SignerAttrs{VIN: "V"})
+	if err != nil {
+		t.Fatalf("BuildRegistry: %v", err)
+	}
+	c, err = Parse(der)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !VerifyOK(c.Verify(VerifyOptions{})) {
+		t.Error("без атрибута и без RequireAlgorithmProtection проверка должна проходить")
+	}
+	if VerifyOK(c.Verify(VerifyOptions{RequireAlgorithmProtection: true})) {
+		t.Error("RequireAlgorithmProtection: ожидается ошибка при отсутствии атрибута")
+	}
+}
+
+// TestCustomAttributeReserved проверяет запрет переопределения атрибутов, формируемых builder (messageDigest и т.д.).
+func TestCustomAttributeReserved(t *testing.T) {
+	ca := CustomAttribute{OID: OIDPKCS9MessageDigest.String(), Type: AttrTypeOctetString, Values: []string{"00"}}
+	if _, err := ca.Attribute(); err == nil {
+		t.Error("ожидается ошибка для зарезервированного OID messageDigest")
+	}
+}
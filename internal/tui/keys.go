@@ -0,0 +1,81 @@
+package tui
+
+import "bufio"
+
+// Key — специальная клавиша; KeyRune — обычный символ (Event.Rune).
+type Key int
+
+const (
+	KeyRune Key = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyPgUp
+	KeyPgDn
+	KeyEnter
+	KeyTab
+	KeyInterrupt // Ctrl-C: в посимвольном режиме терминал не посылает SIGINT
+	KeyUnknown   // нераспознанная ESC-последовательность
+)
+
+// Event — нажатие клавиши.
+type Event struct {
+	Key  Key
+	Rune rune
+}
+
+// csiKeys — последовательности ESC [ x и ESC O x (xterm, VT100) для стрелок и Home/End.
+var csiKeys = map[rune]Key{'A': KeyUp, 'B': KeyDown, 'C': KeyRight, 'D': KeyLeft, 'H': KeyHome, 'F': KeyEnd}
+
+// tildeKeys — последовательности ESC [ n ~ (VT220).
+var tildeKeys = map[string]Key{"1": KeyHome, "4": KeyEnd, "5": KeyPgUp, "6": KeyPgDn, "7": KeyHome, "8": KeyEnd}
+
+// ReadEvent читает одну клавишу: символ UTF-8, управляющий символ или ESC-последовательность.
+// Одиночный ESC (без последующих байт в буфере) — KeyUnknown.
+func ReadEvent(r *bufio.Reader) (Event, error) {
+	c, _, err := r.ReadRune()
+	if err != nil {
+		return Event{}, err
+	}
+	switch c {
+	case '\r', '\n':
+		return Event{Key: KeyEnter}, nil
+	case '\t':
+		return Event{Key: KeyTab}, nil
+	case 3:
+		return Event{Key: KeyInterrupt}, nil
+	case 0x1b:
+		return readEscape(r)
+	}
+	return Event{Key: KeyRune, Rune: c}, nil
+}
+
+func readEscape(r *bufio.Reader) (Event, error) {
+	if r.Buffered() == 0 {
+		return Event{Key: KeyUnknown}, nil
+	}
+	intro, _ := r.ReadByte()
+	if intro != '[' && intro != 'O' {
+		return Event{Key: KeyUnknown}, nil
+	}
+	var params []byte
+	for r.Buffered() > 0 {
+		b, _ := r.ReadByte()
+		if b >= '0' && b <= '9' || b == ';' {
+			params = append(params, b)
+			continue
+		}
+		if b == '~' {
+			if k, ok := tildeKeys[string(params)]; ok {
+				return Event{Key: k}, nil
+			}
+		} else if k, ok := csiKeys[rune(b)]; ok {
+			return Event{Key: k}, nil
+		}
+		break
+	}
+	return Event{Key: KeyUnknown}, nil
+}
@@ -0,0 +1,403 @@
+// Package tui — интерактивный просмотр контейнера в терминале (registry-analyzer -interactive): дерево
+// registry.BrowseNode сверху, подробности выбранного узла (поля, дерево ASN.1 или hex) снизу.
+//
+// Только стандартная библиотека и ANSI-последовательности VT100: работает в обычном терминале и по SSH.
+// Перевод терминала в посимвольный режим — забота вызывающего (stty raw -echo); Run читает клавиши из in,
+// перерисовывает кадр в out и возвращается по q, Ctrl-C или концу ввода.
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sgw-registry/registry-analyzer/internal/asn1schema"
+	"github.com/sgw-registry/registry-analyzer/internal/asn1tree"
+	"github.com/sgw-registry/registry-analyzer/internal/i18n"
+	"github.com/sgw-registry/registry-analyzer/internal/registry"
+)
+
+// View — содержимое нижней панели.
+type View int
+
+const (
+	ViewDetails View = iota // поля узла и результат проверки подписи
+	ViewASN1                // дерево DER узла с подписями полей registry.asn1
+	ViewHex                 // hex-дамп DER узла со смещениями во входном файле
+	viewHelp
+)
+
+var viewNames = [...]string{"details", "ASN.1", "hex"}
+
+// Browser — состояние просмотра: раскрытые узлы, курсор, прокрутка дерева и панели, строка сообщения.
+// Export выгружает сертификат узла в PEM и возвращает путь файла (nil — клавиша e недоступна).
+type Browser struct {
+	Name     string // имя файла в заголовке
+	UseColor bool
+	Export   func(n *registry.BrowseNode) (string, error)
+
+	root     *registry.BrowseNode
+	expanded map[*registry.BrowseNode]bool
+	cursor   int
+	top      int
+	view     View
+	scroll   int
+	message  string
+	schema   *asn1tree.Schema
+}
+
+// row — видимая строка дерева.
+type row struct {
+	node   *registry.BrowseNode
+	parent int // индекс строки родителя, -1 у корня
+	depth  int
+}
+
+// New создаёт просмотр с раскрытыми корнем и его прямыми потомками (SignedData или AuthenticatedSafe).
+func New(root *registry.BrowseNode, name string) *Browser {
+	b := &Browser{Name: name, root: root, expanded: map[*registry.BrowseNode]bool{root: true}}
+	for _, ch := range root.Children {
+		b.expanded[ch] = true
+	}
+	return b
+}
+
+// Selected возвращает узел под курсором.
+func (b *Browser) Selected() *registry.BrowseNode {
+	return b.rows()[b.cursor].node
+}
+
+func (b *Browser) rows() []row {
+	var out []row
+	var walk func(n *registry.BrowseNode, parent, depth int)
+	walk = func(n *registry.BrowseNode, parent, depth int) {
+		idx := len(out)
+		out = append(out, row{n, parent, depth})
+		if b.expanded[n] {
+			for _, ch := range n.Children {
+				walk(ch, idx, depth+1)
+			}
+		}
+	}
+	walk(b.root, -1, 0)
+	return out
+}
+
+// Handle применяет клавишу; true — выход из просмотра.
+func (b *Browser) Handle(ev Event) bool {
+	rows := b.rows()
+	cur := rows[b.cursor]
+	b.message = ""
+	move := func(i int) {
+		b.cursor = max(0, min(i, len(rows)-1))
+		b.scroll = 0
+		if b.view == viewHelp {
+			b.view = ViewDetails
+		}
+	}
+	switch {
+	case ev.Key == KeyUp || ev.Rune == 'k':
+		move(b.cursor - 1)
+	case ev.Key == KeyDown || ev.Rune == 'j':
+		move(b.cursor + 1)
+	case ev.Key == KeyHome || ev.Rune == 'g':
+		move(0)
+	case ev.Key == KeyEnd || ev.Rune == 'G':
+		move(len(rows) - 1)
+	case ev.Key == KeyRight || ev.Rune == 'l':
+		if len(cur.node.Children) > 0 {
+			if b.expanded[cur.node] {
+				move(b.cursor + 1)
+			} else {
+				b.expanded[cur.node] = true
+			}
+		}
+	case ev.Key == KeyLeft || ev.Rune == 'h':
+		if b.expanded[cur.node] && len(cur.node.Children) > 0 {
+			b.expanded[cur.node] = false
+		} else if cur.parent >= 0 {
+			move(cur.parent)
+		}
+	case ev.Key == KeyEnter || ev.Rune == ' ':
+		if len(cur.node.Children) > 0 {
+			b.expanded[cur.node] = !b.expanded[cur.node]
+		}
+	case ev.Key == KeyTab:
+		b.view = (b.view + 1) % viewHelp
+		b.scroll = 0
+	case ev.Rune >= '1' && ev.Rune <= '3':
+		b.view = View(ev.Rune - '1')
+		b.scroll = 0
+	case ev.Rune == '?':
+		b.view = viewHelp
+		b.scroll = 0
+	case ev.Key == KeyPgDn || ev.Rune == 'J':
+		b.scroll += 10
+	case ev.Key == KeyPgUp || ev.Rune == 'K':
+		b.scroll = max(0, b.scroll-10)
+	case ev.Rune == 'e':
+		b.export(cur.node)
+	case ev.Rune == 'q' || ev.Key == KeyInterrupt:
+		return true
+	}
+	return false
+}
+
+func (b *Browser) export(n *registry.BrowseNode) {
+	switch {
+	case n.Cert == nil:
+		b.message = i18n.T("у узла нет сертификата X.509")
+	case b.Export == nil:
+		b.message = i18n.T("выгрузка недоступна")
+	default:
+		path, err := b.Export(n)
+		if err != nil {
+			b.message = i18n.Sprintf("выгрузка: %v", err)
+			return
+		}
+		b.message = i18n.Sprintf("Сертификат записан в %s", path)
+	}
+}
+
+// Render возвращает кадр width×height: заголовок, дерево, разделитель с именем панели, панель и строку состояния.
+// Строки разделены \r\n (в посимвольном режиме терминала \n не возвращает каретку).
+func (b *Browser) Render(width, height int) string {
+	width, height = max(width, 20), max(height, 8)
+	rows := b.rows()
+	treeH := (height - 3) / 2
+	paneH := height - 3 - treeH
+	if b.cursor < b.top {
+		b.top = b.cursor
+	}
+	if b.cursor >= b.top+treeH {
+		b.top = b.cursor - treeH + 1
+	}
+
+	bold, reverse, dim, green, red, reset := "", "", "", "", "", ""
+	if b.UseColor {
+		bold, reverse, dim, green, red, reset = registry.Bold, "\033[7m", registry.Dim, registry.Green, registry.Red, registry.Reset
+	}
+	var lines []string
+	header := fmt.Sprintf(" %s │ 1 details  2 ASN.1  3 hex │ e PEM │ ? %s │ q %s", b.Name, i18n.T("справка"), i18n.T("выход"))
+	lines = append(lines, reverse+bold+pad(header, width)+reset)
+
+	for i := b.top; i < b.top+treeH; i++ {
+		if i >= len(rows) {
+			lines = append(lines, "")
+			continue
+		}
+		r := rows[i]
+		marker := "  "
+		if len(r.node.Children) > 0 {
+			marker = "+ "
+			if b.expanded[r.node] {
+				marker = "- "
+			}
+		}
+		status, statusColor := "  ", ""
+		switch r.node.Status {
+		case registry.BrowseStatusOK:
+			status, statusColor = "✓ ", green
+		case registry.BrowseStatusFailed:
+			status, statusColor = "✗ ", red
+		}
+		cursor := " "
+		if i == b.cursor {
+			cursor = ">"
+		}
+		text := cursor + strings.Repeat("  ", r.depth) + marker
+		if i == b.cursor && b.UseColor {
+			lines = append(lines, reverse+pad(text+status+r.node.Title, width)+reset)
+			continue
+		}
+		lines = append(lines, fit(text, width)+statusColor+fit(status, width-runeLen(text))+reset+fit(r.node.Title, width-runeLen(text)-2))
+	}
+
+	sel := rows[b.cursor].node
+	name := i18n.T("справка")
+	if b.view != viewHelp {
+		name = viewNames[b.view]
+	}
+	lines = append(lines, dim+fit("── "+name+": "+sel.Title+" "+strings.Repeat("─", width), width)+reset)
+
+	pane := b.pane(sel, width)
+	b.scroll = max(0, min(b.scroll, len(pane)-paneH))
+	for i := b.scroll; i < b.scroll+paneH; i++ {
+		if i < len(pane) {
+			lines = append(lines, fit(pane[i], width))
+		} else {
+			lines = append(lines, "")
+		}
+	}
+
+	status := b.message
+	if status == "" {
+		status = i18n.Sprintf("%d/%d  ↑↓ навигация  ←→ свернуть/раскрыть  Tab вид  PgUp/PgDn прокрутка", b.cursor+1, len(rows))
+	}
+	lines = append(lines, reverse+pad(" "+status, width)+reset)
+	return strings.Join(lines, "\033[K\r\n") + "\033[K"
+}
+
+// pane — строки нижней панели для узла n в текущем виде.
+func (b *Browser) pane(n *registry.BrowseNode, width int) []string {
+	switch b.view {
+	case ViewASN1:
+		return b.asn1Lines(n)
+	case ViewHex:
+		return hexLines(n)
+	case viewHelp:
+		return helpLines()
+	}
+	var out []string
+	nameW := 0
+	for _, f := range n.Fields {
+		nameW = max(nameW, min(runeLen(f.Name), 24))
+	}
+	for _, f := range n.Fields {
+		prefix := " " + f.Name + strings.Repeat(" ", max(0, nameW-runeLen(f.Name))) + "  "
+		out = append(out, wrap(prefix, f.Value, width)...)
+	}
+	switch n.Status {
+	case registry.BrowseStatusOK:
+		out = append(out, " "+i18n.T("Проверка подписи")+": OK")
+	case registry.BrowseStatusFailed:
+		out = append(out, " "+i18n.T("Проверка подписи")+": FAILED")
+	}
+	switch {
+	case len(n.DER) == 0:
+	case n.Offset >= 0:
+		out = append(out, " "+i18n.Sprintf("DER: смещение %d, %d байт", n.Offset, len(n.DER)))
+	default:
+		out = append(out, " "+i18n.Sprintf("DER: %d байт (во входном файле не найден)", len(n.DER)))
+	}
+	if n.Cert != nil {
+		out = append(out, " "+i18n.T("e — выгрузить сертификат в PEM"))
+	}
+	return out
+}
+
+func (b *Browser) asn1Lines(n *registry.BrowseNode) []string {
+	if len(n.DER) == 0 {
+		return []string{" " + i18n.T("у узла нет DER")}
+	}
+	nodes, err := asn1tree.Parse(n.DER, max(n.Offset, 0), registry.DefaultLimits.MaxDepth)
+	if n.ASN1Type != "" {
+		if b.schema == nil {
+			b.schema, _ = asn1schema.RegistrySchema()
+		}
+		if b.schema != nil {
+			b.schema.Annotate(nodes, n.ASN1Type)
+		}
+	}
+	var sb strings.Builder
+	asn1tree.TextOutput(&sb, nodes, err, false)
+	return strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+}
+
+// hexLines — hex-дамп по 16 байт: смещение во входном файле (или от начала узла), байты и ASCII.
+func hexLines(n *registry.BrowseNode) []string {
+	if len(n.DER) == 0 {
+		return []string{" " + i18n.T("у узла нет DER")}
+	}
+	base := max(n.Offset, 0)
+	var out []string
+	for off := 0; off < len(n.DER); off += 16 {
+		chunk := n.DER[off:min(off+16, len(n.DER))]
+		var hexPart, ascii strings.Builder
+		for i := 0; i < 16; i++ {
+			if i == 8 {
+				hexPart.WriteByte(' ')
+			}
+			if i < len(chunk) {
+				fmt.Fprintf(&hexPart, "%02x ", chunk[i])
+				if chunk[i] >= 0x20 && chunk[i] < 0x7f {
+					ascii.WriteByte(chunk[i])
+				} else {
+					ascii.WriteByte('.')
+				}
+			} else {
+				hexPart.WriteString("   ")
+			}
+		}
+		out = append(out, fmt.Sprintf("%08x  %s |%s|", base+off, hexPart.String(), ascii.String()))
+	}
+	return out
+}
+
+func helpLines() []string {
+	return []string{
+		" ↑ ↓, j k          " + i18n.T("перемещение по дереву"),
+		" → l, ← h          " + i18n.T("раскрыть узел, свернуть узел или перейти к родителю"),
+		" Enter, Space      " + i18n.T("раскрыть или свернуть узел"),
+		" g G, Home End     " + i18n.T("первый и последний узел"),
+		" 1 2 3, Tab        " + i18n.T("панель: поля, дерево ASN.1, hex"),
+		" PgUp PgDn, K J    " + i18n.T("прокрутка панели"),
+		" e                 " + i18n.T("выгрузить сертификат узла в PEM"),
+		" q, Ctrl-C         " + i18n.T("выход"),
+		"",
+		" ✓ ✗               " + i18n.T("подпись (MAC хранилища, период роли) действительна или нет"),
+	}
+}
+
+// Run показывает просмотр до выхода: альтернативный экран, скрытый курсор, перерисовка после каждой клавиши.
+// size возвращает текущие ширину и высоту терминала. Конец ввода — обычный выход.
+func Run(in io.Reader, out io.Writer, b *Browser, size func() (int, int)) error {
+	r := bufio.NewReader(in)
+	fmt.Fprint(out, "\033[?1049h\033[?25l")
+	defer fmt.Fprint(out, "\033[?25h\033[?1049l")
+	for {
+		w, h := size()
+		if _, err := fmt.Fprint(out, "\033[H"+b.Render(w, h)); err != nil {
+			return err
+		}
+		ev, err := ReadEvent(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if b.Handle(ev) {
+			return nil
+		}
+	}
+}
+
+func runeLen(s string) int { return utf8.RuneCountInString(s) }
+
+// fit обрезает s до width символов.
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if runeLen(s) <= width {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:width-1]) + "…"
+}
+
+// pad обрезает или дополняет s пробелами до width символов.
+func pad(s string, width int) string {
+	s = fit(s, width)
+	return s + strings.Repeat(" ", width-runeLen(s))
+}
+
+// wrap переносит value по ширине width; продолжения выравниваются под начало значения.
+func wrap(prefix, value string, width int) []string {
+	avail := max(width-runeLen(prefix), 10)
+	r := []rune(value)
+	var out []string
+	for first := true; first || len(r) > 0; first = false {
+		n := min(avail, len(r))
+		p := prefix
+		if !first {
+			p = strings.Repeat(" ", runeLen(prefix))
+		}
+		out = append(out, p+string(r[:n]))
+		r = r[n:]
+	}
+	return out
+}
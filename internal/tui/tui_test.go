@@ -0,0 +1,128 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/x509"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sgw-registry/registry-analyzer/internal/registry"
+)
+
+func TestReadEvent(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("\x1b[A\x1b[B\x1bOC\x1b[D\x1b[5~\x1b[6~\x1b[H\x1b[4~\r\tя\x03\x1b[99~"))
+	want := []Event{
+		{Key: KeyUp}, {Key: KeyDown}, {Key: KeyRight}, {Key: KeyLeft}, {Key: KeyPgUp}, {Key: KeyPgDn},
+		{Key: KeyHome}, {Key: KeyEnd}, {Key: KeyEnter}, {Key: KeyTab}, {Key: KeyRune, Rune: 'я'},
+		{Key: KeyInterrupt}, {Key: KeyUnknown},
+	}
+	for i, w := range want {
+		ev, err := ReadEvent(r)
+		if err != nil || ev != w {
+			t.Fatalf("событие %d: %+v, %v; ожидается %+v", i, ev, err, w)
+		}
+	}
+	if _, err := ReadEvent(r); err != io.EOF {
+		t.Errorf("после конца ввода: %v", err)
+	}
+}
+
+// testTree — корень с группой из одного узла сертификата (DER — SEQUENCE { INTEGER 1 } по смещению 16).
+func testTree() (*registry.BrowseNode, *registry.BrowseNode) {
+	leaf := &registry.BrowseNode{
+		Title:      "[1] CN=Leaf",
+		Fields:     []registry.BrowseField{{Name: "Subject", Value: "CN=Leaf"}},
+		DER:        []byte{0x30, 0x03, 0x02, 0x01, 0x01},
+		Offset:     16,
+		Cert:       &x509.Certificate{},
+		ExportName: "leaf",
+		Status:     registry.BrowseStatusOK,
+	}
+	group := &registry.BrowseNode{Title: "Certificates (1)", Offset: -1, Children: []*registry.BrowseNode{leaf}}
+	return &registry.BrowseNode{Title: "PFX", Offset: 0, Children: []*registry.BrowseNode{group}}, leaf
+}
+
+// TestBrowser проверяет навигацию, виды панели и выгрузку сертификата выбранного узла.
+func TestBrowser(t *testing.T) {
+	root, leaf := testTree()
+	b := New(root, "test.p12")
+	var exported *registry.BrowseNode
+	b.Export = func(n *registry.BrowseNode) (string, error) {
+		exported = n
+		return n.ExportName + ".pem", nil
+	}
+	key := func(r rune) bool { return b.Handle(Event{Key: KeyRune, Rune: r}) }
+
+	key('j')
+	key('j')
+	if b.Selected() != leaf {
+		t.Fatalf("выбран %q", b.Selected().Title)
+	}
+	frame := b.Render(80, 20)
+	for _, s := range []string{"test.p12", "> ", "✓ [1] CN=Leaf", "Subject", "CN=Leaf"} {
+		if !strings.Contains(frame, s) {
+			t.Errorf("в кадре нет %q", s)
+		}
+	}
+	key('e')
+	if exported != leaf || !strings.Contains(b.Render(80, 20), "leaf.pem") {
+		t.Errorf("выгрузка: %v", exported)
+	}
+	key('3')
+	if frame := b.Render(80, 20); !strings.Contains(frame, "00000010  30 03 02 01 01") {
+		t.Errorf("hex-дамп без смещения во входе:\n%s", frame)
+	}
+	key('2')
+	if frame := b.Render(80, 20); !strings.Contains(frame, "SEQUENCE") || !strings.Contains(frame, "   18   2      1 univ prim    INTEGER") {
+		t.Errorf("дерево ASN.1:\n%s", frame)
+	}
+
+	b.Handle(Event{Key: KeyLeft}) // лист — к родителю
+	b.Handle(Event{Key: KeyLeft}) // свернуть группу
+	if b.Selected().Title != "Certificates (1)" || len(b.rows()) != 2 {
+		t.Errorf("выбран %q, строк %d", b.Selected().Title, len(b.rows()))
+	}
+	key('e')
+	if b.message == "" {
+		t.Error("нет сообщения о выгрузке узла без сертификата")
+	}
+	if !b.Handle(Event{Key: KeyInterrupt}) {
+		t.Error("Ctrl-C не завершает просмотр")
+	}
+}
+
+// TestRun проверяет цикл: альтернативный экран включается и восстанавливается, q завершает просмотр.
+func TestRun(t *testing.T) {
+	root, _ := testTree()
+	var out bytes.Buffer
+	err := Run(strings.NewReader("j\x1b[Bq"), &out, New(root, "test.p12"), func() (int, int) { return 60, 12 })
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	s := out.String()
+	if !strings.HasPrefix(s, "\033[?1049h") || !strings.HasSuffix(s, "\033[?1049l") || strings.Count(s, "\033[H") != 3 {
+		t.Errorf("вывод Run: %q", s)
+	}
+	for _, frame := range strings.Split(s, "\033[H") {
+		for _, line := range strings.Split(frame, "\r\n") {
+			if n := runeLen(stripANSI(line)); n > 60 {
+				t.Errorf("строка длиннее экрана (%d): %q", n, line)
+			}
+		}
+	}
+}
+
+func stripANSI(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0x1b {
+			for i++; i < len(s) && !(s[i] >= 'A' && s[i] <= 'Z' || s[i] >= 'a' && s[i] <= 'z'); i++ {
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
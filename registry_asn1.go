@@ -0,0 +1,10 @@
+// Package registryanalyzer встраивает нормативную спецификацию формата ATOM-PKCS12-REGISTRY (registry.asn1),
+// чтобы схема для проверки контейнеров загружалась из того же файла, что и документация.
+package registryanalyzer
+
+import _ "embed"
+
+// RegistryASN1 — текст модуля ATOM-PKCS12-REGISTRY (registry.asn1).
+//
+//go:embed registry.asn1
+var RegistryASN1 []byte
@@ -0,0 +1,212 @@
+// Package report — типизированный JSON-отчёт registry-analyzer (-format json и -format json-certificates)
+// для сервисов, которые разбирают отчёты программно.
+//
+// Формат версионируется полем schemaVersion (SchemaVersion) и описан JSON Schema (Schema, файл report.schema.json).
+// В пределах одной версии поля только добавляются; переименование, удаление или смена типа поля увеличивают версию.
+// Время — строки RFC 3339 в UTC, двоичные данные — base64 (raw) или hex в нижнем регистре (отпечатки, идентификаторы ключей),
+// OID — в точечной записи. Структурированные значения атрибутов (время, VER, roleValidityPeriod, CMSAlgorithmProtection)
+// выводятся в отдельных типизированных полях, а не строкой в value.
+package report
+
+import (
+	_ "embed"
+	"time"
+)
+
+// SchemaVersion — версия формата отчёта (поле schemaVersion).
+const SchemaVersion = 1
+
+// Schema — JSON Schema (draft 2020-12) отчёта Report; определение certificatesReport описывает CertificatesReport.
+//
+//go:embed report.schema.json
+var Schema []byte
+
+// Report — полный отчёт о контейнере (-format json).
+type Report struct {
+	SchemaVersion    int                  `json:"schemaVersion"`
+	Kind             string               `json:"kind"` // atom-registry или pkcs12-keystore
+	PFXVersion       int                  `json:"pfxVersion"`
+	ContentType      string               `json:"contentType"` // OID authSafe.contentType
+	Certificates     []Certificate        `json:"certificates"`
+	SafeBags         []SafeBag            `json:"safeBags"`
+	Signers          []Signer             `json:"signers"`
+	AuthSafe         []AuthSafe           `json:"authSafe,omitempty"`         // только pkcs12-keystore
+	MAC              *MAC                 `json:"mac,omitempty"`              // только pkcs12-keystore с macData
+	Verification     []SignerVerification `json:"verification,omitempty"`     // -verify
+	SchemaValidation *SchemaValidation    `json:"schemaValidation,omitempty"` // -validate
+}
+
+// CertificatesReport — отчёт только с сертификатами (-format json-certificates).
+type CertificatesReport struct {
+	SchemaVersion int           `json:"schemaVersion"`
+	Certificates  []Certificate `json:"certificates"`
+}
+
+// Certificate — данные сертификата X.509.
+type Certificate struct {
+	Subject            string       `json:"subject"`
+	Issuer             string       `json:"issuer"`
+	SerialNumber       string       `json:"serialNumber"`    // десятичное
+	SerialNumberHex    string       `json:"serialNumberHex"` // hex без ведущих нулей
+	NotBefore          time.Time    `json:"notBefore"`
+	NotAfter           time.Time    `json:"notAfter"`
+	Version            int          `json:"version"`
+	SignatureAlgorithm string       `json:"signatureAlgorithm"`
+	PublicKeyAlgorithm string       `json:"publicKeyAlgorithm"`
+	IsSigner           bool         `json:"isSigner"` // сертификат подписанта SignedData
+	IsCA               bool         `json:"isCA"`     // basicConstraints cA=TRUE
+	SubjectKeyID       string       `json:"subjectKeyId,omitempty"`
+	AuthorityKeyID     string       `json:"authorityKeyId,omitempty"`
+	KeyUsage           []string     `json:"keyUsage,omitempty"`
+	ExtKeyUsage        []string     `json:"extKeyUsage,omitempty"`
+	DNSNames           []string     `json:"dnsNames,omitempty"`
+	EmailAddresses     []string     `json:"emailAddresses,omitempty"`
+	IPAddresses        []string     `json:"ipAddresses,omitempty"`
+	URIs               []string     `json:"uris,omitempty"`
+	Fingerprints       Fingerprints `json:"fingerprints"`
+	Raw                []byte       `json:"raw"` // DER, в JSON — base64
+}
+
+// Fingerprints — отпечатки сертификата (hex в нижнем регистре).
+type Fingerprints struct {
+	SHA1       string `json:"sha1"`       // SHA-1 от DER сертификата
+	SHA256     string `json:"sha256"`     // SHA-256 от DER сертификата
+	SPKISHA256 string `json:"spkiSha256"` // SHA-256 от SubjectPublicKeyInfo (как pin-sha256 RFC 7469, но в hex)
+}
+
+// SafeBag — мешок SafeContents. Для certBag с X.509 заполнено Certificate, для остальных типов — сводка по типу.
+type SafeBag struct {
+	BagID        string        `json:"bagId"`
+	BagType      string        `json:"bagType"` // certBag, keyBag, ... или OID неизвестного типа
+	CertID       string        `json:"certId,omitempty"`
+	CertType     string        `json:"certType,omitempty"`
+	Certificate  *Certificate  `json:"certificate,omitempty"`
+	CertValueLen int           `json:"certValueLen,omitempty"`
+	Key          *Key          `json:"key,omitempty"`
+	CRL          *CRL          `json:"crl,omitempty"`
+	Secret       *Secret       `json:"secret,omitempty"`
+	Nested       []SafeBag     `json:"nested,omitempty"` // safeContentsBag
+	Error        string        `json:"error,omitempty"`  // ошибка разбора значения мешка
+	RoleName     string        `json:"roleName,omitempty"`
+	RoleValidity *RoleValidity `json:"roleValidity,omitempty"`
+	Attributes   []Attribute   `json:"attributes,omitempty"` // bagAttributes
+}
+
+// Key — сводка по keyBag и pkcs8ShroudedKeyBag; сам ключ в отчёт не выводится.
+type Key struct {
+	Algorithm           string `json:"algorithm,omitempty"` // «RSA 2048», «ECDSA P-256», «Ed25519» или OID
+	Encrypted           bool   `json:"encrypted"`
+	EncryptionAlgorithm string `json:"encryptionAlgorithm,omitempty"`
+	EncryptedLen        int    `json:"encryptedLen,omitempty"`
+	Decrypted           bool   `json:"decrypted,omitempty"` // расшифрован паролем хранилища
+}
+
+// CRL — сводка по crlBag; Issuer и даты — только для X.509 CRL.
+type CRL struct {
+	Type       string     `json:"type"`
+	Issuer     string     `json:"issuer,omitempty"`
+	ThisUpdate *time.Time `json:"thisUpdate,omitempty"`
+	NextUpdate *time.Time `json:"nextUpdate,omitempty"`
+	Number     string     `json:"number,omitempty"` // hex
+	Revoked    int        `json:"revoked"`
+	Len        int        `json:"len"`
+}
+
+// Secret — тип и длина секрета из secretBag; значение не выводится.
+type Secret struct {
+	TypeID string `json:"typeId"`
+	Len    int    `json:"len"`
+}
+
+// Signer — SignerInfo. VIN, UID, RoleName, VER и SigningTime дублируют одноимённые атрибуты для удобства.
+type Signer struct {
+	DigestAlgorithm    string      `json:"digestAlgorithm"`    // OID
+	SignatureAlgorithm string      `json:"signatureAlgorithm"` // OID
+	VIN                string      `json:"vin,omitempty"`
+	UID                string      `json:"uid,omitempty"`
+	RoleName           string      `json:"roleName,omitempty"`
+	VER                *VER        `json:"ver,omitempty"`
+	SigningTime        *time.Time  `json:"signingTime,omitempty"`
+	Attributes         []Attribute `json:"attributes"` // authenticatedAttributes
+}
+
+// Attribute — одно значение атрибута (SignerInfo или SafeBag). Заполнено одно из Value, Time, VER, RoleValidity,
+// AlgorithmProtection; Raw — hex значения, которое не удалось расшифровать.
+type Attribute struct {
+	Name                string               `json:"name"` // VIN, roleName, signingTime, ... или OID
+	OID                 string               `json:"oid"`
+	Value               string               `json:"value,omitempty"`
+	Time                *time.Time           `json:"time,omitempty"`
+	VER                 *VER                 `json:"ver,omitempty"`
+	RoleValidity        *RoleValidity        `json:"roleValidity,omitempty"`
+	AlgorithmProtection *AlgorithmProtection `json:"algorithmProtection,omitempty"`
+	Raw                 string               `json:"raw,omitempty"`
+}
+
+// VER — версия реестра ATOM: метка времени выпуска и номер версии.
+type VER struct {
+	Timestamp time.Time `json:"timestamp"`
+	Version   int       `json:"version"`
+}
+
+// RoleValidity — roleValidityPeriod: период действия роли.
+type RoleValidity struct {
+	NotBefore time.Time `json:"notBefore"`
+	NotAfter  time.Time `json:"notAfter"`
+}
+
+// AlgorithmProtection — CMSAlgorithmProtection (RFC 6211); алгоритмы — OID.
+type AlgorithmProtection struct {
+	DigestAlgorithm    string `json:"digestAlgorithm"`
+	SignatureAlgorithm string `json:"signatureAlgorithm,omitempty"`
+	MACAlgorithm       string `json:"macAlgorithm,omitempty"`
+}
+
+// AuthSafe — ContentInfo из AuthenticatedSafe хранилища PKCS#12.
+type AuthSafe struct {
+	ContentType         string `json:"contentType"` // data, encryptedData или OID
+	Encrypted           bool   `json:"encrypted"`
+	EncryptionAlgorithm string `json:"encryptionAlgorithm,omitempty"`
+	SafeBags            int    `json:"safeBags"`
+	Error               string `json:"error,omitempty"`
+}
+
+// MAC — macData хранилища PKCS#12 и результат проверки.
+type MAC struct {
+	Algorithm  string `json:"algorithm"`
+	Iterations int    `json:"iterations"`
+	SaltLen    int    `json:"saltLen"`
+	Status     string `json:"status"` // ok, failed, unsupported
+}
+
+// SignerVerification — результат проверки одного подписанта (-verify).
+type SignerVerification struct {
+	SignerIndex   int           `json:"signerIndex"` // с 1
+	SignerSubject string        `json:"signerSubject,omitempty"`
+	OK            bool          `json:"ok"`
+	Checks        []VerifyCheck `json:"checks"`
+}
+
+// VerifyCheck — одна проверка подписанта.
+type VerifyCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// SchemaValidation — результат проверки DER по registry.asn1 (-validate).
+type SchemaValidation struct {
+	Module     string     `json:"module"`
+	Valid      bool       `json:"valid"`
+	Mismatches []Mismatch `json:"mismatches"`
+}
+
+// Mismatch — одно расхождение с registry.asn1.
+type Mismatch struct {
+	Message  string `json:"message"`
+	Offset   *int   `json:"offset,omitempty"` // смещение в файле, если известно
+	Path     string `json:"path,omitempty"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	Cause    string `json:"cause,omitempty"`
+}
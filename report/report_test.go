@@ -0,0 +1,240 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// schemaNode — подмножество JSON Schema, которое использует report.schema.json.
+type schemaNode struct {
+	Ref        string                 `json:"$ref"`
+	Defs       map[string]*schemaNode `json:"$defs"`
+	Type       interface{}            `json:"type"`
+	Required   []string               `json:"required"`
+	Properties map[string]*schemaNode `json:"properties"`
+	Items      *schemaNode            `json:"items"`
+	Const      interface{}            `json:"const"`
+	Enum       []interface{}          `json:"enum"`
+	Pattern    string                 `json:"pattern"`
+	Format     string                 `json:"format"`
+	Minimum    *float64               `json:"minimum"`
+}
+
+func loadSchema(t *testing.T) *schemaNode {
+	t.Helper()
+	var root schemaNode
+	if err := json.Unmarshal(Schema, &root); err != nil {
+		t.Fatalf("report.schema.json: %v", err)
+	}
+	return &root
+}
+
+// defTypes — определения схемы и соответствующие им типы Go.
+var defTypes = map[string]interface{}{
+	"report":              Report{},
+	"certificatesReport":  CertificatesReport{},
+	"certificate":         Certificate{},
+	"fingerprints":        Fingerprints{},
+	"safeBag":             SafeBag{},
+	"key":                 Key{},
+	"crl":                 CRL{},
+	"secret":              Secret{},
+	"signer":              Signer{},
+	"attribute":           Attribute{},
+	"ver":                 VER{},
+	"roleValidity":        RoleValidity{},
+	"algorithmProtection": AlgorithmProtection{},
+	"authSafe":            AuthSafe{},
+	"mac":                 MAC{},
+	"signerVerification":  SignerVerification{},
+	"verifyCheck":         VerifyCheck{},
+	"schemaValidation":    SchemaValidation{},
+	"mismatch":            Mismatch{},
+}
+
+// TestSchemaMatchesStructs проверяет, что свойства каждого объекта схемы совпадают с JSON-полями структуры,
+// а required — ровно поля без omitempty.
+func TestSchemaMatchesStructs(t *testing.T) {
+	root := loadSchema(t)
+	for name, v := range defTypes {
+		def := root.Defs[name]
+		if def == nil {
+			t.Errorf("нет определения %s", name)
+			continue
+		}
+		var props, required []string
+		rt := reflect.TypeOf(v)
+		for i := 0; i < rt.NumField(); i++ {
+			tag := strings.Split(rt.Field(i).Tag.Get("json"), ",")
+			props = append(props, tag[0])
+			if len(tag) == 1 {
+				required = append(required, tag[0])
+			}
+		}
+		var schemaProps []string
+		for p := range def.Properties {
+			schemaProps = append(schemaProps, p)
+		}
+		sort.Strings(props)
+		sort.Strings(required)
+		sort.Strings(schemaProps)
+		schemaRequired := append([]string(nil), def.Required...)
+		sort.Strings(schemaRequired)
+		if !reflect.DeepEqual(props, schemaProps) {
+			t.Errorf("%s: properties схемы %v, поля %s %v", name, schemaProps, rt.Name(), props)
+		}
+		if !reflect.DeepEqual(required, schemaRequired) {
+			t.Errorf("%s: required схемы %v, поля без omitempty %v", name, schemaRequired, required)
+		}
+	}
+	for name, def := range root.Defs {
+		if _, ok := defTypes[name]; !ok && def.Properties != nil {
+			t.Errorf("определение %s без структуры Go", name)
+		}
+	}
+	if c, ok := root.Defs["report"].Properties["schemaVersion"].Const.(float64); !ok || int(c) != SchemaVersion {
+		t.Errorf("schemaVersion в схеме: %v, SchemaVersion = %d", root.Defs["report"].Properties["schemaVersion"].Const, SchemaVersion)
+	}
+}
+
+// TestSampleReport проверяет пример отчёта owner-registry.json из корня репозитория по схеме
+// и что он без потерь читается в Report.
+func TestSampleReport(t *testing.T) {
+	data, err := os.ReadFile("../owner-registry.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	root := loadSchema(t)
+	for _, err := range validate(root, root, doc, "$") {
+		t.Error(err)
+	}
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.SchemaVersion != SchemaVersion || len(r.Signers) == 0 || r.Signers[0].VER == nil {
+		t.Errorf("Report: schemaVersion = %d, signers = %+v", r.SchemaVersion, r.Signers)
+	}
+	again, err := json.MarshalIndent(&r, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != strings.TrimSpace(string(data)) {
+		t.Error("owner-registry.json после Unmarshal/Marshal через Report отличается от исходного")
+	}
+}
+
+// TestValidate проверяет, что валидатор теста отвергает отчёт старого формата (VER строкой, без schemaVersion).
+func TestValidate(t *testing.T) {
+	root := loadSchema(t)
+	var doc interface{}
+	old := `{"kind":"atom-registry","pfxVersion":3,"contentType":"1.2.840.113549.1.7.2","certificates":[],"safeBags":[],
+		"signers":[{"digestAlgorithm":"2.16.840.1.101.3.4.2.1","signatureAlgorithm":"1.2.840.10045.4.3.2","ver":"2024-01-01 00:00:00:V100","attributes":[]}]}`
+	if err := json.Unmarshal([]byte(old), &doc); err != nil {
+		t.Fatal(err)
+	}
+	errs := validate(root, root, doc, "$")
+	if len(errs) != 2 {
+		t.Errorf("ошибки: %v, ожидается 2 (schemaVersion и signers[0].ver)", errs)
+	}
+}
+
+// validate проверяет значение v по узлу схемы n (подмножество draft 2020-12 из schemaNode).
+func validate(root, n *schemaNode, v interface{}, path string) []error {
+	if n.Ref != "" {
+		return validate(root, root.Defs[strings.TrimPrefix(n.Ref, "#/$defs/")], v, path)
+	}
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+	}
+	if n.Type != nil && !typeMatches(n.Type, v) {
+		fail("тип %T, ожидается %v", v, n.Type)
+		return errs
+	}
+	if n.Const != nil && !reflect.DeepEqual(n.Const, v) {
+		fail("%v, ожидается %v", v, n.Const)
+	}
+	if n.Enum != nil {
+		found := false
+		for _, e := range n.Enum {
+			found = found || reflect.DeepEqual(e, v)
+		}
+		if !found {
+			fail("%v не из %v", v, n.Enum)
+		}
+	}
+	if s, ok := v.(string); ok {
+		if n.Pattern != "" && !regexp.MustCompile(n.Pattern).MatchString(s) {
+			fail("%q не соответствует %s", s, n.Pattern)
+		}
+		if n.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, s); err != nil {
+				fail("%q — не date-time", s)
+			}
+		}
+	}
+	if f, ok := v.(float64); ok && n.Minimum != nil && f < *n.Minimum {
+		fail("%v < %v", f, *n.Minimum)
+	}
+	if obj, ok := v.(map[string]interface{}); ok {
+		for _, r := range n.Required {
+			if _, ok := obj[r]; !ok {
+				fail("нет обязательного поля %s", r)
+			}
+		}
+		for k, pv := range obj {
+			if p := n.Properties[k]; p != nil {
+				errs = append(errs, validate(root, p, pv, path+"."+k)...)
+			}
+		}
+	}
+	if arr, ok := v.([]interface{}); ok && n.Items != nil {
+		for i, item := range arr {
+			errs = append(errs, validate(root, n.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return errs
+}
+
+func typeMatches(want interface{}, v interface{}) bool {
+	if list, ok := want.([]interface{}); ok {
+		for _, w := range list {
+			if typeMatches(w, v) {
+				return true
+			}
+		}
+		return false
+	}
+	switch want {
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == float64(int64(f))
+	case "null":
+		return v == nil
+	}
+	return false
+}